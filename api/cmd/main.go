@@ -1,39 +1,24 @@
 package main
 
 import (
-	"database/sql"
-	"fmt"
+	"context"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
 
+	"news-portal-web/api/internal/database"
+	"news-portal-web/api/internal/mailer"
 	"news-portal-web/api/internal/server"
+	"news-portal-web/api/internal/storage"
 
 	"github.com/joho/godotenv"
-	_ "github.com/lib/pq"
 )
 
 func main() {
 	// Load .env file
 	_ = godotenv.Load()
 
-	// Get environment variables
-	dbURL := os.Getenv("DATABASE_URL")
-	if dbURL == "" {
-		// Build from components
-		dbHost := getEnvWithDefault("DB_HOST", "localhost")
-		dbPort := getEnvWithDefault("DB_PORT", "5432")
-		dbUser := getEnvWithDefault("DB_USER", "postgres")
-		dbPassword := os.Getenv("DB_PASSWORD")
-		dbName := getEnvWithDefault("DB_NAME", "Winnicode")
-
-		if dbPassword == "" {
-			log.Fatal("❌ Database password required: set DB_PASSWORD or DATABASE_URL")
-		}
-
-		dbURL = fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable",
-			dbUser, dbPassword, dbHost, dbPort, dbName)
-	}
-
 	jwtSecret := os.Getenv("JWT_SECRET_KEY")
 	if jwtSecret == "" {
 		log.Fatal("❌ JWT_SECRET_KEY environment variable is required")
@@ -42,24 +27,31 @@ func main() {
 	port := getEnvWithDefault("PORT", "8080")
 	env := getEnvWithDefault("ENV", "development")
 
-	// Connect to database
+	// Connect to database (DATABASE_URL or DB_HOST/DB_PORT/DB_USER/DB_PASSWORD/DB_NAME)
 	log.Printf("🔌 Connecting to database...")
-	db, err := sql.Open("postgres", dbURL)
+	db, err := database.NewConnection()
 	if err != nil {
 		log.Fatal("❌ Failed to connect to database:", err)
 	}
 	defer db.Close()
+	log.Printf("✅ Database connected successfully")
 
-	// Test database connection
-	if err := db.Ping(); err != nil {
-		log.Fatal("❌ Failed to ping database:", err)
+	// Upload storage (local disk or S3-compatible, see STORAGE_DRIVER)
+	store, err := storage.NewFromEnv()
+	if err != nil {
+		log.Fatal("❌ Failed to configure storage:", err)
 	}
-	log.Printf("✅ Database connected successfully")
+	log.Printf("🗂️  Storage driver: %s", getEnvWithDefault("STORAGE_DRIVER", "local"))
 
-	
+	// Outgoing mail (log, file or SMTP, see MAIL_DRIVER)
+	mail, err := mailer.NewFromEnv()
+	if err != nil {
+		log.Fatal("❌ Failed to configure mailer:", err)
+	}
+	log.Printf("📧 Mail driver: %s", getEnvWithDefault("MAIL_DRIVER", "log"))
 
 	// Create server instance
-	srv := server.NewServer(db, jwtSecret)
+	srv := server.NewServer(db, database.NewRepositories(db), jwtSecret, store, mail)
 
 	// Start server
 	log.Printf("🚀 Server starting on port %s", port)
@@ -68,9 +60,14 @@ func main() {
 	log.Printf("🏓 Ping: http://localhost:%s/ping", port)
 	log.Printf("🗄️  DB Test: http://localhost:%s/db-test", port)
 
-	if err := srv.Start(":" + port); err != nil {
+	// SIGINT/SIGTERM stop the background jobs and drain open requests
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := srv.Start(ctx, ":"+port); err != nil {
 		log.Fatal("❌ Server failed to start:", err)
 	}
+	log.Printf("👋 Server stopped")
 }
 
 func getEnvWithDefault(key, defaultValue string) string {
@@ -31,7 +31,7 @@ func AuthMiddleware(jwtManager *JWTManager) func(http.Handler) http.Handler {
 			}
 
 			token := parts[1]
-			claims, err := jwtManager.ValidateAccessToken(token)
+			claims, err := jwtManager.ValidateAccessToken(r.Context(), token)
 			if err != nil {
 				http.Error(w, `{"error":"Invalid or expired token"}`, http.StatusUnauthorized)
 				return
@@ -57,7 +57,7 @@ func OptionalAuthMiddleware(jwtManager *JWTManager) func(http.Handler) http.Hand
 				parts := strings.Split(authHeader, " ")
 				if len(parts) == 2 && parts[0] == "Bearer" {
 					token := parts[1]
-					claims, err := jwtManager.ValidateAccessToken(token)
+					claims, err := jwtManager.ValidateAccessToken(r.Context(), token)
 					if err == nil {
 						ctx := context.WithValue(r.Context(), ClaimsKey, claims)
 						ctx = context.WithValue(ctx, UserIDKey, claims.UserID)
@@ -72,30 +72,6 @@ func OptionalAuthMiddleware(jwtManager *JWTManager) func(http.Handler) http.Hand
 	}
 }
 
-// AdminOnly middleware - only allows admin role
-func AdminOnly(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		role, ok := r.Context().Value(UserRoleKey).(string)
-		if !ok || role != "admin" {
-			http.Error(w, `{"error":"Admin access required"}`, http.StatusForbidden)
-			return
-		}
-		next.ServeHTTP(w, r)
-	})
-}
-
-// EditorOrAdmin middleware - allows editor or admin role
-func EditorOrAdmin(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		role, ok := r.Context().Value(UserRoleKey).(string)
-		if !ok || (role != "editor" && role != "admin") {
-			http.Error(w, `{"error":"Editor or Admin access required"}`, http.StatusForbidden)
-			return
-		}
-		next.ServeHTTP(w, r)
-	})
-}
-
 // GetUserIDFromContext extracts user ID from context
 func GetUserIDFromContext(ctx context.Context) (int, bool) {
 	userID, ok := ctx.Value(UserIDKey).(int)
@@ -0,0 +1,158 @@
+package auth
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Permissions known to the code. The role -> permission mapping lives in
+// the role_permissions table and is managed through the admin API.
+const (
+	PermEditorAccess     = "editor.access"
+	PermArticleCreate    = "article.create"
+	PermArticleEditOwn   = "article.edit.own"
+	PermArticleEditAny   = "article.edit.any"
+	PermArticleDeleteOwn = "article.delete.own"
+	PermArticleDeleteAny = "article.delete.any"
+	PermArticleSubmit    = "article.submit"
+	PermArticleReview    = "article.review"
+	PermArticlePublish   = "article.publish"
+	PermMediaUpload      = "media.upload"
+	PermMediaEditOwn     = "media.edit.own"
+	PermMediaEditAny     = "media.edit.any"
+	PermMediaDeleteOwn   = "media.delete.own"
+	PermMediaDeleteAny   = "media.delete.any"
+	PermCommentModerate  = "comment.moderate"
+	PermCategoryManage   = "category.manage"
+	PermTagManage        = "tag.manage"
+	PermUserManage       = "user.manage"
+	PermRoleManage       = "role.manage"
+	PermAuditView        = "audit.view"
+	PermTrashManage      = "trash.manage"
+)
+
+// PermissionLoader returns every role with its permissions. Roles without
+// permissions must be present with an empty list.
+type PermissionLoader func(ctx context.Context) (map[string][]string, error)
+
+// PermissionCache resolves role permissions from a loader and keeps the
+// result for ttl, so a permission check does not hit the database on every
+// request. Changes made on another replica show up after at most ttl.
+type PermissionCache struct {
+	load PermissionLoader
+	ttl  time.Duration
+
+	mu       sync.RWMutex
+	roles    map[string]map[string]bool
+	loadedAt time.Time
+}
+
+// NewPermissionCache creates a cache that reloads after ttl
+func NewPermissionCache(load PermissionLoader, ttl time.Duration) *PermissionCache {
+	return &PermissionCache{load: load, ttl: ttl}
+}
+
+// snapshot returns the cached mapping, reloading it when it is stale
+func (c *PermissionCache) snapshot(ctx context.Context) (map[string]map[string]bool, error) {
+	c.mu.RLock()
+	roles, fresh := c.roles, c.roles != nil && time.Since(c.loadedAt) < c.ttl
+	c.mu.RUnlock()
+	if fresh {
+		return roles, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.roles != nil && time.Since(c.loadedAt) < c.ttl {
+		return c.roles, nil
+	}
+
+	loaded, err := c.load(ctx)
+	if err != nil {
+		// Keep serving the last known mapping rather than locking everyone out
+		if c.roles != nil {
+			log.Printf("⚠️  Reloading permissions failed, using cached: %v", err)
+			return c.roles, nil
+		}
+		return nil, err
+	}
+
+	roles = make(map[string]map[string]bool, len(loaded))
+	for role, perms := range loaded {
+		set := make(map[string]bool, len(perms))
+		for _, p := range perms {
+			set[p] = true
+		}
+		roles[role] = set
+	}
+	c.roles, c.loadedAt = roles, time.Now()
+	return roles, nil
+}
+
+// HasPermission reports whether the role grants the permission
+func (c *PermissionCache) HasPermission(ctx context.Context, role, perm string) (bool, error) {
+	roles, err := c.snapshot(ctx)
+	if err != nil {
+		return false, err
+	}
+	return roles[role][perm], nil
+}
+
+// Permissions returns the permissions granted to the role
+func (c *PermissionCache) Permissions(ctx context.Context, role string) ([]string, error) {
+	roles, err := c.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	perms := make([]string, 0, len(roles[role]))
+	for p := range roles[role] {
+		perms = append(perms, p)
+	}
+	return perms, nil
+}
+
+// RoleExists reports whether the role is defined
+func (c *PermissionCache) RoleExists(ctx context.Context, role string) (bool, error) {
+	roles, err := c.snapshot(ctx)
+	if err != nil {
+		return false, err
+	}
+	_, ok := roles[role]
+	return ok, nil
+}
+
+// Invalidate drops the cached mapping so the next check reloads it
+func (c *PermissionCache) Invalidate() {
+	c.mu.Lock()
+	c.roles = nil
+	c.mu.Unlock()
+}
+
+// RequirePermission middleware - only allows roles granting perm. Must run
+// after AuthMiddleware.
+func RequirePermission(perms *PermissionCache, perm string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			role, ok := r.Context().Value(UserRoleKey).(string)
+			if !ok {
+				http.Error(w, `{"error":"Insufficient permission"}`, http.StatusForbidden)
+				return
+			}
+
+			allowed, err := perms.HasPermission(r.Context(), role, perm)
+			if err != nil {
+				log.Printf("⚠️  Permission check failed: %v", err)
+				http.Error(w, `{"error":"Permission check failed"}`, http.StatusInternalServerError)
+				return
+			}
+			if !allowed {
+				http.Error(w, `{"error":"Insufficient permission: `+perm+`"}`, http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
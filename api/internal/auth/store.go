@@ -0,0 +1,182 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	ErrTokenRevoked         = errors.New("token has been revoked")
+	ErrRefreshTokenNotFound = errors.New("refresh token not found")
+	ErrRefreshTokenReused   = errors.New("refresh token reuse detected")
+)
+
+// RefreshToken is the server-side record of an issued refresh token.
+// Tokens issued by rotation share the FamilyID of the login that started them.
+type RefreshToken struct {
+	JTI       string
+	FamilyID  string
+	UserID    int
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+}
+
+// RevocationStore persists revoked access tokens and issued refresh tokens
+// so logouts survive restarts and are shared between replicas
+type RevocationStore interface {
+	// RevokeToken marks a token ID as revoked until it expires
+	RevokeToken(ctx context.Context, jti string, userID int, expiresAt time.Time) error
+
+	// IsTokenRevoked reports whether a token ID has been revoked, either by
+	// itself or because it was issued before a RevokeUserTokens cutoff
+	IsTokenRevoked(ctx context.Context, jti string, userID int, issuedAt time.Time) (bool, error)
+
+	// RevokeUserTokens revokes every refresh token of a user and every
+	// access token issued before the cutoff. The cutoff is kept until
+	// expiresAt, when all access tokens it covers have expired.
+	RevokeUserTokens(ctx context.Context, userID int, before, expiresAt time.Time) error
+
+	// SaveRefreshToken records a newly issued refresh token
+	SaveRefreshToken(ctx context.Context, token *RefreshToken) error
+
+	// UseRefreshToken atomically marks a refresh token as used. It returns
+	// ErrRefreshTokenReused (together with the record) when the token was
+	// already used and ErrTokenRevoked when its family has been revoked.
+	UseRefreshToken(ctx context.Context, jti string) (*RefreshToken, error)
+
+	// RevokeTokenFamily revokes every refresh token of a rotation family
+	RevokeTokenFamily(ctx context.Context, familyID string) error
+
+	// CleanupExpired removes entries whose tokens have expired
+	CleanupExpired(ctx context.Context) error
+}
+
+// ========================================
+// IN-MEMORY STORE
+// ========================================
+
+// MemoryRevocationStore keeps revocations in process memory.
+// Only suitable for development and single-instance deployments.
+type MemoryRevocationStore struct {
+	mu            sync.RWMutex
+	revokedTokens map[string]time.Time
+	refreshTokens map[string]*RefreshToken
+	userCutoffs   map[int]userCutoff
+}
+
+type userCutoff struct {
+	before    time.Time
+	expiresAt time.Time
+}
+
+// NewMemoryRevocationStore creates an empty in-memory store
+func NewMemoryRevocationStore() *MemoryRevocationStore {
+	return &MemoryRevocationStore{
+		revokedTokens: make(map[string]time.Time),
+		refreshTokens: make(map[string]*RefreshToken),
+		userCutoffs:   make(map[int]userCutoff),
+	}
+}
+
+func (s *MemoryRevocationStore) RevokeToken(ctx context.Context, jti string, userID int, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.revokedTokens[jti] = expiresAt
+	return nil
+}
+
+func (s *MemoryRevocationStore) IsTokenRevoked(ctx context.Context, jti string, userID int, issuedAt time.Time) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if _, revoked := s.revokedTokens[jti]; revoked {
+		return true, nil
+	}
+	cutoff, ok := s.userCutoffs[userID]
+	return ok && issuedAt.Before(cutoff.before), nil
+}
+
+func (s *MemoryRevocationStore) RevokeUserTokens(ctx context.Context, userID int, before, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.userCutoffs[userID] = userCutoff{before: before, expiresAt: expiresAt}
+
+	now := time.Now()
+	for _, record := range s.refreshTokens {
+		if record.UserID == userID && record.RevokedAt == nil {
+			record.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+func (s *MemoryRevocationStore) SaveRefreshToken(ctx context.Context, token *RefreshToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record := *token
+	s.refreshTokens[token.JTI] = &record
+	return nil
+}
+
+func (s *MemoryRevocationStore) UseRefreshToken(ctx context.Context, jti string) (*RefreshToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, ok := s.refreshTokens[jti]
+	if !ok {
+		return nil, ErrRefreshTokenNotFound
+	}
+
+	result := *record
+	if record.RevokedAt != nil {
+		return &result, ErrTokenRevoked
+	}
+	if record.UsedAt != nil {
+		return &result, ErrRefreshTokenReused
+	}
+
+	now := time.Now()
+	record.UsedAt = &now
+	result.UsedAt = &now
+	return &result, nil
+}
+
+func (s *MemoryRevocationStore) RevokeTokenFamily(ctx context.Context, familyID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for _, record := range s.refreshTokens {
+		if record.FamilyID == familyID && record.RevokedAt == nil {
+			record.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+func (s *MemoryRevocationStore) CleanupExpired(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for jti, expiresAt := range s.revokedTokens {
+		if now.After(expiresAt) {
+			delete(s.revokedTokens, jti)
+		}
+	}
+	for jti, record := range s.refreshTokens {
+		if now.After(record.ExpiresAt) {
+			delete(s.refreshTokens, jti)
+		}
+	}
+	for userID, cutoff := range s.userCutoffs {
+		if now.After(cutoff.expiresAt) {
+			delete(s.userCutoffs, userID)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// PostgresRevocationStore stores revocations in the revoked_tokens and
+// refresh_tokens tables so every replica sees the same state
+type PostgresRevocationStore struct {
+	db *sql.DB
+}
+
+// NewPostgresRevocationStore creates a store backed by PostgreSQL
+func NewPostgresRevocationStore(db *sql.DB) *PostgresRevocationStore {
+	return &PostgresRevocationStore{db: db}
+}
+
+func (s *PostgresRevocationStore) RevokeToken(ctx context.Context, jti string, userID int, expiresAt time.Time) error {
+	query := `
+        INSERT INTO revoked_tokens (jti, user_id, expires_at)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (jti) DO NOTHING
+    `
+
+	var uid *int
+	if userID > 0 {
+		uid = &userID
+	}
+
+	if _, err := s.db.ExecContext(ctx, query, jti, uid, expiresAt); err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+	return nil
+}
+
+func (s *PostgresRevocationStore) IsTokenRevoked(ctx context.Context, jti string, userID int, issuedAt time.Time) (bool, error) {
+	query := `
+        SELECT EXISTS(SELECT 1 FROM revoked_tokens WHERE jti = $1)
+            OR EXISTS(SELECT 1 FROM user_token_revocations WHERE user_id = $2 AND revoked_before > $3)
+    `
+	var revoked bool
+	if err := s.db.QueryRowContext(ctx, query, jti, userID, issuedAt).Scan(&revoked); err != nil {
+		return false, fmt.Errorf("failed to check token revocation: %w", err)
+	}
+	return revoked, nil
+}
+
+func (s *PostgresRevocationStore) RevokeUserTokens(ctx context.Context, userID int, before, expiresAt time.Time) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+        INSERT INTO user_token_revocations (user_id, revoked_before, expires_at)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (user_id) DO UPDATE
+        SET revoked_before = GREATEST(user_token_revocations.revoked_before, EXCLUDED.revoked_before),
+            expires_at = GREATEST(user_token_revocations.expires_at, EXCLUDED.expires_at)
+    `, userID, before, expiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to revoke user tokens: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        UPDATE refresh_tokens
+        SET revoked_at = NOW()
+        WHERE user_id = $1 AND revoked_at IS NULL
+    `, userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func (s *PostgresRevocationStore) SaveRefreshToken(ctx context.Context, token *RefreshToken) error {
+	query := `
+        INSERT INTO refresh_tokens (jti, family_id, user_id, issued_at, expires_at)
+        VALUES ($1, $2, $3, $4, $5)
+    `
+
+	_, err := s.db.ExecContext(ctx, query,
+		token.JTI, token.FamilyID, token.UserID, token.IssuedAt, token.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to save refresh token: %w", err)
+	}
+	return nil
+}
+
+func (s *PostgresRevocationStore) UseRefreshToken(ctx context.Context, jti string) (*RefreshToken, error) {
+	// Conditional update so only one concurrent request can consume the token
+	query := `
+        UPDATE refresh_tokens
+        SET used_at = NOW()
+        WHERE jti = $1 AND used_at IS NULL AND revoked_at IS NULL
+        RETURNING jti, family_id, user_id, issued_at, expires_at, used_at, revoked_at
+    `
+
+	record, err := scanRefreshToken(s.db.QueryRowContext(ctx, query, jti))
+	if err == nil {
+		return record, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to use refresh token: %w", err)
+	}
+
+	// Nothing updated: find out whether the token is unknown, revoked or reused
+	query = `
+        SELECT jti, family_id, user_id, issued_at, expires_at, used_at, revoked_at
+        FROM refresh_tokens
+        WHERE jti = $1
+    `
+
+	record, err = scanRefreshToken(s.db.QueryRowContext(ctx, query, jti))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrRefreshTokenNotFound
+		}
+		return nil, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+
+	if record.RevokedAt != nil {
+		return record, ErrTokenRevoked
+	}
+	return record, ErrRefreshTokenReused
+}
+
+func (s *PostgresRevocationStore) RevokeTokenFamily(ctx context.Context, familyID string) error {
+	query := `
+        UPDATE refresh_tokens
+        SET revoked_at = NOW()
+        WHERE family_id = $1 AND revoked_at IS NULL
+    `
+
+	if _, err := s.db.ExecContext(ctx, query, familyID); err != nil {
+		return fmt.Errorf("failed to revoke token family: %w", err)
+	}
+	return nil
+}
+
+func (s *PostgresRevocationStore) CleanupExpired(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM revoked_tokens WHERE expires_at < NOW()`); err != nil {
+		return fmt.Errorf("failed to cleanup revoked tokens: %w", err)
+	}
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM refresh_tokens WHERE expires_at < NOW()`); err != nil {
+		return fmt.Errorf("failed to cleanup refresh tokens: %w", err)
+	}
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM user_token_revocations WHERE expires_at < NOW()`); err != nil {
+		return fmt.Errorf("failed to cleanup user token revocations: %w", err)
+	}
+	return nil
+}
+
+func scanRefreshToken(row *sql.Row) (*RefreshToken, error) {
+	var t RefreshToken
+	err := row.Scan(&t.JTI, &t.FamilyID, &t.UserID, &t.IssuedAt, &t.ExpiresAt, &t.UsedAt, &t.RevokedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
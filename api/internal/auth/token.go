@@ -1,13 +1,21 @@
 package auth
 
 import (
+    "context"
+    "crypto/rand"
+    "encoding/hex"
     "errors"
-    "sync"
+    "strconv"
     "time"
 
     "github.com/golang-jwt/jwt/v5"
 )
 
+const (
+    tokenTypeAccess  = "access"
+    tokenTypeRefresh = "refresh"
+)
+
 // TokenPair holds access and refresh tokens
 type TokenPair struct {
     AccessToken  string `json:"access_token"`
@@ -17,10 +25,19 @@ type TokenPair struct {
 
 // Claims represents JWT claims
 type Claims struct {
-    UserID   int    `json:"user_id"`
-    Username string `json:"username"`
-    Email    string `json:"email"`
-    Role     string `json:"role"`
+    UserID    int    `json:"user_id"`
+    Username  string `json:"username"`
+    Email     string `json:"email"`
+    Role      string `json:"role"`
+    TokenType string `json:"typ"`
+    jwt.RegisteredClaims
+}
+
+// RefreshClaims represents refresh token claims.
+// ID (jti) identifies the token, FamilyID ties rotated tokens to one login.
+type RefreshClaims struct {
+    FamilyID  string `json:"fid"`
+    TokenType string `json:"typ"`
     jwt.RegisteredClaims
 }
 
@@ -29,32 +46,61 @@ type JWTManager struct {
     secretKey       []byte
     accessTokenTTL  time.Duration
     refreshTokenTTL time.Duration
-    revokedTokens   map[string]time.Time
-    mu              sync.RWMutex
+    store           RevocationStore
 }
 
-// NewJWTManager creates a new JWT manager
+// NewJWTManager creates a new JWT manager with an in-memory revocation store
 func NewJWTManager(secretKey string) *JWTManager {
+    return NewJWTManagerWithStore(secretKey, NewMemoryRevocationStore())
+}
+
+// NewJWTManagerWithStore creates a new JWT manager using the given revocation store
+func NewJWTManagerWithStore(secretKey string, store RevocationStore) *JWTManager {
     return &JWTManager{
         secretKey:       []byte(secretKey),
         accessTokenTTL:  60 * time.Minute,
         refreshTokenTTL: 7 * 24 * time.Hour,
-        revokedTokens:   make(map[string]time.Time),
+        store:           store,
+    }
+}
+
+// GenerateTokenPair generates access and refresh tokens for a new login
+func (m *JWTManager) GenerateTokenPair(ctx context.Context, userID int, username, email, role string) (*TokenPair, error) {
+    familyID, err := newTokenID()
+    if err != nil {
+        return nil, err
     }
+    return m.generateTokenPair(ctx, familyID, userID, username, email, role)
+}
+
+// RotateTokenPair generates a new token pair that continues an existing refresh token family
+func (m *JWTManager) RotateTokenPair(ctx context.Context, familyID string, userID int, username, email, role string) (*TokenPair, error) {
+    return m.generateTokenPair(ctx, familyID, userID, username, email, role)
 }
 
-// GenerateTokenPair generates access and refresh tokens
-func (m *JWTManager) GenerateTokenPair(userID int, username, email, role string) (*TokenPair, error) {
+func (m *JWTManager) generateTokenPair(ctx context.Context, familyID string, userID int, username, email, role string) (*TokenPair, error) {
     now := time.Now()
+    subject := strconv.Itoa(userID)
+
+    accessID, err := newTokenID()
+    if err != nil {
+        return nil, err
+    }
+    refreshID, err := newTokenID()
+    if err != nil {
+        return nil, err
+    }
 
     // Access token
     accessClaims := Claims{
-        UserID:   userID,
-        Username: username,
-        Email:    email,
-        Role:     role,
+        UserID:    userID,
+        Username:  username,
+        Email:     email,
+        Role:      role,
+        TokenType: tokenTypeAccess,
         RegisteredClaims: jwt.RegisteredClaims{
-            Subject:   string(rune(userID)),
+            ID:        accessID,
+            Subject:   subject,
             IssuedAt:  jwt.NewNumericDate(now),
             ExpiresAt: jwt.NewNumericDate(now.Add(m.accessTokenTTL)),
         },
@@ -67,10 +113,16 @@ func (m *JWTManager) GenerateTokenPair(userID int, username, email, role string)
     }
 
     // Refresh token
-    refreshClaims := jwt.RegisteredClaims{
-        Subject:   string(rune(userID)),
-        IssuedAt:  jwt.NewNumericDate(now),
-        ExpiresAt: jwt.NewNumericDate(now.Add(m.refreshTokenTTL)),
+    refreshExpiresAt := now.Add(m.refreshTokenTTL)
+    refreshClaims := RefreshClaims{
+        FamilyID:  familyID,
+        TokenType: tokenTypeRefresh,
+        RegisteredClaims: jwt.RegisteredClaims{
+            ID:        refreshID,
+            Subject:   subject,
+            IssuedAt:  jwt.NewNumericDate(now),
+            ExpiresAt: jwt.NewNumericDate(refreshExpiresAt),
+        },
     }
 
     refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims)
@@ -79,6 +131,18 @@ func (m *JWTManager) GenerateTokenPair(userID int, username, email, role string)
         return nil, err
     }
 
+    // Record the refresh token so it can be rotated and revoked later
+    err = m.store.SaveRefreshToken(ctx, &RefreshToken{
+        JTI:       refreshID,
+        FamilyID:  familyID,
+        UserID:    userID,
+        IssuedAt:  now,
+        ExpiresAt: refreshExpiresAt,
+    })
+    if err != nil {
+        return nil, err
+    }
+
     return &TokenPair{
         AccessToken:  accessTokenString,
         RefreshToken: refreshTokenString,
@@ -86,72 +150,129 @@ func (m *JWTManager) GenerateTokenPair(userID int, username, email, role string)
     }, nil
 }
 
-// ValidateAccessToken validates an access token
-func (m *JWTManager) ValidateAccessToken(tokenString string) (*Claims, error) {
-    // Check if token is revoked
-    m.mu.RLock()
-    if _, revoked := m.revokedTokens[tokenString]; revoked {
-        m.mu.RUnlock()
-        return nil, errors.New("token has been revoked")
+// ValidateAccessToken validates an access token and checks it has not been revoked
+func (m *JWTManager) ValidateAccessToken(ctx context.Context, tokenString string) (*Claims, error) {
+    claims, err := m.parseAccessToken(tokenString)
+    if err != nil {
+        return nil, err
+    }
+
+    var issuedAt time.Time
+    if claims.IssuedAt != nil {
+        issuedAt = claims.IssuedAt.Time
     }
-    m.mu.RUnlock()
 
-    token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-        if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-            return nil, errors.New("unexpected signing method")
-        }
-        return m.secretKey, nil
-    })
+    revoked, err := m.store.IsTokenRevoked(ctx, claims.ID, claims.UserID, issuedAt)
+    if err != nil {
+        return nil, err
+    }
+    if revoked {
+        return nil, ErrTokenRevoked
+    }
 
+    return claims, nil
+}
+
+func (m *JWTManager) parseAccessToken(tokenString string) (*Claims, error) {
+    token, err := jwt.ParseWithClaims(tokenString, &Claims{}, m.keyFunc)
     if err != nil {
         return nil, err
     }
 
-    if claims, ok := token.Claims.(*Claims); ok && token.Valid {
-        return claims, nil
+    claims, ok := token.Claims.(*Claims)
+    if !ok || !token.Valid || claims.TokenType != tokenTypeAccess || claims.ID == "" {
+        return nil, errors.New("invalid token")
     }
 
-    return nil, errors.New("invalid token")
+    return claims, nil
 }
 
-// ValidateRefreshToken validates a refresh token
-func (m *JWTManager) ValidateRefreshToken(tokenString string) (*jwt.RegisteredClaims, error) {
-    token, err := jwt.ParseWithClaims(tokenString, &jwt.RegisteredClaims{}, func(token *jwt.Token) (interface{}, error) {
-        if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-            return nil, errors.New("unexpected signing method")
-        }
-        return m.secretKey, nil
-    })
+// ValidateRefreshToken validates the signature and expiry of a refresh token.
+// It does not consume the token; use ConsumeRefreshToken for rotation.
+func (m *JWTManager) ValidateRefreshToken(tokenString string) (*RefreshClaims, error) {
+    token, err := jwt.ParseWithClaims(tokenString, &RefreshClaims{}, m.keyFunc)
+    if err != nil {
+        return nil, err
+    }
 
+    claims, ok := token.Claims.(*RefreshClaims)
+    if !ok || !token.Valid || claims.TokenType != tokenTypeRefresh || claims.ID == "" || claims.FamilyID == "" {
+        return nil, errors.New("invalid refresh token")
+    }
+
+    return claims, nil
+}
+
+// ConsumeRefreshToken validates a refresh token and marks it as used so it
+// cannot be presented again. Presenting an already used token is treated as
+// token theft: the whole family is revoked and ErrRefreshTokenReused returned.
+func (m *JWTManager) ConsumeRefreshToken(ctx context.Context, tokenString string) (*RefreshClaims, error) {
+    claims, err := m.ValidateRefreshToken(tokenString)
     if err != nil {
         return nil, err
     }
 
-    if claims, ok := token.Claims.(*jwt.RegisteredClaims); ok && token.Valid {
-        return claims, nil
+    record, err := m.store.UseRefreshToken(ctx, claims.ID)
+    if err != nil {
+        if errors.Is(err, ErrRefreshTokenReused) && record != nil {
+            if revokeErr := m.store.RevokeTokenFamily(ctx, record.FamilyID); revokeErr != nil {
+                return nil, revokeErr
+            }
+        }
+        return nil, err
     }
 
-    return nil, errors.New("invalid refresh token")
+    if record.FamilyID != claims.FamilyID {
+        return nil, errors.New("invalid refresh token")
+    }
+
+    return claims, nil
 }
 
-// RevokeToken adds a token to the revoked list
-func (m *JWTManager) RevokeToken(tokenString string) error {
-    m.mu.Lock()
-    defer m.mu.Unlock()
-    m.revokedTokens[tokenString] = time.Now()
-    return nil
+// RevokeToken revokes an access token until it expires
+func (m *JWTManager) RevokeToken(ctx context.Context, tokenString string) error {
+    claims, err := m.parseAccessToken(tokenString)
+    if err != nil {
+        return err
+    }
+    return m.store.RevokeToken(ctx, claims.ID, claims.UserID, claims.ExpiresAt.Time)
 }
 
-// CleanupRevokedTokens removes expired tokens from the revoked list
-func (m *JWTManager) CleanupRevokedTokens() {
-    m.mu.Lock()
-    defer m.mu.Unlock()
+// RevokeRefreshToken revokes the family of the given refresh token (used on logout)
+func (m *JWTManager) RevokeRefreshToken(ctx context.Context, tokenString string) error {
+    claims, err := m.ValidateRefreshToken(tokenString)
+    if err != nil {
+        return err
+    }
+    return m.store.RevokeTokenFamily(ctx, claims.FamilyID)
+}
 
-    now := time.Now()
-    for token, revokedAt := range m.revokedTokens {
-        // Remove tokens revoked more than refresh token TTL ago
-        if now.Sub(revokedAt) > m.refreshTokenTTL {
-            delete(m.revokedTokens, token)
-        }
+// RevokeAllUserTokens ends every session of a user: all refresh tokens are
+// revoked and access tokens issued until now stop validating
+func (m *JWTManager) RevokeAllUserTokens(ctx context.Context, userID int) error {
+    // iat has second precision, so the cutoff is rounded up to cover
+    // tokens issued earlier in the current second
+    before := time.Now().Truncate(time.Second).Add(time.Second)
+    return m.store.RevokeUserTokens(ctx, userID, before, before.Add(m.accessTokenTTL))
+}
+
+// CleanupRevokedTokens removes expired tokens from the revocation store
+func (m *JWTManager) CleanupRevokedTokens(ctx context.Context) error {
+    return m.store.CleanupExpired(ctx)
+}
+
+func (m *JWTManager) keyFunc(token *jwt.Token) (interface{}, error) {
+    if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+        return nil, errors.New("unexpected signing method")
     }
-}
\ No newline at end of file
+    return m.secretKey, nil
+}
+
+// newTokenID returns a random identifier for jti and family claims
+func newTokenID() (string, error) {
+    b := make([]byte, 16)
+    if _, err := rand.Read(b); err != nil {
+        return "", err
+    }
+    return hex.EncodeToString(b), nil
+}
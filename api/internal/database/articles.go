@@ -1,11 +1,17 @@
 package database
 
 import (
+	"context"
 	"database/sql"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/lib/pq"
 )
 
 type Article struct {
@@ -24,6 +30,8 @@ type Article struct {
 	// Related data (populated separately)
 	Kategori []Category `json:"kategori,omitempty"`
 	Tags     []Tag      `json:"tags,omitempty"`
+	// Responsive variants of gambar_utama, for building srcset
+	GambarUtamaVariants ImageVariants `json:"gambar_utama_variants,omitempty"`
 }
 
 type ArticleInput struct {
@@ -48,6 +56,64 @@ type ArticleFilter struct {
 	Search       string
 	Limit        int
 	Offset       int
+	Cursor       *ArticleCursor // keyset position, only used by the published feed
+}
+
+// IsFeed reports whether the filter lists the published feed, which is
+// ordered by (tanggal_publikasi, artikel_id) and supports cursor paging
+func (f ArticleFilter) IsFeed() bool {
+	return f.Status == "published" && f.Search == ""
+}
+
+// IsSchedule reports whether the filter lists scheduled articles, which are
+// ordered by the time they go live, soonest first
+func (f ArticleFilter) IsSchedule() bool {
+	return f.Status == "scheduled" && f.Search == ""
+}
+
+// ArticleCursor marks the last article of a feed page. The next page starts
+// right after it.
+type ArticleCursor struct {
+	TanggalPublikasi time.Time
+	ArtikelID        int
+}
+
+// Encode returns the cursor as an opaque URL-safe string
+func (c ArticleCursor) Encode() string {
+	raw := c.TanggalPublikasi.UTC().Format(time.RFC3339Nano) + "|" + strconv.Itoa(c.ArtikelID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeArticleCursor parses a cursor produced by ArticleCursor.Encode
+func DecodeArticleCursor(s string) (*ArticleCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+
+	ts, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return nil, errors.New("invalid cursor")
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+	artikelID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+
+	return &ArticleCursor{TanggalPublikasi: t, ArtikelID: artikelID}, nil
+}
+
+// CursorAfter returns the cursor pointing after the given article
+func CursorAfter(a Article) *ArticleCursor {
+	if a.TanggalPublikasi == nil {
+		return nil
+	}
+	return &ArticleCursor{TanggalPublikasi: *a.TanggalPublikasi, ArtikelID: a.ArtikelID}
 }
 
 // GenerateSlug creates URL-friendly slug from title
@@ -72,15 +138,16 @@ func GenerateSlug(title string) string {
 	return slug
 }
 
-// EnsureUniqueSlug checks if slug exists and appends number if needed
-func EnsureUniqueSlug(db *sql.DB, slug string, excludeID int) (string, error) {
+// EnsureUniqueSlug checks if slug exists and appends number if needed.
+// Articles in the trash keep their slug until they are purged.
+func EnsureUniqueSlug(ctx context.Context, db *sql.DB, slug string, excludeID int) (string, error) {
 	baseSlug := slug
 	counter := 1
 
 	for {
 		var exists bool
 		query := `SELECT EXISTS(SELECT 1 FROM articles WHERE slug = $1 AND artikel_id != $2)`
-		err := db.QueryRow(query, slug, excludeID).Scan(&exists)
+		err := db.QueryRowContext(ctx, query, slug, excludeID).Scan(&exists)
 		if err != nil {
 			return "", err
 		}
@@ -94,69 +161,90 @@ func EnsureUniqueSlug(db *sql.DB, slug string, excludeID int) (string, error) {
 	}
 }
 
-// GetAllArticles retrieves articles with optional filters
-func GetAllArticles(db *sql.DB, filter ArticleFilter) ([]Article, error) {
-	query := `
-        SELECT DISTINCT a.artikel_id, a.judul, a.slug, a.konten, a.excerpt, 
-               a.gambar_utama, a.penulis, a.status, a.user_id, 
-               a.tanggal_publikasi, a.tanggal_dibuat, a.tanggal_diperbarui
-        FROM articles a
-        LEFT JOIN artikel_kategori ak ON a.artikel_id = ak.artikel_id
-        LEFT JOIN artikel_tag at ON a.artikel_id = at.artikel_id
-    `
-
-	// NEW: Join categories table if filtering by name
-	if filter.KategoriName != "" {
-		query += `
-        LEFT JOIN categories c ON ak.kategori_id = c.kategori_id
-        `
-	}
-
-	query += `
-        WHERE 1=1
-    `
-
+// articleFilterWhere builds the WHERE clause shared by GetAllArticles and
+// CountArticles. Cursor, limit and offset are not part of it.
+func articleFilterWhere(filter ArticleFilter) (string, []interface{}) {
+	where := " WHERE a.deleted_at IS NULL"
 	args := []interface{}{}
 	argCount := 0
 
 	if filter.Status != "" {
 		argCount++
-		query += fmt.Sprintf(" AND a.status = $%d", argCount)
+		where += fmt.Sprintf(" AND a.status = $%d", argCount)
 		args = append(args, filter.Status)
 	}
 
 	if filter.KategoriID > 0 {
 		argCount++
-		query += fmt.Sprintf(" AND ak.kategori_id = $%d", argCount)
+		where += fmt.Sprintf(` AND EXISTS (
+            SELECT 1 FROM artikel_kategori ak
+            WHERE ak.artikel_id = a.artikel_id AND ak.kategori_id = $%d)`, argCount)
 		args = append(args, filter.KategoriID)
 	}
 
-	// NEW: Filter by category name
+	// Filter by category name
 	if filter.KategoriName != "" {
 		argCount++
-		query += fmt.Sprintf(" AND c.nama_kategori = $%d", argCount)
+		where += fmt.Sprintf(` AND EXISTS (
+            SELECT 1 FROM artikel_kategori ak
+            JOIN categories c ON ak.kategori_id = c.kategori_id
+            WHERE ak.artikel_id = a.artikel_id AND c.nama_kategori = $%d AND c.deleted_at IS NULL)`, argCount)
 		args = append(args, filter.KategoriName)
 	}
 
 	if filter.TagID > 0 {
 		argCount++
-		query += fmt.Sprintf(" AND at.tag_id = $%d", argCount)
+		where += fmt.Sprintf(` AND EXISTS (
+            SELECT 1 FROM artikel_tag at
+            WHERE at.artikel_id = a.artikel_id AND at.tag_id = $%d)`, argCount)
 		args = append(args, filter.TagID)
 	}
 
 	if filter.UserID > 0 {
 		argCount++
-		query += fmt.Sprintf(" AND a.user_id = $%d", argCount)
+		where += fmt.Sprintf(" AND a.user_id = $%d", argCount)
 		args = append(args, filter.UserID)
 	}
 
 	if filter.Search != "" {
 		argCount++
-		query += fmt.Sprintf(" AND (a.judul ILIKE $%d OR a.konten ILIKE $%d)", argCount, argCount)
-		args = append(args, "%"+filter.Search+"%")
+		where += fmt.Sprintf(" AND a.search_vector @@ websearch_to_tsquery('news_search', $%d)", argCount)
+		args = append(args, filter.Search)
 	}
 
-	query += " ORDER BY a.tanggal_dibuat DESC"
+	return where, args
+}
+
+// GetAllArticles retrieves articles with optional filters. When Search is set
+// the full-text index is used and results are ordered by relevance. The
+// published feed is ordered by (tanggal_publikasi, artikel_id) so it can be
+// paged with a Cursor instead of an offset.
+func GetAllArticles(ctx context.Context, db *sql.DB, filter ArticleFilter) ([]Article, error) {
+	where, args := articleFilterWhere(filter)
+	argCount := len(args)
+
+	query := `
+        SELECT a.artikel_id, a.judul, a.slug, a.konten, a.excerpt, 
+               a.gambar_utama, a.penulis, a.status, a.user_id, 
+               a.tanggal_publikasi, a.tanggal_dibuat, a.tanggal_diperbarui
+        FROM articles a
+    ` + where
+
+	switch {
+	case filter.Search != "":
+		query += fmt.Sprintf(" ORDER BY ts_rank(a.search_vector, websearch_to_tsquery('news_search', $%d)) DESC, a.tanggal_dibuat DESC", argCount)
+	case filter.IsFeed():
+		if filter.Cursor != nil {
+			query += fmt.Sprintf(" AND (a.tanggal_publikasi, a.artikel_id) < ($%d, $%d)", argCount+1, argCount+2)
+			args = append(args, filter.Cursor.TanggalPublikasi, filter.Cursor.ArtikelID)
+			argCount += 2
+		}
+		query += " ORDER BY a.tanggal_publikasi DESC, a.artikel_id DESC"
+	case filter.IsSchedule():
+		query += " ORDER BY a.tanggal_publikasi ASC NULLS LAST, a.artikel_id ASC"
+	default:
+		query += " ORDER BY a.tanggal_dibuat DESC"
+	}
 
 	if filter.Limit > 0 {
 		argCount++
@@ -164,13 +252,13 @@ func GetAllArticles(db *sql.DB, filter ArticleFilter) ([]Article, error) {
 		args = append(args, filter.Limit)
 	}
 
-	if filter.Offset > 0 {
+	if filter.Offset > 0 && filter.Cursor == nil {
 		argCount++
 		query += fmt.Sprintf(" OFFSET $%d", argCount)
 		args = append(args, filter.Offset)
 	}
 
-	rows, err := db.Query(query, args...)
+	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -188,28 +276,49 @@ func GetAllArticles(db *sql.DB, filter ArticleFilter) ([]Article, error) {
 			return nil, err
 		}
 
-		// Fetch categories and tags for each article
-		a.Kategori, _ = GetArticleCategories(db, a.ArtikelID)
-		a.Tags, _ = GetArticleTags(db, a.ArtikelID)
-
 		articles = append(articles, a)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Fetch categories and tags for the whole page at once
+	ptrs := make([]*Article, len(articles))
+	for i := range articles {
+		ptrs[i] = &articles[i]
+	}
+	if err := loadArticleRelations(ctx, db, ptrs); err != nil {
+		return nil, err
+	}
 
 	return articles, nil
 }
 
+// CountArticles returns the number of articles matching the filter, ignoring
+// cursor, limit and offset
+func CountArticles(ctx context.Context, db *sql.DB, filter ArticleFilter) (int, error) {
+	where, args := articleFilterWhere(filter)
+
+	var total int
+	err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM articles a"+where, args...).Scan(&total)
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
 // GetArticleByID retrieves a single article by ID
-func GetArticleByID(db *sql.DB, id int) (*Article, error) {
+func GetArticleByID(ctx context.Context, db *sql.DB, id int) (*Article, error) {
 	query := `
         SELECT artikel_id, judul, slug, konten, excerpt, gambar_utama, 
                penulis, status, user_id, tanggal_publikasi, 
                tanggal_dibuat, tanggal_diperbarui
         FROM articles
-        WHERE artikel_id = $1
+        WHERE artikel_id = $1 AND deleted_at IS NULL
     `
 
 	var a Article
-	err := db.QueryRow(query, id).Scan(
+	err := db.QueryRowContext(ctx, query, id).Scan(
 		&a.ArtikelID, &a.Judul, &a.Slug, &a.Konten, &a.Excerpt,
 		&a.GambarUtama, &a.Penulis, &a.Status, &a.UserID,
 		&a.TanggalPublikasi, &a.TanggalDibuat, &a.TanggalDiperbarui,
@@ -219,24 +328,25 @@ func GetArticleByID(db *sql.DB, id int) (*Article, error) {
 	}
 
 	// Fetch related categories and tags
-	a.Kategori, _ = GetArticleCategories(db, a.ArtikelID)
-	a.Tags, _ = GetArticleTags(db, a.ArtikelID)
+	if err := loadArticleRelations(ctx, db, []*Article{&a}); err != nil {
+		return nil, err
+	}
 
 	return &a, nil
 }
 
 // GetArticleBySlug retrieves a single article by slug
-func GetArticleBySlug(db *sql.DB, slug string) (*Article, error) {
+func GetArticleBySlug(ctx context.Context, db *sql.DB, slug string) (*Article, error) {
 	query := `
         SELECT artikel_id, judul, slug, konten, excerpt, gambar_utama, 
                penulis, status, user_id, tanggal_publikasi, 
                tanggal_dibuat, tanggal_diperbarui
         FROM articles
-        WHERE slug = $1
+        WHERE slug = $1 AND deleted_at IS NULL
     `
 
 	var a Article
-	err := db.QueryRow(query, slug).Scan(
+	err := db.QueryRowContext(ctx, query, slug).Scan(
 		&a.ArtikelID, &a.Judul, &a.Slug, &a.Konten, &a.Excerpt,
 		&a.GambarUtama, &a.Penulis, &a.Status, &a.UserID,
 		&a.TanggalPublikasi, &a.TanggalDibuat, &a.TanggalDiperbarui,
@@ -246,24 +356,25 @@ func GetArticleBySlug(db *sql.DB, slug string) (*Article, error) {
 	}
 
 	// Fetch related categories and tags
-	a.Kategori, _ = GetArticleCategories(db, a.ArtikelID)
-	a.Tags, _ = GetArticleTags(db, a.ArtikelID)
+	if err := loadArticleRelations(ctx, db, []*Article{&a}); err != nil {
+		return nil, err
+	}
 
 	return &a, nil
 }
 
 // GetPublishedArticleBySlug retrieves a published article by slug (for public access)
-func GetPublishedArticleBySlug(db *sql.DB, slug string) (*Article, error) {
+func GetPublishedArticleBySlug(ctx context.Context, db *sql.DB, slug string) (*Article, error) {
 	query := `
         SELECT artikel_id, judul, slug, konten, excerpt, gambar_utama, 
                penulis, status, user_id, tanggal_publikasi, 
                tanggal_dibuat, tanggal_diperbarui
         FROM articles
-        WHERE slug = $1 AND status = 'published'
+        WHERE slug = $1 AND status = 'published' AND deleted_at IS NULL
     `
 
 	var a Article
-	err := db.QueryRow(query, slug).Scan(
+	err := db.QueryRowContext(ctx, query, slug).Scan(
 		&a.ArtikelID, &a.Judul, &a.Slug, &a.Konten, &a.Excerpt,
 		&a.GambarUtama, &a.Penulis, &a.Status, &a.UserID,
 		&a.TanggalPublikasi, &a.TanggalDibuat, &a.TanggalDiperbarui,
@@ -273,14 +384,16 @@ func GetPublishedArticleBySlug(db *sql.DB, slug string) (*Article, error) {
 	}
 
 	// Fetch related categories and tags
-	a.Kategori, _ = GetArticleCategories(db, a.ArtikelID)
-	a.Tags, _ = GetArticleTags(db, a.ArtikelID)
+	if err := loadArticleRelations(ctx, db, []*Article{&a}); err != nil {
+		return nil, err
+	}
 
 	return &a, nil
 }
 
-// CreateArticle creates a new article
-func CreateArticle(db *sql.DB, input ArticleInput, userID int) (*Article, error) {
+// CreateArticle creates a new article together with its categories and
+// tags in one transaction, so a failing relation insert leaves nothing behind
+func CreateArticle(ctx context.Context, db *DB, input ArticleInput, userID int) (*Article, error) {
 	// Generate slug if not provided
 	slug := input.Slug
 	if slug == "" {
@@ -288,7 +401,7 @@ func CreateArticle(db *sql.DB, input ArticleInput, userID int) (*Article, error)
 	}
 
 	// Ensure slug is unique
-	slug, err := EnsureUniqueSlug(db, slug, 0)
+	slug, err := EnsureUniqueSlug(ctx, db.DB, slug, 0)
 	if err != nil {
 		return nil, err
 	}
@@ -335,54 +448,42 @@ func CreateArticle(db *sql.DB, input ArticleInput, userID int) (*Article, error)
 		gambarUtama = &input.GambarUtama
 	}
 
-	err = db.QueryRow(
-		query,
-		input.Judul, slug, input.Konten, excerpt, gambarUtama,
-		penulis, status, userID, tanggalPublikasi,
-	).Scan(
-		&a.ArtikelID, &a.Judul, &a.Slug, &a.Konten, &a.Excerpt,
-		&a.GambarUtama, &a.Penulis, &a.Status, &a.UserID,
-		&a.TanggalPublikasi, &a.TanggalDibuat, &a.TanggalDiperbarui,
-	)
-	if err != nil {
-		return nil, err
-	}
-
-	// Add categories
-	if len(input.KategoriIDs) > 0 {
-		for _, katID := range input.KategoriIDs {
-			_, err := db.Exec(
-				"INSERT INTO artikel_kategori (artikel_id, kategori_id) VALUES ($1, $2) ON CONFLICT DO NOTHING",
-				a.ArtikelID, katID,
-			)
-			if err != nil {
-				return nil, err
-			}
+	err = db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx,
+			query,
+			input.Judul, slug, input.Konten, excerpt, gambarUtama,
+			penulis, status, userID, tanggalPublikasi,
+		).Scan(
+			&a.ArtikelID, &a.Judul, &a.Slug, &a.Konten, &a.Excerpt,
+			&a.GambarUtama, &a.Penulis, &a.Status, &a.UserID,
+			&a.TanggalPublikasi, &a.TanggalDibuat, &a.TanggalDiperbarui,
+		)
+		if err != nil {
+			return err
 		}
-	}
 
-	// Add tags
-	if len(input.TagIDs) > 0 {
-		for _, tagID := range input.TagIDs {
-			_, err := db.Exec(
-				"INSERT INTO artikel_tag (artikel_id, tag_id) VALUES ($1, $2) ON CONFLICT DO NOTHING",
-				a.ArtikelID, tagID,
-			)
-			if err != nil {
-				return nil, err
-			}
+		if err := addArticleRelationsTx(ctx, tx, a.ArtikelID, input.KategoriIDs, input.TagIDs); err != nil {
+			return err
 		}
+
+		return auditCreatedTx(ctx, tx, "article.create", auditArticle, a.ArtikelID)
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Fetch related data
-	a.Kategori, _ = GetArticleCategories(db, a.ArtikelID)
-	a.Tags, _ = GetArticleTags(db, a.ArtikelID)
+	if err := loadArticleRelations(ctx, db.DB, []*Article{&a}); err != nil {
+		return nil, err
+	}
 
 	return &a, nil
 }
 
-// UpdateArticle updates an existing article
-func UpdateArticle(db *sql.DB, id int, input ArticleInput) (*Article, error) {
+// UpdateArticle updates an existing article. The content being replaced is
+// saved to article_revisions first, attributed to editorID. The revision,
+// the update and the new categories and tags commit or roll back together.
+func UpdateArticle(ctx context.Context, db *DB, id int, input ArticleInput, editorID int) (*Article, error) {
 	// Generate slug if provided or changed
 	slug := input.Slug
 	if slug == "" {
@@ -390,7 +491,7 @@ func UpdateArticle(db *sql.DB, id int, input ArticleInput) (*Article, error) {
 	}
 
 	// Ensure slug is unique (excluding current article)
-	slug, err := EnsureUniqueSlug(db, slug, id)
+	slug, err := EnsureUniqueSlug(ctx, db.DB, slug, id)
 	if err != nil {
 		return nil, err
 	}
@@ -404,26 +505,11 @@ func UpdateArticle(db *sql.DB, id int, input ArticleInput) (*Article, error) {
 		}
 	}
 
-	// If publishing for first time, set tanggal_publikasi
-	if input.Status == "published" && tanggalPublikasi == nil {
-		// Check if already published
-		var existingStatus string
-		var existingPubDate *time.Time
-		db.QueryRow("SELECT status, tanggal_publikasi FROM articles WHERE artikel_id = $1", id).Scan(&existingStatus, &existingPubDate)
-
-		if existingPubDate == nil {
-			now := time.Now()
-			tanggalPublikasi = &now
-		} else {
-			tanggalPublikasi = existingPubDate
-		}
-	}
-
 	query := `
         UPDATE articles 
         SET judul = $1, slug = $2, konten = $3, excerpt = $4, gambar_utama = $5, 
-            penulis = $6, status = $7, tanggal_publikasi = $8
-        WHERE artikel_id = $9
+            penulis = $6, status = $7, tanggal_publikasi = COALESCE($8, tanggal_publikasi)
+        WHERE artikel_id = $9 AND deleted_at IS NULL
         RETURNING artikel_id, judul, slug, konten, excerpt, gambar_utama, penulis, status, user_id, tanggal_publikasi, tanggal_dibuat, tanggal_diperbarui
     `
 
@@ -439,81 +525,112 @@ func UpdateArticle(db *sql.DB, id int, input ArticleInput) (*Article, error) {
 		penulis = &input.Penulis
 	}
 
-	err = db.QueryRow(
-		query,
-		input.Judul, slug, input.Konten, excerpt, gambarUtama,
-		penulis, input.Status, tanggalPublikasi, id,
-	).Scan(
-		&a.ArtikelID, &a.Judul, &a.Slug, &a.Konten, &a.Excerpt,
-		&a.GambarUtama, &a.Penulis, &a.Status, &a.UserID,
-		&a.TanggalPublikasi, &a.TanggalDibuat, &a.TanggalDiperbarui,
-	)
-	if err != nil {
-		return nil, err
-	}
+	err = db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		return auditedChangeTx(ctx, tx, "article.update", auditArticle, id, func(tx *sql.Tx) error {
+			// If publishing for first time, set tanggal_publikasi
+			if input.Status == "published" && tanggalPublikasi == nil {
+				// Check if already published
+				var existingPubDate *time.Time
+				err := tx.QueryRowContext(ctx,
+					"SELECT tanggal_publikasi FROM articles WHERE artikel_id = $1", id).Scan(&existingPubDate)
+				if err != nil {
+					return err
+				}
+
+				if existingPubDate == nil {
+					now := time.Now()
+					tanggalPublikasi = &now
+				} else {
+					tanggalPublikasi = existingPubDate
+				}
+			}
 
-	// Update categories
-	if input.KategoriIDs != nil {
-		// Remove existing
-		db.Exec("DELETE FROM artikel_kategori WHERE artikel_id = $1", id)
-		// Add new
-		for _, katID := range input.KategoriIDs {
-			db.Exec(
-				"INSERT INTO artikel_kategori (artikel_id, kategori_id) VALUES ($1, $2) ON CONFLICT DO NOTHING",
-				id, katID,
-			)
-		}
-	}
+			// Keep the previous version so the edit can be reviewed or undone
+			if err := createArticleRevision(ctx, tx, id, editorID); err != nil {
+				return err
+			}
 
-	// Update tags
-	if input.TagIDs != nil {
-		// Remove existing
-		db.Exec("DELETE FROM artikel_tag WHERE artikel_id = $1", id)
-		// Add new
-		for _, tagID := range input.TagIDs {
-			db.Exec(
-				"INSERT INTO artikel_tag (artikel_id, tag_id) VALUES ($1, $2) ON CONFLICT DO NOTHING",
-				id, tagID,
+			err := tx.QueryRowContext(ctx,
+				query,
+				input.Judul, slug, input.Konten, excerpt, gambarUtama,
+				penulis, input.Status, tanggalPublikasi, id,
+			).Scan(
+				&a.ArtikelID, &a.Judul, &a.Slug, &a.Konten, &a.Excerpt,
+				&a.GambarUtama, &a.Penulis, &a.Status, &a.UserID,
+				&a.TanggalPublikasi, &a.TanggalDibuat, &a.TanggalDiperbarui,
 			)
-		}
+			if err != nil {
+				return err
+			}
+
+			// Replace categories and tags that were sent
+			if input.KategoriIDs != nil {
+				if _, err := tx.ExecContext(ctx, "DELETE FROM artikel_kategori WHERE artikel_id = $1", id); err != nil {
+					return fmt.Errorf("failed to clear article categories: %w", err)
+				}
+			}
+			if input.TagIDs != nil {
+				if _, err := tx.ExecContext(ctx, "DELETE FROM artikel_tag WHERE artikel_id = $1", id); err != nil {
+					return fmt.Errorf("failed to clear article tags: %w", err)
+				}
+			}
+			return addArticleRelationsTx(ctx, tx, id, input.KategoriIDs, input.TagIDs)
+		})
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Fetch related data
-	a.Kategori, _ = GetArticleCategories(db, a.ArtikelID)
-	a.Tags, _ = GetArticleTags(db, a.ArtikelID)
+	if err := loadArticleRelations(ctx, db.DB, []*Article{&a}); err != nil {
+		return nil, err
+	}
 
 	return &a, nil
 }
 
-// DeleteArticle deletes an article by ID
-func DeleteArticle(db *sql.DB, id int) error {
-	result, err := db.Exec("DELETE FROM articles WHERE artikel_id = $1", id)
-	if err != nil {
-		return err
-	}
-
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return err
+// addArticleRelationsTx links an article to categories and tags
+func addArticleRelationsTx(ctx context.Context, tx *sql.Tx, artikelID int, kategoriIDs, tagIDs []int) error {
+	for _, katID := range kategoriIDs {
+		_, err := tx.ExecContext(ctx,
+			"INSERT INTO artikel_kategori (artikel_id, kategori_id) VALUES ($1, $2) ON CONFLICT DO NOTHING",
+			artikelID, katID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to add article category: %w", err)
+		}
 	}
 
-	if rowsAffected == 0 {
-		return sql.ErrNoRows
+	for _, tagID := range tagIDs {
+		_, err := tx.ExecContext(ctx,
+			"INSERT INTO artikel_tag (artikel_id, tag_id) VALUES ($1, $2) ON CONFLICT DO NOTHING",
+			artikelID, tagID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to add article tag: %w", err)
+		}
 	}
-
 	return nil
 }
 
+// DeleteArticle moves an article to the trash. Its comments, categories
+// and tags are kept so RestoreTrashItem can bring it back whole.
+func DeleteArticle(ctx context.Context, db *sql.DB, id int) error {
+	return auditedChange(ctx, db, "article.delete", auditArticle, id, func(tx *sql.Tx) error {
+		return softDeleteTx(ctx, tx, auditArticle, id)
+	})
+}
+
 // GetArticleCategories retrieves categories for an article
-func GetArticleCategories(db *sql.DB, artikelID int) ([]Category, error) {
+func GetArticleCategories(ctx context.Context, db *sql.DB, artikelID int) ([]Category, error) {
 	query := `
         SELECT c.kategori_id, c.nama_kategori, c.deskripsi, c.created_at
         FROM categories c
         JOIN artikel_kategori ak ON c.kategori_id = ak.kategori_id
-        WHERE ak.artikel_id = $1
+        WHERE ak.artikel_id = $1 AND c.deleted_at IS NULL
     `
 
-	rows, err := db.Query(query, artikelID)
+	rows, err := db.QueryContext(ctx, query, artikelID)
 	if err != nil {
 		return nil, err
 	}
@@ -529,19 +646,19 @@ func GetArticleCategories(db *sql.DB, artikelID int) ([]Category, error) {
 		categories = append(categories, c)
 	}
 
-	return categories, nil
+	return categories, rows.Err()
 }
 
 // GetArticleTags retrieves tags for an article
-func GetArticleTags(db *sql.DB, artikelID int) ([]Tag, error) {
+func GetArticleTags(ctx context.Context, db *sql.DB, artikelID int) ([]Tag, error) {
 	query := `
         SELECT t.tag_id, t.nama_tag, t.created_at
         FROM tags t
         JOIN artikel_tag at ON t.tag_id = at.tag_id
-        WHERE at.artikel_id = $1
+        WHERE at.artikel_id = $1 AND t.deleted_at IS NULL
     `
 
-	rows, err := db.Query(query, artikelID)
+	rows, err := db.QueryContext(ctx, query, artikelID)
 	if err != nil {
 		return nil, err
 	}
@@ -557,27 +674,131 @@ func GetArticleTags(db *sql.DB, artikelID int) ([]Tag, error) {
 		tags = append(tags, t)
 	}
 
-	return tags, nil
+	return tags, rows.Err()
+}
+
+// GetCategoriesForArticles retrieves the categories of several articles in a
+// single query, keyed by artikel_id
+func GetCategoriesForArticles(ctx context.Context, db *sql.DB, artikelIDs []int) (map[int][]Category, error) {
+	query := `
+        SELECT ak.artikel_id, c.kategori_id, c.nama_kategori, c.deskripsi, c.created_at
+        FROM categories c
+        JOIN artikel_kategori ak ON c.kategori_id = ak.kategori_id
+        WHERE ak.artikel_id = ANY($1) AND c.deleted_at IS NULL
+        ORDER BY ak.artikel_id, c.kategori_id
+    `
+
+	rows, err := db.QueryContext(ctx, query, pq.Array(artikelIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load article categories: %w", err)
+	}
+	defer rows.Close()
+
+	categories := make(map[int][]Category)
+	for rows.Next() {
+		var artikelID int
+		var c Category
+		err := rows.Scan(&artikelID, &c.KategoriID, &c.NamaKategori, &c.Deskripsi, &c.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		categories[artikelID] = append(categories[artikelID], c)
+	}
+
+	return categories, rows.Err()
+}
+
+// GetTagsForArticles retrieves the tags of several articles in a single query,
+// keyed by artikel_id
+func GetTagsForArticles(ctx context.Context, db *sql.DB, artikelIDs []int) (map[int][]Tag, error) {
+	query := `
+        SELECT at.artikel_id, t.tag_id, t.nama_tag, t.created_at
+        FROM tags t
+        JOIN artikel_tag at ON t.tag_id = at.tag_id
+        WHERE at.artikel_id = ANY($1) AND t.deleted_at IS NULL
+        ORDER BY at.artikel_id, t.tag_id
+    `
+
+	rows, err := db.QueryContext(ctx, query, pq.Array(artikelIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load article tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := make(map[int][]Tag)
+	for rows.Next() {
+		var artikelID int
+		var t Tag
+		err := rows.Scan(&artikelID, &t.TagID, &t.NamaTag, &t.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		tags[artikelID] = append(tags[artikelID], t)
+	}
+
+	return tags, rows.Err()
+}
+
+// loadArticleRelations fills Kategori and Tags for the given articles using
+// one query per relation, regardless of how many articles there are
+func loadArticleRelations(ctx context.Context, db *sql.DB, articles []*Article) error {
+	if len(articles) == 0 {
+		return nil
+	}
+
+	ids := make([]int, len(articles))
+	for i, a := range articles {
+		ids[i] = a.ArtikelID
+	}
+
+	categories, err := GetCategoriesForArticles(ctx, db, ids)
+	if err != nil {
+		return err
+	}
+	tags, err := GetTagsForArticles(ctx, db, ids)
+	if err != nil {
+		return err
+	}
+
+	var images []string
+	for _, a := range articles {
+		if a.GambarUtama != nil && *a.GambarUtama != "" {
+			images = append(images, *a.GambarUtama)
+		}
+	}
+	variants, err := GetImageVariantsByURL(ctx, db, images)
+	if err != nil {
+		return err
+	}
+
+	for _, a := range articles {
+		a.Kategori = categories[a.ArtikelID]
+		a.Tags = tags[a.ArtikelID]
+		if a.GambarUtama != nil {
+			a.GambarUtamaVariants = variants[*a.GambarUtama]
+		}
+	}
+	return nil
 }
 
 // GetArticlesByCategory retrieves articles by category ID
-func GetArticlesByCategory(db *sql.DB, kategoriID int, limit int, offset int) ([]Article, error) {
+func GetArticlesByCategory(ctx context.Context, db *sql.DB, kategoriID int, limit int, offset int) ([]Article, error) {
 	filter := ArticleFilter{
 		Status:     "published",
 		KategoriID: kategoriID,
 		Limit:      limit,
 		Offset:     offset,
 	}
-	return GetAllArticles(db, filter)
+	return GetAllArticles(ctx, db, filter)
 }
 
 // GetArticlesByTag retrieves articles by tag ID
-func GetArticlesByTag(db *sql.DB, tagID int, limit int, offset int) ([]Article, error) {
+func GetArticlesByTag(ctx context.Context, db *sql.DB, tagID int, limit int, offset int) ([]Article, error) {
 	filter := ArticleFilter{
 		Status: "published",
 		TagID:  tagID,
 		Limit:  limit,
 		Offset: offset,
 	}
-	return GetAllArticles(db, filter)
+	return GetAllArticles(ctx, db, filter)
 }
@@ -0,0 +1,80 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+var articleReturningColumns = []string{
+	"artikel_id", "judul", "slug", "konten", "excerpt", "gambar_utama", "penulis", "status",
+	"user_id", "tanggal_publikasi", "tanggal_dibuat", "tanggal_diperbarui",
+}
+
+func articleRow(id int) *sqlmock.Rows {
+	now := time.Now()
+	return sqlmock.NewRows(articleReturningColumns).
+		AddRow(id, "Judul", "judul", "Konten", nil, nil, nil, "draft", 3, nil, now, now)
+}
+
+func expectFreeSlug(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS(SELECT 1 FROM articles WHERE slug = $1")).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+}
+
+// A failing tag insert must not leave a half-created article behind
+func TestCreateArticleRollsBackWhenTagInsertFails(t *testing.T) {
+	db, mock := newMockDB(t)
+	expectFreeSlug(mock)
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO articles").WillReturnRows(articleRow(42))
+	mock.ExpectExec("INSERT INTO artikel_kategori").WithArgs(42, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO artikel_tag").WithArgs(42, 9).WillReturnError(errBoom)
+	mock.ExpectRollback()
+
+	input := ArticleInput{Judul: "Judul", Konten: "Konten", KategoriIDs: []int{1}, TagIDs: []int{9}}
+	article, err := CreateArticle(context.Background(), db, input, 3)
+	if !errors.Is(err, errBoom) {
+		t.Fatalf("err = %v, want %v", err, errBoom)
+	}
+	if article != nil {
+		t.Fatalf("article = %+v, want nil", article)
+	}
+}
+
+func TestCreateArticleRollsBackWhenAuditFails(t *testing.T) {
+	db, mock := newMockDB(t)
+	expectFreeSlug(mock)
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO articles").WillReturnRows(articleRow(42))
+	expectSnapshot(mock, "articles")
+	mock.ExpectExec("INSERT INTO audit_log").WillReturnError(errBoom)
+	mock.ExpectRollback()
+
+	_, err := CreateArticle(context.Background(), db, ArticleInput{Judul: "Judul", Konten: "Konten"}, 3)
+	if !errors.Is(err, errBoom) {
+		t.Fatalf("err = %v, want %v", err, errBoom)
+	}
+}
+
+// The revision, the update and the cleared relations are undone together
+func TestUpdateArticleRollsBackWhenTagInsertFails(t *testing.T) {
+	db, mock := newMockDB(t)
+	expectFreeSlug(mock)
+	mock.ExpectBegin()
+	expectSnapshot(mock, "articles")
+	mock.ExpectExec("INSERT INTO article_revisions").WithArgs(42, 3).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("UPDATE articles").WillReturnRows(articleRow(42))
+	mock.ExpectExec("DELETE FROM artikel_tag").WithArgs(42).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("INSERT INTO artikel_tag").WithArgs(42, 9).WillReturnError(errBoom)
+	mock.ExpectRollback()
+
+	input := ArticleInput{Judul: "Judul", Konten: "Konten", Status: "draft", TagIDs: []int{9}}
+	if _, err := UpdateArticle(context.Background(), db, 42, input, 3); !errors.Is(err, errBoom) {
+		t.Fatalf("err = %v, want %v", err, errBoom)
+	}
+}
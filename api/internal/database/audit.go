@@ -0,0 +1,303 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// AuditEntry is one row of the audit log
+type AuditEntry struct {
+	AuditID       int64           `json:"audit_id"`
+	ActorID       *int            `json:"actor_id,omitempty"`
+	ActorUsername *string         `json:"actor_username,omitempty"`
+	ActorRole     *string         `json:"actor_role,omitempty"`
+	Action        string          `json:"action"`
+	TargetType    string          `json:"target_type"`
+	TargetID      string          `json:"target_id"`
+	Before        json.RawMessage `json:"before,omitempty"`
+	After         json.RawMessage `json:"after,omitempty"`
+	IPAddress     *string         `json:"ip_address,omitempty"`
+	RequestID     *string         `json:"request_id,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// AuditActor identifies who makes a change and from where. The server puts
+// it into the request context; changes made without one (scheduler,
+// self-registration) are logged without actor.
+type AuditActor struct {
+	UserID    *int
+	Role      string
+	IPAddress string
+	RequestID string
+}
+
+type auditActorKey struct{}
+
+// WithAuditActor returns a context whose database changes are attributed to
+// actor
+func WithAuditActor(ctx context.Context, actor AuditActor) context.Context {
+	return context.WithValue(ctx, auditActorKey{}, actor)
+}
+
+// AuditActorFromContext returns the actor stored by WithAuditActor
+func AuditActorFromContext(ctx context.Context) (AuditActor, bool) {
+	actor, ok := ctx.Value(auditActorKey{}).(AuditActor)
+	return actor, ok
+}
+
+// dbtx is implemented by both *sql.DB and *sql.Tx
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// auditHiddenColumns are never copied into audit snapshots
+var auditHiddenColumns = []string{"password", "token_hash"}
+
+// auditSnapshot returns the row of table whose key column equals id as
+// JSON, or nil when there is no such row. forUpdate locks the row until the
+// transaction ends so the snapshot matches what gets changed.
+func auditSnapshot(ctx context.Context, q dbtx, table, key string, id any, forUpdate bool) (json.RawMessage, error) {
+	// table and key come from code, never from input
+	query := fmt.Sprintf("SELECT to_jsonb(t) - $2::text[] FROM %s t WHERE %s = $1", table, key)
+	if forUpdate {
+		query += " FOR UPDATE"
+	}
+
+	var snapshot []byte
+	err := q.QueryRowContext(ctx, query, id, pq.Array(auditHiddenColumns)).Scan(&snapshot)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot %s: %w", table, err)
+	}
+	return snapshot, nil
+}
+
+// auditJSON marshals a value for the before/after columns; nil stays NULL
+func auditJSON(v any) (json.RawMessage, error) {
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case json.RawMessage:
+		return v, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode audit snapshot: %w", err)
+	}
+	return b, nil
+}
+
+// recordAudit appends an entry attributed to the actor in ctx. Call it with
+// the transaction of the change so both commit or roll back together.
+// before and after are JSON snapshots or values to encode, nil for none.
+func recordAudit(ctx context.Context, q dbtx, action, targetType string, targetID any, before, after any) error {
+	beforeJSON, err := auditJSON(before)
+	if err != nil {
+		return err
+	}
+	afterJSON, err := auditJSON(after)
+	if err != nil {
+		return err
+	}
+
+	actor, _ := AuditActorFromContext(ctx)
+	_, err = q.ExecContext(ctx, `
+        INSERT INTO audit_log (actor_id, actor_role, action, target_type, target_id,
+                               before, after, ip_address, request_id)
+        VALUES ($1, NULLIF($2, ''), $3, $4, $5, $6, $7, NULLIF($8, ''), NULLIF($9, ''))
+    `, actor.UserID, actor.Role, action, targetType, fmt.Sprint(targetID),
+		nullJSON(beforeJSON), nullJSON(afterJSON), actor.IPAddress, actor.RequestID)
+	if err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+// auditTarget names the audit target type and the table row behind it
+type auditTarget struct {
+	Type  string
+	Table string
+	Key   string
+}
+
+var (
+	auditUser       = auditTarget{"user", "users", "user_id"}
+	auditInvitation = auditTarget{"invitation", "user_invitations", "invitation_id"}
+	auditRole       = auditTarget{"role", "roles", "name"}
+	auditArticle    = auditTarget{"article", "articles", "artikel_id"}
+	auditCategory   = auditTarget{"category", "categories", "kategori_id"}
+	auditTag        = auditTarget{"tag", "tags", "tag_id"}
+	auditComment    = auditTarget{"comment", "comments", "komentar_id"}
+	auditMedia      = auditTarget{"media", "media", "media_id"}
+	auditBannedWord = auditTarget{"banned_word", "banned_words", "word_id"}
+)
+
+// auditedChange runs change on one row inside a transaction and records it
+// with before and after snapshots. Returns sql.ErrNoRows when the row does
+// not exist; errors from change roll everything back.
+func auditedChange(ctx context.Context, db *sql.DB, action string, target auditTarget, id any, change func(tx *sql.Tx) error) error {
+	return withTransaction(ctx, db, func(tx *sql.Tx) error {
+		return auditedChangeTx(ctx, tx, action, target, id, change)
+	})
+}
+
+// auditedChangeTx is auditedChange within an existing transaction
+func auditedChangeTx(ctx context.Context, tx *sql.Tx, action string, target auditTarget, id any, change func(tx *sql.Tx) error) error {
+	before, err := auditSnapshot(ctx, tx, target.Table, target.Key, id, true)
+	if err != nil {
+		return err
+	}
+	if before == nil {
+		return sql.ErrNoRows
+	}
+
+	if err := change(tx); err != nil {
+		return err
+	}
+
+	after, err := auditSnapshot(ctx, tx, target.Table, target.Key, id, false)
+	if err != nil {
+		return err
+	}
+	return recordAudit(ctx, tx, action, target.Type, id, before, after)
+}
+
+// auditCreatedTx records a row created in tx
+func auditCreatedTx(ctx context.Context, tx *sql.Tx, action string, target auditTarget, id any) error {
+	after, err := auditSnapshot(ctx, tx, target.Table, target.Key, id, false)
+	if err != nil {
+		return err
+	}
+	return recordAudit(ctx, tx, action, target.Type, id, nil, after)
+}
+
+// nullJSON maps an empty snapshot to NULL
+func nullJSON(b json.RawMessage) any {
+	if len(b) == 0 {
+		return nil
+	}
+	return []byte(b)
+}
+
+// ========================================
+// QUERYING
+// ========================================
+
+// AuditFilter narrows down audit log queries; zero values match everything
+type AuditFilter struct {
+	ActorID    int
+	Action     string // exact, or a prefix ending in '.' such as "article."
+	TargetType string
+	TargetID   string
+	RequestID  string
+	From       *time.Time
+	To         *time.Time
+	Limit      int
+	Offset     int
+}
+
+func auditFilterWhere(filter AuditFilter) (string, []any) {
+	var conds []string
+	var args []any
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, strings.ReplaceAll(cond, "?", "$"+strconv.Itoa(len(args))))
+	}
+
+	if filter.ActorID > 0 {
+		add("l.actor_id = ?", filter.ActorID)
+	}
+	if filter.Action != "" {
+		if strings.HasSuffix(filter.Action, ".") {
+			add("l.action LIKE ? || '%'", filter.Action)
+		} else {
+			add("l.action = ?", filter.Action)
+		}
+	}
+	if filter.TargetType != "" {
+		add("l.target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID != "" {
+		add("l.target_id = ?", filter.TargetID)
+	}
+	if filter.RequestID != "" {
+		add("l.request_id = ?", filter.RequestID)
+	}
+	if filter.From != nil {
+		add("l.created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		add("l.created_at < ?", *filter.To)
+	}
+
+	if len(conds) == 0 {
+		return "", args
+	}
+	return "WHERE " + strings.Join(conds, " AND "), args
+}
+
+// ListAuditLog retrieves audit entries matching the filter, newest first
+func ListAuditLog(ctx context.Context, db *sql.DB, filter AuditFilter) ([]AuditEntry, error) {
+	entries := []AuditEntry{}
+	err := EachAuditEntry(ctx, db, filter, func(e AuditEntry) error {
+		entries = append(entries, e)
+		return nil
+	})
+	return entries, err
+}
+
+// EachAuditEntry calls fn for every entry matching the filter, newest
+// first, without loading them all into memory. Used for exports.
+func EachAuditEntry(ctx context.Context, db *sql.DB, filter AuditFilter, fn func(AuditEntry) error) error {
+	where, args := auditFilterWhere(filter)
+	args = append(args, filter.Limit, filter.Offset)
+	query := fmt.Sprintf(`
+        SELECT l.audit_id, l.actor_id, u.username, l.actor_role, l.action, l.target_type, l.target_id,
+               l.before, l.after, l.ip_address, l.request_id, l.created_at
+        FROM audit_log l
+        LEFT JOIN users u ON u.user_id = l.actor_id
+        %s
+        ORDER BY l.created_at DESC, l.audit_id DESC
+        LIMIT NULLIF($%d, 0) OFFSET $%d
+    `, where, len(args)-1, len(args))
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e AuditEntry
+		var before, after []byte
+		err := rows.Scan(&e.AuditID, &e.ActorID, &e.ActorUsername, &e.ActorRole, &e.Action,
+			&e.TargetType, &e.TargetID, &before, &after, &e.IPAddress, &e.RequestID, &e.CreatedAt)
+		if err != nil {
+			return err
+		}
+		e.Before, e.After = before, after
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// CountAuditLog retrieves the number of entries matching the filter
+func CountAuditLog(ctx context.Context, db *sql.DB, filter AuditFilter) (int, error) {
+	where, args := auditFilterWhere(filter)
+	var count int
+	err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM audit_log l "+where, args...).Scan(&count)
+	return count, err
+}
@@ -21,25 +21,20 @@ type CategoryRequest struct {
 }
 
 func CreateCategory(ctx context.Context, db *sql.DB, req *CategoryRequest) (*Category, error) {
-	query := `
-        INSERT INTO categories (nama_kategori, deskripsi, created_at)
-        VALUES ($1, $2, NOW())
-        RETURNING kategori_id, nama_kategori, deskripsi, created_at
-    `
-
-	var deskripsi *string
-	if req.Deskripsi != "" {
-		deskripsi = &req.Deskripsi
-	}
-
-	var category Category
-	err := db.QueryRowContext(ctx, query, req.NamaKategori, deskripsi).Scan(
-		&category.KategoriID, &category.NamaKategori, &category.Deskripsi, &category.CreatedAt)
+	var category *Category
+	err := withTransaction(ctx, db, func(tx *sql.Tx) error {
+		var err error
+		category, err = CreateCategoryTx(ctx, tx, req)
+		if err != nil {
+			return err
+		}
+		return auditCreatedTx(ctx, tx, "category.create", auditCategory, category.KategoriID)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create category: %w", err)
+		return nil, err
 	}
 
-	return &category, nil
+	return category, nil
 }
 
 func CreateCategoryTx(ctx context.Context, tx *sql.Tx, req *CategoryRequest) (*Category, error) {
@@ -68,7 +63,7 @@ func GetCategoryByID(ctx context.Context, db *sql.DB, categoryID int) (*Category
 	query := `
         SELECT kategori_id, nama_kategori, deskripsi, created_at
         FROM categories
-        WHERE kategori_id = $1
+        WHERE kategori_id = $1 AND deleted_at IS NULL
     `
 
 	var category Category
@@ -88,7 +83,7 @@ func GetCategoryByName(ctx context.Context, db *sql.DB, name string) (*Category,
 	query := `
         SELECT kategori_id, nama_kategori, deskripsi, created_at
         FROM categories
-        WHERE LOWER(nama_kategori) = LOWER($1)
+        WHERE LOWER(nama_kategori) = LOWER($1) AND deleted_at IS NULL
     `
 
 	var category Category
@@ -108,6 +103,7 @@ func ListCategories(ctx context.Context, db *sql.DB) ([]Category, error) {
 	query := `
         SELECT kategori_id, nama_kategori, deskripsi, created_at
         FROM categories
+        WHERE deleted_at IS NULL
         ORDER BY nama_kategori ASC
     `
 
@@ -133,10 +129,11 @@ func ListCategories(ctx context.Context, db *sql.DB) ([]Category, error) {
 func ListCategoriesWithArticleCount(ctx context.Context, db *sql.DB) ([]map[string]interface{}, error) {
 	query := `
         SELECT c.kategori_id, c.nama_kategori, c.deskripsi, c.created_at,
-               COUNT(ak.artikel_id) as article_count
+               COUNT(a.artikel_id) as article_count
         FROM categories c
         LEFT JOIN artikel_kategori ak ON c.kategori_id = ak.kategori_id
-        LEFT JOIN articles a ON ak.artikel_id = a.artikel_id AND a.status = 'published'
+        LEFT JOIN articles a ON ak.artikel_id = a.artikel_id AND a.status = 'published' AND a.deleted_at IS NULL
+        WHERE c.deleted_at IS NULL
         GROUP BY c.kategori_id, c.nama_kategori, c.deskripsi, c.created_at
         ORDER BY c.nama_kategori ASC
     `
@@ -172,36 +169,27 @@ func ListCategoriesWithArticleCount(ctx context.Context, db *sql.DB) ([]map[stri
 }
 
 func UpdateCategory(ctx context.Context, db *sql.DB, categoryID int, req *CategoryRequest) (*Category, error) {
-	query := `
-        UPDATE categories
-        SET nama_kategori = $1, deskripsi = $2
-        WHERE kategori_id = $3
-        RETURNING kategori_id, nama_kategori, deskripsi, created_at
-    `
-
-	var deskripsi *string
-	if req.Deskripsi != "" {
-		deskripsi = &req.Deskripsi
+	var category *Category
+	err := auditedChange(ctx, db, "category.update", auditCategory, categoryID, func(tx *sql.Tx) error {
+		var err error
+		category, err = UpdateCategoryTx(ctx, tx, categoryID, req)
+		return err
+	})
+	if err == sql.ErrNoRows {
+		return nil, errors.New("category not found")
 	}
-
-	var category Category
-	err := db.QueryRowContext(ctx, query, req.NamaKategori, deskripsi, categoryID).Scan(
-		&category.KategoriID, &category.NamaKategori, &category.Deskripsi, &category.CreatedAt)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, errors.New("category not found")
-		}
-		return nil, fmt.Errorf("failed to update category: %w", err)
+		return nil, err
 	}
 
-	return &category, nil
+	return category, nil
 }
 
 func UpdateCategoryTx(ctx context.Context, tx *sql.Tx, categoryID int, req *CategoryRequest) (*Category, error) {
 	query := `
         UPDATE categories
         SET nama_kategori = $1, deskripsi = $2
-        WHERE kategori_id = $3
+        WHERE kategori_id = $3 AND deleted_at IS NULL
         RETURNING kategori_id, nama_kategori, deskripsi, created_at
     `
 
@@ -224,45 +212,19 @@ func UpdateCategoryTx(ctx context.Context, tx *sql.Tx, categoryID int, req *Cate
 }
 
 func DeleteCategory(ctx context.Context, db *sql.DB, categoryID int) error {
-	// Check if category has articles
-	var articleCount int
-	countQuery := `
-        SELECT COUNT(*)
-        FROM artikel_kategori
-        WHERE kategori_id = $1
-    `
-	err := db.QueryRowContext(ctx, countQuery, categoryID).Scan(&articleCount)
-	if err != nil {
-		return fmt.Errorf("failed to check category usage: %w", err)
-	}
-
-	if articleCount > 0 {
-		return errors.New("cannot delete category that has articles")
-	}
-
-	res, err := db.ExecContext(ctx, "DELETE FROM categories WHERE kategori_id = $1", categoryID)
-	if err != nil {
-		return fmt.Errorf("error deleting category ID %d: %w", categoryID, err)
-	}
-
-	count, err := res.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("error getting rows affected for category ID %d delete: %w", categoryID, err)
-	}
-	if count == 0 {
-		return sql.ErrNoRows
-	}
-
-	return nil
+	return auditedChange(ctx, db, "category.delete", auditCategory, categoryID, func(tx *sql.Tx) error {
+		return DeleteCategoryTx(ctx, tx, categoryID)
+	})
 }
 
 func DeleteCategoryTx(ctx context.Context, tx *sql.Tx, categoryID int) error {
-	// Check if category has articles
+	// Check if category has articles (articles in the trash do not count)
 	var articleCount int
 	countQuery := `
         SELECT COUNT(*)
-        FROM artikel_kategori
-        WHERE kategori_id = $1
+        FROM artikel_kategori ak
+        JOIN articles a ON a.artikel_id = ak.artikel_id AND a.deleted_at IS NULL
+        WHERE ak.kategori_id = $1
     `
 	err := tx.QueryRowContext(ctx, countQuery, categoryID).Scan(&articleCount)
 	if err != nil {
@@ -273,54 +235,22 @@ func DeleteCategoryTx(ctx context.Context, tx *sql.Tx, categoryID int) error {
 		return errors.New("cannot delete category that has articles")
 	}
 
-	res, err := tx.ExecContext(ctx, "DELETE FROM categories WHERE kategori_id = $1", categoryID)
-	if err != nil {
-		return fmt.Errorf("error executing delete for category ID %d in tx: %w", categoryID, err)
-	}
-
-	count, err := res.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("error getting rows affected for category ID %d delete in tx: %w", categoryID, err)
-	}
-	if count == 0 {
-		return sql.ErrNoRows
-	}
-
-	return nil
+	return softDeleteTx(ctx, tx, auditCategory, categoryID)
 }
 
-func ForceDeleteCategory(ctx context.Context, db *sql.DB, categoryID int) error {
-	tx, err := db.BeginTx(ctx, nil)
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer tx.Rollback()
-
-	// Delete category relations first
-	_, err = tx.ExecContext(ctx, "DELETE FROM artikel_kategori WHERE kategori_id = $1", categoryID)
-	if err != nil {
-		return fmt.Errorf("failed to delete category relations: %w", err)
-	}
-
-	// Delete category
-	res, err := tx.ExecContext(ctx, "DELETE FROM categories WHERE kategori_id = $1", categoryID)
-	if err != nil {
-		return fmt.Errorf("error deleting category ID %d: %w", categoryID, err)
-	}
-
-	count, err := res.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("error getting rows affected for category ID %d delete: %w", categoryID, err)
-	}
-	if count == 0 {
-		return sql.ErrNoRows
-	}
-
-	return tx.Commit()
+// ForceDeleteCategory moves a category to the trash even if articles use
+// it. The article relations stay until the category is purged, so a restore
+// puts it back on the same articles.
+func ForceDeleteCategory(ctx context.Context, db *DB, categoryID int) error {
+	return db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		return auditedChangeTx(ctx, tx, "category.force_delete", auditCategory, categoryID, func(tx *sql.Tx) error {
+			return softDeleteTx(ctx, tx, auditCategory, categoryID)
+		})
+	})
 }
 
 func IsCategoryExists(ctx context.Context, db *sql.DB, name string) (bool, error) {
-	query := `SELECT EXISTS(SELECT 1 FROM categories WHERE nama_kategori = $1)`
+	query := `SELECT EXISTS(SELECT 1 FROM categories WHERE nama_kategori = $1 AND deleted_at IS NULL)`
 	var exists bool
 	err := db.QueryRowContext(ctx, query, name).Scan(&exists)
 	if err != nil {
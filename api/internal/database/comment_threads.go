@@ -0,0 +1,212 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// MaxCommentDepth is the deepest reply level; depth 0 is a top-level comment
+const MaxCommentDepth = 3
+
+var (
+	ErrParentCommentNotFound = errors.New("parent comment not found")
+	ErrParentCommentMismatch = errors.New("parent comment belongs to another article")
+	ErrCommentTooDeep        = errors.New("comment thread is too deep")
+)
+
+// threadColumns selects a comment together with its number of approved
+// direct replies
+const threadColumns = `
+        c.komentar_id, c.konten, c.nama_pengguna, c.status, c.user_id, c.artikel_id,
+        c.parent_id, c.depth, c.tanggal_dibuat, c.tanggal_diperbarui,
+        (SELECT COUNT(*) FROM comments r WHERE r.parent_id = c.komentar_id AND r.status = 'approved'
+                                          AND r.deleted_at IS NULL)
+`
+
+func scanThreadComments(rows *sql.Rows) ([]Comment, error) {
+	comments := []Comment{}
+	for rows.Next() {
+		var c Comment
+		err := rows.Scan(
+			&c.KomentarID, &c.Konten, &c.NamaPengguna, &c.Status, &c.UserID, &c.ArtikelID,
+			&c.ParentID, &c.Depth, &c.TanggalDibuat, &c.TanggalDiperbarui, &c.ReplyCount,
+		)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, c)
+	}
+	return comments, rows.Err()
+}
+
+// ResolveCommentParent checks that a reply targets an approved, visible
+// comment of the same article and returns the depth of the new reply
+func ResolveCommentParent(ctx context.Context, db *sql.DB, parentID, articleID int) (int, error) {
+	var parentArticle, parentDepth int
+	err := db.QueryRowContext(ctx,
+		"SELECT artikel_id, depth FROM comments WHERE komentar_id = $1 AND deleted_at IS NULL",
+		parentID).Scan(&parentArticle, &parentDepth)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, ErrParentCommentNotFound
+		}
+		return 0, err
+	}
+
+	if parentArticle != articleID {
+		return 0, ErrParentCommentMismatch
+	}
+	if parentDepth >= MaxCommentDepth {
+		return 0, ErrCommentTooDeep
+	}
+
+	visible, err := IsCommentVisible(ctx, db, parentID)
+	if err != nil {
+		return 0, err
+	}
+	if !visible {
+		return 0, ErrParentCommentNotFound
+	}
+
+	return parentDepth + 1, nil
+}
+
+// IsCommentVisible reports whether a comment and all of its ancestors are
+// approved and not in the trash. Replies under a rejected comment are hidden
+// with it.
+func IsCommentVisible(ctx context.Context, db *sql.DB, commentID int) (bool, error) {
+	query := `
+        WITH RECURSIVE chain AS (
+            SELECT komentar_id, parent_id, status, deleted_at FROM comments WHERE komentar_id = $1
+            UNION ALL
+            SELECT c.komentar_id, c.parent_id, c.status, c.deleted_at
+            FROM comments c JOIN chain ch ON c.komentar_id = ch.parent_id
+        )
+        SELECT COUNT(*) > 0 AND BOOL_AND(status = 'approved' AND deleted_at IS NULL) FROM chain
+    `
+
+	var visible bool
+	if err := db.QueryRowContext(ctx, query, commentID).Scan(&visible); err != nil {
+		return false, err
+	}
+	return visible, nil
+}
+
+// ListCommentThreads retrieves the approved top-level comments of an article,
+// newest first, with their reply counts
+func ListCommentThreads(ctx context.Context, db *sql.DB, articleID, limit, offset int) ([]Comment, error) {
+	query := `
+        SELECT ` + threadColumns + `
+        FROM comments c
+        WHERE c.artikel_id = $1 AND c.parent_id IS NULL AND c.status = 'approved' AND c.deleted_at IS NULL
+        ORDER BY c.tanggal_dibuat DESC, c.komentar_id DESC
+        LIMIT NULLIF($2, 0) OFFSET $3
+    `
+
+	rows, err := db.QueryContext(ctx, query, articleID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanThreadComments(rows)
+}
+
+// CountCommentThreads retrieves the number of approved top-level comments
+func CountCommentThreads(ctx context.Context, db *sql.DB, articleID int) (int, error) {
+	query := `
+        SELECT COUNT(*) FROM comments
+        WHERE artikel_id = $1 AND parent_id IS NULL AND status = 'approved' AND deleted_at IS NULL
+    `
+	var count int
+	if err := db.QueryRowContext(ctx, query, articleID).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// ListCommentReplies retrieves the approved direct replies of a comment,
+// oldest first, with their own reply counts
+func ListCommentReplies(ctx context.Context, db *sql.DB, parentID, limit, offset int) ([]Comment, error) {
+	query := `
+        SELECT ` + threadColumns + `
+        FROM comments c
+        WHERE c.parent_id = $1 AND c.status = 'approved' AND c.deleted_at IS NULL
+        ORDER BY c.tanggal_dibuat ASC, c.komentar_id ASC
+        LIMIT NULLIF($2, 0) OFFSET $3
+    `
+
+	rows, err := db.QueryContext(ctx, query, parentID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanThreadComments(rows)
+}
+
+// CountCommentReplies retrieves the number of approved direct replies
+func CountCommentReplies(ctx context.Context, db *sql.DB, parentID int) (int, error) {
+	query := `SELECT COUNT(*) FROM comments WHERE parent_id = $1 AND status = 'approved' AND deleted_at IS NULL`
+	var count int
+	if err := db.QueryRowContext(ctx, query, parentID).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// LoadCommentTrees fills Replies of the given threads with all approved
+// descendants. Replies of rejected or pending comments are not loaded.
+func LoadCommentTrees(ctx context.Context, db *sql.DB, threads []Comment) error {
+	if len(threads) == 0 {
+		return nil
+	}
+
+	ids := make([]int, len(threads))
+	for i, t := range threads {
+		ids[i] = t.KomentarID
+	}
+
+	query := `
+        WITH RECURSIVE tree AS (
+            SELECT c.komentar_id FROM comments c
+            WHERE c.parent_id = ANY($1) AND c.status = 'approved' AND c.deleted_at IS NULL
+            UNION ALL
+            SELECT c.komentar_id FROM comments c
+            JOIN tree t ON c.parent_id = t.komentar_id
+            WHERE c.status = 'approved' AND c.deleted_at IS NULL
+        )
+        SELECT ` + threadColumns + `
+        FROM comments c
+        WHERE c.komentar_id IN (SELECT komentar_id FROM tree)
+        ORDER BY c.depth DESC, c.tanggal_dibuat ASC, c.komentar_id ASC
+    `
+
+	rows, err := db.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("failed to load comment replies: %w", err)
+	}
+	defer rows.Close()
+
+	replies, err := scanThreadComments(rows)
+	if err != nil {
+		return err
+	}
+
+	// Deepest replies come first, so every reply is complete before it is
+	// attached to its parent
+	children := make(map[int][]Comment)
+	for _, r := range replies {
+		r.Replies = children[r.KomentarID]
+		children[*r.ParentID] = append(children[*r.ParentID], r)
+	}
+
+	for i := range threads {
+		threads[i].Replies = children[threads[i].KomentarID]
+	}
+	return nil
+}
@@ -6,6 +6,8 @@ import (
 	"errors"
 	"fmt"
 	"time"
+
+	"github.com/lib/pq"
 )
 
 type Comment struct {
@@ -15,8 +17,16 @@ type Comment struct {
 	Status            string    `json:"status"`
 	UserID            *int      `json:"user_id,omitempty"`
 	ArtikelID         int       `json:"artikel_id"`
+	ParentID          *int      `json:"parent_id,omitempty"`
+	Depth             int       `json:"depth"`
 	TanggalDibuat     time.Time `json:"tanggal_dibuat"`
 	TanggalDiperbarui time.Time `json:"tanggal_diperbarui"`
+	// Automated moderation result (populated for moderators)
+	ModerationScore   *int     `json:"moderation_score,omitempty"`
+	ModerationReasons []string `json:"moderation_reasons,omitempty"`
+	// Thread data (populated by the thread listings)
+	ReplyCount int       `json:"reply_count"`
+	Replies    []Comment `json:"replies,omitempty"`
 }
 
 type CommentWithAuthor struct {
@@ -41,20 +51,25 @@ type CommentInput struct {
 // ========================================
 
 // CreateCommentSimple creates a new comment (simpler signature for handlers)
-func CreateCommentSimple(db *sql.DB, comment *Comment) (*Comment, error) {
+func CreateCommentSimple(ctx context.Context, db *sql.DB, comment *Comment) (*Comment, error) {
 	query := `
-        INSERT INTO comments (konten, nama_pengguna, status, user_id, artikel_id)
-        VALUES ($1, $2, $3, $4, $5)
+        INSERT INTO comments (konten, nama_pengguna, status, user_id, artikel_id, parent_id, depth,
+                              moderation_score, moderation_reasons)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, COALESCE($9, '{}'::text[]))
         RETURNING komentar_id, tanggal_dibuat, tanggal_diperbarui
     `
 
-	err := db.QueryRow(
+	err := db.QueryRowContext(ctx,
 		query,
 		comment.Konten,
 		comment.NamaPengguna,
 		comment.Status,
 		comment.UserID,
 		comment.ArtikelID,
+		comment.ParentID,
+		comment.Depth,
+		comment.ModerationScore,
+		pq.Array(comment.ModerationReasons),
 	).Scan(&comment.KomentarID, &comment.TanggalDibuat, &comment.TanggalDiperbarui)
 
 	if err != nil {
@@ -65,18 +80,18 @@ func CreateCommentSimple(db *sql.DB, comment *Comment) (*Comment, error) {
 }
 
 // GetCommentByIDSimple retrieves a single comment by ID (simpler signature)
-func GetCommentByIDSimple(db *sql.DB, commentID int) (*Comment, error) {
+func GetCommentByIDSimple(ctx context.Context, db *sql.DB, commentID int) (*Comment, error) {
 	query := `
-        SELECT komentar_id, konten, nama_pengguna, status, user_id, artikel_id, 
+        SELECT komentar_id, konten, nama_pengguna, status, user_id, artikel_id, parent_id, depth,
                tanggal_dibuat, tanggal_diperbarui
         FROM comments
-        WHERE komentar_id = $1
+        WHERE komentar_id = $1 AND deleted_at IS NULL
     `
 
 	var c Comment
-	err := db.QueryRow(query, commentID).Scan(
+	err := db.QueryRowContext(ctx, query, commentID).Scan(
 		&c.KomentarID, &c.Konten, &c.NamaPengguna, &c.Status,
-		&c.UserID, &c.ArtikelID, &c.TanggalDibuat, &c.TanggalDiperbarui,
+		&c.UserID, &c.ArtikelID, &c.ParentID, &c.Depth, &c.TanggalDibuat, &c.TanggalDiperbarui,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -88,17 +103,19 @@ func GetCommentByIDSimple(db *sql.DB, commentID int) (*Comment, error) {
 	return &c, nil
 }
 
-// GetCommentsByUserID retrieves all comments by a specific user
-func GetCommentsByUserID(db *sql.DB, userID int) ([]Comment, error) {
+// GetCommentsByUserID retrieves comments by a specific user, newest first.
+// A limit of 0 returns all of them.
+func GetCommentsByUserID(ctx context.Context, db *sql.DB, userID int, limit int, offset int) ([]Comment, error) {
 	query := `
-        SELECT komentar_id, konten, nama_pengguna, status, user_id, artikel_id, 
+        SELECT komentar_id, konten, nama_pengguna, status, user_id, artikel_id, parent_id, depth,
                tanggal_dibuat, tanggal_diperbarui
         FROM comments
-        WHERE user_id = $1
+        WHERE user_id = $1 AND deleted_at IS NULL
         ORDER BY tanggal_dibuat DESC
+        LIMIT NULLIF($2, 0) OFFSET $3
     `
 
-	rows, err := db.Query(query, userID)
+	rows, err := db.QueryContext(ctx, query, userID, limit, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -109,7 +126,7 @@ func GetCommentsByUserID(db *sql.DB, userID int) ([]Comment, error) {
 		var c Comment
 		err := rows.Scan(
 			&c.KomentarID, &c.Konten, &c.NamaPengguna, &c.Status,
-			&c.UserID, &c.ArtikelID, &c.TanggalDibuat, &c.TanggalDiperbarui,
+			&c.UserID, &c.ArtikelID, &c.ParentID, &c.Depth, &c.TanggalDibuat, &c.TanggalDiperbarui,
 		)
 		if err != nil {
 			return nil, err
@@ -124,20 +141,21 @@ func GetCommentsByUserID(db *sql.DB, userID int) ([]Comment, error) {
 	return comments, nil
 }
 
-// UpdateCommentSimple updates a comment's content and status (simpler signature)
-func UpdateCommentSimple(db *sql.DB, commentID int, konten string, status string) (*Comment, error) {
+// UpdateCommentSimple updates a comment's content, status and moderation
+// result in one statement (simpler signature)
+func UpdateCommentSimple(ctx context.Context, db *sql.DB, commentID int, konten string, status string, score int, reasons []string) (*Comment, error) {
 	query := `
         UPDATE comments
-        SET konten = $1, status = $2
-        WHERE komentar_id = $3
-        RETURNING komentar_id, konten, nama_pengguna, status, user_id, artikel_id, 
+        SET konten = $1, status = $2, moderation_score = $3, moderation_reasons = $4
+        WHERE komentar_id = $5 AND deleted_at IS NULL
+        RETURNING komentar_id, konten, nama_pengguna, status, user_id, artikel_id, parent_id, depth,
                   tanggal_dibuat, tanggal_diperbarui
     `
 
 	var c Comment
-	err := db.QueryRow(query, konten, status, commentID).Scan(
+	err := db.QueryRowContext(ctx, query, konten, status, score, pq.Array(reasons), commentID).Scan(
 		&c.KomentarID, &c.Konten, &c.NamaPengguna, &c.Status,
-		&c.UserID, &c.ArtikelID, &c.TanggalDibuat, &c.TanggalDiperbarui,
+		&c.UserID, &c.ArtikelID, &c.ParentID, &c.Depth, &c.TanggalDibuat, &c.TanggalDiperbarui,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update comment: %w", err)
@@ -146,33 +164,43 @@ func UpdateCommentSimple(db *sql.DB, commentID int, konten string, status string
 	return &c, nil
 }
 
-// DeleteCommentSimple deletes a comment by ID (simpler signature, no ownership check)
-func DeleteCommentSimple(db *sql.DB, commentID int) error {
-	query := `DELETE FROM comments WHERE komentar_id = $1`
-	result, err := db.Exec(query, commentID)
-	if err != nil {
-		return fmt.Errorf("error deleting comment: %w", err)
-	}
+// DeleteCommentSimple moves a comment and its replies to the trash (simpler
+// signature, no ownership check)
+func DeleteCommentSimple(ctx context.Context, db *sql.DB, commentID int) error {
+	return auditedChange(ctx, db, "comment.delete", auditComment, commentID, func(tx *sql.Tx) error {
+		return softDeleteCommentTx(ctx, tx, commentID)
+	})
+}
 
-	rowsAffected, err := result.RowsAffected()
+// softDeleteCommentTx moves a comment and the replies below it to the trash
+// with the same deleted_at, so restoring the comment brings them back too
+func softDeleteCommentTx(ctx context.Context, tx *sql.Tx, commentID int) error {
+	res, err := tx.ExecContext(ctx, `
+        WITH RECURSIVE subtree AS (
+            SELECT komentar_id FROM comments WHERE komentar_id = $1 AND deleted_at IS NULL
+            UNION ALL
+            SELECT c.komentar_id FROM comments c JOIN subtree s ON c.parent_id = s.komentar_id
+            WHERE c.deleted_at IS NULL
+        )
+        UPDATE comments SET deleted_at = NOW()
+        WHERE komentar_id IN (SELECT komentar_id FROM subtree)
+    `, commentID)
 	if err != nil {
-		return err
+		return fmt.Errorf("error deleting comment ID %d: %w", commentID, err)
 	}
-
-	if rowsAffected == 0 {
+	if n, _ := res.RowsAffected(); n == 0 {
 		return sql.ErrNoRows
 	}
-
 	return nil
 }
 
 // GetAllComments retrieves all comments with optional status filter
-func GetAllComments(db *sql.DB, status string, limit int, offset int) ([]Comment, error) {
+func GetAllComments(ctx context.Context, db *sql.DB, status string, limit int, offset int) ([]Comment, error) {
 	query := `
-        SELECT komentar_id, konten, nama_pengguna, status, user_id, artikel_id, 
-               tanggal_dibuat, tanggal_diperbarui
+        SELECT komentar_id, konten, nama_pengguna, status, user_id, artikel_id, parent_id, depth,
+               tanggal_dibuat, tanggal_diperbarui, moderation_score, moderation_reasons
         FROM comments
-        WHERE 1=1
+        WHERE deleted_at IS NULL
     `
 	args := []interface{}{}
 	argIndex := 1
@@ -196,7 +224,7 @@ func GetAllComments(db *sql.DB, status string, limit int, offset int) ([]Comment
 		args = append(args, offset)
 	}
 
-	rows, err := db.Query(query, args...)
+	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -207,7 +235,8 @@ func GetAllComments(db *sql.DB, status string, limit int, offset int) ([]Comment
 		var c Comment
 		err := rows.Scan(
 			&c.KomentarID, &c.Konten, &c.NamaPengguna, &c.Status,
-			&c.UserID, &c.ArtikelID, &c.TanggalDibuat, &c.TanggalDiperbarui,
+			&c.UserID, &c.ArtikelID, &c.ParentID, &c.Depth, &c.TanggalDibuat, &c.TanggalDiperbarui,
+			&c.ModerationScore, pq.Array(&c.ModerationReasons),
 		)
 		if err != nil {
 			return nil, err
@@ -230,7 +259,7 @@ func GetAllComments(db *sql.DB, status string, limit int, offset int) ([]Comment
 func CreateComment(ctx context.Context, db *sql.DB, userID *int, req *CommentRequest) (*Comment, error) {
 	// Check if article exists and is published
 	var articleExists bool
-	checkQuery := `SELECT EXISTS(SELECT 1 FROM articles WHERE artikel_id = $1 AND status = 'published')`
+	checkQuery := `SELECT EXISTS(SELECT 1 FROM articles WHERE artikel_id = $1 AND status = 'published' AND deleted_at IS NULL)`
 	err := db.QueryRowContext(ctx, checkQuery, req.ArtikelID).Scan(&articleExists)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check article existence: %w", err)
@@ -260,7 +289,7 @@ func CreateComment(ctx context.Context, db *sql.DB, userID *int, req *CommentReq
 func CreateCommentTx(ctx context.Context, tx *sql.Tx, userID *int, req *CommentRequest) (*Comment, error) {
 	// Check if article exists and is published
 	var articleExists bool
-	checkQuery := `SELECT EXISTS(SELECT 1 FROM articles WHERE artikel_id = $1 AND status = 'published')`
+	checkQuery := `SELECT EXISTS(SELECT 1 FROM articles WHERE artikel_id = $1 AND status = 'published' AND deleted_at IS NULL)`
 	err := tx.QueryRowContext(ctx, checkQuery, req.ArtikelID).Scan(&articleExists)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check article existence: %w", err)
@@ -289,20 +318,20 @@ func CreateCommentTx(ctx context.Context, tx *sql.Tx, userID *int, req *CommentR
 // GetCommentByID retrieves a single comment by ID with context
 func GetCommentByID(ctx context.Context, db *sql.DB, commentID int) (*CommentWithAuthor, error) {
 	query := `
-        SELECT c.komentar_id, c.artikel_id, c.user_id, c.konten, c.status,
+        SELECT c.komentar_id, c.artikel_id, c.user_id, c.konten, c.status, c.parent_id, c.depth,
                c.tanggal_dibuat, c.tanggal_diperbarui,
                u.username, u.email, a.judul
         FROM comments c
         LEFT JOIN users u ON c.user_id = u.user_id
         JOIN articles a ON c.artikel_id = a.artikel_id
-        WHERE c.komentar_id = $1
+        WHERE c.komentar_id = $1 AND c.deleted_at IS NULL
     `
 
 	var comment CommentWithAuthor
 	err := db.QueryRowContext(ctx, query, commentID).Scan(
 		&comment.KomentarID, &comment.ArtikelID, &comment.UserID,
-		&comment.Konten, &comment.Status, &comment.TanggalDibuat, &comment.TanggalDiperbarui,
-		&comment.AuthorUsername, &comment.AuthorEmail, &comment.ArticleTitle)
+		&comment.Konten, &comment.Status, &comment.ParentID, &comment.Depth,
+		&comment.TanggalDibuat, &comment.TanggalDiperbarui, &comment.AuthorUsername, &comment.AuthorEmail, &comment.ArticleTitle)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errors.New("comment not found")
@@ -313,24 +342,20 @@ func GetCommentByID(ctx context.Context, db *sql.DB, commentID int) (*CommentWit
 	return &comment, nil
 }
 
-// GetCommentsByArticleID retrieves all comments for an article
-func GetCommentsByArticleID(db *sql.DB, artikelID int, status string) ([]Comment, error) {
+// GetCommentsByArticleID retrieves comments for an article, newest first.
+// A limit of 0 returns all of them.
+func GetCommentsByArticleID(ctx context.Context, db *sql.DB, artikelID int, status string, limit int, offset int) ([]Comment, error) {
 	query := `
-        SELECT komentar_id, konten, nama_pengguna, status, user_id, artikel_id, 
+        SELECT komentar_id, konten, nama_pengguna, status, user_id, artikel_id, parent_id, depth,
                tanggal_dibuat, tanggal_diperbarui
         FROM comments
-        WHERE artikel_id = $1
+        WHERE artikel_id = $1 AND ($2 = '' OR status = $2) AND deleted_at IS NULL
+        ORDER BY tanggal_dibuat DESC
+        LIMIT NULLIF($3, 0) OFFSET $4
     `
-	args := []interface{}{artikelID}
-
-	if status != "" {
-		query += " AND status = $2"
-		args = append(args, status)
-	}
+	args := []interface{}{artikelID, status, limit, offset}
 
-	query += " ORDER BY tanggal_dibuat DESC"
-
-	rows, err := db.Query(query, args...)
+	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -341,7 +366,7 @@ func GetCommentsByArticleID(db *sql.DB, artikelID int, status string) ([]Comment
 		var c Comment
 		err := rows.Scan(
 			&c.KomentarID, &c.Konten, &c.NamaPengguna, &c.Status,
-			&c.UserID, &c.ArtikelID, &c.TanggalDibuat, &c.TanggalDiperbarui,
+			&c.UserID, &c.ArtikelID, &c.ParentID, &c.Depth, &c.TanggalDibuat, &c.TanggalDiperbarui,
 		)
 		if err != nil {
 			return nil, err
@@ -357,20 +382,20 @@ func GetCommentsByArticleID(db *sql.DB, artikelID int, status string) ([]Comment
 }
 
 // GetApprovedCommentsByArticleID retrieves only approved comments for public view
-func GetApprovedCommentsByArticleID(db *sql.DB, artikelID int) ([]Comment, error) {
-	return GetCommentsByArticleID(db, artikelID, "approved")
+func GetApprovedCommentsByArticleID(ctx context.Context, db *sql.DB, artikelID int) ([]Comment, error) {
+	return GetCommentsByArticleID(ctx, db, artikelID, "approved", 0, 0)
 }
 
 // ListCommentsByArticle retrieves comments for an article with pagination
 func ListCommentsByArticle(ctx context.Context, db *sql.DB, articleID int, limit, offset int) ([]CommentWithAuthor, error) {
 	query := `
-        SELECT c.komentar_id, c.artikel_id, c.user_id, c.konten, c.status,
+        SELECT c.komentar_id, c.artikel_id, c.user_id, c.konten, c.status, c.parent_id, c.depth,
                c.tanggal_dibuat, c.tanggal_diperbarui,
                u.username, u.email, a.judul
         FROM comments c
         LEFT JOIN users u ON c.user_id = u.user_id
         JOIN articles a ON c.artikel_id = a.artikel_id
-        WHERE c.artikel_id = $1
+        WHERE c.artikel_id = $1 AND c.deleted_at IS NULL
         ORDER BY c.tanggal_dibuat ASC
         LIMIT $2 OFFSET $3
     `
@@ -386,8 +411,8 @@ func ListCommentsByArticle(ctx context.Context, db *sql.DB, articleID int, limit
 		var comment CommentWithAuthor
 		err := rows.Scan(
 			&comment.KomentarID, &comment.ArtikelID, &comment.UserID,
-			&comment.Konten, &comment.Status, &comment.TanggalDibuat, &comment.TanggalDiperbarui,
-			&comment.AuthorUsername, &comment.AuthorEmail, &comment.ArticleTitle)
+			&comment.Konten, &comment.Status, &comment.ParentID, &comment.Depth,
+			&comment.TanggalDibuat, &comment.TanggalDiperbarui, &comment.AuthorUsername, &comment.AuthorEmail, &comment.ArticleTitle)
 		if err != nil {
 			return nil, err
 		}
@@ -402,15 +427,15 @@ func ListCommentsByArticle(ctx context.Context, db *sql.DB, articleID int, limit
 }
 
 // ListAllComments retrieves all comments with optional status filter (for admin)
-func ListAllComments(db *sql.DB, status string, limit int, offset int) ([]Comment, error) {
-	return GetAllComments(db, status, limit, offset)
+func ListAllComments(ctx context.Context, db *sql.DB, status string, limit int, offset int) ([]Comment, error) {
+	return GetAllComments(ctx, db, status, limit, offset)
 }
 
 // UpdateComment updates the content of an existing comment with ownership check
 func UpdateComment(ctx context.Context, db *sql.DB, commentID int, userID *int, konten string) (*Comment, error) {
 	// Check if comment exists and user owns it
 	var existingUserID *int
-	err := db.QueryRowContext(ctx, "SELECT user_id FROM comments WHERE komentar_id = $1", commentID).Scan(&existingUserID)
+	err := db.QueryRowContext(ctx, "SELECT user_id FROM comments WHERE komentar_id = $1 AND deleted_at IS NULL", commentID).Scan(&existingUserID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errors.New("comment not found")
@@ -432,15 +457,15 @@ func UpdateComment(ctx context.Context, db *sql.DB, commentID int, userID *int,
 	query := `
         UPDATE comments
         SET konten = $1, status = 'pending'
-        WHERE komentar_id = $2
-        RETURNING komentar_id, konten, nama_pengguna, status, user_id, artikel_id, 
+        WHERE komentar_id = $2 AND deleted_at IS NULL
+        RETURNING komentar_id, konten, nama_pengguna, status, user_id, artikel_id, parent_id, depth,
                   tanggal_dibuat, tanggal_diperbarui
     `
 
 	var comment Comment
 	err = db.QueryRowContext(ctx, query, konten, commentID).Scan(
 		&comment.KomentarID, &comment.Konten, &comment.NamaPengguna, &comment.Status,
-		&comment.UserID, &comment.ArtikelID, &comment.TanggalDibuat, &comment.TanggalDiperbarui)
+		&comment.UserID, &comment.ArtikelID, &comment.ParentID, &comment.Depth, &comment.TanggalDibuat, &comment.TanggalDiperbarui)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update comment: %w", err)
 	}
@@ -448,21 +473,49 @@ func UpdateComment(ctx context.Context, db *sql.DB, commentID int, userID *int,
 	return &comment, nil
 }
 
-// UpdateCommentStatus updates the status of a comment (for moderation)
-func UpdateCommentStatus(db *sql.DB, id int, status string) (*Comment, error) {
+// UpdateCommentStatus updates the status of a comment (for moderation).
+//
+// Replies follow their parent: a rejected comment hides its whole subtree
+// from readers, and replies in it that were still pending are rejected as
+// well so they leave the moderation queue. Approved replies keep their
+// status and reappear if the parent is approved again.
+func UpdateCommentStatus(ctx context.Context, db *sql.DB, id int, status string) (*Comment, error) {
 	query := `
         UPDATE comments
         SET status = $1
-        WHERE komentar_id = $2
-        RETURNING komentar_id, konten, nama_pengguna, status, user_id, artikel_id, 
+        WHERE komentar_id = $2 AND deleted_at IS NULL
+        RETURNING komentar_id, konten, nama_pengguna, status, user_id, artikel_id, parent_id, depth,
                   tanggal_dibuat, tanggal_diperbarui
     `
 
 	var c Comment
-	err := db.QueryRow(query, status, id).Scan(
-		&c.KomentarID, &c.Konten, &c.NamaPengguna, &c.Status,
-		&c.UserID, &c.ArtikelID, &c.TanggalDibuat, &c.TanggalDiperbarui,
-	)
+	err := withTransaction(ctx, db, func(tx *sql.Tx) error {
+		err := auditedChangeTx(ctx, tx, "comment.moderate", auditComment, id, func(tx *sql.Tx) error {
+			return tx.QueryRowContext(ctx, query, status, id).Scan(
+				&c.KomentarID, &c.Konten, &c.NamaPengguna, &c.Status,
+				&c.UserID, &c.ArtikelID, &c.ParentID, &c.Depth, &c.TanggalDibuat, &c.TanggalDiperbarui,
+			)
+		})
+		if err != nil {
+			return err
+		}
+
+		if status == "rejected" {
+			cascade := `
+                WITH RECURSIVE subtree AS (
+                    SELECT komentar_id FROM comments WHERE parent_id = $1 AND deleted_at IS NULL
+                    UNION ALL
+                    SELECT c.komentar_id FROM comments c JOIN subtree s ON c.parent_id = s.komentar_id
+                )
+                UPDATE comments SET status = 'rejected'
+                WHERE komentar_id IN (SELECT komentar_id FROM subtree) AND status = 'pending'
+            `
+			if _, err := tx.ExecContext(ctx, cascade, id); err != nil {
+				return fmt.Errorf("failed to reject replies: %w", err)
+			}
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -470,50 +523,20 @@ func UpdateCommentStatus(db *sql.DB, id int, status string) (*Comment, error) {
 	return &c, nil
 }
 
-// DeleteComment deletes a comment by ID with ownership check
+// DeleteComment moves a comment and its replies to the trash with ownership
+// check
 func DeleteComment(ctx context.Context, db *sql.DB, commentID int, userID *int) error {
-	// Check if comment exists and user owns it
-	var existingUserID *int
-	err := db.QueryRowContext(ctx, "SELECT user_id FROM comments WHERE komentar_id = $1", commentID).Scan(&existingUserID)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return sql.ErrNoRows
-		}
-		return err
-	}
-
-	// Check ownership (only comment owner can delete)
-	if existingUserID == nil && userID != nil {
-		return errors.New("unauthorized: anonymous comment cannot be deleted")
-	}
-	if existingUserID != nil && userID != nil && *existingUserID != *userID {
-		return errors.New("unauthorized: you can only delete your own comments")
-	}
-	if existingUserID != nil && userID == nil {
-		return errors.New("unauthorized: login required to delete comment")
-	}
-
-	res, err := db.ExecContext(ctx, "DELETE FROM comments WHERE komentar_id = $1", commentID)
-	if err != nil {
-		return fmt.Errorf("error deleting comment ID %d: %w", commentID, err)
-	}
-
-	count, err := res.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("error getting rows affected for comment ID %d delete: %w", commentID, err)
-	}
-	if count == 0 {
-		return sql.ErrNoRows
-	}
-
-	return nil
+	return withTransaction(ctx, db, func(tx *sql.Tx) error {
+		return DeleteCommentTx(ctx, tx, commentID, userID)
+	})
 }
 
-// DeleteCommentTx deletes a comment by ID within a transaction
+// DeleteCommentTx moves a comment and its replies to the trash within a
+// transaction
 func DeleteCommentTx(ctx context.Context, tx *sql.Tx, commentID int, userID *int) error {
 	// Check if comment exists and user owns it
 	var existingUserID *int
-	err := tx.QueryRowContext(ctx, "SELECT user_id FROM comments WHERE komentar_id = $1", commentID).Scan(&existingUserID)
+	err := tx.QueryRowContext(ctx, "SELECT user_id FROM comments WHERE komentar_id = $1 AND deleted_at IS NULL", commentID).Scan(&existingUserID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return sql.ErrNoRows
@@ -532,27 +555,49 @@ func DeleteCommentTx(ctx context.Context, tx *sql.Tx, commentID int, userID *int
 		return errors.New("unauthorized: login required to delete comment")
 	}
 
-	res, err := tx.ExecContext(ctx, "DELETE FROM comments WHERE komentar_id = $1", commentID)
+	return softDeleteCommentTx(ctx, tx, commentID)
+}
+
+// GetCommentCount retrieves the number of comments for an article
+func GetCommentCount(ctx context.Context, db *sql.DB, articleID int) (int, error) {
+	query := `SELECT COUNT(*) FROM comments WHERE artikel_id = $1 AND deleted_at IS NULL`
+	var count int
+	err := db.QueryRowContext(ctx, query, articleID).Scan(&count)
 	if err != nil {
-		return fmt.Errorf("error executing delete for comment ID %d in tx: %w", commentID, err)
+		return 0, err
 	}
+	return count, nil
+}
 
-	count, err := res.RowsAffected()
+// CountCommentsByArticle retrieves the number of comments for an article with
+// the given status, or all statuses when status is empty
+func CountCommentsByArticle(ctx context.Context, db *sql.DB, articleID int, status string) (int, error) {
+	query := `SELECT COUNT(*) FROM comments WHERE artikel_id = $1 AND ($2 = '' OR status = $2) AND deleted_at IS NULL`
+	var count int
+	err := db.QueryRowContext(ctx, query, articleID, status).Scan(&count)
 	if err != nil {
-		return fmt.Errorf("error getting rows affected for comment ID %d delete in tx: %w", commentID, err)
-	}
-	if count == 0 {
-		return sql.ErrNoRows
+		return 0, err
 	}
+	return count, nil
+}
 
-	return nil
+// CountCommentsByUserID retrieves the number of comments written by a user
+func CountCommentsByUserID(ctx context.Context, db *sql.DB, userID int) (int, error) {
+	query := `SELECT COUNT(*) FROM comments WHERE user_id = $1 AND deleted_at IS NULL`
+	var count int
+	err := db.QueryRowContext(ctx, query, userID).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
 }
 
-// GetCommentCount retrieves the number of comments for an article
-func GetCommentCount(ctx context.Context, db *sql.DB, articleID int) (int, error) {
-	query := `SELECT COUNT(*) FROM comments WHERE artikel_id = $1`
+// CountComments retrieves the number of comments with the given status, or
+// all comments when status is empty
+func CountComments(ctx context.Context, db *sql.DB, status string) (int, error) {
+	query := `SELECT COUNT(*) FROM comments WHERE ($1 = '' OR status = $1) AND deleted_at IS NULL`
 	var count int
-	err := db.QueryRowContext(ctx, query, articleID).Scan(&count)
+	err := db.QueryRowContext(ctx, query, status).Scan(&count)
 	if err != nil {
 		return 0, err
 	}
@@ -561,7 +606,7 @@ func GetCommentCount(ctx context.Context, db *sql.DB, articleID int) (int, error
 
 // GetTotalCommentCount retrieves the total number of comments
 func GetTotalCommentCount(ctx context.Context, db *sql.DB) (int, error) {
-	query := `SELECT COUNT(*) FROM comments`
+	query := `SELECT COUNT(*) FROM comments WHERE deleted_at IS NULL`
 	var count int
 	err := db.QueryRowContext(ctx, query).Scan(&count)
 	if err != nil {
@@ -571,6 +616,6 @@ func GetTotalCommentCount(ctx context.Context, db *sql.DB) (int, error) {
 }
 
 // GetPendingComments retrieves all pending comments for moderation
-func GetPendingComments(db *sql.DB, limit int, offset int) ([]Comment, error) {
-	return GetAllComments(db, "pending", limit, offset)
+func GetPendingComments(ctx context.Context, db *sql.DB, limit int, offset int) ([]Comment, error) {
+	return GetAllComments(ctx, db, "pending", limit, offset)
 }
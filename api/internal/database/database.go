@@ -1,7 +1,9 @@
 package database
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -14,18 +16,24 @@ type DB struct {
 }
 
 func NewConnection() (*DB, error) {
-	// Sesuaikan dengan .env file Anda
-	host := getEnv("DB_HOST", "localhost")
-	port := getEnv("DB_PORT", "5432")
-	user := getEnv("DB_USER", "postgres")
-	password := getEnv("DB_PASSWORD", "admin") // sesuai .env Anda
-	dbname := getEnv("DB_NAME", "news")        // sesuai .env Anda
-	sslmode := getEnv("DB_SSLMODE", "disable")
+	// DATABASE_URL wins, otherwise the URL is built from the DB_* variables
+	connStr := os.Getenv("DATABASE_URL")
+	if connStr == "" {
+		host := getEnv("DB_HOST", "localhost")
+		port := getEnv("DB_PORT", "5432")
+		user := getEnv("DB_USER", "postgres")
+		password := os.Getenv("DB_PASSWORD")
+		dbname := getEnv("DB_NAME", "Winnicode")
+		sslmode := getEnv("DB_SSLMODE", "disable")
 
-	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-		host, port, user, password, dbname, sslmode)
+		if password == "" {
+			return nil, errors.New("database password required: set DB_PASSWORD or DATABASE_URL")
+		}
 
-	log.Printf("Attempting to connect to database: %s", dbname)
+		connStr = fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+			host, port, user, password, dbname, sslmode)
+		log.Printf("Attempting to connect to database: %s", dbname)
+	}
 
 	db, err := sql.Open("postgres", connStr)
 	if err != nil {
@@ -41,13 +49,19 @@ func NewConnection() (*DB, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	log.Println("Database connected successfully")
 	return &DB{db}, nil
 }
 
-// ACID Transaction wrapper
-func (db *DB) WithTransaction(fn func(*sql.Tx) error) error {
-	tx, err := db.Begin()
+// ACID Transaction wrapper. fn's error rolls back everything it did;
+// returning nil commits.
+func (db *DB) WithTransaction(ctx context.Context, fn func(*sql.Tx) error) error {
+	return withTransaction(ctx, db.DB, fn)
+}
+
+// withTransaction is DB.WithTransaction for the functions that take the
+// plain *sql.DB
+func withTransaction(ctx context.Context, db *sql.DB, fn func(*sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
@@ -61,7 +75,7 @@ func (db *DB) WithTransaction(fn func(*sql.Tx) error) error {
 
 	if err := fn(tx); err != nil {
 		if rbErr := tx.Rollback(); rbErr != nil {
-			return fmt.Errorf("transaction error: %v, rollback error: %v", err, rbErr)
+			return fmt.Errorf("transaction error: %w, rollback error: %v", err, rbErr)
 		}
 		return err
 	}
@@ -0,0 +1,220 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// ========================================
+// SQLMOCK HELPERS
+// ========================================
+
+// newMockDB returns a DB backed by sqlmock. Unmet expectations fail the
+// test when it ends.
+func newMockDB(t *testing.T) (*DB, sqlmock.Sqlmock) {
+	t.Helper()
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+		conn.Close()
+	})
+	return &DB{conn}, mock
+}
+
+// expectSnapshot expects an audit snapshot of one row of table
+func expectSnapshot(mock sqlmock.Sqlmock, table string) {
+	mock.ExpectQuery(`SELECT to_jsonb\(t\) - \$2::text\[\] FROM ` + table + ` t`).
+		WillReturnRows(sqlmock.NewRows([]string{"snapshot"}).AddRow([]byte(`{}`)))
+}
+
+var errBoom = errors.New("boom")
+
+// ========================================
+// WITH TRANSACTION
+// ========================================
+
+func TestWithTransactionCommits(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE tags").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := db.WithTransaction(context.Background(), func(tx *sql.Tx) error {
+		_, err := tx.Exec("UPDATE tags SET nama_tag = 'go'")
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWithTransactionRollsBackOnError(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE tags").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectRollback()
+
+	err := db.WithTransaction(context.Background(), func(tx *sql.Tx) error {
+		if _, err := tx.Exec("UPDATE tags SET nama_tag = 'go'"); err != nil {
+			return err
+		}
+		return errBoom
+	})
+	if !errors.Is(err, errBoom) {
+		t.Fatalf("err = %v, want %v", err, errBoom)
+	}
+}
+
+func TestWithTransactionKeepsErrorWhenRollbackFails(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectBegin()
+	mock.ExpectRollback().WillReturnError(errors.New("connection lost"))
+
+	err := db.WithTransaction(context.Background(), func(tx *sql.Tx) error {
+		return sql.ErrNoRows
+	})
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("err = %v, want sql.ErrNoRows", err)
+	}
+}
+
+func TestWithTransactionRollsBackOnPanic(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Fatalf("recovered %v, want boom", r)
+		}
+	}()
+	db.WithTransaction(context.Background(), func(tx *sql.Tx) error {
+		panic("boom")
+	})
+}
+
+// ========================================
+// SOFT DELETES
+// ========================================
+
+// A failing audit entry must undo the soft delete it describes
+func TestSoftDeletesRollBackWhenAuditFails(t *testing.T) {
+	tests := []struct {
+		name   string
+		table  string
+		delete func(ctx context.Context, db *DB) error
+	}{
+		{"user", "users", func(ctx context.Context, db *DB) error { return DeleteUser(ctx, db, 7) }},
+		{"category", "categories", func(ctx context.Context, db *DB) error { return ForceDeleteCategory(ctx, db, 7) }},
+		{"tag", "tags", func(ctx context.Context, db *DB) error { return ForceDeleteTag(ctx, db, 7) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			mock.ExpectBegin()
+			expectSnapshot(mock, tt.table)
+			mock.ExpectExec("UPDATE " + tt.table + " SET deleted_at = NOW()").
+				WithArgs(7).WillReturnResult(sqlmock.NewResult(0, 1))
+			expectSnapshot(mock, tt.table)
+			mock.ExpectExec("INSERT INTO audit_log").WillReturnError(errBoom)
+			mock.ExpectRollback()
+
+			if err := tt.delete(context.Background(), db); !errors.Is(err, errBoom) {
+				t.Fatalf("err = %v, want %v", err, errBoom)
+			}
+		})
+	}
+}
+
+func TestSoftDeleteMissingRow(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM users t`).WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+
+	if err := DeleteUser(context.Background(), db, 7); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("err = %v, want sql.ErrNoRows", err)
+	}
+}
+
+// ========================================
+// AUDITED CREATES
+// ========================================
+
+// A failing audit entry must undo the row it describes
+func TestCreatesRollBackWhenAuditFails(t *testing.T) {
+	tests := []struct {
+		name   string
+		table  string
+		row    *sqlmock.Rows
+		create func(ctx context.Context, db *sql.DB) error
+	}{
+		{"category", "categories",
+			sqlmock.NewRows([]string{"kategori_id", "nama_kategori", "deskripsi", "created_at"}).AddRow(7, "Politik", nil, time.Now()),
+			func(ctx context.Context, db *sql.DB) error {
+				_, err := CreateCategory(ctx, db, &CategoryRequest{NamaKategori: "Politik"})
+				return err
+			}},
+		{"tag", "tags",
+			sqlmock.NewRows([]string{"tag_id", "nama_tag", "created_at"}).AddRow(7, "pemilu", time.Now()),
+			func(ctx context.Context, db *sql.DB) error {
+				_, err := CreateTag(ctx, db, &TagRequest{NamaTag: "pemilu"})
+				return err
+			}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			mock.ExpectBegin()
+			mock.ExpectQuery("INSERT INTO " + tt.table).WillReturnRows(tt.row)
+			expectSnapshot(mock, tt.table)
+			mock.ExpectExec("INSERT INTO audit_log").WillReturnError(errBoom)
+			mock.ExpectRollback()
+
+			if err := tt.create(context.Background(), db.DB); !errors.Is(err, errBoom) {
+				t.Fatalf("err = %v, want %v", err, errBoom)
+			}
+		})
+	}
+}
+
+// ========================================
+// AUTHENTICATION
+// ========================================
+
+func TestAuthenticateUnknownEmail(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectQuery("FROM users").WithArgs("siapa@contoh.id").WillReturnError(sql.ErrNoRows)
+	mock.ExpectExec("INSERT INTO login_attempts").
+		WithArgs(nil, "siapa@contoh.id", sqlmock.AnyArg(), sqlmock.AnyArg(), false, LoginFailureUnknownUser).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	req := &LoginRequest{Email: "siapa@contoh.id", Password: "rahasia"}
+	if _, err := AuthenticateUser(context.Background(), db.DB, req, LoginClient{}, LockoutPolicy{}); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("err = %v, want %v", err, ErrInvalidCredentials)
+	}
+}
+
+// A failed lookup is not an unknown user: nothing is recorded and the error
+// is returned as is
+func TestAuthenticateLookupError(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectQuery("FROM users").WithArgs("pembaca@contoh.id").WillReturnError(context.DeadlineExceeded)
+
+	req := &LoginRequest{Email: "pembaca@contoh.id", Password: "rahasia"}
+	if _, err := AuthenticateUser(context.Background(), db.DB, req, LoginClient{}, LockoutPolicy{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,103 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrVerificationTokenInvalid = errors.New("email verification token is invalid or expired")
+
+// CreateEmailVerificationToken stores the hash of a verification token for
+// the given address. The token only verifies that address, so changing the
+// email in between invalidates it.
+func CreateEmailVerificationToken(ctx context.Context, db *sql.DB, userID int, email, tokenHash string, expiresAt time.Time) error {
+	_, err := db.ExecContext(ctx, `
+        INSERT INTO email_verification_tokens (user_id, email, token_hash, expires_at)
+        VALUES ($1, $2, $3, $4)
+    `, userID, email, tokenHash, expiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to create verification token: %w", err)
+	}
+	return nil
+}
+
+// VerifyEmailWithToken consumes a verification token and marks the address
+// as verified. Returns the user ID, or ErrVerificationTokenInvalid when the
+// token is unknown, used, expired or was sent to a previous address.
+func VerifyEmailWithToken(ctx context.Context, db *sql.DB, tokenHash string) (int, error) {
+	var userID int
+	err := withTransaction(ctx, db, func(tx *sql.Tx) error {
+		var email string
+		err := tx.QueryRowContext(ctx, `
+            UPDATE email_verification_tokens
+            SET used_at = NOW()
+            WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+            RETURNING user_id, email
+        `, tokenHash).Scan(&userID, &email)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrVerificationTokenInvalid
+			}
+			return fmt.Errorf("failed to use verification token: %w", err)
+		}
+
+		result, err := tx.ExecContext(ctx, `
+            UPDATE users
+            SET email_verified_at = COALESCE(email_verified_at, NOW())
+            WHERE user_id = $1 AND email = $2 AND deleted_at IS NULL
+        `, userID, email)
+		if err != nil {
+			return fmt.Errorf("failed to verify email: %w", err)
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			return ErrVerificationTokenInvalid
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return userID, nil
+}
+
+// MarkEmailVerified verifies a user's address without a token (admin
+// override). Returns sql.ErrNoRows when the user does not exist.
+func MarkEmailVerified(ctx context.Context, db *sql.DB, userID int) error {
+	return auditedChange(ctx, db, "user.verify_email", auditUser, userID, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `
+            UPDATE users
+            SET email_verified_at = COALESCE(email_verified_at, NOW())
+            WHERE user_id = $1
+        `, userID)
+		if err != nil {
+			return fmt.Errorf("failed to verify email: %w", err)
+		}
+		return nil
+	})
+}
+
+// IsEmailVerified reports whether a user confirmed their address
+func IsEmailVerified(ctx context.Context, db *sql.DB, userID int) (bool, error) {
+	var verified bool
+	err := db.QueryRowContext(ctx,
+		"SELECT email_verified_at IS NOT NULL FROM users WHERE user_id = $1",
+		userID).Scan(&verified)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return verified, err
+}
+
+// DeleteExpiredEmailVerificationTokens removes tokens that can no longer be
+// used
+func DeleteExpiredEmailVerificationTokens(ctx context.Context, db *sql.DB) error {
+	_, err := db.ExecContext(ctx,
+		"DELETE FROM email_verification_tokens WHERE expires_at < NOW() OR used_at IS NOT NULL")
+	if err != nil {
+		return fmt.Errorf("failed to cleanup verification tokens: %w", err)
+	}
+	return nil
+}
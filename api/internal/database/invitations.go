@@ -0,0 +1,184 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type UserInvitation struct {
+	InvitationID   int        `json:"invitation_id"`
+	Email          string     `json:"email"`
+	Role           string     `json:"role"`
+	InvitedBy      *int       `json:"invited_by,omitempty"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	AcceptedAt     *time.Time `json:"accepted_at,omitempty"`
+	AcceptedUserID *int       `json:"accepted_user_id,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+var (
+	ErrInvitationInvalid = errors.New("invitation is invalid or expired")
+	ErrUserExists        = errors.New("email or username already registered")
+)
+
+const invitationColumns = `
+        invitation_id, email, role, invited_by, expires_at, accepted_at, accepted_user_id, created_at
+`
+
+func scanInvitation(row interface{ Scan(...any) error }) (*UserInvitation, error) {
+	var inv UserInvitation
+	err := row.Scan(&inv.InvitationID, &inv.Email, &inv.Role, &inv.InvitedBy,
+		&inv.ExpiresAt, &inv.AcceptedAt, &inv.AcceptedUserID, &inv.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+// CreateInvitation stores a new invitation. A pending invitation for the
+// same email is replaced, so only the latest link works.
+func CreateInvitation(ctx context.Context, db *sql.DB, email, role, tokenHash string, invitedBy int, expiresAt time.Time) (*UserInvitation, error) {
+	var inv *UserInvitation
+	err := withTransaction(ctx, db, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx,
+			"DELETE FROM user_invitations WHERE LOWER(email) = LOWER($1) AND accepted_at IS NULL", email)
+		if err != nil {
+			return fmt.Errorf("failed to replace invitation: %w", err)
+		}
+
+		inv, err = scanInvitation(tx.QueryRowContext(ctx, `
+            INSERT INTO user_invitations (email, role, token_hash, invited_by, expires_at)
+            VALUES ($1, $2, $3, $4, $5)
+            RETURNING `+invitationColumns,
+			email, role, tokenHash, invitedBy, expiresAt))
+		if err != nil {
+			return fmt.Errorf("failed to create invitation: %w", err)
+		}
+
+		return auditCreatedTx(ctx, tx, "invitation.create", auditInvitation, inv.InvitationID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+// GetPendingInvitationByToken retrieves an invitation that can still be
+// accepted
+func GetPendingInvitationByToken(ctx context.Context, db *sql.DB, tokenHash string) (*UserInvitation, error) {
+	inv, err := scanInvitation(db.QueryRowContext(ctx, `
+        SELECT `+invitationColumns+`
+        FROM user_invitations
+        WHERE token_hash = $1 AND accepted_at IS NULL AND expires_at > NOW()
+    `, tokenHash))
+	if err == sql.ErrNoRows {
+		return nil, ErrInvitationInvalid
+	}
+	return inv, err
+}
+
+// ListPendingInvitations retrieves invitations that were not accepted yet,
+// newest first. Expired ones are included so admins can resend them.
+func ListPendingInvitations(ctx context.Context, db *sql.DB) ([]UserInvitation, error) {
+	rows, err := db.QueryContext(ctx, `
+        SELECT `+invitationColumns+`
+        FROM user_invitations
+        WHERE accepted_at IS NULL
+        ORDER BY created_at DESC
+    `)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invitations := []UserInvitation{}
+	for rows.Next() {
+		inv, err := scanInvitation(rows)
+		if err != nil {
+			return nil, err
+		}
+		invitations = append(invitations, *inv)
+	}
+	return invitations, rows.Err()
+}
+
+// DeleteInvitation revokes a pending invitation. Returns sql.ErrNoRows when
+// there is no such pending invitation.
+func DeleteInvitation(ctx context.Context, db *sql.DB, id int) error {
+	return auditedChange(ctx, db, "invitation.delete", auditInvitation, id, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx,
+			"DELETE FROM user_invitations WHERE invitation_id = $1 AND accepted_at IS NULL", id)
+		if err != nil {
+			return fmt.Errorf("failed to delete invitation: %w", err)
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			return sql.ErrNoRows
+		}
+		return nil
+	})
+}
+
+// AcceptInvitation consumes an invitation and creates the invited account
+// with the invitation's email and role in one transaction. The email counts
+// as verified since the invitee received the link. Returns
+// ErrInvitationInvalid or ErrUserExists.
+func AcceptInvitation(ctx context.Context, db *sql.DB, tokenHash, username, password string) (*User, error) {
+	hashedPassword, err := HashPassword(password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	var user User
+	err = withTransaction(ctx, db, func(tx *sql.Tx) error {
+		// Conditional update so an invitation can only be accepted once
+		var invitationID int
+		var email, role string
+		err := tx.QueryRowContext(ctx, `
+            UPDATE user_invitations
+            SET accepted_at = NOW()
+            WHERE token_hash = $1 AND accepted_at IS NULL AND expires_at > NOW()
+            RETURNING invitation_id, email, role
+        `, tokenHash).Scan(&invitationID, &email, &role)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrInvitationInvalid
+			}
+			return fmt.Errorf("failed to accept invitation: %w", err)
+		}
+
+		err = tx.QueryRowContext(ctx, `
+            INSERT INTO users (username, email, password, role, email_verified_at)
+            VALUES ($1, $2, $3, $4, NOW())
+            RETURNING user_id, username, email, role, tanggal_dibuat, tanggal_diperbarui, email_verified_at
+        `, username, email, hashedPassword, role).Scan(
+			&user.UserID, &user.Username, &user.Email, &user.Role,
+			&user.TanggalDibuat, &user.TanggalDiperbarui, &user.EmailVerifiedAt,
+		)
+		if err != nil {
+			var pqErr *pq.Error
+			if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+				return ErrUserExists
+			}
+			return fmt.Errorf("failed to create user: %w", err)
+		}
+
+		_, err = tx.ExecContext(ctx,
+			"UPDATE user_invitations SET accepted_user_id = $1 WHERE invitation_id = $2",
+			user.UserID, invitationID)
+		if err != nil {
+			return fmt.Errorf("failed to accept invitation: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	user.CreatedAt = user.TanggalDibuat
+	return &user, nil
+}
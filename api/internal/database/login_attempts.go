@@ -0,0 +1,193 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// LoginAttempt is one row of a user's login history
+type LoginAttempt struct {
+	AttemptID     int64     `json:"attempt_id"`
+	UserID        *int      `json:"user_id,omitempty"`
+	Email         string    `json:"email"`
+	IPAddress     string    `json:"ip_address"`
+	UserAgent     string    `json:"user_agent"`
+	Success       bool      `json:"success"`
+	FailureReason *string   `json:"failure_reason,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// LoginClient identifies where a login attempt comes from
+type LoginClient struct {
+	IPAddress string
+	UserAgent string
+}
+
+// Failure reasons stored with failed attempts
+const (
+	LoginFailureUnknownUser   = "unknown_user"
+	LoginFailureWrongPassword = "wrong_password"
+	LoginFailureLocked        = "locked"
+)
+
+// LockoutPolicy decides when an account gets locked and for how long
+type LockoutPolicy struct {
+	// MaxFailures failed attempts within Window lock the account
+	MaxFailures int
+	Window      time.Duration
+
+	// The first lock lasts BaseDuration, every further lock without a
+	// successful login in between twice as long, up to MaxDuration
+	BaseDuration time.Duration
+	MaxDuration  time.Duration
+}
+
+// DefaultLockoutPolicy locks after 5 failures in 15 minutes, starting at
+// one minute and doubling up to a day
+var DefaultLockoutPolicy = LockoutPolicy{
+	MaxFailures:  5,
+	Window:       15 * time.Minute,
+	BaseDuration: time.Minute,
+	MaxDuration:  24 * time.Hour,
+}
+
+// AccountLockedError is returned by AuthenticateUser while an account is
+// locked. New is set on the attempt that caused the lock.
+type AccountLockedError struct {
+	Until time.Time
+	New   bool
+}
+
+func (e *AccountLockedError) Error() string {
+	return fmt.Sprintf("account locked until %s", e.Until.Format(time.RFC3339))
+}
+
+// RecordLoginAttempt stores a login attempt. userID is nil when the email
+// does not belong to any account.
+func RecordLoginAttempt(ctx context.Context, db *sql.DB, userID *int, email string, client LoginClient, success bool, failureReason string) error {
+	var reason *string
+	if failureReason != "" {
+		reason = &failureReason
+	}
+
+	_, err := db.ExecContext(ctx, `
+        INSERT INTO login_attempts (user_id, email, ip_address, user_agent, success, failure_reason)
+        VALUES ($1, $2, $3, $4, $5, $6)
+    `, userID, email, client.IPAddress, client.UserAgent, success, reason)
+	if err != nil {
+		return fmt.Errorf("failed to record login attempt: %w", err)
+	}
+	return nil
+}
+
+// GetAccountLock returns when the account lock ends, or nil when the
+// account is not locked
+func GetAccountLock(ctx context.Context, db *sql.DB, userID int) (*time.Time, error) {
+	var until *time.Time
+	err := db.QueryRowContext(ctx,
+		"SELECT locked_until FROM users WHERE user_id = $1 AND locked_until > NOW()",
+		userID).Scan(&until)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return until, err
+}
+
+// LockAccountIfNeeded locks the account when it has reached the failure
+// limit of the policy. Failures before the last lock, successful login or
+// unlock do not count. Returns the end of the new lock, or nil.
+func LockAccountIfNeeded(ctx context.Context, db *sql.DB, userID int, policy LockoutPolicy) (*time.Time, error) {
+	// A single statement so concurrent failures cannot both lock or both
+	// miss the limit
+	query := `
+        UPDATE users u
+        SET locked_until = NOW() + LEAST($4::float8, $3::float8 * POWER(2, u.lockout_count)) * INTERVAL '1 second',
+            lockout_count = u.lockout_count + 1,
+            failures_reset_at = NOW()
+        WHERE u.user_id = $1
+          AND (SELECT COUNT(*) FROM login_attempts a
+               WHERE a.user_id = u.user_id AND NOT a.success
+                 AND a.created_at > GREATEST(NOW() - $2::float8 * INTERVAL '1 second',
+                                             COALESCE(u.failures_reset_at, '-infinity'))) >= $5
+        RETURNING locked_until
+    `
+
+	var until time.Time
+	err := db.QueryRowContext(ctx, query, userID,
+		policy.Window.Seconds(), policy.BaseDuration.Seconds(), policy.MaxDuration.Seconds(),
+		policy.MaxFailures).Scan(&until)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock account: %w", err)
+	}
+	return &until, nil
+}
+
+// ResetLoginFailures clears the lock and the failure count after a
+// successful login
+func ResetLoginFailures(ctx context.Context, db *sql.DB, userID int) error {
+	_, err := db.ExecContext(ctx, `
+        UPDATE users
+        SET locked_until = NULL, lockout_count = 0, failures_reset_at = NOW()
+        WHERE user_id = $1
+    `, userID)
+	if err != nil {
+		return fmt.Errorf("failed to reset login failures: %w", err)
+	}
+	return nil
+}
+
+// UnlockUser lifts an account lock and resets the backoff. Returns
+// sql.ErrNoRows when the user does not exist.
+func UnlockUser(ctx context.Context, db *sql.DB, userID int) error {
+	return auditedChange(ctx, db, "user.unlock", auditUser, userID, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `
+            UPDATE users
+            SET locked_until = NULL, lockout_count = 0, failures_reset_at = NOW()
+            WHERE user_id = $1
+        `, userID)
+		if err != nil {
+			return fmt.Errorf("failed to unlock user: %w", err)
+		}
+		return nil
+	})
+}
+
+// ListLoginAttempts retrieves a user's login history, newest first
+func ListLoginAttempts(ctx context.Context, db *sql.DB, userID, limit, offset int) ([]LoginAttempt, error) {
+	rows, err := db.QueryContext(ctx, `
+        SELECT attempt_id, user_id, email, COALESCE(ip_address, ''), COALESCE(user_agent, ''),
+               success, failure_reason, created_at
+        FROM login_attempts
+        WHERE user_id = $1
+        ORDER BY created_at DESC, attempt_id DESC
+        LIMIT NULLIF($2, 0) OFFSET $3
+    `, userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attempts := []LoginAttempt{}
+	for rows.Next() {
+		var a LoginAttempt
+		err := rows.Scan(&a.AttemptID, &a.UserID, &a.Email, &a.IPAddress, &a.UserAgent,
+			&a.Success, &a.FailureReason, &a.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		attempts = append(attempts, a)
+	}
+	return attempts, rows.Err()
+}
+
+// CountLoginAttempts retrieves the number of recorded attempts of a user
+func CountLoginAttempts(ctx context.Context, db *sql.DB, userID int) (int, error) {
+	var count int
+	err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM login_attempts WHERE user_id = $1", userID).Scan(&count)
+	return count, err
+}
@@ -0,0 +1,310 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type Media struct {
+	MediaID    int           `json:"media_id"`
+	URL        string        `json:"url"`
+	TipeMedia  string        `json:"tipe_media"`
+	ArtikelID  *int          `json:"artikel_id,omitempty"`
+	UserID     *int          `json:"user_id,omitempty"`
+	Username   *string       `json:"username,omitempty"`
+	NamaFile   string        `json:"nama_file"`
+	StorageKey string        `json:"storage_key"`
+	MimeType   string        `json:"mime_type"`
+	Ukuran     int64         `json:"ukuran"`
+	Lebar      *int          `json:"lebar,omitempty"`
+	Tinggi     *int          `json:"tinggi,omitempty"`
+	AltText    *string       `json:"alt_text,omitempty"`
+	Caption    *string       `json:"caption,omitempty"`
+	Variants   ImageVariants `json:"variants"`
+	CreatedAt  time.Time     `json:"created_at"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+}
+
+// ImageVariant is a resized copy of an uploaded image. URL points at the
+// JPEG (or PNG for transparent images) encoding, WebPURL at the WebP one.
+type ImageVariant struct {
+	URL     string `json:"url"`
+	WebPURL string `json:"webp_url,omitempty"`
+	Width   int    `json:"width"`
+	Height  int    `json:"height"`
+	Key     string `json:"key"`
+	WebPKey string `json:"webp_key,omitempty"`
+}
+
+// ImageVariants maps a size name (thumbnail, medium, large) to its variant.
+// Stored in the media.variants JSONB column.
+type ImageVariants map[string]ImageVariant
+
+func (v ImageVariants) Value() (driver.Value, error) {
+	if v == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(v)
+}
+
+func (v *ImageVariants) Scan(src interface{}) error {
+	var data []byte
+	switch s := src.(type) {
+	case nil:
+		*v = ImageVariants{}
+		return nil
+	case []byte:
+		data = s
+	case string:
+		data = []byte(s)
+	default:
+		return errors.New("unsupported type for image variants")
+	}
+	return json.Unmarshal(data, v)
+}
+
+// Keys returns the storage keys of every variant file
+func (v ImageVariants) Keys() []string {
+	var keys []string
+	for _, variant := range v {
+		if variant.Key != "" {
+			keys = append(keys, variant.Key)
+		}
+		if variant.WebPKey != "" {
+			keys = append(keys, variant.WebPKey)
+		}
+	}
+	return keys
+}
+
+type MediaFilter struct {
+	Search    string // matches nama_file, alt_text and caption
+	TipeMedia string
+	ArtikelID int
+	UserID    int
+	Limit     int
+	Offset    int
+}
+
+const mediaColumns = `
+        m.media_id, m.url, COALESCE(m.tipe_media, ''), m.artikel_id, m.user_id, u.username,
+        COALESCE(m.nama_file, ''), COALESCE(m.storage_key, ''), COALESCE(m.mime_type, ''),
+        COALESCE(m.ukuran, 0), m.lebar, m.tinggi, m.alt_text, m.caption, m.variants,
+        m.created_at, COALESCE(m.updated_at, m.created_at)
+`
+
+func scanMedia(scanner interface{ Scan(...any) error }) (*Media, error) {
+	var m Media
+	err := scanner.Scan(
+		&m.MediaID, &m.URL, &m.TipeMedia, &m.ArtikelID, &m.UserID, &m.Username,
+		&m.NamaFile, &m.StorageKey, &m.MimeType,
+		&m.Ukuran, &m.Lebar, &m.Tinggi, &m.AltText, &m.Caption, &m.Variants,
+		&m.CreatedAt, &m.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// CreateMedia records an uploaded file in the media library
+func CreateMedia(ctx context.Context, db *sql.DB, m *Media) (*Media, error) {
+	var id int
+	err := db.QueryRowContext(ctx, `
+        INSERT INTO media (url, tipe_media, artikel_id, user_id, nama_file, storage_key,
+                           mime_type, ukuran, lebar, tinggi, alt_text, caption, variants)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+        RETURNING media_id
+    `, m.URL, m.TipeMedia, m.ArtikelID, m.UserID, m.NamaFile, m.StorageKey,
+		m.MimeType, m.Ukuran, m.Lebar, m.Tinggi, m.AltText, m.Caption, m.Variants,
+	).Scan(&id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create media: %w", err)
+	}
+
+	return GetMediaByID(ctx, db, id)
+}
+
+// GetMediaByID retrieves a media item. Returns sql.ErrNoRows if it does not exist.
+func GetMediaByID(ctx context.Context, db *sql.DB, id int) (*Media, error) {
+	query := `
+        SELECT ` + mediaColumns + `
+        FROM media m
+        LEFT JOIN users u ON m.user_id = u.user_id
+        WHERE m.media_id = $1
+    `
+	return scanMedia(db.QueryRowContext(ctx, query, id))
+}
+
+func mediaFilterWhere(filter MediaFilter) (string, []interface{}) {
+	where := " WHERE 1=1"
+	args := []interface{}{}
+
+	if filter.Search != "" {
+		args = append(args, "%"+filter.Search+"%")
+		n := len(args)
+		where += fmt.Sprintf(" AND (m.nama_file ILIKE $%d OR m.alt_text ILIKE $%d OR m.caption ILIKE $%d)", n, n, n)
+	}
+	if filter.TipeMedia != "" {
+		args = append(args, filter.TipeMedia)
+		where += fmt.Sprintf(" AND m.tipe_media = $%d", len(args))
+	}
+	if filter.ArtikelID > 0 {
+		args = append(args, filter.ArtikelID)
+		where += fmt.Sprintf(" AND m.artikel_id = $%d", len(args))
+	}
+	if filter.UserID > 0 {
+		args = append(args, filter.UserID)
+		where += fmt.Sprintf(" AND m.user_id = $%d", len(args))
+	}
+
+	return where, args
+}
+
+// ListMedia retrieves media library items, newest first
+func ListMedia(ctx context.Context, db *sql.DB, filter MediaFilter) ([]Media, error) {
+	where, args := mediaFilterWhere(filter)
+	query := `
+        SELECT ` + mediaColumns + `
+        FROM media m
+        LEFT JOIN users u ON m.user_id = u.user_id
+    ` + where + " ORDER BY m.created_at DESC, m.media_id DESC"
+
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	if filter.Offset > 0 {
+		args = append(args, filter.Offset)
+		query += fmt.Sprintf(" OFFSET $%d", len(args))
+	}
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	media := []Media{}
+	for rows.Next() {
+		m, err := scanMedia(rows)
+		if err != nil {
+			return nil, err
+		}
+		media = append(media, *m)
+	}
+
+	return media, rows.Err()
+}
+
+// CountMedia returns the number of media items matching the filter
+func CountMedia(ctx context.Context, db *sql.DB, filter MediaFilter) (int, error) {
+	where, args := mediaFilterWhere(filter)
+
+	var total int
+	err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM media m"+where, args...).Scan(&total)
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// UpdateMediaMetadata replaces the alt text and caption of a media item
+func UpdateMediaMetadata(ctx context.Context, db *sql.DB, id int, altText, caption *string) (*Media, error) {
+	err := auditedChange(ctx, db, "media.update", auditMedia, id, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx,
+			"UPDATE media SET alt_text = $1, caption = $2 WHERE media_id = $3",
+			altText, caption, id)
+		if err != nil {
+			return fmt.Errorf("failed to update media: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return GetMediaByID(ctx, db, id)
+}
+
+// AttachMedia attaches a media item to an article, or detaches it when
+// artikelID is nil
+func AttachMedia(ctx context.Context, db *sql.DB, id int, artikelID *int) (*Media, error) {
+	action := "media.attach"
+	if artikelID == nil {
+		action = "media.detach"
+	}
+
+	err := auditedChange(ctx, db, action, auditMedia, id, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx,
+			"UPDATE media SET artikel_id = $1 WHERE media_id = $2",
+			artikelID, id)
+		if err != nil {
+			return fmt.Errorf("failed to attach media: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return GetMediaByID(ctx, db, id)
+}
+
+// DeleteMedia removes a media record and returns it so the caller can remove
+// the stored file
+func DeleteMedia(ctx context.Context, db *sql.DB, id int) (*Media, error) {
+	m, err := GetMediaByID(ctx, db, id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = auditedChange(ctx, db, "media.delete", auditMedia, id, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM media WHERE media_id = $1", id); err != nil {
+			return fmt.Errorf("failed to delete media: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// GetImageVariantsByURL returns the variants of the media items with the
+// given URLs, keyed by URL. URLs without variants are left out.
+func GetImageVariantsByURL(ctx context.Context, db *sql.DB, urls []string) (map[string]ImageVariants, error) {
+	result := make(map[string]ImageVariants)
+	if len(urls) == 0 {
+		return result, nil
+	}
+
+	rows, err := db.QueryContext(ctx, `
+        SELECT url, variants
+        FROM media
+        WHERE url = ANY($1) AND variants <> '{}'::jsonb
+    `, pq.Array(urls))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var url string
+		var variants ImageVariants
+		if err := rows.Scan(&url, &variants); err != nil {
+			return nil, err
+		}
+		result[url] = variants
+	}
+
+	return result, rows.Err()
+}
@@ -0,0 +1,1527 @@
+// Package memdb implements the database repositories in memory, for tests
+// that exercise handlers without Postgres
+package memdb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"news-portal-web/api/internal/database"
+)
+
+// ========================================
+// IN-MEMORY REPOSITORIES
+// ========================================
+
+var (
+	errMemoryCategoryNotFound = errors.New("category not found")
+	errMemoryTagNotFound      = errors.New("tag not found")
+	errMemoryCommentNotFound  = errors.New("comment not found")
+	errMemoryUserNotFound     = errors.New("user not found")
+	errMemoryDuplicateKey     = errors.New("duplicate key value violates unique constraint")
+	errMemoryForeignKey       = errors.New("insert violates foreign key constraint")
+)
+
+// memoryStore holds the rows behind the in-memory repositories. Deleted
+// rows are kept and flagged, like the trash, so existence checks and
+// moderation history see them just as they do in Postgres.
+type memoryStore struct {
+	mu     sync.Mutex
+	nextID int
+
+	articles   map[int]*memoryArticle
+	comments   map[int]*memoryComment
+	users      map[int]*memoryUser
+	categories map[int]*memoryCategory
+	tags       map[int]*memoryTag
+}
+
+type memoryArticle struct {
+	database.Article
+	kategoriIDs []int
+	tagIDs      []int
+	deleted     bool
+}
+
+type memoryComment struct {
+	database.Comment
+	deleted bool
+}
+
+type memoryUser struct {
+	database.User
+	deleted bool
+}
+
+type memoryCategory struct {
+	database.Category
+	deleted bool
+}
+
+type memoryTag struct {
+	database.Tag
+	deleted bool
+}
+
+// NewRepositories returns repositories that keep everything in memory.
+// They follow the Postgres repositories' ordering, soft delete and error
+// values, but do not write the audit log, keep article revisions or lock
+// accounts after failed logins, and article search is a plain substring
+// match.
+func NewRepositories() database.Repositories {
+	s := &memoryStore{
+		articles:   make(map[int]*memoryArticle),
+		comments:   make(map[int]*memoryComment),
+		users:      make(map[int]*memoryUser),
+		categories: make(map[int]*memoryCategory),
+		tags:       make(map[int]*memoryTag),
+	}
+	return database.Repositories{
+		Articles: memoryArticles{s},
+		Comments: memoryComments{s},
+		Users:    memoryUsers{s},
+		Taxonomy: memoryTaxonomy{s},
+	}
+}
+
+// newID returns the next row ID. IDs are unique across the whole store.
+// Callers hold mu.
+func (s *memoryStore) newID() int {
+	s.nextID++
+	return s.nextID
+}
+
+// ========================================
+// ARTICLES
+// ========================================
+
+type memoryArticles struct{ s *memoryStore }
+
+// article returns a copy of a with its live categories and tags attached
+func (s *memoryStore) article(a *memoryArticle) database.Article {
+	out := a.Article
+	out.Kategori = nil
+	out.Tags = nil
+	for _, id := range a.kategoriIDs {
+		if c, ok := s.categories[id]; ok && !c.deleted {
+			out.Kategori = append(out.Kategori, c.Category)
+		}
+	}
+	for _, id := range a.tagIDs {
+		if t, ok := s.tags[id]; ok && !t.deleted {
+			out.Tags = append(out.Tags, t.Tag)
+		}
+	}
+	return out
+}
+
+func (s *memoryStore) matchArticle(a *memoryArticle, filter database.ArticleFilter) bool {
+	if a.deleted {
+		return false
+	}
+	if filter.Status != "" && a.Status != filter.Status {
+		return false
+	}
+	if filter.KategoriID > 0 && !containsID(a.kategoriIDs, filter.KategoriID) {
+		return false
+	}
+	if filter.KategoriName != "" {
+		found := false
+		for _, id := range a.kategoriIDs {
+			if c, ok := s.categories[id]; ok && !c.deleted && c.NamaKategori == filter.KategoriName {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if filter.TagID > 0 && !containsID(a.tagIDs, filter.TagID) {
+		return false
+	}
+	if filter.UserID > 0 && a.UserID != filter.UserID {
+		return false
+	}
+	if filter.Search != "" {
+		keyword := strings.ToLower(filter.Search)
+		if !strings.Contains(strings.ToLower(a.Judul), keyword) &&
+			!strings.Contains(strings.ToLower(a.Konten), keyword) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *memoryStore) filterArticles(filter database.ArticleFilter) []*memoryArticle {
+	var matched []*memoryArticle
+	for _, a := range s.articles {
+		if s.matchArticle(a, filter) {
+			matched = append(matched, a)
+		}
+	}
+	return matched
+}
+
+func (r memoryArticles) List(ctx context.Context, filter database.ArticleFilter) ([]database.Article, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	matched := r.s.filterArticles(filter)
+	if filter.IsFeed() {
+		sort.Slice(matched, func(i, j int) bool {
+			return feedBefore(matched[i].Article, matched[j].Article)
+		})
+		if c := filter.Cursor; c != nil {
+			var after []*memoryArticle
+			for _, a := range matched {
+				if feedBefore(database.Article{TanggalPublikasi: &c.TanggalPublikasi, ArtikelID: c.ArtikelID}, a.Article) {
+					after = append(after, a)
+				}
+			}
+			matched = after
+		}
+	} else if filter.IsSchedule() {
+		sort.Slice(matched, func(i, j int) bool {
+			a, b := matched[i].TanggalPublikasi, matched[j].TanggalPublikasi
+			if a == nil || b == nil {
+				return b == nil && a != nil
+			}
+			if !a.Equal(*b) {
+				return a.Before(*b)
+			}
+			return matched[i].ArtikelID < matched[j].ArtikelID
+		})
+	} else {
+		sort.Slice(matched, func(i, j int) bool {
+			return newerFirst(matched[i].TanggalDibuat, matched[j].TanggalDibuat, matched[i].ArtikelID, matched[j].ArtikelID)
+		})
+	}
+
+	offset := filter.Offset
+	if filter.Cursor != nil {
+		offset = 0
+	}
+	matched = pageOf(matched, filter.Limit, offset)
+
+	var articles []database.Article
+	for _, a := range matched {
+		articles = append(articles, r.s.article(a))
+	}
+	return articles, nil
+}
+
+func (r memoryArticles) Count(ctx context.Context, filter database.ArticleFilter) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	return len(r.s.filterArticles(filter)), nil
+}
+
+func (r memoryArticles) GetByID(ctx context.Context, id int) (*database.Article, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	a, ok := r.s.articles[id]
+	if !ok || a.deleted {
+		return nil, sql.ErrNoRows
+	}
+	out := r.s.article(a)
+	return &out, nil
+}
+
+func (r memoryArticles) GetPublishedBySlug(ctx context.Context, slug string) (*database.Article, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, a := range r.s.articles {
+		if a.Slug == slug && a.Status == "published" && !a.deleted {
+			out := r.s.article(a)
+			return &out, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r memoryArticles) ListByCategory(ctx context.Context, kategoriID, limit, offset int) ([]database.Article, error) {
+	return r.List(ctx, database.ArticleFilter{Status: "published", KategoriID: kategoriID, Limit: limit, Offset: offset})
+}
+
+func (r memoryArticles) ListByTag(ctx context.Context, tagID, limit, offset int) ([]database.Article, error) {
+	return r.List(ctx, database.ArticleFilter{Status: "published", TagID: tagID, Limit: limit, Offset: offset})
+}
+
+// uniqueSlug mirrors EnsureUniqueSlug. Callers hold mu.
+func (s *memoryStore) uniqueSlug(slug string, excludeID int) string {
+	baseSlug := slug
+	for counter := 1; ; counter++ {
+		taken := false
+		for _, a := range s.articles {
+			if a.Slug == slug && a.ArtikelID != excludeID {
+				taken = true
+				break
+			}
+		}
+		if !taken {
+			return slug
+		}
+		slug = fmt.Sprintf("%s-%d", baseSlug, counter)
+	}
+}
+
+// checkArticleRelations fails like the foreign keys on artikel_kategori and
+// artikel_tag would. Callers hold mu.
+func (s *memoryStore) checkArticleRelations(kategoriIDs, tagIDs []int) error {
+	for _, id := range kategoriIDs {
+		if _, ok := s.categories[id]; !ok {
+			return fmt.Errorf("failed to add article category: %w", errMemoryForeignKey)
+		}
+	}
+	for _, id := range tagIDs {
+		if _, ok := s.tags[id]; !ok {
+			return fmt.Errorf("failed to add article tag: %w", errMemoryForeignKey)
+		}
+	}
+	return nil
+}
+
+func (r memoryArticles) Create(ctx context.Context, input database.ArticleInput, userID int) (*database.Article, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if err := r.s.checkArticleRelations(input.KategoriIDs, input.TagIDs); err != nil {
+		return nil, err
+	}
+
+	slug := input.Slug
+	if slug == "" {
+		slug = database.GenerateSlug(input.Judul)
+	}
+
+	status := input.Status
+	if status == "" {
+		status = "draft"
+	}
+
+	tanggalPublikasi := parsePublicationDate(input.TanggalPublikasi)
+	if status == "published" && tanggalPublikasi == nil {
+		now := time.Now()
+		tanggalPublikasi = &now
+	}
+
+	now := time.Now()
+	a := &memoryArticle{
+		Article: database.Article{
+			ArtikelID:         r.s.newID(),
+			Judul:             input.Judul,
+			Slug:              r.s.uniqueSlug(slug, 0),
+			Konten:            input.Konten,
+			Excerpt:           optionalString(input.Excerpt),
+			GambarUtama:       optionalString(input.GambarUtama),
+			Penulis:           optionalString(input.Penulis),
+			Status:            status,
+			UserID:            userID,
+			TanggalPublikasi:  tanggalPublikasi,
+			TanggalDibuat:     now,
+			TanggalDiperbarui: now,
+		},
+		kategoriIDs: uniqueIDs(nil, input.KategoriIDs),
+		tagIDs:      uniqueIDs(nil, input.TagIDs),
+	}
+	r.s.articles[a.ArtikelID] = a
+
+	out := r.s.article(a)
+	return &out, nil
+}
+
+func (r memoryArticles) Update(ctx context.Context, id int, input database.ArticleInput, editorID int) (*database.Article, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	a, ok := r.s.articles[id]
+	if !ok || a.deleted {
+		return nil, sql.ErrNoRows
+	}
+	if err := r.s.checkArticleRelations(input.KategoriIDs, input.TagIDs); err != nil {
+		return nil, err
+	}
+
+	slug := input.Slug
+	if slug == "" {
+		slug = database.GenerateSlug(input.Judul)
+	}
+
+	tanggalPublikasi := parsePublicationDate(input.TanggalPublikasi)
+	if input.Status == "published" && tanggalPublikasi == nil {
+		if a.TanggalPublikasi == nil {
+			now := time.Now()
+			tanggalPublikasi = &now
+		} else {
+			tanggalPublikasi = a.TanggalPublikasi
+		}
+	}
+
+	a.Judul = input.Judul
+	a.Slug = r.s.uniqueSlug(slug, id)
+	a.Konten = input.Konten
+	a.Excerpt = optionalString(input.Excerpt)
+	a.GambarUtama = optionalString(input.GambarUtama)
+	a.Penulis = optionalString(input.Penulis)
+	a.Status = input.Status
+	if tanggalPublikasi != nil {
+		a.TanggalPublikasi = tanggalPublikasi
+	}
+	a.TanggalDiperbarui = time.Now()
+
+	if input.KategoriIDs != nil {
+		a.kategoriIDs = nil
+	}
+	if input.TagIDs != nil {
+		a.tagIDs = nil
+	}
+	a.kategoriIDs = uniqueIDs(a.kategoriIDs, input.KategoriIDs)
+	a.tagIDs = uniqueIDs(a.tagIDs, input.TagIDs)
+
+	out := r.s.article(a)
+	return &out, nil
+}
+
+func (r memoryArticles) Delete(ctx context.Context, id int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	a, ok := r.s.articles[id]
+	if !ok || a.deleted {
+		return sql.ErrNoRows
+	}
+	a.deleted = true
+	return nil
+}
+
+// ========================================
+// COMMENTS
+// ========================================
+
+type memoryComments struct{ s *memoryStore }
+
+// comment returns a copy of c without moderation or thread data, which the
+// Postgres queries only select for moderators and thread listings
+func (c *memoryComment) comment() database.Comment {
+	out := c.Comment
+	out.ModerationScore = nil
+	out.ModerationReasons = nil
+	out.ReplyCount = 0
+	out.Replies = nil
+	return out
+}
+
+// threadComment returns a copy of c with its number of approved direct
+// replies. Callers hold mu.
+func (s *memoryStore) threadComment(c *memoryComment) database.Comment {
+	out := c.comment()
+	for _, r := range s.comments {
+		if r.ParentID != nil && *r.ParentID == c.KomentarID && r.Status == "approved" && !r.deleted {
+			out.ReplyCount++
+		}
+	}
+	return out
+}
+
+// sortedComments returns the live comments matching keep, newest first or
+// oldest first. Callers hold mu.
+func (s *memoryStore) sortedComments(keep func(*memoryComment) bool, oldestFirst bool) []*memoryComment {
+	var matched []*memoryComment
+	for _, c := range s.comments {
+		if !c.deleted && keep(c) {
+			matched = append(matched, c)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		a, b := matched[i], matched[j]
+		if oldestFirst {
+			return newerFirst(b.TanggalDibuat, a.TanggalDibuat, b.KomentarID, a.KomentarID)
+		}
+		return newerFirst(a.TanggalDibuat, b.TanggalDibuat, a.KomentarID, b.KomentarID)
+	})
+	return matched
+}
+
+func (r memoryComments) Create(ctx context.Context, comment *database.Comment) (*database.Comment, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.articles[comment.ArtikelID]; !ok {
+		return nil, fmt.Errorf("failed to create comment: %w", errMemoryForeignKey)
+	}
+
+	now := time.Now()
+	comment.KomentarID = r.s.newID()
+	comment.TanggalDibuat = now
+	comment.TanggalDiperbarui = now
+
+	stored := *comment
+	if stored.ModerationReasons == nil {
+		stored.ModerationReasons = []string{}
+	}
+	r.s.comments[comment.KomentarID] = &memoryComment{Comment: stored}
+	return comment, nil
+}
+
+func (r memoryComments) GetByID(ctx context.Context, id int) (*database.Comment, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	c, ok := r.s.comments[id]
+	if !ok || c.deleted {
+		return nil, errMemoryCommentNotFound
+	}
+	out := c.comment()
+	return &out, nil
+}
+
+func (r memoryComments) Update(ctx context.Context, id int, konten, status string, score int, reasons []string) (*database.Comment, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	c, ok := r.s.comments[id]
+	if !ok || c.deleted {
+		return nil, fmt.Errorf("failed to update comment: %w", sql.ErrNoRows)
+	}
+	c.Konten = konten
+	c.Status = status
+	c.ModerationScore = &score
+	c.ModerationReasons = append([]string{}, reasons...)
+	c.TanggalDiperbarui = time.Now()
+
+	out := c.comment()
+	return &out, nil
+}
+
+func (r memoryComments) UpdateStatus(ctx context.Context, id int, status string) (*database.Comment, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	c, ok := r.s.comments[id]
+	if !ok || c.deleted {
+		return nil, sql.ErrNoRows
+	}
+	c.Status = status
+	c.TanggalDiperbarui = time.Now()
+
+	// Pending replies are rejected with their parent, as in UpdateCommentStatus
+	if status == "rejected" {
+		for _, d := range r.s.subtree(id) {
+			if d.Status == "pending" {
+				d.Status = "rejected"
+			}
+		}
+	}
+
+	out := c.comment()
+	return &out, nil
+}
+
+// subtree returns the live descendants of a comment. Callers hold mu.
+func (s *memoryStore) subtree(id int) []*memoryComment {
+	var out []*memoryComment
+	for _, c := range s.comments {
+		if c.ParentID != nil && *c.ParentID == id && !c.deleted {
+			out = append(out, c)
+			out = append(out, s.subtree(c.KomentarID)...)
+		}
+	}
+	return out
+}
+
+func (r memoryComments) Delete(ctx context.Context, id int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	c, ok := r.s.comments[id]
+	if !ok || c.deleted {
+		return sql.ErrNoRows
+	}
+	for _, d := range r.s.subtree(id) {
+		d.deleted = true
+	}
+	c.deleted = true
+	return nil
+}
+
+func (r memoryComments) List(ctx context.Context, status string, limit, offset int) ([]database.Comment, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	matched := r.s.sortedComments(func(c *memoryComment) bool {
+		return status == "" || c.Status == status
+	}, false)
+
+	comments := []database.Comment{}
+	for _, c := range pageOf(matched, limit, offset) {
+		out := c.comment()
+		out.ModerationScore = c.ModerationScore
+		out.ModerationReasons = c.ModerationReasons
+		comments = append(comments, out)
+	}
+	return comments, nil
+}
+
+func (r memoryComments) Count(ctx context.Context, status string) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	return len(r.s.sortedComments(func(c *memoryComment) bool {
+		return status == "" || c.Status == status
+	}, false)), nil
+}
+
+func (r memoryComments) ListByUser(ctx context.Context, userID, limit, offset int) ([]database.Comment, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	matched := r.s.sortedComments(func(c *memoryComment) bool {
+		return c.UserID != nil && *c.UserID == userID
+	}, false)
+
+	comments := []database.Comment{}
+	for _, c := range pageOf(matched, limit, offset) {
+		comments = append(comments, c.comment())
+	}
+	return comments, nil
+}
+
+func (r memoryComments) CountByUser(ctx context.Context, userID int) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	return len(r.s.sortedComments(func(c *memoryComment) bool {
+		return c.UserID != nil && *c.UserID == userID
+	}, false)), nil
+}
+
+func isThread(articleID int) func(*memoryComment) bool {
+	return func(c *memoryComment) bool {
+		return c.ArtikelID == articleID && c.ParentID == nil && c.Status == "approved"
+	}
+}
+
+func isApprovedReply(parentID int) func(*memoryComment) bool {
+	return func(c *memoryComment) bool {
+		return c.ParentID != nil && *c.ParentID == parentID && c.Status == "approved"
+	}
+}
+
+func (r memoryComments) ListThreads(ctx context.Context, articleID, limit, offset int) ([]database.Comment, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	comments := []database.Comment{}
+	for _, c := range pageOf(r.s.sortedComments(isThread(articleID), false), limit, offset) {
+		comments = append(comments, r.s.threadComment(c))
+	}
+	return comments, nil
+}
+
+func (r memoryComments) CountThreads(ctx context.Context, articleID int) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	return len(r.s.sortedComments(isThread(articleID), false)), nil
+}
+
+func (r memoryComments) LoadTrees(ctx context.Context, threads []database.Comment) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for i := range threads {
+		threads[i].Replies = r.s.replyTree(threads[i].KomentarID)
+	}
+	return nil
+}
+
+// replyTree returns the approved descendants of a comment, oldest first on
+// every level. Callers hold mu.
+func (s *memoryStore) replyTree(parentID int) []database.Comment {
+	var replies []database.Comment
+	for _, c := range s.sortedComments(isApprovedReply(parentID), true) {
+		reply := s.threadComment(c)
+		reply.Replies = s.replyTree(c.KomentarID)
+		replies = append(replies, reply)
+	}
+	return replies
+}
+
+func (r memoryComments) ListReplies(ctx context.Context, parentID, limit, offset int) ([]database.Comment, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	comments := []database.Comment{}
+	for _, c := range pageOf(r.s.sortedComments(isApprovedReply(parentID), true), limit, offset) {
+		comments = append(comments, r.s.threadComment(c))
+	}
+	return comments, nil
+}
+
+func (r memoryComments) CountReplies(ctx context.Context, parentID int) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	return len(r.s.sortedComments(isApprovedReply(parentID), true)), nil
+}
+
+// visible mirrors IsCommentVisible. Callers hold mu.
+func (s *memoryStore) visible(id int) bool {
+	c, ok := s.comments[id]
+	if !ok {
+		return false
+	}
+	for ok {
+		if c.Status != "approved" || c.deleted {
+			return false
+		}
+		if c.ParentID == nil {
+			return true
+		}
+		c, ok = s.comments[*c.ParentID]
+	}
+	return true
+}
+
+func (r memoryComments) IsVisible(ctx context.Context, id int) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	return r.s.visible(id), nil
+}
+
+func (r memoryComments) ResolveParent(ctx context.Context, parentID, articleID int) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	parent, ok := r.s.comments[parentID]
+	if !ok || parent.deleted {
+		return 0, database.ErrParentCommentNotFound
+	}
+	if parent.ArtikelID != articleID {
+		return 0, database.ErrParentCommentMismatch
+	}
+	if parent.Depth >= database.MaxCommentDepth {
+		return 0, database.ErrCommentTooDeep
+	}
+	if !r.s.visible(parentID) {
+		return 0, database.ErrParentCommentNotFound
+	}
+	return parent.Depth + 1, nil
+}
+
+func (r memoryComments) BannedWords(ctx context.Context) ([]database.BannedWord, error) {
+	return []database.BannedWord{}, nil
+}
+
+func (r memoryComments) AuthorHistory(ctx context.Context, userID int) (approved, rejected int, err error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, c := range r.s.comments {
+		if c.UserID == nil || *c.UserID != userID {
+			continue
+		}
+		switch c.Status {
+		case "approved":
+			approved++
+		case "rejected":
+			rejected++
+		}
+	}
+	return approved, rejected, nil
+}
+
+func (r memoryComments) CountDuplicates(ctx context.Context, konten string, userID *int, artikelID, excludeID int, since time.Time) (byAuthor, onArticle int, err error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	normalize := func(s string) string {
+		return strings.ToLower(strings.Join(strings.Fields(s), " "))
+	}
+	want := normalize(konten)
+
+	for _, c := range r.s.comments {
+		if c.KomentarID == excludeID || c.TanggalDibuat.Before(since) || normalize(c.Konten) != want {
+			continue
+		}
+		if userID != nil && c.UserID != nil && *c.UserID == *userID {
+			byAuthor++
+		}
+		if c.ArtikelID == artikelID {
+			onArticle++
+		}
+	}
+	return byAuthor, onArticle, nil
+}
+
+// ========================================
+// USERS
+// ========================================
+
+type memoryUsers struct{ s *memoryStore }
+
+// liveUser returns the user matching keep that is not in the trash. Callers
+// hold mu.
+func (s *memoryStore) liveUser(keep func(*memoryUser) bool) (*memoryUser, bool) {
+	for _, u := range s.users {
+		if !u.deleted && keep(u) {
+			return u, true
+		}
+	}
+	return nil, false
+}
+
+// anyUser reports whether a user matching keep exists, including users in
+// the trash. Callers hold mu.
+func (s *memoryStore) anyUser(keep func(*memoryUser) bool) bool {
+	for _, u := range s.users {
+		if keep(u) {
+			return true
+		}
+	}
+	return false
+}
+
+func (u *memoryUser) user() *database.User {
+	out := u.User
+	out.CreatedAt = out.TanggalDibuat
+	return &out
+}
+
+func (r memoryUsers) Authenticate(ctx context.Context, req *database.LoginRequest, client database.LoginClient, policy database.LockoutPolicy) (*database.User, error) {
+	r.s.mu.Lock()
+	u, ok := r.s.liveUser(func(u *memoryUser) bool { return u.Email == req.Email })
+	r.s.mu.Unlock()
+
+	if !ok {
+		return nil, database.ErrInvalidCredentials
+	}
+	if !database.VerifyPassword(u.Password, req.Password) {
+		return nil, database.ErrInvalidCredentials
+	}
+	return u.user(), nil
+}
+
+func (r memoryUsers) GetByID(ctx context.Context, id int) (*database.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	u, ok := r.s.liveUser(func(u *memoryUser) bool { return u.UserID == id })
+	if !ok {
+		return nil, errMemoryUserNotFound
+	}
+	return u.user(), nil
+}
+
+func (r memoryUsers) GetByEmail(ctx context.Context, email string) (*database.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	u, ok := r.s.liveUser(func(u *memoryUser) bool { return u.Email == email })
+	if !ok {
+		return nil, errMemoryUserNotFound
+	}
+	return u.user(), nil
+}
+
+func (r memoryUsers) GetByUsername(ctx context.Context, username string) (*database.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	u, ok := r.s.liveUser(func(u *memoryUser) bool { return u.Username == username })
+	if !ok {
+		return nil, errMemoryUserNotFound
+	}
+	return u.user(), nil
+}
+
+func (r memoryUsers) List(ctx context.Context, limit, offset int) ([]database.UserResponse, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var matched []*memoryUser
+	for _, u := range r.s.users {
+		if !u.deleted {
+			matched = append(matched, u)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return newerFirst(matched[i].TanggalDibuat, matched[j].TanggalDibuat, matched[i].UserID, matched[j].UserID)
+	})
+
+	users := []database.UserResponse{}
+	for _, u := range pageOf(matched, limit, offset) {
+		users = append(users, u.ToPublic())
+	}
+	return users, nil
+}
+
+func (r memoryUsers) Count(ctx context.Context) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	count := 0
+	for _, u := range r.s.users {
+		if !u.deleted {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r memoryUsers) EmailExists(ctx context.Context, email string) (bool, error) {
+	return r.EmailTaken(ctx, email, 0)
+}
+
+func (r memoryUsers) UsernameExists(ctx context.Context, username string) (bool, error) {
+	return r.UsernameTaken(ctx, username, 0)
+}
+
+func (r memoryUsers) EmailTaken(ctx context.Context, email string, excludeUserID int) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	return r.s.anyUser(func(u *memoryUser) bool {
+		return u.Email == email && u.UserID != excludeUserID
+	}), nil
+}
+
+func (r memoryUsers) UsernameTaken(ctx context.Context, username string, excludeUserID int) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	return r.s.anyUser(func(u *memoryUser) bool {
+		return u.Username == username && u.UserID != excludeUserID
+	}), nil
+}
+
+// checkUnique fails like the unique indexes on users.username and
+// users.email would. Callers hold mu.
+func (s *memoryStore) checkUnique(username, email string, excludeUserID int) error {
+	if s.anyUser(func(u *memoryUser) bool {
+		return u.UserID != excludeUserID && (u.Username == username || u.Email == email)
+	}) {
+		return errMemoryDuplicateKey
+	}
+	return nil
+}
+
+func (r memoryUsers) Create(ctx context.Context, req *database.UserRequest) (*database.User, error) {
+	hashedPassword, err := database.HashPassword(req.Password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	role := req.Role
+	if role == "" {
+		role = "user"
+	}
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if err := r.s.checkUnique(req.Username, req.Email, 0); err != nil {
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	now := time.Now()
+	u := &memoryUser{User: database.User{
+		UserID:            r.s.newID(),
+		Username:          req.Username,
+		Email:             req.Email,
+		Password:          hashedPassword,
+		Role:              role,
+		TanggalDibuat:     now,
+		TanggalDiperbarui: now,
+	}}
+	r.s.users[u.UserID] = u
+
+	out := u.user()
+	out.Password = ""
+	return out, nil
+}
+
+func (r memoryUsers) Update(ctx context.Context, id int, req *database.UserUpdateRequest) (*database.User, error) {
+	existing, err := r.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	username := existing.Username
+	email := existing.Email
+	role := existing.Role
+	if req.Username != "" {
+		username = req.Username
+	}
+	if req.Email != "" {
+		email = req.Email
+	}
+	if req.Role != "" {
+		role = req.Role
+	}
+
+	user, err := r.update(id, username, email, func(u *memoryUser) { u.Role = role })
+	if err != nil {
+		return nil, err
+	}
+	user.Password = ""
+
+	if req.Password != "" {
+		if err := r.UpdatePassword(ctx, id, req.Password); err != nil {
+			return nil, err
+		}
+	}
+	return user, nil
+}
+
+func (r memoryUsers) UpdateBasic(ctx context.Context, id int, username, email string) (*database.User, error) {
+	return r.update(id, username, email, nil)
+}
+
+// update changes username and email, clearing the verification when the
+// email changes, and applies extra to the row
+func (r memoryUsers) update(id int, username, email string, extra func(*memoryUser)) (*database.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	u, ok := r.s.liveUser(func(u *memoryUser) bool { return u.UserID == id })
+	if !ok {
+		return nil, fmt.Errorf("failed to update user: %w", sql.ErrNoRows)
+	}
+	if err := r.s.checkUnique(username, email, id); err != nil {
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+
+	if u.Email != email {
+		u.EmailVerifiedAt = nil
+	}
+	u.Username = username
+	u.Email = email
+	if extra != nil {
+		extra(u)
+	}
+	u.TanggalDiperbarui = time.Now()
+	return u.user(), nil
+}
+
+func (r memoryUsers) UpdatePassword(ctx context.Context, id int, newPassword string) error {
+	hashedPassword, err := database.HashPassword(newPassword)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	u, ok := r.s.liveUser(func(u *memoryUser) bool { return u.UserID == id })
+	if !ok {
+		return sql.ErrNoRows
+	}
+	u.Password = hashedPassword
+	return nil
+}
+
+func (r memoryUsers) UpdateRole(ctx context.Context, id int, role string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	u, ok := r.s.liveUser(func(u *memoryUser) bool { return u.UserID == id })
+	if !ok {
+		return sql.ErrNoRows
+	}
+	u.Role = role
+	return nil
+}
+
+func (r memoryUsers) Delete(ctx context.Context, id int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	u, ok := r.s.liveUser(func(u *memoryUser) bool { return u.UserID == id })
+	if !ok {
+		return sql.ErrNoRows
+	}
+	u.deleted = true
+	return nil
+}
+
+func (r memoryUsers) IsEmailVerified(ctx context.Context, id int) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	u, ok := r.s.users[id]
+	return ok && u.EmailVerifiedAt != nil, nil
+}
+
+func (r memoryUsers) MarkEmailVerified(ctx context.Context, id int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	u, ok := r.s.users[id]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	if u.EmailVerifiedAt == nil {
+		now := time.Now()
+		u.EmailVerifiedAt = &now
+	}
+	return nil
+}
+
+// ========================================
+// CATEGORIES AND TAGS
+// ========================================
+
+type memoryTaxonomy struct{ s *memoryStore }
+
+// linkCount counts the published, live articles of a category or tag the
+// way the Postgres listings do. Callers hold mu.
+func (s *memoryStore) linkCount(ids func(*memoryArticle) []int, id int) int {
+	count := 0
+	for _, a := range s.articles {
+		if !a.deleted && a.Status == "published" && containsID(ids(a), id) {
+			count++
+		}
+	}
+	return count
+}
+
+// inUse reports whether a live article uses a category or tag. Callers
+// hold mu.
+func (s *memoryStore) inUse(ids func(*memoryArticle) []int, id int) bool {
+	for _, a := range s.articles {
+		if !a.deleted && containsID(ids(a), id) {
+			return true
+		}
+	}
+	return false
+}
+
+func articleCategoryIDs(a *memoryArticle) []int { return a.kategoriIDs }
+func articleTagIDs(a *memoryArticle) []int      { return a.tagIDs }
+
+// liveCategories returns the categories not in the trash, by name. Callers
+// hold mu.
+func (s *memoryStore) liveCategories() []*memoryCategory {
+	var out []*memoryCategory
+	for _, c := range s.categories {
+		if !c.deleted {
+			out = append(out, c)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].NamaKategori < out[j].NamaKategori })
+	return out
+}
+
+// liveTags returns the tags not in the trash, by name. Callers hold mu.
+func (s *memoryStore) liveTags() []*memoryTag {
+	var out []*memoryTag
+	for _, t := range s.tags {
+		if !t.deleted {
+			out = append(out, t)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].NamaTag < out[j].NamaTag })
+	return out
+}
+
+func (r memoryTaxonomy) ListCategories(ctx context.Context) ([]database.Category, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var categories []database.Category
+	for _, c := range r.s.liveCategories() {
+		categories = append(categories, c.Category)
+	}
+	return categories, nil
+}
+
+func (r memoryTaxonomy) ListCategoriesWithArticleCount(ctx context.Context) ([]map[string]interface{}, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var categories []map[string]interface{}
+	for _, c := range r.s.liveCategories() {
+		deskripsi := ""
+		if c.Deskripsi != nil {
+			deskripsi = *c.Deskripsi
+		}
+		categories = append(categories, map[string]interface{}{
+			"kategori_id":   c.KategoriID,
+			"nama_kategori": c.NamaKategori,
+			"deskripsi":     deskripsi,
+			"created_at":    c.CreatedAt.Format(time.RFC3339Nano),
+			"article_count": r.s.linkCount(articleCategoryIDs, c.KategoriID),
+		})
+	}
+	return categories, nil
+}
+
+func (r memoryTaxonomy) GetCategoryByID(ctx context.Context, id int) (*database.Category, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	c, ok := r.s.categories[id]
+	if !ok || c.deleted {
+		return nil, errMemoryCategoryNotFound
+	}
+	out := c.Category
+	return &out, nil
+}
+
+func (r memoryTaxonomy) GetCategoryByName(ctx context.Context, name string) (*database.Category, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, c := range r.s.liveCategories() {
+		if strings.EqualFold(c.NamaKategori, name) {
+			out := c.Category
+			return &out, nil
+		}
+	}
+	return nil, errMemoryCategoryNotFound
+}
+
+func (r memoryTaxonomy) CategoryExists(ctx context.Context, name string) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, c := range r.s.liveCategories() {
+		if c.NamaKategori == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r memoryTaxonomy) CreateCategory(ctx context.Context, req *database.CategoryRequest) (*database.Category, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	c := &memoryCategory{Category: database.Category{
+		KategoriID:   r.s.newID(),
+		NamaKategori: req.NamaKategori,
+		Deskripsi:    optionalString(req.Deskripsi),
+		CreatedAt:    time.Now(),
+	}}
+	r.s.categories[c.KategoriID] = c
+
+	out := c.Category
+	return &out, nil
+}
+
+func (r memoryTaxonomy) UpdateCategory(ctx context.Context, id int, req *database.CategoryRequest) (*database.Category, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	c, ok := r.s.categories[id]
+	if !ok || c.deleted {
+		return nil, errMemoryCategoryNotFound
+	}
+	c.NamaKategori = req.NamaKategori
+	c.Deskripsi = optionalString(req.Deskripsi)
+
+	out := c.Category
+	return &out, nil
+}
+
+func (r memoryTaxonomy) DeleteCategory(ctx context.Context, id int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	c, ok := r.s.categories[id]
+	if !ok || c.deleted {
+		return sql.ErrNoRows
+	}
+	if r.s.inUse(articleCategoryIDs, id) {
+		return errors.New("cannot delete category that has articles")
+	}
+	c.deleted = true
+	return nil
+}
+
+func (r memoryTaxonomy) ForceDeleteCategory(ctx context.Context, id int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	c, ok := r.s.categories[id]
+	if !ok || c.deleted {
+		return sql.ErrNoRows
+	}
+	c.deleted = true
+	return nil
+}
+
+func (r memoryTaxonomy) ListTags(ctx context.Context) ([]database.Tag, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var tags []database.Tag
+	for _, t := range r.s.liveTags() {
+		tags = append(tags, t.Tag)
+	}
+	return tags, nil
+}
+
+func (r memoryTaxonomy) ListTagsWithArticleCount(ctx context.Context) ([]map[string]interface{}, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var tags []map[string]interface{}
+	for _, t := range r.s.liveTags() {
+		tags = append(tags, map[string]interface{}{
+			"tag_id":        t.TagID,
+			"nama_tag":      t.NamaTag,
+			"article_count": r.s.linkCount(articleTagIDs, t.TagID),
+		})
+	}
+	return tags, nil
+}
+
+func (r memoryTaxonomy) ListPopularTags(ctx context.Context, limit int) ([]map[string]interface{}, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	type popular struct {
+		tag   *memoryTag
+		count int
+	}
+	var ranked []popular
+	for _, t := range r.s.liveTags() {
+		if n := r.s.linkCount(articleTagIDs, t.TagID); n > 0 {
+			ranked = append(ranked, popular{t, n})
+		}
+	}
+	// liveTags is sorted by name, so a stable sort keeps name order on ties
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].count > ranked[j].count })
+
+	var tags []map[string]interface{}
+	for _, p := range pageOf(ranked, limit, 0) {
+		tags = append(tags, map[string]interface{}{
+			"tag_id":        p.tag.TagID,
+			"nama_tag":      p.tag.NamaTag,
+			"article_count": p.count,
+		})
+	}
+	return tags, nil
+}
+
+func (r memoryTaxonomy) SearchTags(ctx context.Context, keyword string) ([]database.Tag, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var tags []database.Tag
+	for _, t := range r.s.liveTags() {
+		if strings.Contains(strings.ToLower(t.NamaTag), strings.ToLower(keyword)) {
+			tags = append(tags, database.Tag{TagID: t.TagID, NamaTag: t.NamaTag})
+		}
+	}
+	return tags, nil
+}
+
+func (r memoryTaxonomy) GetTagByID(ctx context.Context, id int) (*database.Tag, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	t, ok := r.s.tags[id]
+	if !ok || t.deleted {
+		return nil, errMemoryTagNotFound
+	}
+	out := t.Tag
+	return &out, nil
+}
+
+func (r memoryTaxonomy) GetTagByName(ctx context.Context, name string) (*database.Tag, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, t := range r.s.liveTags() {
+		if strings.EqualFold(t.NamaTag, name) {
+			out := t.Tag
+			return &out, nil
+		}
+	}
+	return nil, errMemoryTagNotFound
+}
+
+func (r memoryTaxonomy) TagExists(ctx context.Context, name string) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	return r.s.findTag(name) != nil, nil
+}
+
+// findTag returns the live tag with exactly this name. Callers hold mu.
+func (s *memoryStore) findTag(name string) *memoryTag {
+	for _, t := range s.tags {
+		if t.NamaTag == name && !t.deleted {
+			return t
+		}
+	}
+	return nil
+}
+
+// createTag adds a tag. Callers hold mu.
+func (s *memoryStore) createTag(name string) *memoryTag {
+	t := &memoryTag{Tag: database.Tag{TagID: s.newID(), NamaTag: name, CreatedAt: time.Now()}}
+	s.tags[t.TagID] = t
+	return t
+}
+
+func (r memoryTaxonomy) CreateTag(ctx context.Context, req *database.TagRequest) (*database.Tag, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	out := r.s.createTag(req.NamaTag).Tag
+	return &out, nil
+}
+
+func (r memoryTaxonomy) UpdateTag(ctx context.Context, id int, req *database.TagRequest) (*database.Tag, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	t, ok := r.s.tags[id]
+	if !ok || t.deleted {
+		return nil, errMemoryTagNotFound
+	}
+	t.NamaTag = req.NamaTag
+
+	out := t.Tag
+	return &out, nil
+}
+
+func (r memoryTaxonomy) DeleteTag(ctx context.Context, id int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	t, ok := r.s.tags[id]
+	if !ok || t.deleted {
+		return sql.ErrNoRows
+	}
+	if r.s.inUse(articleTagIDs, id) {
+		return errors.New("cannot delete tag that has articles")
+	}
+	t.deleted = true
+	return nil
+}
+
+func (r memoryTaxonomy) ForceDeleteTag(ctx context.Context, id int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	t, ok := r.s.tags[id]
+	if !ok || t.deleted {
+		return sql.ErrNoRows
+	}
+	t.deleted = true
+	return nil
+}
+
+func (r memoryTaxonomy) GetOrCreateTags(ctx context.Context, names []string) ([]int, error) {
+	if len(names) == 0 {
+		return []int{}, nil
+	}
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var tagIDs []int
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		t := r.s.findTag(name)
+		if t == nil {
+			t = r.s.createTag(name)
+		}
+		tagIDs = append(tagIDs, t.TagID)
+	}
+	return tagIDs, nil
+}
+
+// ========================================
+// HELPERS
+// ========================================
+
+// pageOf applies limit and offset to rows. A limit of 0 returns all rows
+// after offset.
+func pageOf[T any](rows []T, limit, offset int) []T {
+	if offset >= len(rows) {
+		return nil
+	}
+	rows = rows[offset:]
+	if limit > 0 && limit < len(rows) {
+		rows = rows[:limit]
+	}
+	return rows
+}
+
+// newerFirst orders rows by creation time descending, then ID descending
+func newerFirst(a, b time.Time, aID, bID int) bool {
+	if !a.Equal(b) {
+		return a.After(b)
+	}
+	return aID > bID
+}
+
+// feedBefore orders the published feed like GetAllArticles does
+func feedBefore(a, b database.Article) bool {
+	var at, bt time.Time
+	if a.TanggalPublikasi != nil {
+		at = *a.TanggalPublikasi
+	}
+	if b.TanggalPublikasi != nil {
+		bt = *b.TanggalPublikasi
+	}
+	return newerFirst(at, bt, a.ArtikelID, b.ArtikelID)
+}
+
+func parsePublicationDate(s string) *time.Time {
+	if s == "" {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func containsID(ids []int, id int) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+// uniqueIDs appends the IDs in add that are not in ids yet, like the
+// ON CONFLICT DO NOTHING relation inserts
+func uniqueIDs(ids, add []int) []int {
+	for _, id := range add {
+		if !containsID(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
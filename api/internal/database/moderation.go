@@ -0,0 +1,114 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type BannedWord struct {
+	WordID    int       `json:"word_id"`
+	Kata      string    `json:"kata"`
+	Tingkat   string    `json:"tingkat"` // reject atau review
+	CreatedBy *int      `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+var ErrBannedWordExists = errors.New("banned word already exists")
+
+// ListBannedWords retrieves the banned word list ordered alphabetically
+func ListBannedWords(ctx context.Context, db *sql.DB) ([]BannedWord, error) {
+	rows, err := db.QueryContext(ctx, `
+        SELECT word_id, kata, tingkat, created_by, created_at
+        FROM banned_words
+        ORDER BY LOWER(kata)
+    `)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	words := []BannedWord{}
+	for rows.Next() {
+		var w BannedWord
+		if err := rows.Scan(&w.WordID, &w.Kata, &w.Tingkat, &w.CreatedBy, &w.CreatedAt); err != nil {
+			return nil, err
+		}
+		words = append(words, w)
+	}
+
+	return words, rows.Err()
+}
+
+// CreateBannedWord adds a word or phrase to the list. Returns
+// ErrBannedWordExists when it is already listed.
+func CreateBannedWord(ctx context.Context, db *sql.DB, kata, tingkat string, createdBy int) (*BannedWord, error) {
+	w := BannedWord{Kata: strings.ToLower(strings.TrimSpace(kata)), Tingkat: tingkat}
+	err := withTransaction(ctx, db, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, `
+            INSERT INTO banned_words (kata, tingkat, created_by)
+            VALUES ($1, $2, $3)
+            RETURNING word_id, created_by, created_at
+        `, w.Kata, w.Tingkat, createdBy).Scan(&w.WordID, &w.CreatedBy, &w.CreatedAt)
+		if err != nil {
+			var pqErr *pq.Error
+			if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+				return ErrBannedWordExists
+			}
+			return fmt.Errorf("failed to create banned word: %w", err)
+		}
+
+		return auditCreatedTx(ctx, tx, "banned_word.create", auditBannedWord, w.WordID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &w, nil
+}
+
+// DeleteBannedWord removes a word from the list
+func DeleteBannedWord(ctx context.Context, db *sql.DB, id int) error {
+	return auditedChange(ctx, db, "banned_word.delete", auditBannedWord, id, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM banned_words WHERE word_id = $1", id); err != nil {
+			return fmt.Errorf("failed to delete banned word: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetCommentHistory returns how many of a user's comments were approved and
+// rejected. Comments in the trash still count.
+func GetCommentHistory(ctx context.Context, db *sql.DB, userID int) (approved, rejected int, err error) {
+	err = db.QueryRowContext(ctx, `
+        SELECT COUNT(*) FILTER (WHERE status = 'approved'),
+               COUNT(*) FILTER (WHERE status = 'rejected')
+        FROM comments
+        WHERE user_id = $1
+    `, userID).Scan(&approved, &rejected)
+	return approved, rejected, err
+}
+
+// CountDuplicateComments counts comments with the same content (ignoring
+// case and whitespace) created since the given time: those by the same
+// user and those on the same article. Anonymous comments have no author
+// to match (the name is free text, most are "Anonymous"), so byAuthor is
+// always 0 when userID is nil. excludeID skips the comment being edited.
+// Comments in the trash still count, so deleting spam does not reset the
+// check.
+func CountDuplicateComments(ctx context.Context, db *sql.DB, konten string, userID *int, artikelID, excludeID int, since time.Time) (byAuthor, onArticle int, err error) {
+	err = db.QueryRowContext(ctx, `
+        SELECT COUNT(*) FILTER (WHERE $2::int IS NOT NULL AND user_id = $2),
+               COUNT(*) FILTER (WHERE artikel_id = $3)
+        FROM comments
+        WHERE LOWER(REGEXP_REPLACE(TRIM(konten), '\s+', ' ', 'g')) = LOWER(REGEXP_REPLACE(TRIM($1), '\s+', ' ', 'g'))
+          AND tanggal_dibuat >= $4
+          AND komentar_id <> $5
+    `, konten, userID, artikelID, since, excludeID).Scan(&byAuthor, &onArticle)
+	return byAuthor, onArticle, err
+}
@@ -0,0 +1,84 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrResetTokenInvalid = errors.New("password reset token is invalid or expired")
+
+// CreatePasswordResetToken stores the hash of a new reset token. Earlier
+// unused tokens of the user are discarded, so only the latest link works.
+func CreatePasswordResetToken(ctx context.Context, db *sql.DB, userID int, tokenHash string, expiresAt time.Time, ipAddress string) error {
+	return withTransaction(ctx, db, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx,
+			"DELETE FROM password_reset_tokens WHERE user_id = $1 AND used_at IS NULL", userID)
+		if err != nil {
+			return fmt.Errorf("failed to discard old reset tokens: %w", err)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+            INSERT INTO password_reset_tokens (user_id, token_hash, expires_at, ip_address)
+            VALUES ($1, $2, $3, $4)
+        `, userID, tokenHash, expiresAt, ipAddress)
+		if err != nil {
+			return fmt.Errorf("failed to create reset token: %w", err)
+		}
+		return nil
+	})
+}
+
+// ResetPasswordWithToken consumes a reset token and sets the new password
+// in one transaction. Returns the user ID, or ErrResetTokenInvalid when the
+// token is unknown, used or expired.
+func ResetPasswordWithToken(ctx context.Context, db *sql.DB, tokenHash, newPassword string) (int, error) {
+	hashedPassword, err := HashPassword(newPassword)
+	if err != nil {
+		return 0, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	var userID int
+	err = withTransaction(ctx, db, func(tx *sql.Tx) error {
+		// Conditional update so a token can only be used once, even concurrently
+		err := tx.QueryRowContext(ctx, `
+            UPDATE password_reset_tokens
+            SET used_at = NOW()
+            WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+            RETURNING user_id
+        `, tokenHash).Scan(&userID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrResetTokenInvalid
+			}
+			return fmt.Errorf("failed to use reset token: %w", err)
+		}
+
+		result, err := tx.ExecContext(ctx,
+			"UPDATE users SET password = $1 WHERE user_id = $2 AND deleted_at IS NULL",
+			hashedPassword, userID)
+		if err != nil {
+			return fmt.Errorf("failed to update password: %w", err)
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			return ErrResetTokenInvalid
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return userID, nil
+}
+
+// DeleteExpiredPasswordResetTokens removes tokens that can no longer be used
+func DeleteExpiredPasswordResetTokens(ctx context.Context, db *sql.DB) error {
+	_, err := db.ExecContext(ctx,
+		"DELETE FROM password_reset_tokens WHERE expires_at < NOW() OR used_at IS NOT NULL")
+	if err != nil {
+		return fmt.Errorf("failed to cleanup reset tokens: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,398 @@
+package database
+
+import (
+	"context"
+	"time"
+)
+
+// ========================================
+// REPOSITORY INTERFACES
+// ========================================
+
+// ArticleRepository reads and writes articles together with their
+// categories and tags. Missing articles are reported as sql.ErrNoRows.
+type ArticleRepository interface {
+	List(ctx context.Context, filter ArticleFilter) ([]Article, error)
+	Count(ctx context.Context, filter ArticleFilter) (int, error)
+	GetByID(ctx context.Context, id int) (*Article, error)
+	GetPublishedBySlug(ctx context.Context, slug string) (*Article, error)
+	ListByCategory(ctx context.Context, kategoriID, limit, offset int) ([]Article, error)
+	ListByTag(ctx context.Context, tagID, limit, offset int) ([]Article, error)
+	Create(ctx context.Context, input ArticleInput, userID int) (*Article, error)
+	Update(ctx context.Context, id int, input ArticleInput, editorID int) (*Article, error)
+	Delete(ctx context.Context, id int) error
+}
+
+// CommentRepository reads and writes comments, their threads and the
+// signals automated moderation scores them with
+type CommentRepository interface {
+	Create(ctx context.Context, comment *Comment) (*Comment, error)
+	GetByID(ctx context.Context, id int) (*Comment, error)
+	// Update replaces the content together with its new moderation result
+	Update(ctx context.Context, id int, konten, status string, score int, reasons []string) (*Comment, error)
+	UpdateStatus(ctx context.Context, id int, status string) (*Comment, error)
+	Delete(ctx context.Context, id int) error
+	List(ctx context.Context, status string, limit, offset int) ([]Comment, error)
+	Count(ctx context.Context, status string) (int, error)
+	ListByUser(ctx context.Context, userID, limit, offset int) ([]Comment, error)
+	CountByUser(ctx context.Context, userID int) (int, error)
+
+	ListThreads(ctx context.Context, articleID, limit, offset int) ([]Comment, error)
+	CountThreads(ctx context.Context, articleID int) (int, error)
+	LoadTrees(ctx context.Context, threads []Comment) error
+	ListReplies(ctx context.Context, parentID, limit, offset int) ([]Comment, error)
+	CountReplies(ctx context.Context, parentID int) (int, error)
+	IsVisible(ctx context.Context, id int) (bool, error)
+	ResolveParent(ctx context.Context, parentID, articleID int) (int, error)
+
+	BannedWords(ctx context.Context) ([]BannedWord, error)
+	AuthorHistory(ctx context.Context, userID int) (approved, rejected int, err error)
+	CountDuplicates(ctx context.Context, konten string, userID *int, artikelID, excludeID int, since time.Time) (byAuthor, onArticle int, err error)
+}
+
+// UserRepository reads and writes user accounts
+type UserRepository interface {
+	Authenticate(ctx context.Context, req *LoginRequest, client LoginClient, policy LockoutPolicy) (*User, error)
+	GetByID(ctx context.Context, id int) (*User, error)
+	GetByEmail(ctx context.Context, email string) (*User, error)
+	GetByUsername(ctx context.Context, username string) (*User, error)
+	List(ctx context.Context, limit, offset int) ([]UserResponse, error)
+	Count(ctx context.Context) (int, error)
+	EmailExists(ctx context.Context, email string) (bool, error)
+	UsernameExists(ctx context.Context, username string) (bool, error)
+	EmailTaken(ctx context.Context, email string, excludeUserID int) (bool, error)
+	UsernameTaken(ctx context.Context, username string, excludeUserID int) (bool, error)
+	Create(ctx context.Context, req *UserRequest) (*User, error)
+	Update(ctx context.Context, id int, req *UserUpdateRequest) (*User, error)
+	UpdateBasic(ctx context.Context, id int, username, email string) (*User, error)
+	UpdatePassword(ctx context.Context, id int, newPassword string) error
+	UpdateRole(ctx context.Context, id int, role string) error
+	Delete(ctx context.Context, id int) error
+	IsEmailVerified(ctx context.Context, id int) (bool, error)
+	MarkEmailVerified(ctx context.Context, id int) error
+}
+
+// TaxonomyRepository reads and writes categories and tags
+type TaxonomyRepository interface {
+	ListCategories(ctx context.Context) ([]Category, error)
+	ListCategoriesWithArticleCount(ctx context.Context) ([]map[string]interface{}, error)
+	GetCategoryByID(ctx context.Context, id int) (*Category, error)
+	GetCategoryByName(ctx context.Context, name string) (*Category, error)
+	CategoryExists(ctx context.Context, name string) (bool, error)
+	CreateCategory(ctx context.Context, req *CategoryRequest) (*Category, error)
+	UpdateCategory(ctx context.Context, id int, req *CategoryRequest) (*Category, error)
+	DeleteCategory(ctx context.Context, id int) error
+	ForceDeleteCategory(ctx context.Context, id int) error
+
+	ListTags(ctx context.Context) ([]Tag, error)
+	ListTagsWithArticleCount(ctx context.Context) ([]map[string]interface{}, error)
+	ListPopularTags(ctx context.Context, limit int) ([]map[string]interface{}, error)
+	SearchTags(ctx context.Context, keyword string) ([]Tag, error)
+	GetTagByID(ctx context.Context, id int) (*Tag, error)
+	GetTagByName(ctx context.Context, name string) (*Tag, error)
+	TagExists(ctx context.Context, name string) (bool, error)
+	CreateTag(ctx context.Context, req *TagRequest) (*Tag, error)
+	UpdateTag(ctx context.Context, id int, req *TagRequest) (*Tag, error)
+	DeleteTag(ctx context.Context, id int) error
+	ForceDeleteTag(ctx context.Context, id int) error
+	GetOrCreateTags(ctx context.Context, names []string) ([]int, error)
+}
+
+// Repositories bundles the repositories the HTTP handlers depend on
+type Repositories struct {
+	Articles ArticleRepository
+	Comments CommentRepository
+	Users    UserRepository
+	Taxonomy TaxonomyRepository
+}
+
+// ========================================
+// POSTGRES REPOSITORIES
+// ========================================
+
+// NewRepositories returns repositories backed by the package functions
+// on db
+func NewRepositories(db *DB) Repositories {
+	return Repositories{
+		Articles: articleRepository{db},
+		Comments: commentRepository{db},
+		Users:    userRepository{db},
+		Taxonomy: taxonomyRepository{db},
+	}
+}
+
+type articleRepository struct{ db *DB }
+
+func (r articleRepository) List(ctx context.Context, filter ArticleFilter) ([]Article, error) {
+	return GetAllArticles(ctx, r.db.DB, filter)
+}
+
+func (r articleRepository) Count(ctx context.Context, filter ArticleFilter) (int, error) {
+	return CountArticles(ctx, r.db.DB, filter)
+}
+
+func (r articleRepository) GetByID(ctx context.Context, id int) (*Article, error) {
+	return GetArticleByID(ctx, r.db.DB, id)
+}
+
+func (r articleRepository) GetPublishedBySlug(ctx context.Context, slug string) (*Article, error) {
+	return GetPublishedArticleBySlug(ctx, r.db.DB, slug)
+}
+
+func (r articleRepository) ListByCategory(ctx context.Context, kategoriID, limit, offset int) ([]Article, error) {
+	return GetArticlesByCategory(ctx, r.db.DB, kategoriID, limit, offset)
+}
+
+func (r articleRepository) ListByTag(ctx context.Context, tagID, limit, offset int) ([]Article, error) {
+	return GetArticlesByTag(ctx, r.db.DB, tagID, limit, offset)
+}
+
+func (r articleRepository) Create(ctx context.Context, input ArticleInput, userID int) (*Article, error) {
+	return CreateArticle(ctx, r.db, input, userID)
+}
+
+func (r articleRepository) Update(ctx context.Context, id int, input ArticleInput, editorID int) (*Article, error) {
+	return UpdateArticle(ctx, r.db, id, input, editorID)
+}
+
+func (r articleRepository) Delete(ctx context.Context, id int) error {
+	return DeleteArticle(ctx, r.db.DB, id)
+}
+
+type commentRepository struct{ db *DB }
+
+func (r commentRepository) Create(ctx context.Context, comment *Comment) (*Comment, error) {
+	return CreateCommentSimple(ctx, r.db.DB, comment)
+}
+
+func (r commentRepository) GetByID(ctx context.Context, id int) (*Comment, error) {
+	return GetCommentByIDSimple(ctx, r.db.DB, id)
+}
+
+func (r commentRepository) Update(ctx context.Context, id int, konten, status string, score int, reasons []string) (*Comment, error) {
+	return UpdateCommentSimple(ctx, r.db.DB, id, konten, status, score, reasons)
+}
+
+func (r commentRepository) UpdateStatus(ctx context.Context, id int, status string) (*Comment, error) {
+	return UpdateCommentStatus(ctx, r.db.DB, id, status)
+}
+
+func (r commentRepository) Delete(ctx context.Context, id int) error {
+	return DeleteCommentSimple(ctx, r.db.DB, id)
+}
+
+func (r commentRepository) List(ctx context.Context, status string, limit, offset int) ([]Comment, error) {
+	return GetAllComments(ctx, r.db.DB, status, limit, offset)
+}
+
+func (r commentRepository) Count(ctx context.Context, status string) (int, error) {
+	return CountComments(ctx, r.db.DB, status)
+}
+
+func (r commentRepository) ListByUser(ctx context.Context, userID, limit, offset int) ([]Comment, error) {
+	return GetCommentsByUserID(ctx, r.db.DB, userID, limit, offset)
+}
+
+func (r commentRepository) CountByUser(ctx context.Context, userID int) (int, error) {
+	return CountCommentsByUserID(ctx, r.db.DB, userID)
+}
+
+func (r commentRepository) ListThreads(ctx context.Context, articleID, limit, offset int) ([]Comment, error) {
+	return ListCommentThreads(ctx, r.db.DB, articleID, limit, offset)
+}
+
+func (r commentRepository) CountThreads(ctx context.Context, articleID int) (int, error) {
+	return CountCommentThreads(ctx, r.db.DB, articleID)
+}
+
+func (r commentRepository) LoadTrees(ctx context.Context, threads []Comment) error {
+	return LoadCommentTrees(ctx, r.db.DB, threads)
+}
+
+func (r commentRepository) ListReplies(ctx context.Context, parentID, limit, offset int) ([]Comment, error) {
+	return ListCommentReplies(ctx, r.db.DB, parentID, limit, offset)
+}
+
+func (r commentRepository) CountReplies(ctx context.Context, parentID int) (int, error) {
+	return CountCommentReplies(ctx, r.db.DB, parentID)
+}
+
+func (r commentRepository) IsVisible(ctx context.Context, id int) (bool, error) {
+	return IsCommentVisible(ctx, r.db.DB, id)
+}
+
+func (r commentRepository) ResolveParent(ctx context.Context, parentID, articleID int) (int, error) {
+	return ResolveCommentParent(ctx, r.db.DB, parentID, articleID)
+}
+
+func (r commentRepository) BannedWords(ctx context.Context) ([]BannedWord, error) {
+	return ListBannedWords(ctx, r.db.DB)
+}
+
+func (r commentRepository) AuthorHistory(ctx context.Context, userID int) (int, int, error) {
+	return GetCommentHistory(ctx, r.db.DB, userID)
+}
+
+func (r commentRepository) CountDuplicates(ctx context.Context, konten string, userID *int, artikelID, excludeID int, since time.Time) (int, int, error) {
+	return CountDuplicateComments(ctx, r.db.DB, konten, userID, artikelID, excludeID, since)
+}
+
+type userRepository struct{ db *DB }
+
+func (r userRepository) Authenticate(ctx context.Context, req *LoginRequest, client LoginClient, policy LockoutPolicy) (*User, error) {
+	return AuthenticateUser(ctx, r.db.DB, req, client, policy)
+}
+
+func (r userRepository) GetByID(ctx context.Context, id int) (*User, error) {
+	return GetUserByID(ctx, r.db.DB, id)
+}
+
+func (r userRepository) GetByEmail(ctx context.Context, email string) (*User, error) {
+	return GetUserByEmail(ctx, r.db.DB, email)
+}
+
+func (r userRepository) GetByUsername(ctx context.Context, username string) (*User, error) {
+	return GetUserByUsername(ctx, r.db.DB, username)
+}
+
+func (r userRepository) List(ctx context.Context, limit, offset int) ([]UserResponse, error) {
+	return GetAllUsers(ctx, r.db.DB, limit, offset)
+}
+
+func (r userRepository) Count(ctx context.Context) (int, error) {
+	return CountUsers(ctx, r.db.DB)
+}
+
+func (r userRepository) EmailExists(ctx context.Context, email string) (bool, error) {
+	return IsEmailExists(ctx, r.db.DB, email)
+}
+
+func (r userRepository) UsernameExists(ctx context.Context, username string) (bool, error) {
+	return IsUsernameExists(ctx, r.db.DB, username)
+}
+
+func (r userRepository) EmailTaken(ctx context.Context, email string, excludeUserID int) (bool, error) {
+	return CheckEmailExists(ctx, r.db.DB, email, excludeUserID)
+}
+
+func (r userRepository) UsernameTaken(ctx context.Context, username string, excludeUserID int) (bool, error) {
+	return CheckUsernameExists(ctx, r.db.DB, username, excludeUserID)
+}
+
+func (r userRepository) Create(ctx context.Context, req *UserRequest) (*User, error) {
+	return CreateUser(ctx, r.db.DB, req)
+}
+
+func (r userRepository) Update(ctx context.Context, id int, req *UserUpdateRequest) (*User, error) {
+	return UpdateUser(ctx, r.db.DB, id, req)
+}
+
+func (r userRepository) UpdateBasic(ctx context.Context, id int, username, email string) (*User, error) {
+	return UpdateUserBasic(ctx, r.db.DB, id, username, email)
+}
+
+func (r userRepository) UpdatePassword(ctx context.Context, id int, newPassword string) error {
+	return UpdateUserPassword(ctx, r.db.DB, id, newPassword)
+}
+
+func (r userRepository) UpdateRole(ctx context.Context, id int, role string) error {
+	return UpdateUserRole(ctx, r.db.DB, id, role)
+}
+
+func (r userRepository) Delete(ctx context.Context, id int) error {
+	return DeleteUser(ctx, r.db, id)
+}
+
+func (r userRepository) IsEmailVerified(ctx context.Context, id int) (bool, error) {
+	return IsEmailVerified(ctx, r.db.DB, id)
+}
+
+func (r userRepository) MarkEmailVerified(ctx context.Context, id int) error {
+	return MarkEmailVerified(ctx, r.db.DB, id)
+}
+
+type taxonomyRepository struct{ db *DB }
+
+func (r taxonomyRepository) ListCategories(ctx context.Context) ([]Category, error) {
+	return ListCategories(ctx, r.db.DB)
+}
+
+func (r taxonomyRepository) ListCategoriesWithArticleCount(ctx context.Context) ([]map[string]interface{}, error) {
+	return ListCategoriesWithArticleCount(ctx, r.db.DB)
+}
+
+func (r taxonomyRepository) GetCategoryByID(ctx context.Context, id int) (*Category, error) {
+	return GetCategoryByID(ctx, r.db.DB, id)
+}
+
+func (r taxonomyRepository) GetCategoryByName(ctx context.Context, name string) (*Category, error) {
+	return GetCategoryByName(ctx, r.db.DB, name)
+}
+
+func (r taxonomyRepository) CategoryExists(ctx context.Context, name string) (bool, error) {
+	return IsCategoryExists(ctx, r.db.DB, name)
+}
+
+func (r taxonomyRepository) CreateCategory(ctx context.Context, req *CategoryRequest) (*Category, error) {
+	return CreateCategory(ctx, r.db.DB, req)
+}
+
+func (r taxonomyRepository) UpdateCategory(ctx context.Context, id int, req *CategoryRequest) (*Category, error) {
+	return UpdateCategory(ctx, r.db.DB, id, req)
+}
+
+func (r taxonomyRepository) DeleteCategory(ctx context.Context, id int) error {
+	return DeleteCategory(ctx, r.db.DB, id)
+}
+
+func (r taxonomyRepository) ForceDeleteCategory(ctx context.Context, id int) error {
+	return ForceDeleteCategory(ctx, r.db, id)
+}
+
+func (r taxonomyRepository) ListTags(ctx context.Context) ([]Tag, error) {
+	return ListTags(ctx, r.db.DB)
+}
+
+func (r taxonomyRepository) ListTagsWithArticleCount(ctx context.Context) ([]map[string]interface{}, error) {
+	return ListTagsWithArticleCount(ctx, r.db.DB)
+}
+
+func (r taxonomyRepository) ListPopularTags(ctx context.Context, limit int) ([]map[string]interface{}, error) {
+	return ListPopularTags(ctx, r.db.DB, limit)
+}
+
+func (r taxonomyRepository) SearchTags(ctx context.Context, keyword string) ([]Tag, error) {
+	return SearchTags(ctx, r.db.DB, keyword)
+}
+
+func (r taxonomyRepository) GetTagByID(ctx context.Context, id int) (*Tag, error) {
+	return GetTagByID(ctx, r.db.DB, id)
+}
+
+func (r taxonomyRepository) GetTagByName(ctx context.Context, name string) (*Tag, error) {
+	return GetTagByName(ctx, r.db.DB, name)
+}
+
+func (r taxonomyRepository) TagExists(ctx context.Context, name string) (bool, error) {
+	return IsTagExists(ctx, r.db.DB, name)
+}
+
+func (r taxonomyRepository) CreateTag(ctx context.Context, req *TagRequest) (*Tag, error) {
+	return CreateTag(ctx, r.db.DB, req)
+}
+
+func (r taxonomyRepository) UpdateTag(ctx context.Context, id int, req *TagRequest) (*Tag, error) {
+	return UpdateTag(ctx, r.db.DB, id, req)
+}
+
+func (r taxonomyRepository) DeleteTag(ctx context.Context, id int) error {
+	return DeleteTag(ctx, r.db.DB, id)
+}
+
+func (r taxonomyRepository) ForceDeleteTag(ctx context.Context, id int) error {
+	return ForceDeleteTag(ctx, r.db, id)
+}
+
+func (r taxonomyRepository) GetOrCreateTags(ctx context.Context, names []string) ([]int, error) {
+	return GetOrCreateTags(ctx, r.db.DB, names)
+}
@@ -0,0 +1,137 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ArticleRevision is a snapshot of an article's content taken right before
+// it was overwritten by an update
+type ArticleRevision struct {
+	RevisiID    int       `json:"revisi_id"`
+	ArtikelID   int       `json:"artikel_id"`
+	NomorRevisi int       `json:"nomor_revisi"`
+	Judul       string    `json:"judul"`
+	Slug        *string   `json:"slug,omitempty"`
+	Konten      string    `json:"konten,omitempty"` // not loaded by ListArticleRevisions
+	Excerpt     *string   `json:"excerpt,omitempty"`
+	GambarUtama *string   `json:"gambar_utama,omitempty"`
+	Penulis     *string   `json:"penulis,omitempty"`
+	KategoriIDs []int     `json:"kategori_ids"`
+	TagIDs      []int     `json:"tag_ids"`
+	UserID      *int      `json:"user_id,omitempty"`
+	Username    *string   `json:"username,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// createArticleRevision copies the current content of an article, including
+// its category and tag IDs, into article_revisions. editorID is the user whose
+// update replaces this content. Nothing is written if the article does not exist.
+func createArticleRevision(ctx context.Context, tx *sql.Tx, articleID, editorID int) error {
+	_, err := tx.ExecContext(ctx, `
+        INSERT INTO article_revisions (
+            artikel_id, nomor_revisi, judul, slug, konten, excerpt,
+            gambar_utama, penulis, kategori_ids, tag_ids, user_id
+        )
+        SELECT a.artikel_id,
+               COALESCE((SELECT MAX(nomor_revisi) FROM article_revisions WHERE artikel_id = a.artikel_id), 0) + 1,
+               a.judul, a.slug, a.konten, a.excerpt, a.gambar_utama, a.penulis,
+               ARRAY(SELECT kategori_id FROM artikel_kategori WHERE artikel_id = a.artikel_id ORDER BY kategori_id),
+               ARRAY(SELECT tag_id FROM artikel_tag WHERE artikel_id = a.artikel_id ORDER BY tag_id),
+               $2
+        FROM articles a
+        WHERE a.artikel_id = $1
+    `, articleID, editorID)
+	if err != nil {
+		return fmt.Errorf("failed to save article revision: %w", err)
+	}
+	return nil
+}
+
+const articleRevisionColumns = `
+        r.revisi_id, r.artikel_id, r.nomor_revisi, r.judul, r.slug, r.konten,
+        r.excerpt, r.gambar_utama, r.penulis, r.kategori_ids, r.tag_ids,
+        r.user_id, u.username, r.created_at
+`
+
+func scanArticleRevision(scanner interface{ Scan(...any) error }) (*ArticleRevision, error) {
+	var rev ArticleRevision
+	var kategoriIDs, tagIDs pq.Int64Array
+
+	err := scanner.Scan(
+		&rev.RevisiID, &rev.ArtikelID, &rev.NomorRevisi, &rev.Judul, &rev.Slug, &rev.Konten,
+		&rev.Excerpt, &rev.GambarUtama, &rev.Penulis, &kategoriIDs, &tagIDs,
+		&rev.UserID, &rev.Username, &rev.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	rev.KategoriIDs = int64sToInts(kategoriIDs)
+	rev.TagIDs = int64sToInts(tagIDs)
+	return &rev, nil
+}
+
+// ListArticleRevisions returns one page of an article's revisions, newest
+// first. Konten is left empty; GetArticleRevision loads a full revision.
+func ListArticleRevisions(ctx context.Context, db *sql.DB, articleID, limit, offset int) ([]ArticleRevision, error) {
+	query := `
+        SELECT r.revisi_id, r.artikel_id, r.nomor_revisi, r.judul, r.slug, '' AS konten,
+               r.excerpt, r.gambar_utama, r.penulis, r.kategori_ids, r.tag_ids,
+               r.user_id, u.username, r.created_at
+        FROM article_revisions r
+        LEFT JOIN users u ON r.user_id = u.user_id
+        WHERE r.artikel_id = $1
+        ORDER BY r.nomor_revisi DESC
+        LIMIT NULLIF($2, 0) OFFSET $3
+    `
+
+	rows, err := db.QueryContext(ctx, query, articleID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []ArticleRevision{}
+	for rows.Next() {
+		rev, err := scanArticleRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, *rev)
+	}
+
+	return revisions, rows.Err()
+}
+
+// CountArticleRevisions retrieves the number of revisions of an article
+func CountArticleRevisions(ctx context.Context, db *sql.DB, articleID int) (int, error) {
+	var count int
+	err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM article_revisions WHERE artikel_id = $1", articleID).Scan(&count)
+	return count, err
+}
+
+// GetArticleRevision returns a single revision by its number within the article.
+// Returns sql.ErrNoRows if it does not exist.
+func GetArticleRevision(ctx context.Context, db *sql.DB, articleID, nomorRevisi int) (*ArticleRevision, error) {
+	query := `
+        SELECT ` + articleRevisionColumns + `
+        FROM article_revisions r
+        LEFT JOIN users u ON r.user_id = u.user_id
+        WHERE r.artikel_id = $1 AND r.nomor_revisi = $2
+    `
+
+	return scanArticleRevision(db.QueryRowContext(ctx, query, articleID, nomorRevisi))
+}
+
+func int64sToInts(values []int64) []int {
+	ints := make([]int, len(values))
+	for i, v := range values {
+		ints[i] = int(v)
+	}
+	return ints
+}
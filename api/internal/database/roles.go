@@ -0,0 +1,251 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// AdminRole always holds every permission; its permissions cannot be edited
+const AdminRole = "admin"
+
+type Role struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	IsSystem    bool      `json:"is_system"`
+	Permissions []string  `json:"permissions"`
+	UserCount   int       `json:"user_count"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type Permission struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+var (
+	ErrRoleExists        = errors.New("role already exists")
+	ErrRoleInUse         = errors.New("role is still assigned to users")
+	ErrRoleProtected     = errors.New("role cannot be changed")
+	ErrUnknownPermission = errors.New("unknown permission")
+)
+
+const roleQuery = `
+        SELECT r.name, r.description, r.is_system, r.created_at, r.updated_at,
+               COALESCE(ARRAY(SELECT rp.permission FROM role_permissions rp
+                              WHERE rp.role = r.name ORDER BY rp.permission), '{}'),
+               (SELECT COUNT(*) FROM users u WHERE u.role = r.name)
+        FROM roles r
+`
+
+func scanRole(row interface{ Scan(...any) error }) (*Role, error) {
+	var role Role
+	var perms pq.StringArray
+	err := row.Scan(&role.Name, &role.Description, &role.IsSystem, &role.CreatedAt, &role.UpdatedAt,
+		&perms, &role.UserCount)
+	if err != nil {
+		return nil, err
+	}
+	role.Permissions = []string(perms)
+	return &role, nil
+}
+
+// LoadRolePermissions returns every role with its permissions, roles
+// without any permission included with an empty list
+func LoadRolePermissions(ctx context.Context, db *sql.DB) (map[string][]string, error) {
+	rows, err := db.QueryContext(ctx, `
+        SELECT r.name, rp.permission
+        FROM roles r
+        LEFT JOIN role_permissions rp ON rp.role = r.name
+    `)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load role permissions: %w", err)
+	}
+	defer rows.Close()
+
+	roles := make(map[string][]string)
+	for rows.Next() {
+		var role string
+		var perm sql.NullString
+		if err := rows.Scan(&role, &perm); err != nil {
+			return nil, err
+		}
+		if _, ok := roles[role]; !ok {
+			roles[role] = []string{}
+		}
+		if perm.Valid {
+			roles[role] = append(roles[role], perm.String)
+		}
+	}
+	return roles, rows.Err()
+}
+
+// ListRoles retrieves all roles with their permissions
+func ListRoles(ctx context.Context, db *sql.DB) ([]Role, error) {
+	rows, err := db.QueryContext(ctx, roleQuery+" ORDER BY r.is_system DESC, r.name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := []Role{}
+	for rows.Next() {
+		role, err := scanRole(rows)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, *role)
+	}
+	return roles, rows.Err()
+}
+
+// GetRole retrieves a role by name. Returns sql.ErrNoRows when it does
+// not exist.
+func GetRole(ctx context.Context, db *sql.DB, name string) (*Role, error) {
+	return getRole(ctx, db, name)
+}
+
+func getRole(ctx context.Context, q dbtx, name string) (*Role, error) {
+	return scanRole(q.QueryRowContext(ctx, roleQuery+" WHERE r.name = $1", name))
+}
+
+// ListPermissions retrieves every known permission
+func ListPermissions(ctx context.Context, db *sql.DB) ([]Permission, error) {
+	rows, err := db.QueryContext(ctx, "SELECT name, description FROM permissions ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	perms := []Permission{}
+	for rows.Next() {
+		var p Permission
+		if err := rows.Scan(&p.Name, &p.Description); err != nil {
+			return nil, err
+		}
+		perms = append(perms, p)
+	}
+	return perms, rows.Err()
+}
+
+// CreateRole creates a custom role with the given permissions
+func CreateRole(ctx context.Context, db *sql.DB, name, description string, permissions []string) (*Role, error) {
+	var role *Role
+	err := withTransaction(ctx, db, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, "INSERT INTO roles (name, description) VALUES ($1, $2)", name, description)
+		if err != nil {
+			var pqErr *pq.Error
+			if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+				return ErrRoleExists
+			}
+			return fmt.Errorf("failed to create role: %w", err)
+		}
+
+		if err := setRolePermissions(ctx, tx, name, permissions); err != nil {
+			return err
+		}
+
+		role, err = getRole(ctx, tx, name)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, "role.create", auditRole.Type, name, nil, role)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return role, nil
+}
+
+// UpdateRole replaces the description and permissions of a role. Returns
+// sql.ErrNoRows when it does not exist and ErrRoleProtected for AdminRole.
+func UpdateRole(ctx context.Context, db *sql.DB, name, description string, permissions []string) (*Role, error) {
+	if name == AdminRole {
+		return nil, ErrRoleProtected
+	}
+
+	var after *Role
+	err := withTransaction(ctx, db, func(tx *sql.Tx) error {
+		before, err := getRole(ctx, tx, name)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx,
+			"UPDATE roles SET description = $2, updated_at = NOW() WHERE name = $1", name, description)
+		if err != nil {
+			return fmt.Errorf("failed to update role: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, "DELETE FROM role_permissions WHERE role = $1", name); err != nil {
+			return fmt.Errorf("failed to clear role permissions: %w", err)
+		}
+		if err := setRolePermissions(ctx, tx, name, permissions); err != nil {
+			return err
+		}
+
+		after, err = getRole(ctx, tx, name)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, "role.update", auditRole.Type, name, before, after)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return after, nil
+}
+
+// DeleteRole removes a custom role. System roles return ErrRoleProtected,
+// roles still assigned to users ErrRoleInUse and unknown roles
+// sql.ErrNoRows. Pending invitations for the role are dropped with it.
+func DeleteRole(ctx context.Context, db *sql.DB, name string) error {
+	return withTransaction(ctx, db, func(tx *sql.Tx) error {
+		before, err := getRole(ctx, tx, name)
+		if err != nil {
+			return err
+		}
+		if before.IsSystem {
+			return ErrRoleProtected
+		}
+
+		_, err = tx.ExecContext(ctx, "DELETE FROM roles WHERE name = $1", name)
+		if err != nil {
+			var pqErr *pq.Error
+			if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+				return ErrRoleInUse
+			}
+			return fmt.Errorf("failed to delete role: %w", err)
+		}
+
+		return recordAudit(ctx, tx, "role.delete", auditRole.Type, name, before, nil)
+	})
+}
+
+// setRolePermissions grants the permissions to the role
+func setRolePermissions(ctx context.Context, tx *sql.Tx, role string, permissions []string) error {
+	if len(permissions) == 0 {
+		return nil
+	}
+
+	_, err := tx.ExecContext(ctx, `
+        INSERT INTO role_permissions (role, permission)
+        SELECT $1, UNNEST($2::text[])
+        ON CONFLICT DO NOTHING
+    `, role, pq.Array(permissions))
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+			return ErrUnknownPermission
+		}
+		return fmt.Errorf("failed to set role permissions: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+)
+
+// SearchResult is a published article matched by full-text search. JudulHighlight
+// and Snippet mark the matched terms with <mark>…</mark>.
+type SearchResult struct {
+	Article
+	Rank           float64 `json:"rank"`
+	JudulHighlight string  `json:"judul_highlight"`
+	Snippet        string  `json:"snippet"`
+}
+
+// SearchArticles runs a full-text search over published articles, ordered by
+// ts_rank. The query accepts web search syntax ("quoted phrases", -exclude, or).
+// Matches in the title weigh more than the excerpt, which weighs more than the body.
+func SearchArticles(ctx context.Context, db *sql.DB, q string, limit, offset int) ([]SearchResult, error) {
+	query := `
+        SELECT a.artikel_id, a.judul, a.slug, a.konten, a.excerpt,
+               a.gambar_utama, a.penulis, a.status, a.user_id,
+               a.tanggal_publikasi, a.tanggal_dibuat, a.tanggal_diperbarui,
+               ts_rank(a.search_vector, q.query) AS rank,
+               ts_headline('news_search', a.judul, q.query,
+                   'StartSel=<mark>, StopSel=</mark>, HighlightAll=true'),
+               ts_headline('news_search', regexp_replace(a.konten, '<[^>]*>', ' ', 'g'), q.query,
+                   'StartSel=<mark>, StopSel=</mark>, MaxWords=35, MinWords=15, MaxFragments=2')
+        FROM articles a, websearch_to_tsquery('news_search', $1) AS q(query)
+        WHERE a.status = 'published' AND a.deleted_at IS NULL AND a.search_vector @@ q.query
+        ORDER BY rank DESC, a.tanggal_publikasi DESC
+        LIMIT $2 OFFSET $3
+    `
+
+	rows, err := db.QueryContext(ctx, query, q, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := []SearchResult{}
+	for rows.Next() {
+		var r SearchResult
+		err := rows.Scan(
+			&r.ArtikelID, &r.Judul, &r.Slug, &r.Konten, &r.Excerpt,
+			&r.GambarUtama, &r.Penulis, &r.Status, &r.UserID,
+			&r.TanggalPublikasi, &r.TanggalDibuat, &r.TanggalDiperbarui,
+			&r.Rank, &r.JudulHighlight, &r.Snippet,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	ptrs := make([]*Article, len(results))
+	for i := range results {
+		ptrs[i] = &results[i].Article
+	}
+	if err := loadArticleRelations(ctx, db, ptrs); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// CountSearchResults returns the number of published articles matching q
+func CountSearchResults(ctx context.Context, db *sql.DB, q string) (int, error) {
+	query := `
+        SELECT COUNT(*)
+        FROM articles a
+        WHERE a.status = 'published' AND a.deleted_at IS NULL
+          AND a.search_vector @@ websearch_to_tsquery('news_search', $1)
+    `
+
+	var total int
+	if err := db.QueryRowContext(ctx, query, q).Scan(&total); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
@@ -0,0 +1,169 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// SitemapArticle is the minimal article data needed for a sitemap entry
+type SitemapArticle struct {
+	ArtikelID         int
+	Judul             string
+	Slug              string
+	TanggalPublikasi  time.Time
+	TanggalDiperbarui time.Time
+	Tags              []string
+}
+
+// SitemapLandingPage is a category or tag page with the time its newest
+// published article was last updated (nil if it has no published articles)
+type SitemapLandingPage struct {
+	Type    string // "kategori" or "tag"
+	Name    string
+	LastMod *time.Time
+}
+
+// GetSitemapVersion returns a value that changes whenever an article is
+// published, unpublished or edited while published, or when a category or
+// tag is added, renamed or removed. Cached sitemaps are rebuilt when it
+// differs.
+func GetSitemapVersion(ctx context.Context, db *sql.DB) (string, error) {
+	query := `
+        SELECT
+            (SELECT COUNT(*) FROM articles WHERE status = 'published' AND deleted_at IS NULL),
+            (SELECT COALESCE(MAX(tanggal_diperbarui), 'epoch') FROM articles
+             WHERE status = 'published' AND deleted_at IS NULL),
+            (SELECT md5(COALESCE(string_agg(kategori_id || ':' || nama_kategori, ',' ORDER BY kategori_id), ''))
+             FROM categories WHERE deleted_at IS NULL),
+            (SELECT md5(COALESCE(string_agg(tag_id || ':' || nama_tag, ',' ORDER BY tag_id), ''))
+             FROM tags WHERE deleted_at IS NULL)
+    `
+
+	var published int
+	var lastUpdate time.Time
+	var categories, tags string
+	err := db.QueryRowContext(ctx, query).Scan(&published, &lastUpdate, &categories, &tags)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%d-%d-%s-%s", published, lastUpdate.UnixNano(), categories, tags), nil
+}
+
+// sitemapArticleWhere selects the articles listed in the article sitemaps
+const sitemapArticleWhere = "status = 'published' AND slug IS NOT NULL AND deleted_at IS NULL"
+
+// CountSitemapArticles returns how many articles ListSitemapArticles pages
+// through
+func CountSitemapArticles(ctx context.Context, db *sql.DB) (int, error) {
+	var count int
+	err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM articles WHERE "+sitemapArticleWhere).Scan(&count)
+	return count, err
+}
+
+// ListSitemapArticles returns published articles ordered by artikel_id so
+// sitemap chunks stay stable as new articles are added
+func ListSitemapArticles(ctx context.Context, db *sql.DB, limit, offset int) ([]SitemapArticle, error) {
+	query := `
+        SELECT artikel_id, judul, slug, COALESCE(tanggal_publikasi, tanggal_dibuat), tanggal_diperbarui
+        FROM articles
+        WHERE ` + sitemapArticleWhere + `
+        ORDER BY artikel_id
+        LIMIT $1 OFFSET $2
+    `
+
+	rows, err := db.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var articles []SitemapArticle
+	for rows.Next() {
+		var a SitemapArticle
+		if err := rows.Scan(&a.ArtikelID, &a.Judul, &a.Slug, &a.TanggalPublikasi, &a.TanggalDiperbarui); err != nil {
+			return nil, err
+		}
+		articles = append(articles, a)
+	}
+
+	return articles, rows.Err()
+}
+
+// ListRecentlyPublishedArticles returns articles published since the given
+// time, newest first, with their tag names. Google News accepts at most 1000
+// URLs per news sitemap.
+func ListRecentlyPublishedArticles(ctx context.Context, db *sql.DB, since time.Time) ([]SitemapArticle, error) {
+	query := `
+        SELECT a.artikel_id, a.judul, a.slug, a.tanggal_publikasi, a.tanggal_diperbarui,
+               ARRAY(SELECT t.nama_tag FROM artikel_tag at
+                     JOIN tags t ON at.tag_id = t.tag_id
+                     WHERE at.artikel_id = a.artikel_id AND t.deleted_at IS NULL ORDER BY t.nama_tag)
+        FROM articles a
+        WHERE a.status = 'published' AND a.slug IS NOT NULL AND a.deleted_at IS NULL
+          AND a.tanggal_publikasi >= $1
+        ORDER BY a.tanggal_publikasi DESC
+        LIMIT 1000
+    `
+
+	rows, err := db.QueryContext(ctx, query, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var articles []SitemapArticle
+	for rows.Next() {
+		var a SitemapArticle
+		err := rows.Scan(&a.ArtikelID, &a.Judul, &a.Slug, &a.TanggalPublikasi,
+			&a.TanggalDiperbarui, pq.Array(&a.Tags))
+		if err != nil {
+			return nil, err
+		}
+		articles = append(articles, a)
+	}
+
+	return articles, rows.Err()
+}
+
+// ListSitemapLandingPages returns every category and tag together with the
+// last update of their newest published article
+func ListSitemapLandingPages(ctx context.Context, db *sql.DB) ([]SitemapLandingPage, error) {
+	query := `
+        SELECT 'kategori', c.nama_kategori, MAX(a.tanggal_diperbarui)
+        FROM categories c
+        LEFT JOIN artikel_kategori ak ON ak.kategori_id = c.kategori_id
+        LEFT JOIN articles a ON a.artikel_id = ak.artikel_id AND a.status = 'published' AND a.deleted_at IS NULL
+        WHERE c.deleted_at IS NULL
+        GROUP BY c.kategori_id, c.nama_kategori
+        UNION ALL
+        SELECT 'tag', t.nama_tag, MAX(a.tanggal_diperbarui)
+        FROM tags t
+        LEFT JOIN artikel_tag at ON at.tag_id = t.tag_id
+        LEFT JOIN articles a ON a.artikel_id = at.artikel_id AND a.status = 'published' AND a.deleted_at IS NULL
+        WHERE t.deleted_at IS NULL
+        GROUP BY t.tag_id, t.nama_tag
+        ORDER BY 1, 2
+    `
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pages []SitemapLandingPage
+	for rows.Next() {
+		var p SitemapLandingPage
+		if err := rows.Scan(&p.Type, &p.Name, &p.LastMod); err != nil {
+			return nil, err
+		}
+		pages = append(pages, p)
+	}
+
+	return pages, rows.Err()
+}
@@ -20,20 +20,20 @@ type TagRequest struct {
 }
 
 func CreateTag(ctx context.Context, db *sql.DB, req *TagRequest) (*Tag, error) {
-	query := `
-        INSERT INTO tags (nama_tag)
-        VALUES ($1)
-        RETURNING tag_id, nama_tag, created_at
-    `
-
-	var tag Tag
-	err := db.QueryRowContext(ctx, query, req.NamaTag).Scan(
-		&tag.TagID, &tag.NamaTag, &tag.CreatedAt)
+	var tag *Tag
+	err := withTransaction(ctx, db, func(tx *sql.Tx) error {
+		var err error
+		tag, err = CreateTagTx(ctx, tx, req)
+		if err != nil {
+			return err
+		}
+		return auditCreatedTx(ctx, tx, "tag.create", auditTag, tag.TagID)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create tag: %w", err)
+		return nil, err
 	}
 
-	return &tag, nil
+	return tag, nil
 }
 
 func CreateTagTx(ctx context.Context, tx *sql.Tx, req *TagRequest) (*Tag, error) {
@@ -57,7 +57,7 @@ func GetTagByID(ctx context.Context, db *sql.DB, tagID int) (*Tag, error) {
 	query := `
         SELECT tag_id, nama_tag, created_at
         FROM tags
-        WHERE tag_id = $1
+        WHERE tag_id = $1 AND deleted_at IS NULL
     `
 
 	var tag Tag
@@ -77,7 +77,7 @@ func GetTagByName(ctx context.Context, db *sql.DB, name string) (*Tag, error) {
 	query := `
         SELECT tag_id, nama_tag, created_at
         FROM tags
-        WHERE LOWER(nama_tag) = LOWER($1)
+        WHERE LOWER(nama_tag) = LOWER($1) AND deleted_at IS NULL
     `
 
 	var tag Tag
@@ -97,6 +97,7 @@ func ListTags(ctx context.Context, db *sql.DB) ([]Tag, error) {
 	query := `
         SELECT tag_id, nama_tag, created_at
         FROM tags
+        WHERE deleted_at IS NULL
         ORDER BY nama_tag ASC
     `
 
@@ -122,10 +123,11 @@ func ListTags(ctx context.Context, db *sql.DB) ([]Tag, error) {
 func ListTagsWithArticleCount(ctx context.Context, db *sql.DB) ([]map[string]interface{}, error) {
 	query := `
         SELECT t.tag_id, t.nama_tag,
-               COUNT(at.artikel_id) as article_count
+               COUNT(a.artikel_id) as article_count
         FROM tags t
         LEFT JOIN artikel_tag at ON t.tag_id = at.tag_id
-        LEFT JOIN articles a ON at.artikel_id = a.artikel_id AND a.status = 'published'
+        LEFT JOIN articles a ON at.artikel_id = a.artikel_id AND a.status = 'published' AND a.deleted_at IS NULL
+        WHERE t.deleted_at IS NULL
         GROUP BY t.tag_id, t.nama_tag
         ORDER BY t.nama_tag ASC
     `
@@ -161,12 +163,13 @@ func ListTagsWithArticleCount(ctx context.Context, db *sql.DB) ([]map[string]int
 func ListPopularTags(ctx context.Context, db *sql.DB, limit int) ([]map[string]interface{}, error) {
 	query := `
         SELECT t.tag_id, t.nama_tag,
-               COUNT(at.artikel_id) as article_count
+               COUNT(a.artikel_id) as article_count
         FROM tags t
         LEFT JOIN artikel_tag at ON t.tag_id = at.tag_id
-        LEFT JOIN articles a ON at.artikel_id = a.artikel_id AND a.status = 'published'
+        LEFT JOIN articles a ON at.artikel_id = a.artikel_id AND a.status = 'published' AND a.deleted_at IS NULL
+        WHERE t.deleted_at IS NULL
         GROUP BY t.tag_id, t.nama_tag
-        HAVING COUNT(at.artikel_id) > 0
+        HAVING COUNT(a.artikel_id) > 0
         ORDER BY article_count DESC, t.nama_tag ASC
         LIMIT $1
     `
@@ -203,7 +206,7 @@ func SearchTags(ctx context.Context, db *sql.DB, keyword string) ([]Tag, error)
 	query := `
         SELECT tag_id, nama_tag
         FROM tags
-        WHERE LOWER(nama_tag) LIKE LOWER($1)
+        WHERE LOWER(nama_tag) LIKE LOWER($1) AND deleted_at IS NULL
         ORDER BY nama_tag ASC
     `
 
@@ -228,31 +231,27 @@ func SearchTags(ctx context.Context, db *sql.DB, keyword string) ([]Tag, error)
 }
 
 func UpdateTag(ctx context.Context, db *sql.DB, tagID int, req *TagRequest) (*Tag, error) {
-	query := `
-        UPDATE tags
-        SET nama_tag = $1
-        WHERE tag_id = $2
-        RETURNING tag_id, nama_tag, created_at
-    `
-
-	var tag Tag
-	err := db.QueryRowContext(ctx, query, req.NamaTag, tagID).Scan(
-		&tag.TagID, &tag.NamaTag, &tag.CreatedAt)
+	var tag *Tag
+	err := auditedChange(ctx, db, "tag.update", auditTag, tagID, func(tx *sql.Tx) error {
+		var err error
+		tag, err = UpdateTagTx(ctx, tx, tagID, req)
+		return err
+	})
+	if err == sql.ErrNoRows {
+		return nil, errors.New("tag not found")
+	}
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, errors.New("tag not found")
-		}
-		return nil, fmt.Errorf("failed to update tag: %w", err)
+		return nil, err
 	}
 
-	return &tag, nil
+	return tag, nil
 }
 
 func UpdateTagTx(ctx context.Context, tx *sql.Tx, tagID int, req *TagRequest) (*Tag, error) {
 	query := `
         UPDATE tags
         SET nama_tag = $1
-        WHERE tag_id = $2
+        WHERE tag_id = $2 AND deleted_at IS NULL
         RETURNING tag_id, nama_tag, created_at
     `
 
@@ -270,45 +269,19 @@ func UpdateTagTx(ctx context.Context, tx *sql.Tx, tagID int, req *TagRequest) (*
 }
 
 func DeleteTag(ctx context.Context, db *sql.DB, tagID int) error {
-	// Check if tag has articles
-	var articleCount int
-	countQuery := `
-        SELECT COUNT(*)
-        FROM artikel_tag
-        WHERE tag_id = $1
-    `
-	err := db.QueryRowContext(ctx, countQuery, tagID).Scan(&articleCount)
-	if err != nil {
-		return fmt.Errorf("failed to check tag usage: %w", err)
-	}
-
-	if articleCount > 0 {
-		return errors.New("cannot delete tag that has articles")
-	}
-
-	res, err := db.ExecContext(ctx, "DELETE FROM tags WHERE tag_id = $1", tagID)
-	if err != nil {
-		return fmt.Errorf("error deleting tag ID %d: %w", tagID, err)
-	}
-
-	count, err := res.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("error getting rows affected for tag ID %d delete: %w", tagID, err)
-	}
-	if count == 0 {
-		return sql.ErrNoRows
-	}
-
-	return nil
+	return auditedChange(ctx, db, "tag.delete", auditTag, tagID, func(tx *sql.Tx) error {
+		return DeleteTagTx(ctx, tx, tagID)
+	})
 }
 
 func DeleteTagTx(ctx context.Context, tx *sql.Tx, tagID int) error {
-	// Check if tag has articles
+	// Check if tag has articles (articles in the trash do not count)
 	var articleCount int
 	countQuery := `
         SELECT COUNT(*)
-        FROM artikel_tag
-        WHERE tag_id = $1
+        FROM artikel_tag at
+        JOIN articles a ON a.artikel_id = at.artikel_id AND a.deleted_at IS NULL
+        WHERE at.tag_id = $1
     `
 	err := tx.QueryRowContext(ctx, countQuery, tagID).Scan(&articleCount)
 	if err != nil {
@@ -319,54 +292,22 @@ func DeleteTagTx(ctx context.Context, tx *sql.Tx, tagID int) error {
 		return errors.New("cannot delete tag that has articles")
 	}
 
-	res, err := tx.ExecContext(ctx, "DELETE FROM tags WHERE tag_id = $1", tagID)
-	if err != nil {
-		return fmt.Errorf("error executing delete for tag ID %d in tx: %w", tagID, err)
-	}
-
-	count, err := res.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("error getting rows affected for tag ID %d delete in tx: %w", tagID, err)
-	}
-	if count == 0 {
-		return sql.ErrNoRows
-	}
-
-	return nil
+	return softDeleteTx(ctx, tx, auditTag, tagID)
 }
 
-func ForceDeleteTag(ctx context.Context, db *sql.DB, tagID int) error {
-	tx, err := db.BeginTx(ctx, nil)
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer tx.Rollback()
-
-	// Delete tag relations first
-	_, err = tx.ExecContext(ctx, "DELETE FROM artikel_tag WHERE tag_id = $1", tagID)
-	if err != nil {
-		return fmt.Errorf("failed to delete tag relations: %w", err)
-	}
-
-	// Delete tag
-	res, err := tx.ExecContext(ctx, "DELETE FROM tags WHERE tag_id = $1", tagID)
-	if err != nil {
-		return fmt.Errorf("error deleting tag ID %d: %w", tagID, err)
-	}
-
-	count, err := res.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("error getting rows affected for tag ID %d delete: %w", tagID, err)
-	}
-	if count == 0 {
-		return sql.ErrNoRows
-	}
-
-	return tx.Commit()
+// ForceDeleteTag moves a tag to the trash even if articles use it. The
+// article relations stay until the tag is purged, so a restore puts it back
+// on the same articles.
+func ForceDeleteTag(ctx context.Context, db *DB, tagID int) error {
+	return db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		return auditedChangeTx(ctx, tx, "tag.force_delete", auditTag, tagID, func(tx *sql.Tx) error {
+			return softDeleteTx(ctx, tx, auditTag, tagID)
+		})
+	})
 }
 
 func IsTagExists(ctx context.Context, db *sql.DB, name string) (bool, error) {
-	query := `SELECT EXISTS(SELECT 1 FROM tags WHERE nama_tag = $1)`
+	query := `SELECT EXISTS(SELECT 1 FROM tags WHERE nama_tag = $1 AND deleted_at IS NULL)`
 	var exists bool
 	err := db.QueryRowContext(ctx, query, name).Scan(&exists)
 	if err != nil {
@@ -380,40 +321,38 @@ func GetOrCreateTags(ctx context.Context, db *sql.DB, tagNames []string) ([]int,
 		return []int{}, nil
 	}
 
-	tx, err := db.BeginTx(ctx, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer tx.Rollback()
-
 	var tagIDs []int
-
-	for _, tagName := range tagNames {
-		tagName = strings.TrimSpace(tagName)
-		if tagName == "" {
-			continue
-		}
-
-		// Try to get existing tag
-		var tagID int
-		err := tx.QueryRowContext(ctx, "SELECT tag_id FROM tags WHERE nama_tag = $1", tagName).Scan(&tagID)
-		if err == sql.ErrNoRows {
-			// Create new tag
-			err = tx.QueryRowContext(ctx,
-				"INSERT INTO tags (nama_tag) VALUES ($1) RETURNING tag_id",
-				tagName).Scan(&tagID)
-			if err != nil {
-				return nil, fmt.Errorf("failed to create tag %s: %w", tagName, err)
+	err := withTransaction(ctx, db, func(tx *sql.Tx) error {
+		for _, tagName := range tagNames {
+			tagName = strings.TrimSpace(tagName)
+			if tagName == "" {
+				continue
 			}
-		} else if err != nil {
-			return nil, fmt.Errorf("failed to get tag %s: %w", tagName, err)
-		}
 
-		tagIDs = append(tagIDs, tagID)
-	}
+			// Try to get existing tag
+			var tagID int
+			err := tx.QueryRowContext(ctx, "SELECT tag_id FROM tags WHERE nama_tag = $1 AND deleted_at IS NULL", tagName).Scan(&tagID)
+			if err == sql.ErrNoRows {
+				// Create new tag
+				err = tx.QueryRowContext(ctx,
+					"INSERT INTO tags (nama_tag) VALUES ($1) RETURNING tag_id",
+					tagName).Scan(&tagID)
+				if err != nil {
+					return fmt.Errorf("failed to create tag %s: %w", tagName, err)
+				}
+				if err := auditCreatedTx(ctx, tx, "tag.create", auditTag, tagID); err != nil {
+					return err
+				}
+			} else if err != nil {
+				return fmt.Errorf("failed to get tag %s: %w", tagName, err)
+			}
 
-	if err := tx.Commit(); err != nil {
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+			tagIDs = append(tagIDs, tagID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return tagIDs, nil
@@ -424,7 +363,7 @@ func GetTagsByArticleID(ctx context.Context, db *sql.DB, articleID int) ([]Tag,
         SELECT t.tag_id, t.nama_tag
         FROM tags t
         JOIN artikel_tag at ON t.tag_id = at.tag_id
-        WHERE at.artikel_id = $1
+        WHERE at.artikel_id = $1 AND t.deleted_at IS NULL
         ORDER BY t.nama_tag ASC
     `
 
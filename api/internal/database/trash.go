@@ -0,0 +1,245 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// TrashItem is a soft-deleted row waiting in the trash
+type TrashItem struct {
+	Type      string    `json:"type"`
+	ID        int       `json:"id"`
+	Title     string    `json:"title"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+var ErrUnknownTrashType = errors.New("unknown trash item type")
+
+// ErrTrashItemConflict is returned when restoring an item would give it the
+// same name as a live row
+var ErrTrashItemConflict = errors.New("a live item with the same name exists")
+
+// trashType is a table with a deleted_at column; Title is the expression
+// shown in trash listings. Unique lists the columns no two live rows may
+// share; the schema does not enforce this because trashed rows keep theirs.
+type trashType struct {
+	auditTarget
+	Title  string
+	Unique []string
+}
+
+// trashTypes in the order the retention job purges them, children first
+var trashTypes = []trashType{
+	{auditComment, "LEFT(konten, 100)", nil},
+	{auditArticle, "judul", nil},
+	{auditCategory, "nama_kategori", []string{"nama_kategori"}},
+	{auditTag, "nama_tag", []string{"nama_tag"}},
+	{auditUser, "username", []string{"username", "email"}},
+}
+
+func lookupTrashType(name string) (trashType, error) {
+	for _, t := range trashTypes {
+		if t.Type == name {
+			return t, nil
+		}
+	}
+	return trashType{}, ErrUnknownTrashType
+}
+
+// TrashTypes returns the item types that can be in the trash
+func TrashTypes() []string {
+	types := make([]string, len(trashTypes))
+	for i, t := range trashTypes {
+		types[i] = t.Type
+	}
+	return types
+}
+
+// softDeleteTx moves a single row of target to the trash. Returns
+// sql.ErrNoRows when it does not exist or is already in the trash.
+func softDeleteTx(ctx context.Context, tx *sql.Tx, target auditTarget, id any) error {
+	// table and key come from code, never from input
+	query := fmt.Sprintf("UPDATE %s SET deleted_at = NOW() WHERE %s = $1 AND deleted_at IS NULL",
+		target.Table, target.Key)
+	res, err := tx.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete %s: %w", target.Type, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ListTrash retrieves the items in the trash, most recently deleted first.
+// An empty itemType lists every type.
+func ListTrash(ctx context.Context, db *sql.DB, itemType string) ([]TrashItem, error) {
+	types := trashTypes
+	if itemType != "" {
+		t, err := lookupTrashType(itemType)
+		if err != nil {
+			return nil, err
+		}
+		types = []trashType{t}
+	}
+
+	selects := make([]string, len(types))
+	for i, t := range types {
+		selects[i] = fmt.Sprintf("SELECT '%s', %s, %s, deleted_at FROM %s WHERE deleted_at IS NOT NULL",
+			t.Type, t.Key, t.Title, t.Table)
+	}
+	query := strings.Join(selects, "\nUNION ALL\n") + "\nORDER BY 4 DESC, 1, 2"
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []TrashItem{}
+	for rows.Next() {
+		var item TrashItem
+		if err := rows.Scan(&item.Type, &item.ID, &item.Title, &item.DeletedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// RestoreTrashItem moves an item out of the trash. Replies deleted together
+// with a comment come back with it. Returns sql.ErrNoRows when the item is
+// not in the trash and ErrTrashItemConflict when a live category, tag or user
+// took its name in the meantime.
+func RestoreTrashItem(ctx context.Context, db *sql.DB, itemType string, id int) error {
+	t, err := lookupTrashType(itemType)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET deleted_at = NULL WHERE %s = $1 AND deleted_at IS NOT NULL", t.Table, t.Key)
+	if t.Table == auditComment.Table {
+		query = `
+            WITH RECURSIVE target AS (
+                SELECT komentar_id, deleted_at FROM comments
+                WHERE komentar_id = $1 AND deleted_at IS NOT NULL
+            ), subtree AS (
+                SELECT komentar_id FROM target
+                UNION ALL
+                SELECT c.komentar_id FROM comments c JOIN subtree s ON c.parent_id = s.komentar_id
+                WHERE c.deleted_at = (SELECT deleted_at FROM target)
+            )
+            UPDATE comments SET deleted_at = NULL
+            WHERE komentar_id IN (SELECT komentar_id FROM subtree)
+        `
+	}
+
+	return auditedChange(ctx, db, t.Type+".restore", t.auditTarget, id, func(tx *sql.Tx) error {
+		if len(t.Unique) > 0 {
+			clash := make([]string, len(t.Unique))
+			for i, col := range t.Unique {
+				clash[i] = fmt.Sprintf("live.%s = old.%s", col, col)
+			}
+			var taken bool
+			err := tx.QueryRowContext(ctx, fmt.Sprintf(`
+                SELECT EXISTS(
+                    SELECT 1 FROM %s old JOIN %s live ON %s
+                    WHERE old.%s = $1 AND old.deleted_at IS NOT NULL AND live.deleted_at IS NULL
+                )
+            `, t.Table, t.Table, strings.Join(clash, " OR "), t.Key), id).Scan(&taken)
+			if err != nil {
+				return fmt.Errorf("failed to check %s names: %w", t.Type, err)
+			}
+			if taken {
+				return ErrTrashItemConflict
+			}
+		}
+
+		res, err := tx.ExecContext(ctx, query, id)
+		if err != nil {
+			return fmt.Errorf("failed to restore %s: %w", t.Type, err)
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return sql.ErrNoRows
+		}
+		return nil
+	})
+}
+
+// PurgeTrashItem permanently deletes an item in the trash together with
+// everything that cascades from it: comments of an article, article
+// relations of a category or tag, and articles and comments of a user.
+// Returns sql.ErrNoRows when the item is not in the trash.
+func PurgeTrashItem(ctx context.Context, db *sql.DB, itemType string, id int) error {
+	t, err := lookupTrashType(itemType)
+	if err != nil {
+		return err
+	}
+
+	return auditedChange(ctx, db, t.Type+".purge", t.auditTarget, id, func(tx *sql.Tx) error {
+		query := fmt.Sprintf("DELETE FROM %s WHERE %s = $1 AND deleted_at IS NOT NULL", t.Table, t.Key)
+		res, err := tx.ExecContext(ctx, query, id)
+		if err != nil {
+			return fmt.Errorf("failed to purge %s: %w", t.Type, err)
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return sql.ErrNoRows
+		}
+		return nil
+	})
+}
+
+// PurgeExpiredTrash permanently deletes every item that was moved to the
+// trash before the cutoff and returns how many were purged
+func PurgeExpiredTrash(ctx context.Context, db *sql.DB, before time.Time) (int, error) {
+	purged := 0
+	err := withTransaction(ctx, db, func(tx *sql.Tx) error {
+		for _, t := range trashTypes {
+			query := fmt.Sprintf(`
+                DELETE FROM %s t WHERE t.deleted_at < $1
+                RETURNING t.%s, to_jsonb(t) - $2::text[]
+            `, t.Table, t.Key)
+			rows, err := tx.QueryContext(ctx, query, before, pq.Array(auditHiddenColumns))
+			if err != nil {
+				return fmt.Errorf("failed to purge %s: %w", t.Table, err)
+			}
+
+			type purgedRow struct {
+				id       int
+				snapshot []byte
+			}
+			var deleted []purgedRow
+			for rows.Next() {
+				var r purgedRow
+				if err := rows.Scan(&r.id, &r.snapshot); err != nil {
+					rows.Close()
+					return err
+				}
+				deleted = append(deleted, r)
+			}
+			rows.Close()
+			if err := rows.Err(); err != nil {
+				return err
+			}
+
+			for _, r := range deleted {
+				if err := recordAudit(ctx, tx, t.Type+".purge", t.Type, r.id, json.RawMessage(r.snapshot), nil); err != nil {
+					return err
+				}
+			}
+			purged += len(deleted)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return purged, nil
+}
@@ -15,28 +15,31 @@ import (
 // ========================================
 
 type User struct {
-	UserID            int       `json:"user_id"`
-	Username          string    `json:"username"`
-	Email             string    `json:"email"`
-	Password          string    `json:"-"` // Never expose password
-	Role              string    `json:"role"`
-	TanggalDibuat     time.Time `json:"tanggal_dibuat"`
-	TanggalDiperbarui time.Time `json:"tanggal_diperbarui"`
-	CreatedAt         time.Time `json:"created_at"` // Alias for compatibility
+	UserID            int        `json:"user_id"`
+	Username          string     `json:"username"`
+	Email             string     `json:"email"`
+	Password          string     `json:"-"` // Never expose password
+	Role              string     `json:"role"`
+	TanggalDibuat     time.Time  `json:"tanggal_dibuat"`
+	TanggalDiperbarui time.Time  `json:"tanggal_diperbarui"`
+	CreatedAt         time.Time  `json:"created_at"` // Alias for compatibility
+	EmailVerifiedAt   *time.Time `json:"email_verified_at,omitempty"`
 }
 
 type UserProfile struct {
-	UserID    int       `json:"user_id"`
-	Username  string    `json:"username"`
-	Email     string    `json:"email"`
-	Role      string    `json:"role"`
-	CreatedAt time.Time `json:"created_at"`
+	UserID        int       `json:"user_id"`
+	Username      string    `json:"username"`
+	Email         string    `json:"email"`
+	EmailVerified bool      `json:"email_verified"`
+	Role          string    `json:"role"`
+	CreatedAt     time.Time `json:"created_at"`
 }
 
 type UserResponse struct {
 	UserID            int       `json:"user_id"`
 	Username          string    `json:"username"`
 	Email             string    `json:"email"`
+	EmailVerified     bool      `json:"email_verified"`
 	Role              string    `json:"role"`
 	TanggalDibuat     time.Time `json:"tanggal_dibuat"`
 	TanggalDiperbarui time.Time `json:"tanggal_diperbarui"`
@@ -51,6 +54,15 @@ type LoginRequest struct {
 	Password string `json:"password"`
 }
 
+// RegisterRequest is the body of public self-registration. It has no role:
+// public accounts are always created as "user".
+type RegisterRequest struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// UserRequest creates a user with a role (admin user management)
 type UserRequest struct {
 	Username string `json:"username"`
 	Email    string `json:"email"`
@@ -82,12 +94,18 @@ func (u *User) ToPublic() UserResponse {
 		UserID:            u.UserID,
 		Username:          u.Username,
 		Email:             u.Email,
+		EmailVerified:     u.IsEmailVerified(),
 		Role:              u.Role,
 		TanggalDibuat:     u.TanggalDibuat,
 		TanggalDiperbarui: u.TanggalDiperbarui,
 	}
 }
 
+// IsEmailVerified reports whether the user confirmed their email address
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
 // ToProfile returns user profile data
 func (u *User) ToProfile() UserProfile {
 	createdAt := u.TanggalDibuat
@@ -95,11 +113,12 @@ func (u *User) ToProfile() UserProfile {
 		createdAt = u.CreatedAt
 	}
 	return UserProfile{
-		UserID:    u.UserID,
-		Username:  u.Username,
-		Email:     u.Email,
-		Role:      u.Role,
-		CreatedAt: createdAt,
+		UserID:        u.UserID,
+		Username:      u.Username,
+		Email:         u.Email,
+		EmailVerified: u.IsEmailVerified(),
+		Role:          u.Role,
+		CreatedAt:     createdAt,
 	}
 }
 
@@ -128,15 +147,60 @@ func ValidatePassword(hashedPassword, password string) bool {
 // AUTHENTICATION
 // ========================================
 
-// AuthenticateUser validates credentials and returns user if valid
-func AuthenticateUser(ctx context.Context, db *sql.DB, req *LoginRequest) (*User, error) {
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// dummyPasswordHash is compared against for unknown emails so the response
+// time does not reveal whether an account exists
+const dummyPasswordHash = "$2a$10$ywa4IriH2vXxr7Yka4F2AeokTm0Ux7rYSbQorq88iTr8EeCZqlgpu"
+
+// AuthenticateUser validates credentials and returns user if valid. Every
+// attempt is recorded; too many failures lock the account according to the
+// policy. Returns ErrInvalidCredentials or *AccountLockedError.
+func AuthenticateUser(ctx context.Context, db *sql.DB, req *LoginRequest, client LoginClient, policy LockoutPolicy) (*User, error) {
 	user, err := GetUserByEmail(ctx, db, req.Email)
 	if err != nil {
-		return nil, errors.New("invalid credentials")
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		VerifyPassword(dummyPasswordHash, req.Password)
+		if err := RecordLoginAttempt(ctx, db, nil, req.Email, client, false, LoginFailureUnknownUser); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidCredentials
+	}
+
+	lockedUntil, err := GetAccountLock(ctx, db, user.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if lockedUntil != nil {
+		// Same bcrypt cost as any other attempt, so timing does not reveal the lock
+		VerifyPassword(user.Password, req.Password)
+		if err := RecordLoginAttempt(ctx, db, &user.UserID, req.Email, client, false, LoginFailureLocked); err != nil {
+			return nil, err
+		}
+		return nil, &AccountLockedError{Until: *lockedUntil}
 	}
 
 	if !VerifyPassword(user.Password, req.Password) {
-		return nil, errors.New("invalid credentials")
+		if err := RecordLoginAttempt(ctx, db, &user.UserID, req.Email, client, false, LoginFailureWrongPassword); err != nil {
+			return nil, err
+		}
+		lockedUntil, err := LockAccountIfNeeded(ctx, db, user.UserID, policy)
+		if err != nil {
+			return nil, err
+		}
+		if lockedUntil != nil {
+			return nil, &AccountLockedError{Until: *lockedUntil, New: true}
+		}
+		return nil, ErrInvalidCredentials
+	}
+
+	if err := RecordLoginAttempt(ctx, db, &user.UserID, req.Email, client, true, ""); err != nil {
+		return nil, err
+	}
+	if err := ResetLoginFailures(ctx, db, user.UserID); err != nil {
+		return nil, err
 	}
 
 	return user, nil
@@ -146,6 +210,9 @@ func AuthenticateUser(ctx context.Context, db *sql.DB, req *LoginRequest) (*User
 // EXISTENCE CHECKS
 // ========================================
 
+// Users in the trash keep their email and username until they are purged,
+// so the checks below include them.
+
 // IsEmailExists checks if email already exists in database
 func IsEmailExists(ctx context.Context, db *sql.DB, email string) (bool, error) {
 	query := `SELECT EXISTS(SELECT 1 FROM users WHERE email = $1)`
@@ -169,10 +236,10 @@ func IsUsernameExists(ctx context.Context, db *sql.DB, username string) (bool, e
 }
 
 // CheckUsernameExists checks if username exists excluding a specific user ID
-func CheckUsernameExists(db *sql.DB, username string, excludeUserID int) (bool, error) {
+func CheckUsernameExists(ctx context.Context, db *sql.DB, username string, excludeUserID int) (bool, error) {
 	query := `SELECT EXISTS(SELECT 1 FROM users WHERE username = $1 AND user_id != $2)`
 	var exists bool
-	err := db.QueryRow(query, username, excludeUserID).Scan(&exists)
+	err := db.QueryRowContext(ctx, query, username, excludeUserID).Scan(&exists)
 	if err != nil {
 		return false, err
 	}
@@ -180,10 +247,10 @@ func CheckUsernameExists(db *sql.DB, username string, excludeUserID int) (bool,
 }
 
 // CheckEmailExists checks if email exists excluding a specific user ID
-func CheckEmailExists(db *sql.DB, email string, excludeUserID int) (bool, error) {
+func CheckEmailExists(ctx context.Context, db *sql.DB, email string, excludeUserID int) (bool, error) {
 	query := `SELECT EXISTS(SELECT 1 FROM users WHERE email = $1 AND user_id != $2)`
 	var exists bool
-	err := db.QueryRow(query, email, excludeUserID).Scan(&exists)
+	err := db.QueryRowContext(ctx, query, email, excludeUserID).Scan(&exists)
 	if err != nil {
 		return false, err
 	}
@@ -209,16 +276,22 @@ func CreateUser(ctx context.Context, db *sql.DB, req *UserRequest) (*User, error
 	query := `
         INSERT INTO users (username, email, password, role)
         VALUES ($1, $2, $3, $4)
-        RETURNING user_id, username, email, role, tanggal_dibuat, tanggal_diperbarui
+        RETURNING user_id, username, email, role, tanggal_dibuat, tanggal_diperbarui, email_verified_at
     `
 
 	var user User
-	err = db.QueryRowContext(ctx, query, req.Username, req.Email, hashedPassword, role).Scan(
-		&user.UserID, &user.Username, &user.Email, &user.Role,
-		&user.TanggalDibuat, &user.TanggalDiperbarui,
-	)
+	err = withTransaction(ctx, db, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, query, req.Username, req.Email, hashedPassword, role).Scan(
+			&user.UserID, &user.Username, &user.Email, &user.Role,
+			&user.TanggalDibuat, &user.TanggalDiperbarui, &user.EmailVerifiedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create user: %w", err)
+		}
+		return auditCreatedTx(ctx, tx, "user.create", auditUser, user.UserID)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create user: %w", err)
+		return nil, err
 	}
 
 	user.CreatedAt = user.TanggalDibuat
@@ -228,15 +301,15 @@ func CreateUser(ctx context.Context, db *sql.DB, req *UserRequest) (*User, error
 // GetUserByID retrieves a user by ID
 func GetUserByID(ctx context.Context, db *sql.DB, id int) (*User, error) {
 	query := `
-        SELECT user_id, username, email, password, role, tanggal_dibuat, tanggal_diperbarui
+        SELECT user_id, username, email, password, role, tanggal_dibuat, tanggal_diperbarui, email_verified_at
         FROM users
-        WHERE user_id = $1
+        WHERE user_id = $1 AND deleted_at IS NULL
     `
 
 	var user User
 	err := db.QueryRowContext(ctx, query, id).Scan(
 		&user.UserID, &user.Username, &user.Email, &user.Password, &user.Role,
-		&user.TanggalDibuat, &user.TanggalDiperbarui,
+		&user.TanggalDibuat, &user.TanggalDiperbarui, &user.EmailVerifiedAt,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -250,17 +323,17 @@ func GetUserByID(ctx context.Context, db *sql.DB, id int) (*User, error) {
 }
 
 // GetUserByIDSimple retrieves a user by ID without context
-func GetUserByIDSimple(db *sql.DB, id int) (*User, error) {
+func GetUserByIDSimple(ctx context.Context, db *sql.DB, id int) (*User, error) {
 	query := `
-        SELECT user_id, username, email, password, role, tanggal_dibuat, tanggal_diperbarui
+        SELECT user_id, username, email, password, role, tanggal_dibuat, tanggal_diperbarui, email_verified_at
         FROM users
-        WHERE user_id = $1
+        WHERE user_id = $1 AND deleted_at IS NULL
     `
 
 	var user User
-	err := db.QueryRow(query, id).Scan(
+	err := db.QueryRowContext(ctx, query, id).Scan(
 		&user.UserID, &user.Username, &user.Email, &user.Password, &user.Role,
-		&user.TanggalDibuat, &user.TanggalDiperbarui,
+		&user.TanggalDibuat, &user.TanggalDiperbarui, &user.EmailVerifiedAt,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -273,22 +346,23 @@ func GetUserByIDSimple(db *sql.DB, id int) (*User, error) {
 	return &user, nil
 }
 
-// GetUserByEmail retrieves a user by email
+// GetUserByEmail retrieves a user by email. The error wraps sql.ErrNoRows
+// when no live user has that email.
 func GetUserByEmail(ctx context.Context, db *sql.DB, email string) (*User, error) {
 	query := `
-        SELECT user_id, username, email, password, role, tanggal_dibuat, tanggal_diperbarui
+        SELECT user_id, username, email, password, role, tanggal_dibuat, tanggal_diperbarui, email_verified_at
         FROM users
-        WHERE email = $1
+        WHERE email = $1 AND deleted_at IS NULL
     `
 
 	var user User
 	err := db.QueryRowContext(ctx, query, email).Scan(
 		&user.UserID, &user.Username, &user.Email, &user.Password, &user.Role,
-		&user.TanggalDibuat, &user.TanggalDiperbarui,
+		&user.TanggalDibuat, &user.TanggalDiperbarui, &user.EmailVerifiedAt,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, errors.New("user not found")
+			return nil, fmt.Errorf("user not found: %w", err)
 		}
 		return nil, err
 	}
@@ -300,15 +374,15 @@ func GetUserByEmail(ctx context.Context, db *sql.DB, email string) (*User, error
 // GetUserByUsername retrieves a user by username
 func GetUserByUsername(ctx context.Context, db *sql.DB, username string) (*User, error) {
 	query := `
-        SELECT user_id, username, email, password, role, tanggal_dibuat, tanggal_diperbarui
+        SELECT user_id, username, email, password, role, tanggal_dibuat, tanggal_diperbarui, email_verified_at
         FROM users
-        WHERE username = $1
+        WHERE username = $1 AND deleted_at IS NULL
     `
 
 	var user User
 	err := db.QueryRowContext(ctx, query, username).Scan(
 		&user.UserID, &user.Username, &user.Email, &user.Password, &user.Role,
-		&user.TanggalDibuat, &user.TanggalDiperbarui,
+		&user.TanggalDibuat, &user.TanggalDiperbarui, &user.EmailVerifiedAt,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -321,15 +395,18 @@ func GetUserByUsername(ctx context.Context, db *sql.DB, username string) (*User,
 	return &user, nil
 }
 
-// GetAllUsers retrieves all users
-func GetAllUsers(db *sql.DB) ([]UserResponse, error) {
+// GetAllUsers retrieves users, newest first. A limit of 0 returns all of them.
+func GetAllUsers(ctx context.Context, db *sql.DB, limit int, offset int) ([]UserResponse, error) {
 	query := `
-        SELECT user_id, username, email, role, tanggal_dibuat, tanggal_diperbarui
+        SELECT user_id, username, email, role, tanggal_dibuat, tanggal_diperbarui,
+               email_verified_at IS NOT NULL
         FROM users
+        WHERE deleted_at IS NULL
         ORDER BY tanggal_dibuat DESC
+        LIMIT NULLIF($1, 0) OFFSET $2
     `
 
-	rows, err := db.Query(query)
+	rows, err := db.QueryContext(ctx, query, limit, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -340,7 +417,7 @@ func GetAllUsers(db *sql.DB) ([]UserResponse, error) {
 		var user UserResponse
 		err := rows.Scan(
 			&user.UserID, &user.Username, &user.Email, &user.Role,
-			&user.TanggalDibuat, &user.TanggalDiperbarui,
+			&user.TanggalDibuat, &user.TanggalDiperbarui, &user.EmailVerified,
 		)
 		if err != nil {
 			return nil, err
@@ -355,6 +432,16 @@ func GetAllUsers(db *sql.DB) ([]UserResponse, error) {
 	return users, nil
 }
 
+// CountUsers retrieves the total number of users
+func CountUsers(ctx context.Context, db *sql.DB) (int, error) {
+	var count int
+	err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM users WHERE deleted_at IS NULL`).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
 // UpdateUser updates a user's information
 func UpdateUser(ctx context.Context, db *sql.DB, id int, req *UserUpdateRequest) (*User, error) {
 	// Get existing user
@@ -380,15 +467,16 @@ func UpdateUser(ctx context.Context, db *sql.DB, id int, req *UserUpdateRequest)
 
 	query := `
         UPDATE users
-        SET username = $1, email = $2, role = $3
-        WHERE user_id = $4
-        RETURNING user_id, username, email, role, tanggal_dibuat, tanggal_diperbarui
+        SET username = $1, email = $2, role = $3,
+            email_verified_at = CASE WHEN email = $2 THEN email_verified_at END
+        WHERE user_id = $4 AND deleted_at IS NULL
+        RETURNING user_id, username, email, role, tanggal_dibuat, tanggal_diperbarui, email_verified_at
     `
 
 	var user User
 	err = db.QueryRowContext(ctx, query, username, email, role, id).Scan(
 		&user.UserID, &user.Username, &user.Email, &user.Role,
-		&user.TanggalDibuat, &user.TanggalDiperbarui,
+		&user.TanggalDibuat, &user.TanggalDiperbarui, &user.EmailVerifiedAt,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update user: %w", err)
@@ -396,7 +484,7 @@ func UpdateUser(ctx context.Context, db *sql.DB, id int, req *UserUpdateRequest)
 
 	// Update password if provided
 	if req.Password != "" {
-		if err := UpdateUserPassword(db, id, req.Password); err != nil {
+		if err := UpdateUserPassword(ctx, db, id, req.Password); err != nil {
 			return nil, err
 		}
 	}
@@ -406,18 +494,19 @@ func UpdateUser(ctx context.Context, db *sql.DB, id int, req *UserUpdateRequest)
 }
 
 // UpdateUserBasic updates username and email only
-func UpdateUserBasic(db *sql.DB, id int, username, email string) (*User, error) {
+func UpdateUserBasic(ctx context.Context, db *sql.DB, id int, username, email string) (*User, error) {
 	query := `
         UPDATE users
-        SET username = $1, email = $2
-        WHERE user_id = $3
-        RETURNING user_id, username, email, password, role, tanggal_dibuat, tanggal_diperbarui
+        SET username = $1, email = $2,
+            email_verified_at = CASE WHEN email = $2 THEN email_verified_at END
+        WHERE user_id = $3 AND deleted_at IS NULL
+        RETURNING user_id, username, email, password, role, tanggal_dibuat, tanggal_diperbarui, email_verified_at
     `
 
 	var user User
-	err := db.QueryRow(query, username, email, id).Scan(
+	err := db.QueryRowContext(ctx, query, username, email, id).Scan(
 		&user.UserID, &user.Username, &user.Email, &user.Password, &user.Role,
-		&user.TanggalDibuat, &user.TanggalDiperbarui,
+		&user.TanggalDibuat, &user.TanggalDiperbarui, &user.EmailVerifiedAt,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update user: %w", err)
@@ -428,14 +517,14 @@ func UpdateUserBasic(db *sql.DB, id int, username, email string) (*User, error)
 }
 
 // UpdateUserPassword updates only the password
-func UpdateUserPassword(db *sql.DB, userID int, newPassword string) error {
+func UpdateUserPassword(ctx context.Context, db *sql.DB, userID int, newPassword string) error {
 	hashedPassword, err := HashPassword(newPassword)
 	if err != nil {
 		return fmt.Errorf("failed to hash password: %w", err)
 	}
 
-	query := `UPDATE users SET password = $1 WHERE user_id = $2`
-	result, err := db.Exec(query, hashedPassword, userID)
+	query := `UPDATE users SET password = $1 WHERE user_id = $2 AND deleted_at IS NULL`
+	result, err := db.ExecContext(ctx, query, hashedPassword, userID)
 	if err != nil {
 		return fmt.Errorf("failed to update password: %w", err)
 	}
@@ -453,41 +542,26 @@ func UpdateUserPassword(db *sql.DB, userID int, newPassword string) error {
 }
 
 // UpdateUserRole updates only the role
-func UpdateUserRole(db *sql.DB, userID int, role string) error {
-	query := `UPDATE users SET role = $1 WHERE user_id = $2`
-	result, err := db.Exec(query, role, userID)
-	if err != nil {
-		return fmt.Errorf("failed to update role: %w", err)
-	}
-
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return err
-	}
-
-	if rowsAffected == 0 {
-		return sql.ErrNoRows
-	}
-
-	return nil
+func UpdateUserRole(ctx context.Context, db *sql.DB, userID int, role string) error {
+	return auditedChange(ctx, db, "user.role_update", auditUser, userID, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx,
+			`UPDATE users SET role = $1 WHERE user_id = $2 AND deleted_at IS NULL`, role, userID)
+		if err != nil {
+			return fmt.Errorf("failed to update role: %w", err)
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			return sql.ErrNoRows
+		}
+		return nil
+	})
 }
 
-// DeleteUser deletes a user by ID
-func DeleteUser(db *sql.DB, id int) error {
-	query := `DELETE FROM users WHERE user_id = $1`
-	result, err := db.Exec(query, id)
-	if err != nil {
-		return fmt.Errorf("failed to delete user: %w", err)
-	}
-
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return err
-	}
-
-	if rowsAffected == 0 {
-		return sql.ErrNoRows
-	}
-
-	return nil
+// DeleteUser moves a user to the trash. Their articles and comments stay
+// until the user is purged.
+func DeleteUser(ctx context.Context, db *DB, id int) error {
+	return db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		return auditedChangeTx(ctx, tx, "user.delete", auditUser, id, func(tx *sql.Tx) error {
+			return softDeleteTx(ctx, tx, auditUser, id)
+		})
+	})
 }
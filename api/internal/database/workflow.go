@@ -0,0 +1,201 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ErrInvalidStatusTransition is returned when an article is not in one of the
+// statuses a transition starts from
+var ErrInvalidStatusTransition = errors.New("invalid status transition")
+
+type ArticleStatusChange struct {
+	RiwayatID  int       `json:"riwayat_id"`
+	ArtikelID  int       `json:"artikel_id"`
+	StatusAwal string    `json:"status_awal"`
+	StatusBaru string    `json:"status_baru"`
+	UserID     *int      `json:"user_id,omitempty"`
+	Username   *string   `json:"username,omitempty"`
+	Catatan    *string   `json:"catatan,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// TransitionArticleStatus moves an article from one of the allowed statuses to
+// a new status and records who made the change. The article row is locked so
+// concurrent transitions cannot both succeed.
+func TransitionArticleStatus(ctx context.Context, db *sql.DB, articleID int, from []string, to string, userID int, catatan string) (*Article, error) {
+	return transitionArticleStatus(ctx, db, articleID, from, to, userID, catatan, nil)
+}
+
+// ScheduleArticle moves an article to 'scheduled' with the given publication time.
+// The publish scheduler promotes it to 'published' once that time has passed.
+func ScheduleArticle(ctx context.Context, db *sql.DB, articleID int, from []string, publishAt time.Time, userID int, catatan string) (*Article, error) {
+	return transitionArticleStatus(ctx, db, articleID, from, "scheduled", userID, catatan, &publishAt)
+}
+
+func transitionArticleStatus(ctx context.Context, db *sql.DB, articleID int, from []string, to string, userID int, catatan string, publishAt *time.Time) (*Article, error) {
+	err := withTransaction(ctx, db, func(tx *sql.Tx) error {
+		var current string
+		err := tx.QueryRowContext(ctx,
+			"SELECT status FROM articles WHERE artikel_id = $1 AND deleted_at IS NULL FOR UPDATE", articleID,
+		).Scan(&current)
+		if err != nil {
+			return err
+		}
+
+		allowed := false
+		for _, status := range from {
+			if current == status {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("%w: cannot move article from %s to %s", ErrInvalidStatusTransition, current, to)
+		}
+
+		// An explicit publish time wins. Publishing sets tanggal_publikasi the first
+		// time, and also when a cancelled schedule left a date in the future.
+		_, err = tx.ExecContext(ctx, `
+            UPDATE articles
+            SET status = $1,
+                tanggal_publikasi = CASE
+                    WHEN $3::timestamptz IS NOT NULL THEN $3::timestamptz
+                    WHEN $1 = 'published' AND (tanggal_publikasi IS NULL OR tanggal_publikasi > NOW()) THEN NOW()
+                    ELSE tanggal_publikasi
+                END
+            WHERE artikel_id = $2
+        `, to, articleID, publishAt)
+		if err != nil {
+			return fmt.Errorf("failed to update article status: %w", err)
+		}
+
+		return insertArticleStatusChangeTx(ctx, tx, articleID, current, to, &userID, catatan)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return GetArticleByID(ctx, db, articleID)
+}
+
+// PublishDueArticles promotes scheduled articles whose tanggal_publikasi has
+// passed to 'published'. Rows are claimed with FOR UPDATE SKIP LOCKED so
+// several instances can run the scheduler at the same time without
+// publishing an article twice. Returns the IDs that were published.
+func PublishDueArticles(ctx context.Context, db *sql.DB, limit int) ([]int, error) {
+	var ids []int
+	err := withTransaction(ctx, db, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, `
+            SELECT artikel_id
+            FROM articles
+            WHERE status = 'scheduled' AND tanggal_publikasi <= NOW() AND deleted_at IS NULL
+            ORDER BY tanggal_publikasi ASC
+            LIMIT $1
+            FOR UPDATE SKIP LOCKED
+        `, limit)
+		if err != nil {
+			return fmt.Errorf("failed to select due articles: %w", err)
+		}
+
+		for rows.Next() {
+			var id int
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return err
+			}
+			ids = append(ids, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		if len(ids) == 0 {
+			return nil
+		}
+
+		_, err = tx.ExecContext(ctx,
+			"UPDATE articles SET status = 'published' WHERE artikel_id = ANY($1)",
+			pq.Array(ids))
+		if err != nil {
+			return fmt.Errorf("failed to publish due articles: %w", err)
+		}
+
+		for _, id := range ids {
+			err := insertArticleStatusChangeTx(ctx, tx, id, "scheduled", "published", nil, "Dipublikasikan otomatis sesuai jadwal")
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// insertArticleStatusChangeTx writes a row to the status history and the
+// audit log
+func insertArticleStatusChangeTx(ctx context.Context, tx *sql.Tx, articleID int, from, to string, userID *int, catatan string) error {
+	var note *string
+	if catatan != "" {
+		note = &catatan
+	}
+
+	_, err := tx.ExecContext(ctx, `
+        INSERT INTO article_status_history (artikel_id, status_awal, status_baru, user_id, catatan)
+        VALUES ($1, $2, $3, $4, $5)
+    `, articleID, from, to, userID, note)
+	if err != nil {
+		return fmt.Errorf("failed to record status change: %w", err)
+	}
+
+	return recordAudit(ctx, tx, "article.status_change", auditArticle.Type, articleID,
+		map[string]any{"status": from},
+		map[string]any{"status": to, "catatan": note})
+}
+
+// GetArticleStatusHistory returns the status changes of an article, newest first
+func GetArticleStatusHistory(ctx context.Context, db *sql.DB, articleID int) ([]ArticleStatusChange, error) {
+	query := `
+        SELECT h.riwayat_id, h.artikel_id, h.status_awal, h.status_baru,
+               h.user_id, u.username, h.catatan, h.created_at
+        FROM article_status_history h
+        LEFT JOIN users u ON h.user_id = u.user_id
+        WHERE h.artikel_id = $1
+        ORDER BY h.created_at DESC, h.riwayat_id DESC
+    `
+
+	rows, err := db.QueryContext(ctx, query, articleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []ArticleStatusChange
+	for rows.Next() {
+		var h ArticleStatusChange
+		err := rows.Scan(
+			&h.RiwayatID, &h.ArtikelID, &h.StatusAwal, &h.StatusBaru,
+			&h.UserID, &h.Username, &h.Catatan, &h.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, h)
+	}
+
+	if history == nil {
+		history = []ArticleStatusChange{}
+	}
+
+	return history, rows.Err()
+}
@@ -0,0 +1,231 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"github.com/HugoSmits86/nativewebp"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported image format")
+	ErrInvalidImage      = errors.New("invalid image")
+	ErrImageTooLarge     = errors.New("image dimensions too large")
+)
+
+// maxPixels guards against decompression bombs (50 megapixels)
+const maxPixels = 50_000_000
+
+const (
+	jpegQuality        = 90
+	variantJPEGQuality = 82
+)
+
+// Size is a named target width for a responsive variant
+type Size struct {
+	Name  string
+	Width int
+}
+
+// Sizes are the variants generated for every upload, smallest first
+var Sizes = []Size{
+	{Name: "thumbnail", Width: 320},
+	{Name: "medium", Width: 768},
+	{Name: "large", Width: 1280},
+}
+
+// formats maps the sniffed content types we accept to file extensions
+var formats = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// Variant is a resized copy of an image in one encoding
+type Variant struct {
+	Name        string
+	Width       int
+	Height      int
+	ContentType string
+	Ext         string
+	Data        []byte
+}
+
+// Result is a processed upload
+type Result struct {
+	ContentType string
+	Ext         string
+	Width       int
+	Height      int
+	Original    []byte // the upload with EXIF/XMP/text metadata removed
+	Variants    []Variant
+}
+
+// Sniff detects the image type from its magic bytes, ignoring whatever
+// Content-Type the client claimed
+func Sniff(data []byte) (contentType, ext string, err error) {
+	contentType = http.DetectContentType(data)
+	ext, ok := formats[contentType]
+	if !ok {
+		return "", "", ErrUnsupportedFormat
+	}
+	return contentType, ext, nil
+}
+
+// Process validates an uploaded image by decoding it, strips its metadata
+// and renders the responsive variants. Every variant is produced as JPEG (or
+// PNG when the image has transparency) and as WebP. Sizes wider than the
+// image itself are skipped.
+func Process(data []byte) (*Result, error) {
+	contentType, ext, err := Sniff(data)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxPixels {
+		return nil, ErrImageTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+
+	result := &Result{ContentType: contentType, Ext: ext}
+
+	// The orientation lives in the EXIF block we are about to drop, so a
+	// rotated JPEG is re-encoded upright instead of stripped in place
+	if orientation := exifOrientation(data); contentType == "image/jpeg" && orientation > 1 {
+		img = applyOrientation(img, orientation)
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return nil, err
+		}
+		result.Original = buf.Bytes()
+	} else {
+		result.Original, err = stripMetadata(contentType, data)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+		}
+	}
+
+	bounds := img.Bounds()
+	result.Width, result.Height = bounds.Dx(), bounds.Dy()
+
+	opaque := isOpaque(img)
+	for _, size := range Sizes {
+		if size.Width >= result.Width {
+			break
+		}
+
+		height := result.Height * size.Width / result.Width
+		if height < 1 {
+			height = 1
+		}
+		resized := image.NewRGBA(image.Rect(0, 0, size.Width, height))
+		draw.CatmullRom.Scale(resized, resized.Bounds(), img, bounds, draw.Src, nil)
+
+		variants, err := encodeVariant(size.Name, resized, opaque)
+		if err != nil {
+			return nil, err
+		}
+		result.Variants = append(result.Variants, variants...)
+	}
+
+	return result, nil
+}
+
+// encodeVariant returns the fallback (JPEG or PNG) and WebP encodings
+func encodeVariant(name string, img *image.RGBA, opaque bool) ([]Variant, error) {
+	b := img.Bounds()
+	fallback := Variant{Name: name, Width: b.Dx(), Height: b.Dy()}
+
+	var buf bytes.Buffer
+	if opaque {
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: variantJPEGQuality}); err != nil {
+			return nil, err
+		}
+		fallback.ContentType, fallback.Ext = "image/jpeg", ".jpg"
+	} else {
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, err
+		}
+		fallback.ContentType, fallback.Ext = "image/png", ".png"
+	}
+	fallback.Data = buf.Bytes()
+
+	var webpBuf bytes.Buffer
+	if err := nativewebp.Encode(&webpBuf, img, nil); err != nil {
+		return nil, err
+	}
+	webp := Variant{
+		Name:        name,
+		Width:       b.Dx(),
+		Height:      b.Dy(),
+		ContentType: "image/webp",
+		Ext:         ".webp",
+		Data:        webpBuf.Bytes(),
+	}
+
+	return []Variant{fallback, webp}, nil
+}
+
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	return false
+}
+
+// applyOrientation turns an image upright according to its EXIF
+// orientation (2-8)
+func applyOrientation(img image.Image, orientation int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // mirrored horizontally
+				sx, sy = w-1-x, y
+			case 3: // rotated 180
+				sx, sy = w-1-x, h-1-y
+			case 4: // mirrored vertically
+				sx, sy = x, h-1-y
+			case 5: // transposed
+				sx, sy = y, x
+			case 6: // rotated 90 CW
+				sx, sy = y, h-1-x
+			case 7: // transversed
+				sx, sy = w-1-y, h-1-x
+			case 8: // rotated 90 CCW
+				sx, sy = w-1-y, x
+			default:
+				sx, sy = x, y
+			}
+			dst.Set(x, y, img.At(b.Min.X+sx, b.Min.Y+sy))
+		}
+	}
+
+	return dst
+}
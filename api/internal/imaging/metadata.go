@@ -0,0 +1,211 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+// ========================================
+// METADATA STRIPPING
+// ========================================
+//
+// Metadata is removed at the container level so the pixels are not
+// re-encoded: JPEG APP1 (EXIF/XMP), APP13 (IPTC) and comment segments, PNG
+// eXIf and text chunks, and WebP EXIF and XMP chunks. GIF has no EXIF and is
+// kept as is.
+
+var errMalformed = errors.New("malformed image container")
+
+func stripMetadata(contentType string, data []byte) ([]byte, error) {
+	switch contentType {
+	case "image/jpeg":
+		return stripJPEG(data)
+	case "image/png":
+		return stripPNG(data)
+	case "image/webp":
+		return stripWebP(data)
+	default:
+		return data, nil
+	}
+}
+
+// jpegSegments calls fn for every marker segment before the image data with
+// the marker byte and the full segment (marker and length included). It
+// returns the offset of the start-of-scan marker.
+func jpegSegments(data []byte, fn func(marker byte, segment []byte)) (int, error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 0, errMalformed
+	}
+
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return 0, errMalformed
+		}
+		marker := data[pos+1]
+		if marker == 0xFF { // fill byte
+			pos++
+			continue
+		}
+		if marker == 0xDA { // start of scan: entropy coded data follows
+			return pos, nil
+		}
+
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		end := pos + 2 + length
+		if length < 2 || end > len(data) {
+			return 0, errMalformed
+		}
+		fn(marker, data[pos:end])
+		pos = end
+	}
+
+	return 0, errMalformed
+}
+
+func stripJPEG(data []byte) ([]byte, error) {
+	var out bytes.Buffer
+	out.Write(data[:2])
+
+	sos, err := jpegSegments(data, func(marker byte, segment []byte) {
+		switch marker {
+		case 0xE1, 0xED, 0xFE: // APP1, APP13, COM
+			return
+		}
+		out.Write(segment)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out.Write(data[sos:])
+	return out.Bytes(), nil
+}
+
+// exifOrientation returns the EXIF orientation tag of a JPEG, or 1 when it
+// is missing or unreadable
+func exifOrientation(data []byte) int {
+	orientation := 1
+	jpegSegments(data, func(marker byte, segment []byte) {
+		if marker != 0xE1 || len(segment) < 10 || string(segment[4:10]) != "Exif\x00\x00" {
+			return
+		}
+		if o := tiffOrientation(segment[10:]); o >= 1 && o <= 8 {
+			orientation = o
+		}
+	})
+	return orientation
+}
+
+// tiffOrientation reads tag 0x0112 from the first IFD of a TIFF header
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 0
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 0
+	}
+
+	count := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			return int(order.Uint16(tiff[entry+8:]))
+		}
+	}
+	return 0
+}
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+func stripPNG(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, errMalformed
+	}
+
+	var out bytes.Buffer
+	out.Write(pngSignature)
+
+	pos := len(pngSignature)
+	for pos < len(data) {
+		if pos+12 > len(data) {
+			return nil, errMalformed
+		}
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		end := pos + 12 + length
+		if end > len(data) {
+			return nil, errMalformed
+		}
+
+		switch string(data[pos+4 : pos+8]) {
+		case "eXIf", "tEXt", "zTXt", "iTXt", "tIME":
+		default:
+			out.Write(data[pos:end])
+		}
+		pos = end
+	}
+
+	return out.Bytes(), nil
+}
+
+func stripWebP(data []byte) ([]byte, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, errMalformed
+	}
+
+	var body bytes.Buffer
+	body.WriteString("WEBP")
+
+	pos := 12
+	for pos < len(data) {
+		if pos+8 > len(data) {
+			return nil, errMalformed
+		}
+		size := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		end := pos + 8 + size + size%2
+		if end > len(data) {
+			// The final padding byte is sometimes missing
+			if pos+8+size == len(data) {
+				end = len(data)
+			} else {
+				return nil, errMalformed
+			}
+		}
+
+		chunk := data[pos:end]
+		switch string(chunk[:4]) {
+		case "EXIF", "XMP ":
+		case "VP8X":
+			// Clear the EXIF (0x08) and XMP (0x04) flags
+			chunk = append([]byte(nil), chunk...)
+			if len(chunk) > 8 {
+				chunk[8] &^= 0x08 | 0x04
+			}
+			body.Write(chunk)
+		default:
+			body.Write(chunk)
+		}
+		pos = end
+	}
+
+	out := make([]byte, 8, 8+body.Len())
+	copy(out, "RIFF")
+	binary.LittleEndian.PutUint32(out[4:], uint32(body.Len()))
+	return append(out, body.Bytes()...), nil
+}
@@ -0,0 +1,49 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Log writes messages to the server log instead of sending them. The body
+// is logged in full so links can be followed during development; it is
+// refused in production by NewFromEnv.
+type Log struct {
+	from string
+}
+
+// NewLog creates a mailer that only logs
+func NewLog(from string) *Log {
+	return &Log{from: from}
+}
+
+func (m *Log) Send(ctx context.Context, msg Message) error {
+	log.Printf("📧 Mail to %s: %s\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// File writes every message as an .eml file, which mail clients can open
+type File struct {
+	dir  string
+	from string
+}
+
+// NewFile creates a mailer writing into dir
+func NewFile(dir, from string) (*File, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create mail directory: %w", err)
+	}
+	return &File{dir: dir, from: from}, nil
+}
+
+func (m *File) Send(ctx context.Context, msg Message) error {
+	name := fmt.Sprintf("%d.eml", time.Now().UnixNano())
+	if err := os.WriteFile(filepath.Join(m.dir, name), compose(m.from, msg.To, msg), 0o644); err != nil {
+		return fmt.Errorf("failed to write mail: %w", err)
+	}
+	return nil
+}
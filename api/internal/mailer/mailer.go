@@ -0,0 +1,61 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// Message is a plain text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends emails
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// NewFromEnv creates the mailer selected by MAIL_DRIVER ("log", "file" or
+// "smtp"), sending from MAIL_FROM.
+//
+// log: writes every message to the server log (default, for development).
+// Mail bodies carry password reset, verification and invitation links, so
+// this driver is refused when ENV=production.
+//
+// file: writes every message as an .eml file into MAIL_DIR (default ./mail).
+//
+// smtp: SMTP_HOST, SMTP_PORT (default 587), SMTP_USERNAME and SMTP_PASSWORD.
+// STARTTLS is used when the server offers it.
+func NewFromEnv() (Mailer, error) {
+	from := getEnv("MAIL_FROM", "Bintaro Times <no-reply@localhost>")
+
+	switch driver := getEnv("MAIL_DRIVER", "log"); driver {
+	case "log":
+		if os.Getenv("ENV") == "production" {
+			return nil, fmt.Errorf("MAIL_DRIVER %q writes token links to the server log and cannot be used in production, use \"smtp\"", driver)
+		}
+		return NewLog(from), nil
+	case "file":
+		return NewFile(getEnv("MAIL_DIR", "./mail"), from)
+	case "smtp":
+		return NewSMTP(SMTPConfig{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     getEnv("SMTP_PORT", "587"),
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     from,
+		})
+	default:
+		return nil, fmt.Errorf("unknown MAIL_DRIVER %q", driver)
+	}
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
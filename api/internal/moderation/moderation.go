@@ -0,0 +1,266 @@
+package moderation
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Comment statuses produced by Evaluate
+const (
+	StatusApproved = "approved"
+	StatusPending  = "pending"
+	StatusRejected = "rejected"
+)
+
+// Banned word severities
+const (
+	SeverityReject = "reject" // comment is rejected outright
+	SeverityReview = "review" // comment waits for a moderator
+)
+
+// Config holds the thresholds of the pipeline
+type Config struct {
+	// MaxLinks is the number of links above which a comment is rejected
+	MaxLinks int
+
+	// DuplicateWindow is how far back identical comments are looked up
+	DuplicateWindow time.Duration
+
+	// TrustedApproved is the number of approved comments (without
+	// rejections) after which an author may post links without review
+	TrustedApproved int
+
+	// AnonymousStatus is the status of clean comments without an account,
+	// either "approved" or "pending"
+	AnonymousStatus string
+}
+
+// ConfigFromEnv reads MODERATION_MAX_LINKS (default 2),
+// MODERATION_DUPLICATE_WINDOW (default 24h), MODERATION_TRUSTED_APPROVED
+// (default 3) and MODERATION_ANONYMOUS_STATUS (default pending)
+func ConfigFromEnv() Config {
+	cfg := Config{
+		MaxLinks:        2,
+		DuplicateWindow: 24 * time.Hour,
+		TrustedApproved: 3,
+		AnonymousStatus: StatusPending,
+	}
+
+	if v, err := strconv.Atoi(os.Getenv("MODERATION_MAX_LINKS")); err == nil && v >= 0 {
+		cfg.MaxLinks = v
+	}
+	if v, err := time.ParseDuration(os.Getenv("MODERATION_DUPLICATE_WINDOW")); err == nil && v > 0 {
+		cfg.DuplicateWindow = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("MODERATION_TRUSTED_APPROVED")); err == nil && v >= 0 {
+		cfg.TrustedApproved = v
+	}
+	if os.Getenv("MODERATION_ANONYMOUS_STATUS") == StatusApproved {
+		cfg.AnonymousStatus = StatusApproved
+	}
+
+	return cfg
+}
+
+// BannedWord is an entry of the admin-managed word list
+type BannedWord struct {
+	Word     string
+	Severity string
+}
+
+// Signals are the facts gathered about a comment before deciding
+type Signals struct {
+	Content       string
+	Authenticated bool
+
+	// Author history, for authenticated users
+	ApprovedCount int
+	RejectedCount int
+
+	// Identical comments within the duplicate window. Only authenticated
+	// authors can be matched, for anonymous comments DuplicatesByAuthor is 0
+	// and a repeat on the same article goes to review instead.
+	DuplicatesByAuthor  int
+	DuplicatesOnArticle int
+}
+
+// Decision is the outcome of the pipeline
+type Decision struct {
+	Status  string   `json:"status"`
+	Score   int      `json:"score"`
+	Reasons []string `json:"reasons"`
+}
+
+// TrustScore rates an author by their moderation history. Every rejection
+// outweighs two approvals.
+func TrustScore(approved, rejected int) int {
+	return approved - 2*rejected
+}
+
+// Evaluate runs every check and returns the resulting status with the
+// reasons behind it. Any rejecting check wins over a reviewing one.
+func Evaluate(cfg Config, words []BannedWord, sig Signals) Decision {
+	d := Decision{Status: StatusApproved, Reasons: []string{}}
+	if sig.Authenticated {
+		d.Score = TrustScore(sig.ApprovedCount, sig.RejectedCount)
+	}
+	trusted := sig.Authenticated && sig.RejectedCount == 0 && sig.ApprovedCount >= cfg.TrustedApproved
+
+	reject := func(reason string) {
+		d.Status = StatusRejected
+		d.Reasons = append(d.Reasons, reason)
+	}
+	review := func(reason string) {
+		if d.Status != StatusRejected {
+			d.Status = StatusPending
+		}
+		d.Reasons = append(d.Reasons, reason)
+	}
+
+	for _, m := range FindBannedWords(sig.Content, words) {
+		if m.Severity == SeverityReject {
+			reject("kata terlarang: " + m.Word)
+		} else {
+			review("kata yang perlu ditinjau: " + m.Word)
+		}
+	}
+
+	if links := CountLinks(sig.Content); links > cfg.MaxLinks {
+		reject(fmt.Sprintf("terlalu banyak tautan (%d)", links))
+	} else if links > 0 && !trusted {
+		review(fmt.Sprintf("mengandung tautan (%d)", links))
+	}
+
+	if sig.DuplicatesByAuthor > 0 {
+		reject("komentar duplikat")
+	} else if sig.DuplicatesOnArticle > 0 {
+		review("komentar yang sama sudah dikirim pengguna lain")
+	}
+
+	switch {
+	case !sig.Authenticated:
+		if cfg.AnonymousStatus != StatusApproved {
+			review("komentar anonim")
+		}
+	case d.Score < 0:
+		review(fmt.Sprintf("skor kepercayaan rendah (%d)", d.Score))
+	}
+
+	return d
+}
+
+// ========================================
+// TEXT ANALYSIS
+// ========================================
+
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)\S+|\b[a-z0-9-]+\.(?:com|net|org|id|co\.id|info|xyz|io|ly|me)\b`)
+
+// CountLinks counts URLs and bare domains in the text
+func CountLinks(text string) int {
+	return len(linkPattern.FindAllString(text, -1))
+}
+
+// leet maps digits and symbols commonly used to disguise letters. Symbols
+// only count as letters inside a word, so "anjing!" is not read as "anjingi".
+var leet = map[rune]rune{
+	'4': 'a', '@': 'a',
+	'8': 'b',
+	'3': 'e',
+	'6': 'g', '9': 'g',
+	'1': 'i', '!': 'i', '|': 'i',
+	'0': 'o',
+	'5': 's', '$': 's',
+	'7': 't', '+': 't',
+	'2': 'z',
+}
+
+// Normalize lowercases the text, undoes leetspeak, collapses repeated
+// letters ("anjiiing") and rejoins letters split by spaces or punctuation
+// ("a.n.j.i.n.g", "b a n g s a t"). It returns the resulting words.
+func Normalize(text string) []string {
+	var b strings.Builder
+	var last rune
+	runes := []rune(strings.ToLower(text))
+	for i, r := range runes {
+		if mapped, ok := leet[r]; ok && (unicode.IsDigit(r) || i+1 < len(runes) && isWordRune(runes[i+1])) {
+			r = mapped
+		}
+		if unicode.IsLetter(r) {
+			if r == last {
+				continue
+			}
+			b.WriteRune(r)
+		} else {
+			b.WriteRune(' ')
+		}
+		last = r
+	}
+
+	var words []string
+	var split strings.Builder
+	flush := func() {
+		if split.Len() > 0 {
+			words = append(words, split.String())
+			split.Reset()
+		}
+	}
+	for _, w := range strings.Fields(b.String()) {
+		if len([]rune(w)) == 1 {
+			split.WriteString(w)
+			continue
+		}
+		flush()
+		words = append(words, w)
+	}
+	flush()
+
+	return words
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// suffixes are Indonesian particles and pronouns attached to a word
+var suffixes = []string{"", "nya", "lah", "kah", "mu", "ku", "in", "an", "lu", "lo"}
+
+// FindBannedWords returns the banned words that occur in the text. Single
+// words also match with a suffix ("anjingnya"); phrases match as a whole.
+func FindBannedWords(text string, words []BannedWord) []BannedWord {
+	tokens := Normalize(text)
+	joined := " " + strings.Join(tokens, " ") + " "
+
+	present := make(map[string]bool, len(tokens))
+	for _, t := range tokens {
+		present[t] = true
+	}
+
+	var found []BannedWord
+	for _, w := range words {
+		normalized := Normalize(w.Word)
+		if len(normalized) == 0 {
+			continue
+		}
+
+		matched := false
+		if len(normalized) > 1 {
+			matched = strings.Contains(joined, " "+strings.Join(normalized, " ")+" ")
+		} else {
+			for _, suffix := range suffixes {
+				if present[normalized[0]+suffix] {
+					matched = true
+					break
+				}
+			}
+		}
+		if matched {
+			found = append(found, w)
+		}
+	}
+	return found
+}
@@ -0,0 +1,161 @@
+package moderation
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"plain words", "Halo Dunia", []string{"halo", "dunia"}},
+		{"punctuation splits words", "halo,dunia.", []string{"halo", "dunia"}},
+		{"repeated letters", "anjiiiing", []string{"anjing"}},
+		{"leetspeak", "4nj1ng", []string{"anjing"}},
+		{"leet symbols", "b@ngs@t", []string{"bangsat"}},
+		{"leet symbol starting a word", "$etan", []string{"setan"}},
+		{"trailing symbols are punctuation", "halo! apa kabar?!", []string{"halo", "apa", "kabar"}},
+		{"dotted letters", "a.n.j.i.n.g", []string{"anjing"}},
+		{"spaced letters", "dasar b a n g s a t", []string{"dasar", "bangsat"}},
+		{"spaced letters between words", "kamu b o d o h sekali", []string{"kamu", "bodoh", "sekali"}},
+		{"empty", " ... ", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Normalize(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindBannedWords(t *testing.T) {
+	words := []BannedWord{
+		{Word: "anjing", Severity: SeverityReject},
+		{Word: "bodoh", Severity: SeverityReview},
+		{Word: "tidak berguna", Severity: SeverityReview},
+	}
+
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"clean", "Artikel yang bagus sekali", nil},
+		{"exact", "dasar anjing", []string{"anjing"}},
+		{"uppercase", "ANJING", []string{"anjing"}},
+		{"trailing punctuation", "dasar anjing!", []string{"anjing"}},
+		{"suffix nya", "anjingnya galak", []string{"anjing"}},
+		{"suffix lah", "bodohlah kamu", []string{"bodoh"}},
+		{"leetspeak", "4nj1ng", []string{"anjing"}},
+		{"stretched", "bodoooh", []string{"bodoh"}},
+		{"spaced", "kamu b o d o h", []string{"bodoh"}},
+		{"inside a longer word", "penganjingan", nil},
+		{"suffix an", "anjingan", []string{"anjing"}},
+		{"phrase", "penulis tidak berguna", []string{"tidak berguna"}},
+		{"phrase across punctuation", "tidak, berguna", []string{"tidak berguna"}},
+		{"phrase words apart", "tidak terlalu berguna", nil},
+		{"several", "anjing bodoh", []string{"anjing", "bodoh"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, w := range FindBannedWords(tt.text, words) {
+				got = append(got, w.Word)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindBannedWords(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCountLinks(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"tidak ada tautan. Sama sekali", 0},
+		{"lihat https://contoh.com/promo", 1},
+		{"lihat http://contoh.com dan www.lain.net", 2},
+		{"kunjungi contoh.co.id sekarang", 1},
+		{"toko.xyz, promo.ly dan murah.com", 3},
+		{"HTTPS://CONTOH.COM", 1},
+	}
+	for _, tt := range tests {
+		if got := CountLinks(tt.text); got != tt.want {
+			t.Errorf("CountLinks(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestTrustScore(t *testing.T) {
+	if got := TrustScore(5, 2); got != 1 {
+		t.Errorf("TrustScore(5, 2) = %d, want 1", got)
+	}
+	if got := TrustScore(1, 1); got != -1 {
+		t.Errorf("TrustScore(1, 1) = %d, want -1", got)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	cfg := Config{
+		MaxLinks:        2,
+		DuplicateWindow: 24 * time.Hour,
+		TrustedApproved: 3,
+		AnonymousStatus: StatusPending,
+	}
+	openCfg := cfg
+	openCfg.AnonymousStatus = StatusApproved
+
+	words := []BannedWord{
+		{Word: "anjing", Severity: SeverityReject},
+		{Word: "bodoh", Severity: SeverityReview},
+	}
+
+	tests := []struct {
+		name      string
+		cfg       Config
+		sig       Signals
+		want      string
+		wantScore int
+	}{
+		{"clean from new user", cfg, Signals{Content: "Setuju", Authenticated: true}, StatusApproved, 0},
+		{"clean anonymous", cfg, Signals{Content: "Setuju"}, StatusPending, 0},
+		{"clean anonymous allowed", openCfg, Signals{Content: "Setuju"}, StatusApproved, 0},
+		{"review word", cfg, Signals{Content: "bodoh", Authenticated: true}, StatusPending, 0},
+		{"reject word", cfg, Signals{Content: "anjing", Authenticated: true}, StatusRejected, 0},
+		{"reject wins over review", cfg, Signals{Content: "anjing bodoh", Authenticated: true}, StatusRejected, 0},
+		{"reject wins over anonymous", openCfg, Signals{Content: "anjing"}, StatusRejected, 0},
+		{"link from new user", cfg, Signals{Content: "lihat contoh.com", Authenticated: true}, StatusPending, 0},
+		{"link from trusted user", cfg,
+			Signals{Content: "lihat contoh.com", Authenticated: true, ApprovedCount: 3}, StatusApproved, 3},
+		{"link from user with a rejection", cfg,
+			Signals{Content: "lihat contoh.com", Authenticated: true, ApprovedCount: 5, RejectedCount: 1}, StatusPending, 3},
+		{"too many links from trusted user", cfg,
+			Signals{Content: "a.com b.com c.com", Authenticated: true, ApprovedCount: 10}, StatusRejected, 10},
+		{"duplicate by author", cfg,
+			Signals{Content: "Setuju", Authenticated: true, ApprovedCount: 10, DuplicatesByAuthor: 1}, StatusRejected, 10},
+		{"duplicate on article", cfg,
+			Signals{Content: "Setuju", Authenticated: true, DuplicatesOnArticle: 1}, StatusPending, 0},
+		{"anonymous duplicate on article", openCfg, Signals{Content: "Setuju", DuplicatesOnArticle: 2}, StatusPending, 0},
+		{"low trust score", cfg,
+			Signals{Content: "Setuju", Authenticated: true, ApprovedCount: 1, RejectedCount: 1}, StatusPending, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := Evaluate(tt.cfg, words, tt.sig)
+			if d.Status != tt.want || d.Score != tt.wantScore {
+				t.Errorf("status %q score %d, want %q score %d (reasons %q)", d.Status, d.Score, tt.want, tt.wantScore, d.Reasons)
+			}
+			// Every held or rejected comment tells the moderator why
+			if (d.Status == StatusApproved) != (len(d.Reasons) == 0) {
+				t.Errorf("status %q with reasons %q", d.Status, d.Reasons)
+			}
+		})
+	}
+}
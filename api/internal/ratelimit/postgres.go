@@ -0,0 +1,55 @@
+package ratelimit
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// PostgresStore keeps buckets in the rate_limit_buckets table so every
+// replica shares the same limits
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore creates a store backed by PostgreSQL
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+func (s *PostgresStore) Take(ctx context.Context, key string, policy Policy) (Result, error) {
+	// Refill and take in a single upsert so concurrent requests on the same
+	// key serialize on the row lock. The database clock is used so replicas
+	// with drifting clocks agree on the refill.
+	query := `
+        INSERT INTO rate_limit_buckets AS b (bucket_key, tokens, allowed, updated_at)
+        VALUES ($1, $2 - 1, TRUE, NOW())
+        ON CONFLICT (bucket_key) DO UPDATE SET
+            tokens = CASE
+                WHEN LEAST($2, b.tokens + GREATEST(0, EXTRACT(EPOCH FROM NOW() - b.updated_at)) * $3) >= 1
+                THEN LEAST($2, b.tokens + GREATEST(0, EXTRACT(EPOCH FROM NOW() - b.updated_at)) * $3) - 1
+                ELSE LEAST($2, b.tokens + GREATEST(0, EXTRACT(EPOCH FROM NOW() - b.updated_at)) * $3)
+            END,
+            allowed = LEAST($2, b.tokens + GREATEST(0, EXTRACT(EPOCH FROM NOW() - b.updated_at)) * $3) >= 1,
+            updated_at = NOW()
+        RETURNING tokens, allowed
+    `
+
+	var tokens float64
+	var allowed bool
+	err := s.db.QueryRowContext(ctx, query, key, policy.Capacity(), policy.Rate()).Scan(&tokens, &allowed)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to take rate limit token: %w", err)
+	}
+
+	return result(policy, tokens, allowed), nil
+}
+
+func (s *PostgresStore) Cleanup(ctx context.Context, idle time.Duration) error {
+	query := `DELETE FROM rate_limit_buckets WHERE updated_at < NOW() - $1 * INTERVAL '1 second'`
+	if _, err := s.db.ExecContext(ctx, query, idle.Seconds()); err != nil {
+		return fmt.Errorf("failed to clean up rate limit buckets: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Policy is a token bucket: Limit requests per Window, refilled
+// continuously, with room for bursts of up to Burst requests
+type Policy struct {
+	Name   string
+	Limit  int
+	Window time.Duration
+	Burst  int
+}
+
+// Rate is the refill speed in tokens per second
+func (p Policy) Rate() float64 {
+	return float64(p.Limit) / p.Window.Seconds()
+}
+
+// Capacity is the bucket size
+func (p Policy) Capacity() float64 {
+	if p.Burst > 0 {
+		return float64(p.Burst)
+	}
+	return float64(p.Limit)
+}
+
+// Enabled reports whether the policy limits anything
+func (p Policy) Enabled() bool {
+	return p.Limit > 0 && p.Window > 0
+}
+
+// ParsePolicy parses "N/unit" or "N/unit:burst", where unit is s, m or h
+// (e.g. "10/m", "300/m:50"). "off" returns a disabled policy.
+func ParsePolicy(name, spec string) (Policy, error) {
+	p := Policy{Name: name}
+	spec = strings.TrimSpace(spec)
+	if spec == "off" {
+		return p, nil
+	}
+
+	rate, burst, hasBurst := strings.Cut(spec, ":")
+	count, unit, ok := strings.Cut(rate, "/")
+	if !ok {
+		return p, fmt.Errorf("invalid rate limit %q for %s", spec, name)
+	}
+
+	limit, err := strconv.Atoi(count)
+	if err != nil || limit <= 0 {
+		return p, fmt.Errorf("invalid rate limit %q for %s", spec, name)
+	}
+	p.Limit = limit
+
+	switch unit {
+	case "s":
+		p.Window = time.Second
+	case "m":
+		p.Window = time.Minute
+	case "h":
+		p.Window = time.Hour
+	default:
+		return p, fmt.Errorf("invalid rate limit unit %q for %s", unit, name)
+	}
+
+	if hasBurst {
+		if p.Burst, err = strconv.Atoi(burst); err != nil || p.Burst <= 0 {
+			return p, fmt.Errorf("invalid burst %q for %s", burst, name)
+		}
+	}
+
+	return p, nil
+}
+
+// Result is the outcome of taking a token
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	ResetAfter time.Duration // until the bucket is full again
+	RetryAfter time.Duration // until the next request is allowed (0 if allowed)
+}
+
+// Store keeps bucket state
+type Store interface {
+	// Take removes one token from the bucket identified by key
+	Take(ctx context.Context, key string, policy Policy) (Result, error)
+
+	// Cleanup removes buckets that have not been used for the given duration
+	Cleanup(ctx context.Context, idle time.Duration) error
+}
+
+// result builds a Result from the token count after a take attempt
+func result(policy Policy, tokens float64, allowed bool) Result {
+	rate := policy.Rate()
+	r := Result{
+		Allowed:    allowed,
+		Limit:      policy.Limit,
+		Remaining:  int(math.Max(0, math.Floor(tokens))),
+		ResetAfter: seconds((policy.Capacity() - tokens) / rate),
+	}
+	if !allowed {
+		r.RetryAfter = seconds((1 - tokens) / rate)
+	}
+	return r
+}
+
+// refill returns the token count after the time elapsed since the last update
+func refill(policy Policy, tokens float64, elapsed time.Duration) float64 {
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	return math.Min(policy.Capacity(), tokens+elapsed.Seconds()*policy.Rate())
+}
+
+func seconds(s float64) time.Duration {
+	if s <= 0 {
+		return 0
+	}
+	return time.Duration(s * float64(time.Second))
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"time"
 
 	"news-portal-web/api/internal/auth"
 	"news-portal-web/api/internal/database"
@@ -12,74 +13,164 @@ import (
 	"github.com/gorilla/mux"
 )
 
-// handleGetArticles returns all articles (with filters)
+// handleGetArticles returns published articles (with filters). Other
+// statuses are only listed through GET /editor/articles.
 func (s *Server) handleGetArticles() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		// Parse query parameters
-		filter := database.ArticleFilter{}
-
-		if status := r.URL.Query().Get("status"); status != "" {
-			filter.Status = status
-		} else {
-			// Default to published for public access
-			filter.Status = "published"
+		if status := r.URL.Query().Get("status"); status != "" && status != "published" {
+			writeJSONError(w, "Hanya artikel published yang dapat diakses publik", http.StatusBadRequest)
+			return
 		}
 
-		if kategoriID := r.URL.Query().Get("kategori_id"); kategoriID != "" {
-			if id, err := strconv.Atoi(kategoriID); err == nil {
-				filter.KategoriID = id
+		filter := articleFilterFromQuery(r)
+		filter.Status = "published"
+
+		s.writeArticleList(w, r, filter, defaultPageLimit)
+	}
+}
+
+// handleGetEditorArticles - GET /api/v1/editor/articles
+// Daftar artikel dengan status apa pun (?status= opsional). Tanpa izin
+// article.edit.any atau article.review hanya artikel milik sendiri yang tampil.
+func (s *Server) handleGetEditorArticles() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filter := articleFilterFromQuery(r)
+		filter.Status = r.URL.Query().Get("status")
+
+		if !s.can(r.Context(), auth.PermArticleEditAny) && !s.can(r.Context(), auth.PermArticleReview) {
+			userID, ok := getUserIDFromContext(r.Context())
+			if !ok {
+				writeJSONError(w, "Unauthorized", http.StatusUnauthorized)
+				return
 			}
+			filter.UserID = userID
 		}
 
-		// NEW: Support filter by category name
-		if kategori := r.URL.Query().Get("kategori"); kategori != "" {
-			filter.KategoriName = kategori
+		s.writeArticleList(w, r, filter, defaultPageLimit)
+	}
+}
+
+// articleFilterFromQuery reads the category, tag and search filters shared
+// by the public and editor article lists
+func articleFilterFromQuery(r *http.Request) database.ArticleFilter {
+	filter := database.ArticleFilter{}
+
+	if kategoriID := r.URL.Query().Get("kategori_id"); kategoriID != "" {
+		if id, err := strconv.Atoi(kategoriID); err == nil {
+			filter.KategoriID = id
 		}
+	}
 
-		if tagID := r.URL.Query().Get("tag_id"); tagID != "" {
-			if id, err := strconv.Atoi(tagID); err == nil {
-				filter.TagID = id
-			}
+	// NEW: Support filter by category name
+	if kategori := r.URL.Query().Get("kategori"); kategori != "" {
+		filter.KategoriName = kategori
+	}
+
+	if tagID := r.URL.Query().Get("tag_id"); tagID != "" {
+		if id, err := strconv.Atoi(tagID); err == nil {
+			filter.TagID = id
 		}
+	}
+
+	if search := r.URL.Query().Get("search"); search != "" {
+		filter.Search = search
+	}
+
+	return filter
+}
 
-		if search := r.URL.Query().Get("search"); search != "" {
-			filter.Search = search
+// writeArticleList writes one page of articles matching filter in the
+// pagination envelope. The published feed also accepts ?cursor= for keyset
+// paging and returns next_cursor; offset is ignored when a cursor is given.
+func (s *Server) writeArticleList(w http.ResponseWriter, r *http.Request, filter database.ArticleFilter, defaultLimit int) {
+	page := parsePagination(r, defaultLimit)
+
+	if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+		if !filter.IsFeed() {
+			writeJSONError(w, "Cursor hanya didukung untuk feed artikel published tanpa pencarian", http.StatusBadRequest)
+			return
 		}
+		c, err := database.DecodeArticleCursor(cursor)
+		if err != nil {
+			writeJSONError(w, "Invalid cursor", http.StatusBadRequest)
+			return
+		}
+		filter.Cursor = c
+		page.Offset = 0
+	}
 
-		if limit := r.URL.Query().Get("limit"); limit != "" {
-			if l, err := strconv.Atoi(limit); err == nil {
-				filter.Limit = l
-			}
+	filter.Limit = page.Limit
+	filter.Offset = page.Offset
+
+	articles, err := s.articles.List(r.Context(), filter)
+	if err != nil {
+		writeJSONError(w, "Error fetching articles", http.StatusInternalServerError)
+		return
+	}
+
+	total, err := s.articles.Count(r.Context(), filter)
+	if err != nil {
+		writeJSONError(w, "Error counting articles", http.StatusInternalServerError)
+		return
+	}
+
+	if articles == nil {
+		articles = []database.Article{}
+	}
+
+	nextCursor := ""
+	if filter.IsFeed() && len(articles) == page.Limit {
+		if c := database.CursorAfter(articles[len(articles)-1]); c != nil {
+			nextCursor = c.Encode()
 		}
+	}
 
-		if offset := r.URL.Query().Get("offset"); offset != "" {
-			if o, err := strconv.Atoi(offset); err == nil {
-				filter.Offset = o
-			}
+	writePaginated(w, articles, total, page, nextCursor)
+}
+
+// handleGetArticleByID returns a single published article by ID
+func (s *Server) handleGetArticleByID() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["id"])
+		if err != nil {
+			writeJSONError(w, "Invalid article ID", http.StatusBadRequest)
+			return
 		}
 
-		articles, err := database.GetAllArticles(s.GetDB(), filter)
+		article, err := s.articles.GetByID(r.Context(), id)
 		if err != nil {
-			writeJSONError(w, "Error fetching articles", http.StatusInternalServerError)
+			if err == sql.ErrNoRows {
+				writeJSONError(w, "Article not found", http.StatusNotFound)
+				return
+			}
+			writeJSONError(w, "Error fetching article", http.StatusInternalServerError)
+			return
+		}
+
+		// Drafts, articles in review and embargoed scheduled articles stay hidden
+		if article.Status != "published" {
+			writeJSONError(w, "Article not found", http.StatusNotFound)
 			return
 		}
 
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(articles)
+		json.NewEncoder(w).Encode(article)
 	}
 }
 
-// handleGetArticleByID returns a single article by ID
-func (s *Server) handleGetArticleByID() http.HandlerFunc {
+// handleGetEditorArticle - GET /api/v1/editor/articles/{id}
+// Artikel dengan status apa pun, untuk penulisnya atau role dengan izin
+// article.edit.any atau article.review
+func (s *Server) handleGetEditorArticle() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		vars := mux.Vars(r)
-		id, err := strconv.Atoi(vars["id"])
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
 		if err != nil {
 			writeJSONError(w, "Invalid article ID", http.StatusBadRequest)
 			return
 		}
 
-		article, err := database.GetArticleByID(s.GetDB(), id)
+		article, err := s.articles.GetByID(r.Context(), id)
 		if err != nil {
 			if err == sql.ErrNoRows {
 				writeJSONError(w, "Article not found", http.StatusNotFound)
@@ -89,6 +180,12 @@ func (s *Server) handleGetArticleByID() http.HandlerFunc {
 			return
 		}
 
+		if !s.canOnOwned(r.Context(), &article.UserID, auth.PermArticleEditOwn, auth.PermArticleEditAny) &&
+			!s.can(r.Context(), auth.PermArticleReview) {
+			writeJSONError(w, "Anda tidak memiliki izin untuk melihat artikel ini", http.StatusForbidden)
+			return
+		}
+
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(article)
 	}
@@ -105,7 +202,7 @@ func (s *Server) handleGetArticleBySlug() http.HandlerFunc {
 			return
 		}
 
-		article, err := database.GetPublishedArticleBySlug(s.GetDB(), slug)
+		article, err := s.articles.GetPublishedBySlug(r.Context(), slug)
 		if err != nil {
 			if err == sql.ErrNoRows {
 				writeJSONError(w, "Article not found", http.StatusNotFound)
@@ -130,29 +227,12 @@ func (s *Server) handleGetArticlesByCategory() http.HandlerFunc {
 			return
 		}
 
-		limit := 10
-		offset := 0
-
-		if l := r.URL.Query().Get("limit"); l != "" {
-			if lInt, err := strconv.Atoi(l); err == nil {
-				limit = lInt
-			}
-		}
-
-		if o := r.URL.Query().Get("offset"); o != "" {
-			if oInt, err := strconv.Atoi(o); err == nil {
-				offset = oInt
-			}
-		}
-
-		articles, err := database.GetArticlesByCategory(s.GetDB(), kategoriID, limit, offset)
-		if err != nil {
-			writeJSONError(w, "Error fetching articles", http.StatusInternalServerError)
-			return
+		filter := database.ArticleFilter{
+			Status:     "published",
+			KategoriID: kategoriID,
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(articles)
+		s.writeArticleList(w, r, filter, 10)
 	}
 }
 
@@ -176,14 +256,25 @@ func (s *Server) handleCreateArticle() http.HandlerFunc {
 			return
 		}
 
+		// New articles always start as draft; status changes go through the workflow endpoints
+		if input.Status != "" && input.Status != "draft" {
+			writeJSONError(w, "Artikel baru harus berstatus draft, gunakan endpoint workflow untuk mengubah status", http.StatusBadRequest)
+			return
+		}
+		input.Status = "draft"
+
 		// Get user ID from context
 		userID, ok := r.Context().Value(auth.UserIDKey).(int)
 		if !ok {
 			writeJSONError(w, "Unauthorized", http.StatusUnauthorized)
 			return
 		}
+		if !s.can(r.Context(), auth.PermArticleCreate) {
+			writeJSONError(w, "Anda tidak memiliki izin untuk membuat artikel", http.StatusForbidden)
+			return
+		}
 
-		article, err := database.CreateArticle(s.GetDB(), input, userID)
+		article, err := s.articles.Create(r.Context(), input, userID)
 		if err != nil {
 			writeJSONError(w, "Error creating article: "+err.Error(), http.StatusInternalServerError)
 			return
@@ -222,7 +313,44 @@ func (s *Server) handleUpdateArticle() http.HandlerFunc {
 			return
 		}
 
-		article, err := database.UpdateArticle(s.GetDB(), id, input)
+		existing, err := s.articles.GetByID(r.Context(), id)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				writeJSONError(w, "Article not found", http.StatusNotFound)
+				return
+			}
+			writeJSONError(w, "Error fetching article", http.StatusInternalServerError)
+			return
+		}
+
+		if !s.canOnOwned(r.Context(), &existing.UserID, auth.PermArticleEditOwn, auth.PermArticleEditAny) {
+			writeJSONError(w, "Anda tidak memiliki izin untuk mengubah artikel ini", http.StatusForbidden)
+			return
+		}
+
+		// Status is only changed through the workflow endpoints
+		if input.Status != "" && input.Status != existing.Status {
+			writeJSONError(w, "Status tidak dapat diubah langsung, gunakan endpoint workflow", http.StatusBadRequest)
+			return
+		}
+		input.Status = existing.Status
+
+		// Rescheduling through an edit must still point to the future
+		if existing.Status == "scheduled" && input.TanggalPublikasi != "" {
+			publishAt, err := time.Parse(time.RFC3339, input.TanggalPublikasi)
+			if err != nil || !publishAt.After(time.Now()) {
+				writeJSONError(w, "tanggal_publikasi artikel terjadwal harus di masa depan (RFC3339)", http.StatusBadRequest)
+				return
+			}
+		}
+
+		userID, ok := getUserIDFromContext(r.Context())
+		if !ok {
+			writeJSONError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		article, err := s.articles.Update(r.Context(), id, input, userID)
 		if err != nil {
 			if err == sql.ErrNoRows {
 				writeJSONError(w, "Article not found", http.StatusNotFound)
@@ -247,7 +375,22 @@ func (s *Server) handleDeleteArticle() http.HandlerFunc {
 			return
 		}
 
-		err = database.DeleteArticle(s.GetDB(), id)
+		existing, err := s.articles.GetByID(r.Context(), id)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				writeJSONError(w, "Article not found", http.StatusNotFound)
+				return
+			}
+			writeJSONError(w, "Error fetching article", http.StatusInternalServerError)
+			return
+		}
+
+		if !s.canOnOwned(r.Context(), &existing.UserID, auth.PermArticleDeleteOwn, auth.PermArticleDeleteAny) {
+			writeJSONError(w, "Anda tidak memiliki izin untuk menghapus artikel ini", http.StatusForbidden)
+			return
+		}
+
+		err = s.articles.Delete(r.Context(), id)
 		if err != nil {
 			if err == sql.ErrNoRows {
 				writeJSONError(w, "Article not found", http.StatusNotFound)
@@ -275,7 +418,9 @@ func (s *Server) RegisterPublicArticleRoutes(r *mux.Router) {
 
 // RegisterEditorArticleRoutes registers editor article routes
 func (s *Server) RegisterEditorArticleRoutes(r *mux.Router) {
+	r.HandleFunc("/articles", s.handleGetEditorArticles()).Methods("GET")
 	r.HandleFunc("/articles", s.handleCreateArticle()).Methods("POST")
+	r.HandleFunc("/articles/{id:[0-9]+}", s.handleGetEditorArticle()).Methods("GET")
 	r.HandleFunc("/articles/{id:[0-9]+}", s.handleUpdateArticle()).Methods("PUT")
 	r.HandleFunc("/articles/{id:[0-9]+}", s.handleDeleteArticle()).Methods("DELETE")
 }
@@ -0,0 +1,139 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"news-portal-web/api/internal/database"
+)
+
+func TestPublicArticleList(t *testing.T) {
+	ts := newTestServer(t)
+	editor, _ := ts.createUser("penulis", "editor", true)
+
+	category, err := ts.repos.Taxonomy.CreateCategory(context.Background(), &database.CategoryRequest{NamaKategori: "Politik"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts.createArticle(editor.UserID, database.ArticleInput{Judul: "Pemilu", Status: "published", KategoriIDs: []int{category.KategoriID}})
+	ts.createArticle(editor.UserID, database.ArticleInput{Judul: "Cuaca", Status: "published"})
+	ts.createArticle(editor.UserID, database.ArticleInput{Judul: "Rancangan"})
+
+	var articles []database.Article
+	if total := ts.listPage("/articles", "", &articles); total != 2 || len(articles) != 2 {
+		t.Fatalf("published articles: total %d, got %d, want 2", total, len(articles))
+	}
+	for _, a := range articles {
+		if a.Status != "published" {
+			t.Errorf("article %q has status %q in the public list", a.Judul, a.Status)
+		}
+	}
+
+	articles = nil
+	ts.listPage(fmt.Sprintf("/articles?kategori_id=%d", category.KategoriID), "", &articles)
+	if len(articles) != 1 || articles[0].Judul != "Pemilu" {
+		t.Fatalf("kategori_id filter returned %+v", articles)
+	}
+
+	articles = nil
+	if total := ts.listPage("/articles?limit=1", "", &articles); total != 2 || len(articles) != 1 {
+		t.Fatalf("limit=1: total %d, got %d", total, len(articles))
+	}
+}
+
+func TestGetArticle(t *testing.T) {
+	ts := newTestServer(t)
+	editor, _ := ts.createUser("penulis", "editor", true)
+
+	published := ts.createArticle(editor.UserID, database.ArticleInput{Judul: "Berita Utama", Status: "published"})
+	draft := ts.createArticle(editor.UserID, database.ArticleInput{Judul: "Belum Terbit"})
+
+	var article database.Article
+	ts.expectStatus(http.MethodGet, fmt.Sprintf("/articles/%d", published.ArtikelID), "", nil, &article, http.StatusOK)
+	if article.Judul != "Berita Utama" {
+		t.Errorf("got article %q", article.Judul)
+	}
+
+	ts.expectStatus(http.MethodGet, "/articles/slug/"+published.Slug, "", nil, &article, http.StatusOK)
+	if article.ArtikelID != published.ArtikelID {
+		t.Errorf("slug lookup returned article %d, want %d", article.ArtikelID, published.ArtikelID)
+	}
+
+	ts.expectStatus(http.MethodGet, "/articles/slug/"+draft.Slug, "", nil, nil, http.StatusNotFound)
+	ts.expectStatus(http.MethodGet, fmt.Sprintf("/articles/%d", draft.ArtikelID), "", nil, nil, http.StatusNotFound)
+	ts.expectStatus(http.MethodGet, "/articles/9999", "", nil, nil, http.StatusNotFound)
+}
+
+func TestUnpublishedArticlesStayPrivate(t *testing.T) {
+	ts := newTestServer(t)
+	author, token := ts.createUser("penulis", "editor", true)
+	_, otherToken := ts.createUser("penulis2", "editor", true)
+	_, reviewerToken := ts.createUser("redaktur", "reviewer", true)
+
+	scheduled := ts.createArticle(author.UserID, database.ArticleInput{Judul: "Embargo", Status: "scheduled"})
+	ts.createArticle(author.UserID, database.ArticleInput{Judul: "Rancangan"})
+	ts.createArticle(author.UserID, database.ArticleInput{Judul: "Sudah Terbit", Status: "published"})
+
+	// Public routes only serve published articles
+	ts.expectStatus(http.MethodGet, "/articles?status=scheduled", "", nil, nil, http.StatusBadRequest)
+	ts.expectStatus(http.MethodGet, fmt.Sprintf("/articles/%d", scheduled.ArtikelID), "", nil, nil, http.StatusNotFound)
+
+	path := fmt.Sprintf("/editor/articles/%d", scheduled.ArtikelID)
+	ts.expectStatus(http.MethodGet, path, token, nil, nil, http.StatusOK)
+	ts.expectStatus(http.MethodGet, path, reviewerToken, nil, nil, http.StatusOK)
+	ts.expectStatus(http.MethodGet, path, otherToken, nil, nil, http.StatusForbidden)
+
+	var articles []database.Article
+	if total := ts.listPage("/editor/articles?status=scheduled", token, &articles); total != 1 || articles[0].Judul != "Embargo" {
+		t.Fatalf("author's scheduled articles: total %d, got %+v", total, articles)
+	}
+
+	// Editors without article.edit.any only see their own articles
+	articles = nil
+	if total := ts.listPage("/editor/articles", otherToken, &articles); total != 0 {
+		t.Errorf("other editor sees %d articles, want 0", total)
+	}
+	articles = nil
+	if total := ts.listPage("/editor/articles", reviewerToken, &articles); total != 3 {
+		t.Errorf("reviewer sees %d articles, want 3", total)
+	}
+}
+
+func TestEditorArticleLifecycle(t *testing.T) {
+	ts := newTestServer(t)
+	_, token := ts.createUser("penulis", "editor", true)
+	_, otherToken := ts.createUser("penulis2", "editor", true)
+
+	// New articles always start as drafts
+	published := database.ArticleInput{Judul: "Langsung Terbit", Konten: "Isi", Status: "published"}
+	ts.expectStatus(http.MethodPost, "/editor/articles", token, published, nil, http.StatusBadRequest)
+	ts.expectStatus(http.MethodPost, "/editor/articles", token, database.ArticleInput{Judul: "Tanpa Isi"}, nil, http.StatusBadRequest)
+
+	var article database.Article
+	input := database.ArticleInput{Judul: "Banjir di Jakarta", Konten: "Isi berita"}
+	ts.expectStatus(http.MethodPost, "/editor/articles", token, input, &article, http.StatusCreated)
+	if article.Status != "draft" || article.Slug != "banjir-di-jakarta" {
+		t.Fatalf("created article has status %q and slug %q", article.Status, article.Slug)
+	}
+	path := fmt.Sprintf("/editor/articles/%d", article.ArtikelID)
+
+	// Editors only change their own articles
+	input.Judul = "Banjir Surut"
+	ts.expectStatus(http.MethodPut, path, otherToken, input, nil, http.StatusForbidden)
+	ts.expectStatus(http.MethodDelete, path, otherToken, nil, nil, http.StatusForbidden)
+
+	input.Status = "published"
+	ts.expectStatus(http.MethodPut, path, token, input, nil, http.StatusBadRequest)
+
+	input.Status = ""
+	ts.expectStatus(http.MethodPut, path, token, input, &article, http.StatusOK)
+	if article.Judul != "Banjir Surut" || article.Status != "draft" {
+		t.Fatalf("updated article has title %q and status %q", article.Judul, article.Status)
+	}
+
+	ts.expectStatus(http.MethodDelete, path, token, nil, nil, http.StatusOK)
+	ts.expectStatus(http.MethodGet, fmt.Sprintf("/articles/%d", article.ArtikelID), "", nil, nil, http.StatusNotFound)
+	ts.expectStatus(http.MethodPut, path, token, input, nil, http.StatusNotFound)
+}
@@ -0,0 +1,236 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/csv"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"news-portal-web/api/internal/database"
+
+	"github.com/gorilla/mux"
+)
+
+// ========================================
+// REQUEST ID & AUDIT ACTOR MIDDLEWARE
+// ========================================
+
+type requestIDKey struct{}
+
+// requestIDRegex - request id dari client hanya dipakai jika formatnya aman
+var requestIDRegex = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// requestID tags every request with an ID, taken from X-Request-ID when the
+// client (or proxy) sends a usable one, and echoes it in the response
+func (s *Server) requestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-ID")
+		if !requestIDRegex.MatchString(id) {
+			id = newRequestID()
+		}
+		w.Header().Set("X-Request-ID", id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+	})
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(b)
+}
+
+// getRequestIDFromContext returns the ID set by the requestID middleware
+func getRequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// auditActor attributes database changes made while handling the request
+// to the logged-in user, if any. Must run after the auth middleware to pick
+// up the user.
+func (s *Server) auditActor(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		actor := database.AuditActor{
+			IPAddress: s.clientIP(r),
+			RequestID: getRequestIDFromContext(r.Context()),
+		}
+		if userID, ok := GetUserIDFromContext(r.Context()); ok {
+			actor.UserID = &userID
+			actor.Role, _ = GetUserRoleFromContext(r.Context())
+		}
+		next.ServeHTTP(w, r.WithContext(database.WithAuditActor(r.Context(), actor)))
+	})
+}
+
+// ========================================
+// AUDIT LOG HANDLERS (ADMIN)
+// ========================================
+
+// parseAuditFilter reads the audit log filters from the query string:
+// actor_id, action (exact or prefix ending in '.'), target_type, target_id,
+// request_id, from and to (RFC3339 or YYYY-MM-DD, to is inclusive)
+func parseAuditFilter(r *http.Request) (database.AuditFilter, string) {
+	q := r.URL.Query()
+	filter := database.AuditFilter{
+		Action:     q.Get("action"),
+		TargetType: q.Get("target_type"),
+		TargetID:   q.Get("target_id"),
+		RequestID:  q.Get("request_id"),
+	}
+
+	if v := q.Get("actor_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil || id <= 0 {
+			return filter, "actor_id tidak valid"
+		}
+		filter.ActorID = id
+	}
+
+	if v := q.Get("from"); v != "" {
+		from, err := parseAuditTime(v, false)
+		if err != nil {
+			return filter, "Format from tidak valid, gunakan RFC3339 atau YYYY-MM-DD"
+		}
+		filter.From = &from
+	}
+	if v := q.Get("to"); v != "" {
+		to, err := parseAuditTime(v, true)
+		if err != nil {
+			return filter, "Format to tidak valid, gunakan RFC3339 atau YYYY-MM-DD"
+		}
+		filter.To = &to
+	}
+
+	return filter, ""
+}
+
+// parseAuditTime parses an RFC3339 time or a date. A date used as upper
+// bound covers the whole day.
+func parseAuditTime(v string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		if endOfDay {
+			// the filter is exclusive, keep the given instant itself
+			return t.Add(time.Nanosecond), nil
+		}
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", v)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// handleListAuditLog - GET /api/v1/admin/audit-log
+// Query params: actor_id, action, target_type, target_id, request_id, from, to,
+// limit, offset
+func (s *Server) handleListAuditLog() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filter, msg := parseAuditFilter(r)
+		if msg != "" {
+			writeJSONError(w, msg, http.StatusBadRequest)
+			return
+		}
+
+		total, err := database.CountAuditLog(r.Context(), s.GetDB(), filter)
+		if err != nil {
+			writeJSONError(w, "Error fetching audit log", http.StatusInternalServerError)
+			return
+		}
+
+		page := parsePagination(r, defaultPageLimit)
+		filter.Limit, filter.Offset = page.Limit, page.Offset
+
+		entries, err := database.ListAuditLog(r.Context(), s.GetDB(), filter)
+		if err != nil {
+			writeJSONError(w, "Error fetching audit log", http.StatusInternalServerError)
+			return
+		}
+
+		writePaginated(w, entries, total, page, "")
+	}
+}
+
+// handleExportAuditLog - GET /api/v1/admin/audit-log/export
+// Mengekspor seluruh entri yang cocok dengan filter sebagai CSV
+func (s *Server) handleExportAuditLog() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filter, msg := parseAuditFilter(r)
+		if msg != "" {
+			writeJSONError(w, msg, http.StatusBadRequest)
+			return
+		}
+
+		filename := "audit-log-" + time.Now().Format("20060102-150405") + ".csv"
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+
+		cw := csv.NewWriter(w)
+		cw.Write([]string{
+			"audit_id", "created_at", "actor_id", "actor_username", "actor_role", "action",
+			"target_type", "target_id", "before", "after", "ip_address", "request_id",
+		})
+
+		err := database.EachAuditEntry(r.Context(), s.GetDB(), filter, func(e database.AuditEntry) error {
+			actorID := ""
+			if e.ActorID != nil {
+				actorID = strconv.Itoa(*e.ActorID)
+			}
+			cw.Write(csvSafe([]string{
+				strconv.FormatInt(e.AuditID, 10),
+				e.CreatedAt.UTC().Format(time.RFC3339),
+				actorID,
+				derefString(e.ActorUsername),
+				derefString(e.ActorRole),
+				e.Action,
+				e.TargetType,
+				e.TargetID,
+				string(e.Before),
+				string(e.After),
+				derefString(e.IPAddress),
+				derefString(e.RequestID),
+			}))
+			return cw.Error()
+		})
+		cw.Flush()
+		if err == nil {
+			err = cw.Error()
+		}
+		if err != nil {
+			// Headers are already sent, the client gets a truncated file
+			log.Printf("⚠️  Audit log export failed: %v", err)
+		}
+	}
+}
+
+// csvSafe prefixes cells that a spreadsheet would read as a formula with a
+// single quote. Usernames, snapshots and request IDs come from users.
+func csvSafe(record []string) []string {
+	for i, cell := range record {
+		if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+			record[i] = "'" + cell
+		}
+	}
+	return record
+}
+
+// ========================================
+// ROUTE REGISTRATION
+// ========================================
+
+// RegisterAdminAuditRoutes registers audit log routes
+func (s *Server) RegisterAdminAuditRoutes(r *mux.Router) {
+	r.HandleFunc("/audit-log", s.handleListAuditLog()).Methods("GET")
+	r.HandleFunc("/audit-log/export", s.handleExportAuditLog()).Methods("GET").Name(routeAuditLogExport)
+}
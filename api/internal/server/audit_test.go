@@ -0,0 +1,91 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"news-portal-web/api/internal/database"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+var auditColumns = []string{
+	"audit_id", "actor_id", "username", "actor_role", "action", "target_type", "target_id",
+	"before", "after", "ip_address", "request_id", "created_at",
+}
+
+func TestListAuditLog(t *testing.T) {
+	ts := newTestServer(t)
+	admin, token := ts.createUser("admin", "admin", true)
+
+	// Prefix filters end in '.', date upper bounds cover the whole day
+	to := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	ts.mock.ExpectQuery(`SELECT COUNT\(\*\) FROM audit_log l WHERE l.action LIKE \$1 \|\| '%' AND l.created_at < \$2`).
+		WithArgs("article.", to).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	ts.mock.ExpectQuery(`FROM audit_log l.*LIMIT NULLIF\(\$3, 0\) OFFSET \$4`).
+		WithArgs("article.", to, 1, 0).
+		WillReturnRows(sqlmock.NewRows(auditColumns).AddRow(
+			9, admin.UserID, "admin", "admin", "article.status_change", "article", "7",
+			[]byte(`{"status":"draft"}`), []byte(`{"status":"in_review"}`), "127.0.0.1", "req-1", time.Now()))
+
+	var entries []database.AuditEntry
+	if total := ts.listPage("/admin/audit-log?action=article.&to=2026-03-01&limit=1", token, &entries); total != 3 || len(entries) != 1 {
+		t.Fatalf("audit log: total %d, got %d", total, len(entries))
+	}
+	if string(entries[0].After) != `{"status":"in_review"}` {
+		t.Errorf("after = %s", entries[0].After)
+	}
+
+	ts.expectStatus(http.MethodGet, "/admin/audit-log?actor_id=abc", token, nil, nil, http.StatusBadRequest)
+	ts.expectStatus(http.MethodGet, "/admin/audit-log?from=kemarin", token, nil, nil, http.StatusBadRequest)
+}
+
+func TestExportAuditLog(t *testing.T) {
+	ts := newTestServer(t)
+	_, token := ts.createUser("admin", "admin", true)
+
+	ts.mock.ExpectQuery("FROM audit_log l").
+		WithArgs("user", 0, 0).
+		WillReturnRows(sqlmock.NewRows(auditColumns).AddRow(
+			4, nil, nil, nil, "user.create", "user", "5",
+			nil, []byte(`{"username":"=cmd"}`), nil, nil, time.Now()))
+
+	req, err := http.NewRequest(http.MethodGet, ts.url+"/admin/audit-log/export?target_type=user", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+	body := new(strings.Builder)
+	if _, err := io.Copy(body, resp.Body); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(body.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "audit_id,") {
+		t.Fatalf("export = %q", body.String())
+	}
+	if !strings.Contains(lines[1], `,user.create,user,5,`) {
+		t.Errorf("export row = %q", lines[1])
+	}
+}
+
+func TestCSVSafe(t *testing.T) {
+	got := csvSafe([]string{"", "12", "admin", "=HYPERLINK(\"x\")", "+1", "-1", "@SUM(A1)", "\tx", "\rx", `{"a":1}`})
+	want := []string{"", "12", "admin", "'=HYPERLINK(\"x\")", "'+1", "'-1", "'@SUM(A1)", "'\tx", "'\rx", `{"a":1}`}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("csvSafe = %q, want %q", got, want)
+	}
+}
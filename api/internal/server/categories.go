@@ -27,7 +27,7 @@ func (s *Server) handleCreateCategory() http.HandlerFunc {
 		}
 
 		// Check if category already exists
-		exists, err := database.IsCategoryExists(r.Context(), s.GetDB(), req.NamaKategori)
+		exists, err := s.taxonomy.CategoryExists(r.Context(), req.NamaKategori)
 		if err != nil {
 			writeJSONError(w, "Failed to check category existence: "+err.Error(), http.StatusInternalServerError)
 			return
@@ -37,7 +37,7 @@ func (s *Server) handleCreateCategory() http.HandlerFunc {
 			return
 		}
 
-		category, err := database.CreateCategory(r.Context(), s.GetDB(), &req)
+		category, err := s.taxonomy.CreateCategory(r.Context(), &req)
 		if err != nil {
 			if strings.Contains(err.Error(), "duplicate") {
 				writeJSONError(w, "Category already exists", http.StatusConflict)
@@ -61,7 +61,7 @@ func (s *Server) handleGetCategoryByID() http.HandlerFunc {
 			return
 		}
 
-		category, err := database.GetCategoryByID(r.Context(), s.GetDB(), categoryID)
+		category, err := s.taxonomy.GetCategoryByID(r.Context(), categoryID)
 		if err != nil {
 			if errors.Is(err, sql.ErrNoRows) || strings.Contains(err.Error(), "not found") {
 				writeJSONError(w, "Category not found", http.StatusNotFound)
@@ -78,33 +78,29 @@ func (s *Server) handleGetCategoryByID() http.HandlerFunc {
 
 func (s *Server) handleGetCategories() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		page := parsePagination(r, maxPageLimit)
+
 		// Check if requesting categories with article count
 		withCount := r.URL.Query().Get("with_count")
 
 		if withCount == "true" {
-			categories, err := database.ListCategoriesWithArticleCount(r.Context(), s.GetDB())
+			categories, err := s.taxonomy.ListCategoriesWithArticleCount(r.Context())
 			if err != nil {
 				writeJSONError(w, "Failed to fetch categories: "+err.Error(), http.StatusInternalServerError)
 				return
 			}
 
-			w.Header().Set("Content-Type", "application/json")
-			json.NewEncoder(w).Encode(map[string]interface{}{
-				"categories": categories,
-			})
+			writePaginated(w, paginateSlice(categories, page), len(categories), page, "")
 			return
 		}
 
-		categories, err := database.ListCategories(r.Context(), s.GetDB())
+		categories, err := s.taxonomy.ListCategories(r.Context())
 		if err != nil {
 			writeJSONError(w, "Failed to fetch categories: "+err.Error(), http.StatusInternalServerError)
 			return
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"categories": categories,
-		})
+		writePaginated(w, paginateSlice(categories, page), len(categories), page, "")
 	}
 }
 
@@ -129,13 +125,13 @@ func (s *Server) handleUpdateCategory() http.HandlerFunc {
 		}
 
 		// Check if new name already exists (excluding current category)
-		existing, err := database.GetCategoryByName(r.Context(), s.GetDB(), req.NamaKategori)
+		existing, err := s.taxonomy.GetCategoryByName(r.Context(), req.NamaKategori)
 		if err == nil && existing.KategoriID != categoryID {
 			writeJSONError(w, "Category name already exists", http.StatusConflict)
 			return
 		}
 
-		category, err := database.UpdateCategory(r.Context(), s.GetDB(), categoryID, &req)
+		category, err := s.taxonomy.UpdateCategory(r.Context(), categoryID, &req)
 		if err != nil {
 			if strings.Contains(err.Error(), "not found") {
 				writeJSONError(w, "Category not found", http.StatusNotFound)
@@ -168,11 +164,11 @@ func (s *Server) handleDeleteCategory() http.HandlerFunc {
 		force := r.URL.Query().Get("force")
 
 		if force == "true" {
-			// Force delete - removes category and all its relations
-			err = database.ForceDeleteCategory(r.Context(), s.GetDB(), categoryID)
+			// Force delete - moves the category to the trash even if articles use it
+			err = s.taxonomy.ForceDeleteCategory(r.Context(), categoryID)
 		} else {
 			// Safe delete - only delete if no articles are using this category
-			err = database.DeleteCategory(r.Context(), s.GetDB(), categoryID)
+			err = s.taxonomy.DeleteCategory(r.Context(), categoryID)
 		}
 
 		if err != nil {
@@ -0,0 +1,46 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"news-portal-web/api/internal/database"
+)
+
+func TestCategoryCRUD(t *testing.T) {
+	ts := newTestServer(t)
+	admin, token := ts.createUser("admin", "admin", true)
+
+	ts.expectStatus(http.MethodPost, "/admin/categories", token, database.CategoryRequest{NamaKategori: "X"}, nil, http.StatusBadRequest)
+
+	var category database.Category
+	req := database.CategoryRequest{NamaKategori: "Ekonomi", Deskripsi: "Berita ekonomi"}
+	ts.expectStatus(http.MethodPost, "/admin/categories", token, req, &category, http.StatusCreated)
+	if category.NamaKategori != "Ekonomi" {
+		t.Fatalf("created category %q", category.NamaKategori)
+	}
+	ts.expectStatus(http.MethodPost, "/admin/categories", token, req, nil, http.StatusConflict)
+
+	var categories []database.Category
+	if total := ts.listPage("/categories", "", &categories); total != 1 {
+		t.Fatalf("listed %d categories, want 1", total)
+	}
+
+	path := fmt.Sprintf("/categories/%d", category.KategoriID)
+	ts.expectStatus(http.MethodGet, path, "", nil, &category, http.StatusOK)
+
+	req.NamaKategori = "Bisnis"
+	ts.expectStatus(http.MethodPut, "/admin"+path, token, req, &category, http.StatusOK)
+	if category.NamaKategori != "Bisnis" {
+		t.Fatalf("updated category %q", category.NamaKategori)
+	}
+	ts.expectStatus(http.MethodPut, "/admin/categories/9999", token, database.CategoryRequest{NamaKategori: "Olahraga"}, nil, http.StatusNotFound)
+
+	// Categories in use are only removed with force=true
+	ts.createArticle(admin.UserID, database.ArticleInput{Judul: "Saham", KategoriIDs: []int{category.KategoriID}})
+	ts.expectStatus(http.MethodDelete, "/admin"+path, token, nil, nil, http.StatusConflict)
+	ts.expectStatus(http.MethodDelete, "/admin"+path+"?force=true", token, nil, nil, http.StatusNoContent)
+	ts.expectStatus(http.MethodGet, path, "", nil, nil, http.StatusNotFound)
+	ts.expectStatus(http.MethodDelete, "/admin"+path, token, nil, nil, http.StatusNotFound)
+}
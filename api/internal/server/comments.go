@@ -17,8 +17,11 @@ import (
 // PUBLIC HANDLERS
 // ========================================
 
-// handleGetArticleComments - GET /api/v1/articles/{id}/comments
-// Mendapatkan semua komentar yang sudah approved untuk artikel tertentu
+// handleGetArticleComments - GET /api/v1/articles/{id}/comments?view=tree
+// Mendapatkan thread komentar approved untuk artikel tertentu. Pagination
+// berlaku untuk komentar utama; setiap thread membawa reply_count dan
+// balasannya diambil lewat /comments/{id}/replies. Dengan view=tree seluruh
+// balasan approved ikut dimuat di field replies.
 func (s *Server) handleGetArticleComments() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		vars := mux.Vars(r)
@@ -28,15 +31,67 @@ func (s *Server) handleGetArticleComments() http.HandlerFunc {
 			return
 		}
 
+		page := parsePagination(r, defaultPageLimit)
+
 		// Hanya tampilkan komentar yang sudah approved untuk public
-		comments, err := database.GetCommentsByArticleID(s.GetDB(), articleID, "approved")
+		threads, err := s.comments.ListThreads(r.Context(), articleID, page.Limit, page.Offset)
 		if err != nil {
 			writeJSONError(w, "Error fetching comments", http.StatusInternalServerError)
 			return
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(comments)
+		if r.URL.Query().Get("view") == "tree" {
+			if err := s.comments.LoadTrees(r.Context(), threads); err != nil {
+				writeJSONError(w, "Error fetching replies", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		total, err := s.comments.CountThreads(r.Context(), articleID)
+		if err != nil {
+			writeJSONError(w, "Error counting comments", http.StatusInternalServerError)
+			return
+		}
+
+		writePaginated(w, threads, total, page, "")
+	}
+}
+
+// handleGetCommentReplies - GET /api/v1/comments/{id}/replies
+// Balasan langsung dari sebuah komentar, terlama lebih dulu
+func (s *Server) handleGetCommentReplies() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		commentID, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			writeJSONError(w, "Invalid comment ID", http.StatusBadRequest)
+			return
+		}
+
+		visible, err := s.comments.IsVisible(r.Context(), commentID)
+		if err != nil {
+			writeJSONError(w, "Error fetching comment", http.StatusInternalServerError)
+			return
+		}
+		if !visible {
+			writeJSONError(w, "Komentar tidak ditemukan", http.StatusNotFound)
+			return
+		}
+
+		page := parsePagination(r, defaultPageLimit)
+
+		replies, err := s.comments.ListReplies(r.Context(), commentID, page.Limit, page.Offset)
+		if err != nil {
+			writeJSONError(w, "Error fetching replies", http.StatusInternalServerError)
+			return
+		}
+
+		total, err := s.comments.CountReplies(r.Context(), commentID)
+		if err != nil {
+			writeJSONError(w, "Error counting replies", http.StatusInternalServerError)
+			return
+		}
+
+		writePaginated(w, replies, total, page, "")
 	}
 }
 
@@ -44,6 +99,7 @@ func (s *Server) handleGetArticleComments() http.HandlerFunc {
 type CreateCommentRequest struct {
 	Konten       string `json:"konten"`
 	NamaPengguna string `json:"nama_pengguna,omitempty"` // untuk anonymous
+	ParentID     *int   `json:"parent_id,omitempty"`     // untuk balasan
 }
 
 // handleCreateComment - POST /api/v1/articles/{id}/comments
@@ -57,10 +113,7 @@ func (s *Server) handleCreateComment() http.HandlerFunc {
 			return
 		}
 
-		var req struct {
-			Konten       string `json:"konten"`
-			NamaPengguna string `json:"nama_pengguna,omitempty"`
-		}
+		var req CreateCommentRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			writeJSONError(w, "Invalid request body", http.StatusBadRequest)
 			return
@@ -72,6 +125,26 @@ func (s *Server) handleCreateComment() http.HandlerFunc {
 			return
 		}
 
+		// Balasan harus menuju komentar approved di artikel yang sama
+		depth := 0
+		if req.ParentID != nil {
+			depth, err = s.comments.ResolveParent(r.Context(), *req.ParentID, articleID)
+			switch {
+			case errors.Is(err, database.ErrParentCommentNotFound):
+				writeJSONError(w, "Komentar yang dibalas tidak ditemukan", http.StatusNotFound)
+				return
+			case errors.Is(err, database.ErrParentCommentMismatch):
+				writeJSONError(w, "Komentar yang dibalas bukan milik artikel ini", http.StatusBadRequest)
+				return
+			case errors.Is(err, database.ErrCommentTooDeep):
+				writeJSONError(w, "Balasan sudah mencapai batas kedalaman", http.StatusBadRequest)
+				return
+			case err != nil:
+				writeJSONError(w, "Error validating parent comment", http.StatusInternalServerError)
+				return
+			}
+		}
+
 		// Ambil claims dari context jika ada (OptionalAuthMiddleware)
 		var userID *int
 		var namaPengguna string
@@ -92,27 +165,39 @@ func (s *Server) handleCreateComment() http.HandlerFunc {
 			}
 		}
 
-		// Tentukan status: user terautentikasi => approved agar muncul setelah reload,
-		// anonymous => pending (sesuaikan kebijakan)
-		status := "approved"
-		
+		// Tentukan status lewat moderasi otomatis (kata terlarang, tautan,
+		// duplikat, skor kepercayaan penulis)
+		decision, err := s.moderateComment(r.Context(), req.Konten, userID, articleID, 0)
+		if err != nil {
+			writeJSONError(w, "Error moderating comment", http.StatusInternalServerError)
+			return
+		}
 
 		// Buat objek database.Comment sesuai signature CreateCommentSimple(*sql.DB, *database.Comment)
 		commentObj := &database.Comment{
-			ArtikelID:    articleID,
-			UserID:       userID,
-			NamaPengguna: &namaPengguna,
-			Konten:       req.Konten,
-			Status:       status,
+			ArtikelID:         articleID,
+			UserID:            userID,
+			NamaPengguna:      &namaPengguna,
+			Konten:            req.Konten,
+			Status:            decision.Status,
+			ParentID:          req.ParentID,
+			Depth:             depth,
+			ModerationScore:   &decision.Score,
+			ModerationReasons: decision.Reasons,
 		}
 
 		// Simpan ke DB (gunakan helper yang ada di package database)
-		comment, err := database.CreateCommentSimple(s.GetDB(), commentObj)
+		comment, err := s.comments.Create(r.Context(), commentObj)
 		if err != nil {
 			writeJSONError(w, "Failed to create comment", http.StatusInternalServerError)
 			return
 		}
 
+		// Alasan moderasi hanya untuk moderator, pengirim tidak boleh tahu
+		// filter mana yang cocok
+		comment.ModerationScore = nil
+		comment.ModerationReasons = nil
+
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(comment)
 	}
@@ -132,14 +217,21 @@ func (s *Server) handleGetUserComments() http.HandlerFunc {
 			return
 		}
 
-		comments, err := database.GetCommentsByUserID(s.GetDB(), claims.UserID)
+		page := parsePagination(r, defaultPageLimit)
+
+		comments, err := s.comments.ListByUser(r.Context(), claims.UserID, page.Limit, page.Offset)
 		if err != nil {
 			writeJSONError(w, "Error fetching comments", http.StatusInternalServerError)
 			return
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(comments)
+		total, err := s.comments.CountByUser(r.Context(), claims.UserID)
+		if err != nil {
+			writeJSONError(w, "Error counting comments", http.StatusInternalServerError)
+			return
+		}
+
+		writePaginated(w, comments, total, page, "")
 	}
 }
 
@@ -161,7 +253,7 @@ func (s *Server) handleUpdateUserComment() http.HandlerFunc {
 		}
 
 		// Cek ownership
-		existingComment, err := database.GetCommentByIDSimple(s.GetDB(), commentID)
+		existingComment, err := s.comments.GetByID(r.Context(), commentID)
 		if err != nil {
 			writeJSONError(w, "Komentar tidak ditemukan", http.StatusNotFound)
 			return
@@ -178,13 +270,19 @@ func (s *Server) handleUpdateUserComment() http.HandlerFunc {
 			return
 		}
 
-		if req.Konten == "" {
-			writeJSONError(w, "Konten komentar harus diisi", http.StatusBadRequest)
+		if err := validateCommentContent(req.Konten); err != nil {
+			writeJSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// Konten baru melewati moderasi otomatis lagi
+		decision, err := s.moderateComment(r.Context(), req.Konten, existingComment.UserID, existingComment.ArtikelID, commentID)
+		if err != nil {
+			writeJSONError(w, "Error moderating comment", http.StatusInternalServerError)
 			return
 		}
 
-		// Update komentar - status kembali ke pending untuk re-moderasi
-		updatedComment, err := database.UpdateCommentSimple(s.GetDB(), commentID, req.Konten, "approved")
+		updatedComment, err := s.comments.Update(r.Context(), commentID, req.Konten, decision.Status, decision.Score, decision.Reasons)
 		if err != nil {
 			writeJSONError(w, "Error updating comment", http.StatusInternalServerError)
 			return
@@ -213,7 +311,7 @@ func (s *Server) handleDeleteUserComment() http.HandlerFunc {
 		}
 
 		// Cek ownership
-		existingComment, err := database.GetCommentByIDSimple(s.GetDB(), commentID)
+		existingComment, err := s.comments.GetByID(r.Context(), commentID)
 		if err != nil {
 			writeJSONError(w, "Komentar tidak ditemukan", http.StatusNotFound)
 			return
@@ -224,7 +322,7 @@ func (s *Server) handleDeleteUserComment() http.HandlerFunc {
 			return
 		}
 
-		err = database.DeleteCommentSimple(s.GetDB(), commentID)
+		err = s.comments.Delete(r.Context(), commentID)
 		if err != nil {
 			writeJSONError(w, "Error deleting comment", http.StatusInternalServerError)
 			return
@@ -248,27 +346,21 @@ func (s *Server) handleGetAllComments() http.HandlerFunc {
 		status := r.URL.Query().Get("status") // pending, approved, rejected, atau kosong untuk semua
 
 		// Parse pagination
-		limit := 50 // default
-		offset := 0
-		if l := r.URL.Query().Get("limit"); l != "" {
-			if parsed, err := strconv.Atoi(l); err == nil {
-				limit = parsed
-			}
-		}
-		if o := r.URL.Query().Get("offset"); o != "" {
-			if parsed, err := strconv.Atoi(o); err == nil {
-				offset = parsed
-			}
-		}
+		page := parsePagination(r, 50)
 
-		comments, err := database.GetAllComments(s.GetDB(), status, limit, offset)
+		comments, err := s.comments.List(r.Context(), status, page.Limit, page.Offset)
 		if err != nil {
 			writeJSONError(w, "Error fetching comments", http.StatusInternalServerError)
 			return
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(comments)
+		total, err := s.comments.Count(r.Context(), status)
+		if err != nil {
+			writeJSONError(w, "Error counting comments", http.StatusInternalServerError)
+			return
+		}
+
+		writePaginated(w, comments, total, page, "")
 	}
 }
 
@@ -301,14 +393,14 @@ func (s *Server) handleModerateComment() http.HandlerFunc {
 		}
 
 		// Cek apakah komentar exists
-		_, err = database.GetCommentByIDSimple(s.GetDB(), commentID)
+		_, err = s.comments.GetByID(r.Context(), commentID)
 		if err != nil {
 			writeJSONError(w, "Komentar tidak ditemukan", http.StatusNotFound)
 			return
 		}
 
 		// Update status
-		updatedComment, err := database.UpdateCommentStatus(s.GetDB(), commentID, req.Status)
+		updatedComment, err := s.comments.UpdateStatus(r.Context(), commentID, req.Status)
 		if err != nil {
 			writeJSONError(w, "Error moderating comment", http.StatusInternalServerError)
 			return
@@ -331,13 +423,13 @@ func (s *Server) handleAdminDeleteComment() http.HandlerFunc {
 		}
 
 		// Cek apakah komentar exists
-		_, err = database.GetCommentByIDSimple(s.GetDB(), commentID)
+		_, err = s.comments.GetByID(r.Context(), commentID)
 		if err != nil {
 			writeJSONError(w, "Komentar tidak ditemukan", http.StatusNotFound)
 			return
 		}
 
-		err = database.DeleteCommentSimple(s.GetDB(), commentID)
+		err = s.comments.Delete(r.Context(), commentID)
 		if err != nil {
 			writeJSONError(w, "Error deleting comment", http.StatusInternalServerError)
 			return
@@ -357,12 +449,13 @@ func (s *Server) handleAdminDeleteComment() http.HandlerFunc {
 // RegisterPublicCommentRoutes - Register public comment routes
 func (s *Server) RegisterPublicCommentRoutes(r *mux.Router) {
 	r.HandleFunc("/articles/{id:[0-9]+}/comments", s.handleGetArticleComments()).Methods("GET")
+	r.HandleFunc("/comments/{id:[0-9]+}/replies", s.handleGetCommentReplies()).Methods("GET")
 }
 
 // RegisterUserCommentRoutes - Register authenticated user comment routes
 func (s *Server) RegisterUserCommentRoutes(r *mux.Router) {
 	r.HandleFunc("/users/me/comments", s.handleGetUserComments()).Methods("GET")
-	r.HandleFunc("/users/me/comments/{id:[0-9]+}", s.handleUpdateUserComment()).Methods("PUT")
+	r.Handle("/users/me/comments/{id:[0-9]+}", s.requireVerifiedEmail(s.handleUpdateUserComment())).Methods("PUT")
 	r.HandleFunc("/users/me/comments/{id:[0-9]+}", s.handleDeleteUserComment()).Methods("DELETE")
 }
 
@@ -0,0 +1,175 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"news-portal-web/api/internal/database"
+)
+
+// postComment creates a comment on articleID and returns it
+func (ts *testServer) postComment(articleID int, token string, req CreateCommentRequest) database.Comment {
+	ts.t.Helper()
+
+	var comment database.Comment
+	ts.expectStatus(http.MethodPost, fmt.Sprintf("/articles/%d/comments", articleID), token, req, &comment, http.StatusOK)
+	return comment
+}
+
+func TestCreateCommentModeration(t *testing.T) {
+	ts := newTestServer(t)
+	editor, _ := ts.createUser("penulis", "editor", true)
+	_, userToken := ts.createUser("pembaca", "user", true)
+	_, unverifiedToken := ts.createUser("baru", "user", false)
+	article := ts.createArticle(editor.UserID, database.ArticleInput{Judul: "Berita", Status: "published"})
+
+	anonymous := ts.postComment(article.ArtikelID, "", CreateCommentRequest{Konten: "Tulisan yang menarik", NamaPengguna: "Tamu"})
+	if anonymous.Status != "pending" || anonymous.NamaPengguna == nil || *anonymous.NamaPengguna != "Tamu" {
+		t.Fatalf("anonymous comment: status %q, name %v", anonymous.Status, anonymous.NamaPengguna)
+	}
+	if anonymous.ModerationScore != nil || anonymous.ModerationReasons != nil {
+		t.Fatalf("moderation result returned to the poster: %v %v", anonymous.ModerationScore, anonymous.ModerationReasons)
+	}
+
+	comment := ts.postComment(article.ArtikelID, userToken, CreateCommentRequest{Konten: "Setuju dengan penulis"})
+	if comment.Status != "approved" || comment.UserID == nil {
+		t.Fatalf("user comment: status %q, user %v", comment.Status, comment.UserID)
+	}
+
+	// Posting the same text again is treated as spam
+	duplicate := ts.postComment(article.ArtikelID, userToken, CreateCommentRequest{Konten: "Setuju dengan penulis"})
+	if duplicate.Status != "rejected" {
+		t.Fatalf("duplicate comment has status %q", duplicate.Status)
+	}
+
+	// Anonymous readers share the "Anonymous" name but are not one author
+	other := ts.createArticle(editor.UserID, database.ArticleInput{Judul: "Berita Lain", Status: "published"})
+	for _, articleID := range []int{article.ArtikelID, other.ArtikelID, other.ArtikelID} {
+		if c := ts.postComment(articleID, "", CreateCommentRequest{Konten: "Setuju"}); c.Status != "pending" {
+			t.Fatalf("anonymous comment on article %d has status %q", articleID, c.Status)
+		}
+	}
+
+	path := fmt.Sprintf("/articles/%d/comments", article.ArtikelID)
+	ts.expectStatus(http.MethodPost, path, unverifiedToken, CreateCommentRequest{Konten: "Halo semua"}, nil, http.StatusForbidden)
+	ts.expectStatus(http.MethodPost, path, "", CreateCommentRequest{Konten: ""}, nil, http.StatusBadRequest)
+
+	// Only approved comments are public
+	var threads []database.Comment
+	if total := ts.listPage(path, "", &threads); total != 1 || threads[0].KomentarID != comment.KomentarID {
+		t.Fatalf("public comments: total %d, got %+v", total, threads)
+	}
+}
+
+func TestCommentThreads(t *testing.T) {
+	ts := newTestServer(t)
+	editor, _ := ts.createUser("penulis", "editor", true)
+	_, token := ts.createUser("pembaca", "user", true)
+	article := ts.createArticle(editor.UserID, database.ArticleInput{Judul: "Berita", Status: "published"})
+	other := ts.createArticle(editor.UserID, database.ArticleInput{Judul: "Lainnya", Status: "published"})
+
+	root := ts.postComment(article.ArtikelID, token, CreateCommentRequest{Konten: "Komentar utama"})
+	reply := ts.postComment(article.ArtikelID, token, CreateCommentRequest{Konten: "Balasan pertama", ParentID: &root.KomentarID})
+	if reply.Depth != 1 || reply.ParentID == nil || *reply.ParentID != root.KomentarID {
+		t.Fatalf("reply has depth %d and parent %v", reply.Depth, reply.ParentID)
+	}
+
+	var threads []database.Comment
+	path := fmt.Sprintf("/articles/%d/comments", article.ArtikelID)
+	ts.listPage(path, "", &threads)
+	if len(threads) != 1 || threads[0].ReplyCount != 1 || len(threads[0].Replies) != 0 {
+		t.Fatalf("threads without tree view: %+v", threads)
+	}
+
+	threads = nil
+	ts.listPage(path+"?view=tree", "", &threads)
+	if len(threads) != 1 || len(threads[0].Replies) != 1 || threads[0].Replies[0].KomentarID != reply.KomentarID {
+		t.Fatalf("threads with tree view: %+v", threads)
+	}
+
+	var replies []database.Comment
+	if total := ts.listPage(fmt.Sprintf("/comments/%d/replies", root.KomentarID), "", &replies); total != 1 {
+		t.Fatalf("listed %d replies, want 1", total)
+	}
+
+	// Replies must target a visible comment on the same article
+	pending := ts.postComment(article.ArtikelID, "", CreateCommentRequest{Konten: "Menunggu moderasi"})
+	ts.expectStatus(http.MethodGet, fmt.Sprintf("/comments/%d/replies", pending.KomentarID), "", nil, nil, http.StatusNotFound)
+	ts.expectStatus(http.MethodPost, path, token, CreateCommentRequest{Konten: "Balasan", ParentID: &pending.KomentarID}, nil, http.StatusNotFound)
+	ts.expectStatus(http.MethodPost, fmt.Sprintf("/articles/%d/comments", other.ArtikelID), token,
+		CreateCommentRequest{Konten: "Salah artikel", ParentID: &root.KomentarID}, nil, http.StatusBadRequest)
+}
+
+func TestUserComments(t *testing.T) {
+	ts := newTestServer(t)
+	editor, _ := ts.createUser("penulis", "editor", true)
+	_, token := ts.createUser("pembaca", "user", true)
+	_, otherToken := ts.createUser("pembaca2", "user", true)
+	article := ts.createArticle(editor.UserID, database.ArticleInput{Judul: "Berita", Status: "published"})
+
+	comment := ts.postComment(article.ArtikelID, token, CreateCommentRequest{Konten: "Komentar awal"})
+
+	var own []database.Comment
+	if total := ts.listPage("/users/me/comments", token, &own); total != 1 {
+		t.Fatalf("listed %d own comments, want 1", total)
+	}
+	if total := ts.listPage("/users/me/comments", otherToken, nil); total != 0 {
+		t.Fatalf("other user listed %d comments, want 0", total)
+	}
+
+	path := fmt.Sprintf("/users/me/comments/%d", comment.KomentarID)
+	ts.expectStatus(http.MethodPut, path, otherToken, CreateCommentRequest{Konten: "Diubah orang lain"}, nil, http.StatusForbidden)
+	ts.expectStatus(http.MethodDelete, path, otherToken, nil, nil, http.StatusForbidden)
+
+	var updated database.Comment
+	ts.expectStatus(http.MethodPut, path, token, CreateCommentRequest{Konten: "Komentar diperbaiki"}, &updated, http.StatusOK)
+	if updated.Konten != "Komentar diperbaiki" || updated.Status != "approved" {
+		t.Fatalf("updated comment: %q with status %q", updated.Konten, updated.Status)
+	}
+	if updated.ModerationScore != nil || updated.ModerationReasons != nil {
+		t.Fatalf("moderation result returned to the poster: %v %v", updated.ModerationScore, updated.ModerationReasons)
+	}
+	// The new moderation result is stored with the new content
+	stored, err := ts.repos.Comments.List(context.Background(), "", 10, 0)
+	if err != nil || len(stored) != 1 || stored[0].ModerationScore == nil {
+		t.Fatalf("edited comment has no stored moderation score: %+v %v", stored, err)
+	}
+
+	ts.expectStatus(http.MethodDelete, path, token, nil, nil, http.StatusOK)
+	ts.expectStatus(http.MethodDelete, path, token, nil, nil, http.StatusNotFound)
+}
+
+func TestAdminCommentModeration(t *testing.T) {
+	ts := newTestServer(t)
+	editor, _ := ts.createUser("penulis", "editor", true)
+	_, token := ts.createUser("admin", "admin", true)
+	article := ts.createArticle(editor.UserID, database.ArticleInput{Judul: "Berita", Status: "published"})
+
+	pending := ts.postComment(article.ArtikelID, "", CreateCommentRequest{Konten: "Menunggu moderasi"})
+
+	var comments []database.Comment
+	if total := ts.listPage("/admin/comments?status=pending", token, &comments); total != 1 || comments[0].KomentarID != pending.KomentarID {
+		t.Fatalf("pending comments: total %d, got %+v", total, comments)
+	}
+	if len(comments[0].ModerationReasons) == 0 {
+		t.Fatal("moderators do not see the moderation reasons")
+	}
+
+	path := fmt.Sprintf("/admin/comments/%d", pending.KomentarID)
+	ts.expectStatus(http.MethodPut, path+"/moderate", token, ModerateCommentRequest{Status: "spam"}, nil, http.StatusBadRequest)
+	ts.expectStatus(http.MethodPut, "/admin/comments/9999/moderate", token, ModerateCommentRequest{Status: "approved"}, nil, http.StatusNotFound)
+
+	var moderated database.Comment
+	ts.expectStatus(http.MethodPut, path+"/moderate", token, ModerateCommentRequest{Status: "approved"}, &moderated, http.StatusOK)
+	if moderated.Status != "approved" {
+		t.Fatalf("moderated comment has status %q", moderated.Status)
+	}
+	if total := ts.listPage(fmt.Sprintf("/articles/%d/comments", article.ArtikelID), "", nil); total != 1 {
+		t.Fatalf("approved comment not public, listed %d", total)
+	}
+
+	ts.expectStatus(http.MethodDelete, path, token, nil, nil, http.StatusOK)
+	ts.expectStatus(http.MethodDelete, path, token, nil, nil, http.StatusNotFound)
+}
@@ -0,0 +1,137 @@
+package server
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"news-portal-web/api/internal/database"
+)
+
+// getEnv returns the value of an environment variable or defaultValue if unset
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// siteName is the portal name shown as feed title
+func siteName() string {
+	return getEnv("SITE_NAME", "Bintaro Times")
+}
+
+// siteURL is the public URL of the portal frontend, used for article links in
+// feeds and sitemaps
+func siteURL() string {
+	return strings.TrimRight(getEnv("SITE_URL", "http://localhost:3000"), "/")
+}
+
+// apiURL is the public URL of this API, used to make upload paths absolute
+func apiURL() string {
+	return strings.TrimRight(getEnv("API_URL", "http://localhost:8080"), "/")
+}
+
+// articleURL returns the public URL of an article page
+func articleURL(slug string) string {
+	return siteURL() + "/article/" + slug
+}
+
+// categoryURL returns the public URL of a category landing page
+func categoryURL(name string) string {
+	return siteURL() + "/" + database.GenerateSlug(name)
+}
+
+// tagURL returns the public URL of a tag landing page
+func tagURL(name string) string {
+	return siteURL() + "/tag/" + database.GenerateSlug(name)
+}
+
+// absoluteURL turns an upload path such as /uploads/articles/x.jpg into an
+// absolute URL. Values that already are absolute are returned unchanged.
+func absoluteURL(path string) string {
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return path
+	}
+	return apiURL() + "/" + strings.TrimLeft(path, "/")
+}
+
+// lockoutPolicyFromEnv reads LOGIN_MAX_FAILURES, LOGIN_FAILURE_WINDOW,
+// LOGIN_LOCKOUT_BASE and LOGIN_LOCKOUT_MAX, falling back to
+// database.DefaultLockoutPolicy
+func lockoutPolicyFromEnv() database.LockoutPolicy {
+	p := database.DefaultLockoutPolicy
+
+	if v, err := strconv.Atoi(os.Getenv("LOGIN_MAX_FAILURES")); err == nil && v > 0 {
+		p.MaxFailures = v
+	}
+	if v, err := time.ParseDuration(os.Getenv("LOGIN_FAILURE_WINDOW")); err == nil && v > 0 {
+		p.Window = v
+	}
+	if v, err := time.ParseDuration(os.Getenv("LOGIN_LOCKOUT_BASE")); err == nil && v > 0 {
+		p.BaseDuration = v
+	}
+	if v, err := time.ParseDuration(os.Getenv("LOGIN_LOCKOUT_MAX")); err == nil && v >= p.BaseDuration {
+		p.MaxDuration = v
+	}
+
+	return p
+}
+
+// passwordResetTTL is how long a password reset link stays valid,
+// PASSWORD_RESET_TTL (default 1h)
+func passwordResetTTL() time.Duration {
+	if v, err := time.ParseDuration(os.Getenv("PASSWORD_RESET_TTL")); err == nil && v > 0 {
+		return v
+	}
+	return time.Hour
+}
+
+// emailVerificationTTL is how long an email verification link stays valid,
+// EMAIL_VERIFICATION_TTL (default 24h)
+func emailVerificationTTL() time.Duration {
+	if v, err := time.ParseDuration(os.Getenv("EMAIL_VERIFICATION_TTL")); err == nil && v > 0 {
+		return v
+	}
+	return 24 * time.Hour
+}
+
+// invitationTTL is how long an invitation link stays valid, INVITATION_TTL
+// (default 72h)
+func invitationTTL() time.Duration {
+	if v, err := time.ParseDuration(os.Getenv("INVITATION_TTL")); err == nil && v > 0 {
+		return v
+	}
+	return 72 * time.Hour
+}
+
+// permissionCacheTTL is how long role permissions are cached before being
+// reloaded, PERMISSION_CACHE_TTL (default 1m). Changes made through this
+// instance apply immediately; other replicas pick them up after the TTL.
+func permissionCacheTTL() time.Duration {
+	if v, err := time.ParseDuration(os.Getenv("PERMISSION_CACHE_TTL")); err == nil && v > 0 {
+		return v
+	}
+	return time.Minute
+}
+
+// trashRetention is how long deleted items stay in the trash before being
+// purged, TRASH_RETENTION_DAYS (default 30). 0 disables automatic purging.
+func trashRetention() time.Duration {
+	days := 30
+	if v, err := strconv.Atoi(os.Getenv("TRASH_RETENTION_DAYS")); err == nil && v >= 0 {
+		days = v
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// requestTimeout is the deadline for handling one request, REQUEST_TIMEOUT
+// (default 30s). Database queries still running when it passes are
+// cancelled.
+func requestTimeout() time.Duration {
+	if v, err := time.ParseDuration(os.Getenv("REQUEST_TIMEOUT")); err == nil && v > 0 {
+		return v
+	}
+	return 30 * time.Second
+}
@@ -0,0 +1,169 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"news-portal-web/api/internal/database"
+	"news-portal-web/api/internal/mailer"
+
+	"github.com/gorilla/mux"
+)
+
+// ========================================
+// EMAIL VERIFICATION
+// ========================================
+
+// sendVerificationEmail creates a verification token for the user's current
+// address and mails the link
+func (s *Server) sendVerificationEmail(ctx context.Context, user *database.User) error {
+	token, hash, err := newOneTimeToken()
+	if err != nil {
+		return err
+	}
+
+	ttl := emailVerificationTTL()
+	if err := database.CreateEmailVerificationToken(ctx, s.GetDB(), user.UserID, user.Email, hash, time.Now().Add(ttl)); err != nil {
+		return err
+	}
+
+	s.sendMail(mailer.Message{
+		To:      user.Email,
+		Subject: "Verifikasi email " + siteName(),
+		Body: fmt.Sprintf(`Halo %s,
+
+Terima kasih telah mendaftar di %s. Buka link berikut untuk memverifikasi alamat email Anda:
+
+%s
+
+Link ini berlaku selama %s. Jika Anda tidak mendaftar, abaikan email ini.
+
+%s`, user.Username, siteName(), emailVerificationURL(token), formatTTL(ttl), siteName()),
+	})
+	return nil
+}
+
+// requireVerifiedEmail rejects logged-in users whose email is not verified
+// yet. Anonymous requests pass through, so it can follow
+// OptionalAuthMiddleware.
+func (s *Server) requireVerifiedEmail(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := getUserIDFromContext(r.Context())
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Read from the database, tokens issued before verification would
+		// otherwise stay restricted until they expire
+		verified, err := s.users.IsEmailVerified(r.Context(), userID)
+		if err != nil {
+			writeJSONError(w, "Error checking email verification", http.StatusInternalServerError)
+			return
+		}
+		if !verified {
+			writeJSONError(w, "Verifikasi email Anda terlebih dahulu", http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// VerifyEmailRequest - Request body untuk verifikasi email
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+// handleVerifyEmail - POST /api/v1/auth/verify-email
+func (s *Server) handleVerifyEmail() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req VerifyEmailRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		if req.Token == "" {
+			writeJSONError(w, "Token harus diisi", http.StatusBadRequest)
+			return
+		}
+
+		if _, err := database.VerifyEmailWithToken(r.Context(), s.GetDB(), hashToken(req.Token)); err != nil {
+			if errors.Is(err, database.ErrVerificationTokenInvalid) {
+				writeJSONError(w, "Link verifikasi tidak valid atau sudah kedaluwarsa", http.StatusBadRequest)
+				return
+			}
+			writeJSONError(w, "Gagal memverifikasi email", http.StatusInternalServerError)
+			return
+		}
+
+		writeJSONSuccess(w, "Email berhasil diverifikasi", nil, http.StatusOK)
+	}
+}
+
+// handleResendVerification - POST /api/v1/auth/resend-verification
+func (s *Server) handleResendVerification() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := getUserIDFromContext(r.Context())
+		if !ok {
+			writeJSONError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		user, err := s.users.GetByID(r.Context(), userID)
+		if err != nil {
+			writeJSONError(w, "User tidak ditemukan", http.StatusNotFound)
+			return
+		}
+
+		if user.IsEmailVerified() {
+			writeJSONError(w, "Email sudah terverifikasi", http.StatusConflict)
+			return
+		}
+
+		if err := s.sendVerificationEmail(r.Context(), user); err != nil {
+			log.Printf("⚠️  Failed to create verification token: %v", err)
+			writeJSONError(w, "Gagal mengirim email verifikasi", http.StatusInternalServerError)
+			return
+		}
+
+		writeJSONSuccess(w, "Email verifikasi telah dikirim", nil, http.StatusOK)
+	}
+}
+
+// handleAdminVerifyEmail - POST /api/v1/admin/users/{id}/verify-email
+func (s *Server) handleAdminVerifyEmail() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			writeJSONError(w, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+
+		if err := s.users.MarkEmailVerified(r.Context(), userID); err != nil {
+			if err == sql.ErrNoRows {
+				writeJSONError(w, "User tidak ditemukan", http.StatusNotFound)
+				return
+			}
+			writeJSONError(w, "Gagal memverifikasi email", http.StatusInternalServerError)
+			return
+		}
+
+		writeJSONSuccess(w, "Email user berhasil diverifikasi", nil, http.StatusOK)
+	}
+}
+
+// emailVerificationURL returns the frontend page where a verification token
+// is used
+func emailVerificationURL(token string) string {
+	return siteURL() + "/verify-email?token=" + url.QueryEscape(token)
+}
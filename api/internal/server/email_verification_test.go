@@ -0,0 +1,37 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestVerifyEmail(t *testing.T) {
+	ts := newTestServer(t)
+
+	ts.expectStatus(http.MethodPost, "/auth/verify-email", "", VerifyEmailRequest{}, nil, http.StatusBadRequest)
+
+	ts.mock.ExpectBegin()
+	ts.mock.ExpectQuery(`UPDATE email_verification_tokens\s+SET used_at = NOW\(\)`).WithArgs(hashToken("kedaluwarsa")).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "email"}))
+	ts.mock.ExpectRollback()
+	ts.expectStatus(http.MethodPost, "/auth/verify-email", "", VerifyEmailRequest{Token: "kedaluwarsa"}, nil, http.StatusBadRequest)
+
+	// The token only verifies the address it was sent to
+	ts.mock.ExpectBegin()
+	ts.mock.ExpectQuery(`UPDATE email_verification_tokens`).WithArgs(hashToken("lama")).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "email"}).AddRow(3, "lama@example.com"))
+	ts.mock.ExpectExec(`UPDATE users\s+SET email_verified_at`).WithArgs(3, "lama@example.com").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	ts.mock.ExpectRollback()
+	ts.expectStatus(http.MethodPost, "/auth/verify-email", "", VerifyEmailRequest{Token: "lama"}, nil, http.StatusBadRequest)
+
+	ts.mock.ExpectBegin()
+	ts.mock.ExpectQuery(`UPDATE email_verification_tokens`).WithArgs(hashToken("baru")).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "email"}).AddRow(3, "baru@example.com"))
+	ts.mock.ExpectExec(`UPDATE users\s+SET email_verified_at`).WithArgs(3, "baru@example.com").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	ts.mock.ExpectCommit()
+	ts.expectStatus(http.MethodPost, "/auth/verify-email", "", VerifyEmailRequest{Token: "baru"}, nil, http.StatusOK)
+}
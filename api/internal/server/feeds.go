@@ -0,0 +1,425 @@
+package server
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"news-portal-web/api/internal/database"
+
+	"github.com/gorilla/mux"
+)
+
+// ========================================
+// RSS & ATOM FEEDS
+// ========================================
+
+// feedItemLimit is the number of latest articles included in each feed
+const feedItemLimit = 20
+
+// feedSource is the channel data shared by the RSS and Atom renderers
+type feedSource struct {
+	Title       string
+	Description string
+	SelfURL     string
+	Articles    []database.Article
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string      `xml:"title"`
+	Link          string      `xml:"link"`
+	Description   string      `xml:"description"`
+	Language      string      `xml:"language"`
+	LastBuildDate string      `xml:"lastBuildDate,omitempty"`
+	AtomLink      rssAtomLink `xml:"atom:link"`
+	Items         []rssItem   `xml:"item"`
+}
+
+type rssAtomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssItem struct {
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link"`
+	GUID        rssGUID       `xml:"guid"`
+	Description string        `xml:"description,omitempty"`
+	Categories  []string      `xml:"category"`
+	PubDate     string        `xml:"pubDate,omitempty"`
+	Enclosure   *rssEnclosure `xml:"enclosure"`
+}
+
+type rssGUID struct {
+	IsPermaLink string `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Length int64  `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	ID       string      `xml:"id"`
+	Updated  string      `xml:"updated"`
+	Author   atomPerson  `xml:"author"`
+	Links    []atomLink  `xml:"link"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Href   string `xml:"href,attr"`
+	Rel    string `xml:"rel,attr,omitempty"`
+	Type   string `xml:"type,attr,omitempty"`
+	Length int64  `xml:"length,attr,omitempty"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Links      []atomLink     `xml:"link"`
+	Published  string         `xml:"published,omitempty"`
+	Updated    string         `xml:"updated"`
+	Author     *atomPerson    `xml:"author"`
+	Summary    string         `xml:"summary,omitempty"`
+	Categories []atomCategory `xml:"category"`
+}
+
+// handleRSSFeed - GET /feed.xml
+func (s *Server) handleRSSFeed() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.serveSiteFeed(w, r, "rss", "/feed.xml")
+	}
+}
+
+// handleAtomFeed - GET /feed/atom.xml
+func (s *Server) handleAtomFeed() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.serveSiteFeed(w, r, "atom", "/feed/atom.xml")
+	}
+}
+
+func (s *Server) serveSiteFeed(w http.ResponseWriter, r *http.Request, format, path string) {
+	articles, err := s.articles.List(r.Context(), database.ArticleFilter{
+		Status: "published",
+		Limit:  feedItemLimit,
+	})
+	if err != nil {
+		http.Error(w, "Error fetching articles", http.StatusInternalServerError)
+		return
+	}
+
+	s.writeFeed(w, r, format, feedSource{
+		Title:       siteName(),
+		Description: "Berita terbaru dari " + siteName(),
+		SelfURL:     apiURL() + path,
+		Articles:    articles,
+	})
+}
+
+// handleCategoryFeed - GET /kategori/{name}/feed.xml
+func (s *Server) handleCategoryFeed() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		category, err := s.taxonomy.GetCategoryByName(r.Context(), mux.Vars(r)["name"])
+		if err != nil {
+			if strings.Contains(err.Error(), "not found") {
+				http.Error(w, "Category not found", http.StatusNotFound)
+				return
+			}
+			http.Error(w, "Error fetching category", http.StatusInternalServerError)
+			return
+		}
+
+		articles, err := s.articles.ListByCategory(r.Context(), category.KategoriID, feedItemLimit, 0)
+		if err != nil {
+			http.Error(w, "Error fetching articles", http.StatusInternalServerError)
+			return
+		}
+
+		s.writeFeed(w, r, "rss", feedSource{
+			Title:       siteName() + " - " + category.NamaKategori,
+			Description: "Berita terbaru kategori " + category.NamaKategori,
+			SelfURL:     apiURL() + r.URL.Path,
+			Articles:    articles,
+		})
+	}
+}
+
+// handleTagFeed - GET /tag/{name}/feed.xml
+func (s *Server) handleTagFeed() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tag, err := s.taxonomy.GetTagByName(r.Context(), mux.Vars(r)["name"])
+		if err != nil {
+			if strings.Contains(err.Error(), "not found") {
+				http.Error(w, "Tag not found", http.StatusNotFound)
+				return
+			}
+			http.Error(w, "Error fetching tag", http.StatusInternalServerError)
+			return
+		}
+
+		articles, err := s.articles.ListByTag(r.Context(), tag.TagID, feedItemLimit, 0)
+		if err != nil {
+			http.Error(w, "Error fetching articles", http.StatusInternalServerError)
+			return
+		}
+
+		s.writeFeed(w, r, "rss", feedSource{
+			Title:       siteName() + " - #" + tag.NamaTag,
+			Description: "Berita terbaru dengan tag " + tag.NamaTag,
+			SelfURL:     apiURL() + r.URL.Path,
+			Articles:    articles,
+		})
+	}
+}
+
+// writeFeed renders the feed in the requested format, answering with
+// 304 Not Modified when the client already has the current version
+func (s *Server) writeFeed(w http.ResponseWriter, r *http.Request, format string, src feedSource) {
+	lastModified := latestUpdate(src.Articles)
+	etag := articlesETag(format+src.SelfURL, src.Articles)
+
+	if checkNotModified(w, r, etag, lastModified) {
+		return
+	}
+
+	var doc interface{}
+	contentType := "application/rss+xml; charset=utf-8"
+	if format == "atom" {
+		doc = s.buildAtomFeed(r.Context(), src, lastModified)
+		contentType = "application/atom+xml; charset=utf-8"
+	} else {
+		doc = s.buildRSSFeed(r.Context(), src, lastModified)
+	}
+
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		http.Error(w, "Error generating feed", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Write([]byte(xml.Header))
+	w.Write(out)
+}
+
+func (s *Server) buildRSSFeed(ctx context.Context, src feedSource, lastModified time.Time) rssFeed {
+	channel := rssChannel{
+		Title:       src.Title,
+		Link:        siteURL(),
+		Description: src.Description,
+		Language:    "id",
+		AtomLink:    rssAtomLink{Href: src.SelfURL, Rel: "self", Type: "application/rss+xml"},
+		Items:       []rssItem{},
+	}
+	if !lastModified.IsZero() {
+		channel.LastBuildDate = lastModified.UTC().Format(time.RFC1123Z)
+	}
+
+	for _, a := range src.Articles {
+		link := articleURL(a.Slug)
+		item := rssItem{
+			Title:       a.Judul,
+			Link:        link,
+			GUID:        rssGUID{IsPermaLink: "true", Value: link},
+			Description: articleSummary(a),
+		}
+		if a.TanggalPublikasi != nil {
+			item.PubDate = a.TanggalPublikasi.UTC().Format(time.RFC1123Z)
+		}
+		for _, k := range a.Kategori {
+			item.Categories = append(item.Categories, k.NamaKategori)
+		}
+		if a.GambarUtama != nil && *a.GambarUtama != "" {
+			url, length, mimeType := s.imageEnclosure(ctx, *a.GambarUtama)
+			item.Enclosure = &rssEnclosure{URL: url, Length: length, Type: mimeType}
+		}
+		channel.Items = append(channel.Items, item)
+	}
+
+	return rssFeed{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		Channel: channel,
+	}
+}
+
+func (s *Server) buildAtomFeed(ctx context.Context, src feedSource, lastModified time.Time) atomFeed {
+	if lastModified.IsZero() {
+		lastModified = time.Now()
+	}
+
+	feed := atomFeed{
+		Title:    src.Title,
+		Subtitle: src.Description,
+		ID:       src.SelfURL,
+		Updated:  lastModified.UTC().Format(time.RFC3339),
+		Author:   atomPerson{Name: siteName()},
+		Links: []atomLink{
+			{Href: src.SelfURL, Rel: "self", Type: "application/atom+xml"},
+			{Href: siteURL(), Rel: "alternate", Type: "text/html"},
+		},
+		Entries: []atomEntry{},
+	}
+
+	for _, a := range src.Articles {
+		link := articleURL(a.Slug)
+		entry := atomEntry{
+			Title:   a.Judul,
+			ID:      link,
+			Links:   []atomLink{{Href: link, Rel: "alternate", Type: "text/html"}},
+			Updated: a.TanggalDiperbarui.UTC().Format(time.RFC3339),
+			Summary: articleSummary(a),
+		}
+		if a.TanggalPublikasi != nil {
+			entry.Published = a.TanggalPublikasi.UTC().Format(time.RFC3339)
+		}
+		if a.Penulis != nil && *a.Penulis != "" {
+			entry.Author = &atomPerson{Name: *a.Penulis}
+		}
+		for _, k := range a.Kategori {
+			entry.Categories = append(entry.Categories, atomCategory{Term: k.NamaKategori})
+		}
+		if a.GambarUtama != nil && *a.GambarUtama != "" {
+			url, length, mimeType := s.imageEnclosure(ctx, *a.GambarUtama)
+			entry.Links = append(entry.Links, atomLink{Href: url, Rel: "enclosure", Type: mimeType, Length: length})
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	return feed
+}
+
+// ========================================
+// HELPERS
+// ========================================
+
+var htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// articleSummary returns the excerpt, or the first 300 characters of the
+// content with HTML tags removed
+func articleSummary(a database.Article) string {
+	if a.Excerpt != nil && *a.Excerpt != "" {
+		return *a.Excerpt
+	}
+
+	text := strings.Join(strings.Fields(htmlTagPattern.ReplaceAllString(a.Konten, " ")), " ")
+	runes := []rune(text)
+	if len(runes) > 300 {
+		return string(runes[:300]) + "…"
+	}
+	return text
+}
+
+// imageEnclosure returns the absolute URL, size and MIME type of an article
+// image. The size is read from storage for uploaded images and is 0 otherwise.
+func (s *Server) imageEnclosure(ctx context.Context, path string) (string, int64, string) {
+	mimeType := mime.TypeByExtension(strings.ToLower(filepath.Ext(path)))
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+
+	var length int64
+	if key, ok := s.storage.KeyFromURL(path); ok {
+		if obj, err := s.storage.Stat(ctx, key); err == nil {
+			length = obj.Size
+		}
+	}
+
+	return absoluteURL(path), length, mimeType
+}
+
+// latestUpdate returns the most recent tanggal_diperbarui, truncated to
+// seconds as HTTP dates have no sub-second precision
+func latestUpdate(articles []database.Article) time.Time {
+	var latest time.Time
+	for _, a := range articles {
+		if a.TanggalDiperbarui.After(latest) {
+			latest = a.TanggalDiperbarui
+		}
+	}
+	return latest.Truncate(time.Second)
+}
+
+// articlesETag derives a strong ETag from the feed key and the ID and update
+// time of each article, so any edit, addition or removal changes it
+func articlesETag(key string, articles []database.Article) string {
+	h := sha1.New()
+	h.Write([]byte(key))
+	for _, a := range articles {
+		fmt.Fprintf(h, "|%d:%d", a.ArtikelID, a.TanggalDiperbarui.UnixNano())
+	}
+	return `"` + hex.EncodeToString(h.Sum(nil))[:20] + `"`
+}
+
+// checkNotModified sets ETag/Last-Modified and writes 304 if the request's
+// If-None-Match or If-Modified-Since shows the client is up to date
+func checkNotModified(w http.ResponseWriter, r *http.Request, etag string, lastModified time.Time) bool {
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	// If-None-Match takes precedence over If-Modified-Since (RFC 9110)
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == etag || candidate == "*" {
+				w.WriteHeader(http.StatusNotModified)
+				return true
+			}
+		}
+		return false
+	}
+
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" && !lastModified.IsZero() {
+		if t, err := http.ParseTime(ims); err == nil && !lastModified.After(t) {
+			w.WriteHeader(http.StatusNotModified)
+			return true
+		}
+	}
+
+	return false
+}
+
+// ========================================
+// ROUTE REGISTRATION
+// ========================================
+
+// RegisterFeedRoutes registers the RSS and Atom feed routes on the root router
+func (s *Server) RegisterFeedRoutes(r *mux.Router) {
+	r.HandleFunc("/feed.xml", s.handleRSSFeed()).Methods("GET", "HEAD")
+	r.HandleFunc("/feed/atom.xml", s.handleAtomFeed()).Methods("GET", "HEAD")
+	r.HandleFunc("/kategori/{name}/feed.xml", s.handleCategoryFeed()).Methods("GET", "HEAD")
+	r.HandleFunc("/tag/{name}/feed.xml", s.handleTagFeed()).Methods("GET", "HEAD")
+}
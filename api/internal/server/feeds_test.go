@@ -0,0 +1,68 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"news-portal-web/api/internal/database"
+)
+
+func TestSiteFeeds(t *testing.T) {
+	ts := newTestServer(t)
+	editor, _ := ts.createUser("penulis", "editor", true)
+
+	ts.createArticle(editor.UserID, database.ArticleInput{Judul: "Banjir di Jakarta", Status: "published"})
+	ts.createArticle(editor.UserID, database.ArticleInput{Judul: "Rancangan Rahasia"})
+
+	for _, path := range []string{"/feed.xml", "/feed/atom.xml"} {
+		status, body := ts.get(path)
+		if status != http.StatusOK {
+			t.Fatalf("%s: status %d", path, status)
+		}
+		if !strings.Contains(body, "Banjir di Jakarta") {
+			t.Errorf("%s is missing the published article", path)
+		}
+		if strings.Contains(body, "Rancangan Rahasia") {
+			t.Errorf("%s lists a draft", path)
+		}
+	}
+}
+
+func TestTaxonomyFeeds(t *testing.T) {
+	ts := newTestServer(t)
+	editor, _ := ts.createUser("penulis", "editor", true)
+	ctx := context.Background()
+
+	category, err := ts.repos.Taxonomy.CreateCategory(ctx, &database.CategoryRequest{NamaKategori: "Politik"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag, err := ts.repos.Taxonomy.CreateTag(ctx, &database.TagRequest{NamaTag: "pemilu"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts.createArticle(editor.UserID, database.ArticleInput{
+		Judul: "Hasil Pemilu", Status: "published",
+		KategoriIDs: []int{category.KategoriID}, TagIDs: []int{tag.TagID},
+	})
+	ts.createArticle(editor.UserID, database.ArticleInput{Judul: "Cuaca Cerah", Status: "published"})
+
+	for _, path := range []string{"/kategori/Politik/feed.xml", "/tag/pemilu/feed.xml"} {
+		status, body := ts.get(path)
+		if status != http.StatusOK {
+			t.Fatalf("%s: status %d", path, status)
+		}
+		if !strings.Contains(body, "Hasil Pemilu") || strings.Contains(body, "Cuaca Cerah") {
+			t.Errorf("%s lists the wrong articles: %s", path, body)
+		}
+	}
+
+	if status, _ := ts.get("/kategori/Ekonomi/feed.xml"); status != http.StatusNotFound {
+		t.Errorf("unknown category feed: status %d, want 404", status)
+	}
+	if status, _ := ts.get("/tag/tidak-ada/feed.xml"); status != http.StatusNotFound {
+		t.Errorf("unknown tag feed: status %d, want 404", status)
+	}
+}
@@ -0,0 +1,268 @@
+package server
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"news-portal-web/api/internal/database"
+	"news-portal-web/api/internal/mailer"
+
+	"github.com/gorilla/mux"
+)
+
+// ========================================
+// INVITATION HANDLERS (ADMIN)
+// ========================================
+
+// InviteUserRequest - Request body untuk mengundang user
+type InviteUserRequest struct {
+	Email string `json:"email"`
+	Role  string `json:"role"` // default editor
+}
+
+// handleCreateInvitation - POST /api/v1/admin/invitations
+func (s *Server) handleCreateInvitation() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		adminID, ok := getUserIDFromContext(r.Context())
+		if !ok {
+			writeJSONError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		var req InviteUserRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		req.Email = strings.TrimSpace(req.Email)
+		if !isValidEmail(req.Email) {
+			writeJSONError(w, "Format email tidak valid", http.StatusBadRequest)
+			return
+		}
+		if req.Role == "" {
+			req.Role = "editor"
+		}
+		if !s.checkRole(w, r, req.Role) {
+			return
+		}
+
+		exists, err := s.users.EmailExists(r.Context(), req.Email)
+		if err != nil {
+			writeJSONError(w, "Error checking email", http.StatusInternalServerError)
+			return
+		}
+		if exists {
+			writeJSONError(w, "Email sudah terdaftar", http.StatusConflict)
+			return
+		}
+
+		token, hash, err := newOneTimeToken()
+		if err != nil {
+			writeJSONError(w, "Gagal membuat undangan", http.StatusInternalServerError)
+			return
+		}
+
+		ttl := invitationTTL()
+		invitation, err := database.CreateInvitation(r.Context(), s.GetDB(), req.Email, req.Role, hash, adminID, time.Now().Add(ttl))
+		if err != nil {
+			log.Printf("⚠️  Failed to create invitation: %v", err)
+			writeJSONError(w, "Gagal membuat undangan", http.StatusInternalServerError)
+			return
+		}
+
+		s.sendMail(mailer.Message{
+			To:      invitation.Email,
+			Subject: "Undangan bergabung dengan " + siteName(),
+			Body: fmt.Sprintf(`Halo,
+
+Anda diundang untuk bergabung dengan %s sebagai %s. Buka link berikut untuk membuat akun dan password Anda:
+
+%s
+
+Undangan ini berlaku selama %s dan hanya dapat digunakan sekali.
+
+%s`, siteName(), invitation.Role, invitationURL(token), formatTTL(ttl), siteName()),
+		})
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(invitation)
+	}
+}
+
+// handleListInvitations - GET /api/v1/admin/invitations
+func (s *Server) handleListInvitations() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		invitations, err := database.ListPendingInvitations(r.Context(), s.GetDB())
+		if err != nil {
+			writeJSONError(w, "Error fetching invitations", http.StatusInternalServerError)
+			return
+		}
+
+		page := parsePagination(r, defaultPageLimit)
+		writePaginated(w, paginateSlice(invitations, page), len(invitations), page, "")
+	}
+}
+
+// handleDeleteInvitation - DELETE /api/v1/admin/invitations/{id}
+func (s *Server) handleDeleteInvitation() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			writeJSONError(w, "Invalid invitation ID", http.StatusBadRequest)
+			return
+		}
+
+		if err := database.DeleteInvitation(r.Context(), s.GetDB(), id); err != nil {
+			if err == sql.ErrNoRows {
+				writeJSONError(w, "Undangan tidak ditemukan", http.StatusNotFound)
+				return
+			}
+			writeJSONError(w, "Error deleting invitation", http.StatusInternalServerError)
+			return
+		}
+
+		writeJSONSuccess(w, "Undangan berhasil dibatalkan", nil, http.StatusOK)
+	}
+}
+
+// ========================================
+// INVITATION HANDLERS (PUBLIC)
+// ========================================
+
+// handleGetInvitation - GET /api/v1/auth/invitation?token=...
+// Menampilkan email dan role undangan sebelum invitee membuat akun
+func (s *Server) handleGetInvitation() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Query().Get("token")
+		if token == "" {
+			writeJSONError(w, "Token harus diisi", http.StatusBadRequest)
+			return
+		}
+
+		invitation, err := database.GetPendingInvitationByToken(r.Context(), s.GetDB(), hashToken(token))
+		if err != nil {
+			if errors.Is(err, database.ErrInvitationInvalid) {
+				writeJSONError(w, "Undangan tidak valid atau sudah kedaluwarsa", http.StatusNotFound)
+				return
+			}
+			writeJSONError(w, "Error fetching invitation", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"email":      invitation.Email,
+			"role":       invitation.Role,
+			"expires_at": invitation.ExpiresAt,
+		})
+	}
+}
+
+// AcceptInvitationRequest - Request body untuk menerima undangan
+type AcceptInvitationRequest struct {
+	Token    string `json:"token"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// handleAcceptInvitation - POST /api/v1/auth/accept-invite
+func (s *Server) handleAcceptInvitation() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req AcceptInvitationRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(w, "Invalid request payload", http.StatusBadRequest)
+			return
+		}
+
+		if req.Token == "" {
+			writeJSONError(w, "Token harus diisi", http.StatusBadRequest)
+			return
+		}
+		hash := hashToken(req.Token)
+
+		invitation, err := database.GetPendingInvitationByToken(r.Context(), s.GetDB(), hash)
+		if err != nil {
+			if errors.Is(err, database.ErrInvitationInvalid) {
+				writeJSONError(w, "Undangan tidak valid atau sudah kedaluwarsa", http.StatusBadRequest)
+				return
+			}
+			writeJSONError(w, "Error fetching invitation", http.StatusInternalServerError)
+			return
+		}
+
+		// Email and role come from the invitation, not from the request
+		userReq := database.UserRequest{
+			Username: req.Username,
+			Email:    invitation.Email,
+			Password: req.Password,
+			Role:     invitation.Role,
+		}
+		if err := validateUserRequest(&userReq); err != nil {
+			writeJSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		usernameExists, err := s.users.UsernameExists(r.Context(), req.Username)
+		if err != nil {
+			writeJSONError(w, "Error checking username", http.StatusInternalServerError)
+			return
+		}
+		if usernameExists {
+			writeJSONError(w, "Username sudah digunakan", http.StatusConflict)
+			return
+		}
+
+		user, err := database.AcceptInvitation(r.Context(), s.GetDB(), hash, req.Username, req.Password)
+		if err != nil {
+			switch {
+			case errors.Is(err, database.ErrInvitationInvalid):
+				writeJSONError(w, "Undangan tidak valid atau sudah kedaluwarsa", http.StatusBadRequest)
+			case errors.Is(err, database.ErrUserExists):
+				writeJSONError(w, "Email atau username sudah terdaftar", http.StatusConflict)
+			default:
+				writeJSONError(w, "Gagal membuat user", http.StatusInternalServerError)
+			}
+			return
+		}
+
+		tokenPair, err := s.GetJWTManager().GenerateTokenPair(r.Context(), user.UserID, user.Username, user.Email, user.Role)
+		if err != nil {
+			writeJSONError(w, "Failed to generate tokens", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": "Akun berhasil dibuat",
+			"user":    user.ToPublic(),
+			"tokens":  tokenPair,
+		})
+	}
+}
+
+// invitationURL returns the frontend page where an invitation is accepted
+func invitationURL(token string) string {
+	return siteURL() + "/accept-invite?token=" + url.QueryEscape(token)
+}
+
+// ========================================
+// ROUTE REGISTRATION
+// ========================================
+
+// RegisterAdminInvitationRoutes registers invitation management routes
+func (s *Server) RegisterAdminInvitationRoutes(r *mux.Router) {
+	r.HandleFunc("/invitations", s.handleListInvitations()).Methods("GET")
+	r.HandleFunc("/invitations", s.handleCreateInvitation()).Methods("POST")
+	r.HandleFunc("/invitations/{id:[0-9]+}", s.handleDeleteInvitation()).Methods("DELETE")
+}
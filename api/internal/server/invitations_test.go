@@ -0,0 +1,123 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"news-portal-web/api/internal/database"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+var invitationColumns = []string{
+	"invitation_id", "email", "role", "invited_by", "expires_at", "accepted_at", "accepted_user_id", "created_at",
+}
+
+// invitationRows returns one pending invitation row
+func invitationRows(id int, email, role string, invitedBy int) *sqlmock.Rows {
+	return sqlmock.NewRows(invitationColumns).
+		AddRow(id, email, role, invitedBy, time.Now().Add(time.Hour), nil, nil, time.Now())
+}
+
+func TestCreateInvitation(t *testing.T) {
+	ts := newTestServer(t)
+	admin, token := ts.createUser("admin", "admin", true)
+	ts.createUser("penulis", "editor", true)
+
+	tests := []struct {
+		name string
+		req  InviteUserRequest
+		want int
+	}{
+		{"invalid email", InviteUserRequest{Email: "bukan-email"}, http.StatusBadRequest},
+		{"unknown role", InviteUserRequest{Email: "baru@example.com", Role: "wartawan"}, http.StatusBadRequest},
+		{"registered email", InviteUserRequest{Email: "penulis@example.com"}, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts.expectStatus(http.MethodPost, "/admin/invitations", token, tt.req, nil, tt.want)
+		})
+	}
+
+	ts.mock.ExpectBegin()
+	ts.mock.ExpectExec(`DELETE FROM user_invitations WHERE LOWER\(email\) = LOWER\(\$1\) AND accepted_at IS NULL`).
+		WithArgs("baru@example.com").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	ts.mock.ExpectQuery("INSERT INTO user_invitations").
+		WithArgs("baru@example.com", "editor", sqlmock.AnyArg(), admin.UserID, sqlmock.AnyArg()).
+		WillReturnRows(invitationRows(3, "baru@example.com", "editor", admin.UserID))
+	ts.expectSnapshot("user_invitations")
+	ts.expectAudit(admin.UserID, "invitation.create", "invitation", "3")
+	ts.mock.ExpectCommit()
+
+	// Role defaults to editor
+	var invitation database.UserInvitation
+	ts.expectStatus(http.MethodPost, "/admin/invitations", token, InviteUserRequest{Email: " baru@example.com "}, &invitation, http.StatusCreated)
+	if invitation.InvitationID != 3 || invitation.Role != "editor" {
+		t.Errorf("invitation = %+v", invitation)
+	}
+}
+
+func TestManageInvitations(t *testing.T) {
+	ts := newTestServer(t)
+	admin, token := ts.createUser("admin", "admin", true)
+
+	ts.mock.ExpectQuery(`FROM user_invitations\s+WHERE accepted_at IS NULL`).
+		WillReturnRows(invitationRows(3, "baru@example.com", "editor", admin.UserID))
+
+	var invitations []database.UserInvitation
+	if total := ts.listPage("/admin/invitations", token, &invitations); total != 1 || len(invitations) != 1 {
+		t.Fatalf("invitations: total %d, got %d", total, len(invitations))
+	}
+
+	ts.mock.ExpectBegin()
+	ts.expectSnapshot("user_invitations")
+	ts.mock.ExpectExec(`DELETE FROM user_invitations WHERE invitation_id = \$1 AND accepted_at IS NULL`).
+		WithArgs(3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	ts.mock.ExpectQuery("FROM user_invitations t").WillReturnRows(sqlmock.NewRows([]string{"snapshot"}))
+	ts.expectAudit(admin.UserID, "invitation.delete", "invitation", "3")
+	ts.mock.ExpectCommit()
+	ts.expectStatus(http.MethodDelete, "/admin/invitations/3", token, nil, nil, http.StatusOK)
+
+	ts.mock.ExpectBegin()
+	ts.mock.ExpectQuery("FROM user_invitations t").WithArgs(4, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"snapshot"}))
+	ts.mock.ExpectRollback()
+	ts.expectStatus(http.MethodDelete, "/admin/invitations/4", token, nil, nil, http.StatusNotFound)
+}
+
+func TestAcceptInvitation(t *testing.T) {
+	ts := newTestServer(t)
+	hash := hashToken("token-undangan")
+
+	ts.mock.ExpectQuery("FROM user_invitations").WithArgs(hashToken("kedaluwarsa")).
+		WillReturnRows(sqlmock.NewRows(invitationColumns))
+	req := AcceptInvitationRequest{Token: "kedaluwarsa", Username: "baru", Password: testPassword}
+	ts.expectStatus(http.MethodPost, "/auth/accept-invite", "", req, nil, http.StatusBadRequest)
+
+	ts.mock.ExpectQuery("FROM user_invitations").WithArgs(hash).
+		WillReturnRows(invitationRows(3, "baru@example.com", "editor", 1))
+	ts.mock.ExpectBegin()
+	ts.mock.ExpectQuery(`UPDATE user_invitations\s+SET accepted_at = NOW\(\)`).WithArgs(hash).
+		WillReturnRows(sqlmock.NewRows([]string{"invitation_id", "email", "role"}).AddRow(3, "baru@example.com", "editor"))
+	ts.mock.ExpectQuery("INSERT INTO users").
+		WithArgs("baru", "baru@example.com", sqlmock.AnyArg(), "editor").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "username", "email", "role", "tanggal_dibuat", "tanggal_diperbarui", "email_verified_at"}).
+			AddRow(10, "baru", "baru@example.com", "editor", time.Now(), time.Now(), time.Now()))
+	ts.mock.ExpectExec(`UPDATE user_invitations SET accepted_user_id = \$1 WHERE invitation_id = \$2`).
+		WithArgs(10, 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	ts.mock.ExpectCommit()
+
+	// Email and role come from the invitation
+	var resp struct {
+		User database.UserResponse `json:"user"`
+	}
+	req = AcceptInvitationRequest{Token: "token-undangan", Username: "baru", Password: testPassword}
+	ts.expectStatus(http.MethodPost, "/auth/accept-invite", "", req, &resp, http.StatusCreated)
+	if resp.User.Email != "baru@example.com" || resp.User.Role != "editor" {
+		t.Errorf("accepted user = %+v", resp.User)
+	}
+}
@@ -1,13 +1,20 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"news-portal-web/api/internal/auth"
 	"news-portal-web/api/internal/database"
+	"news-portal-web/api/internal/mailer"
 
 	"github.com/gorilla/mux"
 )
@@ -30,9 +37,24 @@ func (s *Server) handleLogin() http.HandlerFunc {
 			return
 		}
 
-		user, err := database.AuthenticateUser(r.Context(), s.GetDB(), &req)
+		client := database.LoginClient{IPAddress: s.clientIP(r), UserAgent: r.UserAgent()}
+		user, err := s.users.Authenticate(r.Context(), &req, client, s.lockout)
 		if err != nil {
-			writeJSONError(w, "Email atau password salah", http.StatusUnauthorized)
+			// A locked account gets the same answer as a wrong password, so
+			// the lockout cannot be used to find registered emails. The
+			// owner is told by email instead.
+			var locked *database.AccountLockedError
+			switch {
+			case errors.As(err, &locked):
+				if locked.New {
+					s.sendLockNotice(r.Context(), req.Email, locked.Until)
+				}
+				writeJSONError(w, "Email atau password salah", http.StatusUnauthorized)
+			case errors.Is(err, database.ErrInvalidCredentials):
+				writeJSONError(w, "Email atau password salah", http.StatusUnauthorized)
+			default:
+				writeJSONError(w, "Gagal memproses login", http.StatusInternalServerError)
+			}
 			return
 		}
 
@@ -43,7 +65,7 @@ func (s *Server) handleLogin() http.HandlerFunc {
 			return
 		}
 
-		tokenPair, err := jwtManager.GenerateTokenPair(user.UserID, user.Username, user.Email, user.Role)
+		tokenPair, err := jwtManager.GenerateTokenPair(r.Context(), user.UserID, user.Username, user.Email, user.Role)
 		if err != nil {
 			writeJSONError(w, "Failed to generate tokens", http.StatusInternalServerError)
 			return
@@ -61,27 +83,53 @@ func (s *Server) handleLogin() http.HandlerFunc {
 	}
 }
 
+// sendLockNotice tells the owner of an account that it was locked after
+// too many failed logins
+func (s *Server) sendLockNotice(ctx context.Context, email string, until time.Time) {
+	user, err := s.users.GetByEmail(ctx, email)
+	if err != nil {
+		log.Printf("⚠️  Failed to load locked account %s: %v", email, err)
+		return
+	}
+
+	s.sendMail(mailer.Message{
+		To:      user.Email,
+		Subject: "Akun " + siteName() + " dikunci sementara",
+		Body: fmt.Sprintf(`Halo %s,
+
+Akun Anda dikunci sementara karena terlalu banyak percobaan login yang gagal. Anda dapat login kembali setelah %s.
+
+Jika bukan Anda yang mencoba login, sebaiknya reset password Anda melalui %s.
+
+%s`, user.Username, until.Format("2 January 2006 15:04 MST"), siteURL()+"/forgot-password", siteName()),
+	})
+}
+
 // handleRegister - POST /api/v1/auth/register
 func (s *Server) handleRegister() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		var req database.UserRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		var body database.RegisterRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 			writeJSONError(w, "Invalid request payload", http.StatusBadRequest)
 			return
 		}
 
+		// Public registration always creates a regular user; other roles are
+		// assigned by admins or through invitations
+		req := database.UserRequest{
+			Username: body.Username,
+			Email:    body.Email,
+			Password: body.Password,
+			Role:     "user",
+		}
+
 		if err := validateUserRequest(&req); err != nil {
 			writeJSONError(w, err.Error(), http.StatusBadRequest)
 			return
 		}
 
-		// Default role
-		if req.Role == "" {
-			req.Role = "user"
-		}
-
 		// Check email exists
-		emailExists, err := database.IsEmailExists(r.Context(), s.GetDB(), req.Email)
+		emailExists, err := s.users.EmailExists(r.Context(), req.Email)
 		if err != nil {
 			writeJSONError(w, "Error checking email", http.StatusInternalServerError)
 			return
@@ -92,7 +140,7 @@ func (s *Server) handleRegister() http.HandlerFunc {
 		}
 
 		// Check username exists
-		usernameExists, err := database.IsUsernameExists(r.Context(), s.GetDB(), req.Username)
+		usernameExists, err := s.users.UsernameExists(r.Context(), req.Username)
 		if err != nil {
 			writeJSONError(w, "Error checking username", http.StatusInternalServerError)
 			return
@@ -102,7 +150,7 @@ func (s *Server) handleRegister() http.HandlerFunc {
 			return
 		}
 
-		user, err := database.CreateUser(r.Context(), s.GetDB(), &req)
+		user, err := s.users.Create(r.Context(), &req)
 		if err != nil {
 			if strings.Contains(err.Error(), "duplicate") {
 				writeJSONError(w, "Email atau username sudah terdaftar", http.StatusConflict)
@@ -112,6 +160,11 @@ func (s *Server) handleRegister() http.HandlerFunc {
 			return
 		}
 
+		// Akun baru belum terverifikasi sampai link di email dibuka
+		if err := s.sendVerificationEmail(r.Context(), user); err != nil {
+			log.Printf("⚠️  Failed to send verification email to user %d: %v", user.UserID, err)
+		}
+
 		// Generate JWT tokens
 		jwtManager := s.GetJWTManager()
 		if jwtManager == nil {
@@ -119,14 +172,14 @@ func (s *Server) handleRegister() http.HandlerFunc {
 			return
 		}
 
-		tokenPair, err := jwtManager.GenerateTokenPair(user.UserID, user.Username, user.Email, user.Role)
+		tokenPair, err := jwtManager.GenerateTokenPair(r.Context(), user.UserID, user.Username, user.Email, user.Role)
 		if err != nil {
 			writeJSONError(w, "Failed to generate tokens", http.StatusInternalServerError)
 			return
 		}
 
 		response := map[string]interface{}{
-			"message": "Registrasi berhasil",
+			"message": "Registrasi berhasil, silakan cek email untuk verifikasi",
 			"user":    user.ToPublic(),
 			"tokens":  tokenPair,
 		}
@@ -160,9 +213,13 @@ func (s *Server) handleRefreshToken() http.HandlerFunc {
 			return
 		}
 
-		// Validate refresh token
-		claims, err := jwtManager.ValidateRefreshToken(req.RefreshToken)
+		// Validate and consume refresh token (each refresh token can only be used once)
+		claims, err := jwtManager.ConsumeRefreshToken(r.Context(), req.RefreshToken)
 		if err != nil {
+			if errors.Is(err, auth.ErrRefreshTokenReused) {
+				writeJSONError(w, "Refresh token sudah pernah digunakan, silakan login ulang", http.StatusUnauthorized)
+				return
+			}
 			writeJSONError(w, "Refresh token tidak valid", http.StatusUnauthorized)
 			return
 		}
@@ -174,14 +231,14 @@ func (s *Server) handleRefreshToken() http.HandlerFunc {
 			return
 		}
 
-		user, err := database.GetUserByID(r.Context(), s.GetDB(), userID)
+		user, err := s.users.GetByID(r.Context(), userID)
 		if err != nil {
 			writeJSONError(w, "User tidak ditemukan", http.StatusNotFound)
 			return
 		}
 
-		// Generate new token pair
-		tokenPair, err := jwtManager.GenerateTokenPair(user.UserID, user.Username, user.Email, user.Role)
+		// Generate new token pair in the same family (rotation)
+		tokenPair, err := jwtManager.RotateTokenPair(r.Context(), claims.FamilyID, user.UserID, user.Username, user.Email, user.Role)
 		if err != nil {
 			writeJSONError(w, "Failed to refresh token", http.StatusInternalServerError)
 			return
@@ -211,11 +268,26 @@ func (s *Server) handleLogout() http.HandlerFunc {
 		}
 
 		// Revoke token
-		if err := jwtManager.RevokeToken(tokenString); err != nil {
+		if err := jwtManager.RevokeToken(r.Context(), tokenString); err != nil {
 			writeJSONError(w, "Gagal logout", http.StatusInternalServerError)
 			return
 		}
 
+		// Optionally revoke the refresh token family as well
+		var req struct {
+			RefreshToken string `json:"refresh_token"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			writeJSONError(w, "Invalid request payload", http.StatusBadRequest)
+			return
+		}
+		if req.RefreshToken != "" {
+			if err := jwtManager.RevokeRefreshToken(r.Context(), req.RefreshToken); err != nil {
+				writeJSONError(w, "Refresh token tidak valid", http.StatusBadRequest)
+				return
+			}
+		}
+
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]string{
 			"message": "Logout berhasil",
@@ -237,7 +309,7 @@ func (s *Server) handleGetProfile() http.HandlerFunc {
 			userID = *userIDPtr
 		}
 
-		user, err := database.GetUserByID(r.Context(), s.GetDB(), userID)
+		user, err := s.users.GetByID(r.Context(), userID)
 		if err != nil {
 			writeJSONError(w, "User not found", http.StatusNotFound)
 			return
@@ -276,7 +348,7 @@ func (s *Server) handleUpdateProfile() http.HandlerFunc {
 
 		// Check if new email already exists (excluding current user)
 		if req.Email != "" {
-			existing, err := database.GetUserByEmail(r.Context(), s.GetDB(), req.Email)
+			existing, err := s.users.GetByEmail(r.Context(), req.Email)
 			if err == nil && existing.UserID != userID {
 				writeJSONError(w, "Email already exists", http.StatusConflict)
 				return
@@ -285,7 +357,7 @@ func (s *Server) handleUpdateProfile() http.HandlerFunc {
 
 		// Check if new username already exists (excluding current user)
 		if req.Username != "" {
-			existing, err := database.GetUserByUsername(r.Context(), s.GetDB(), req.Username)
+			existing, err := s.users.GetByUsername(r.Context(), req.Username)
 			if err == nil && existing.UserID != userID {
 				writeJSONError(w, "Username already exists", http.StatusConflict)
 				return
@@ -295,7 +367,7 @@ func (s *Server) handleUpdateProfile() http.HandlerFunc {
 		// Users can't change their own role
 		req.Role = ""
 
-		user, err := database.UpdateUser(r.Context(), s.GetDB(), userID, &req)
+		user, err := s.users.Update(r.Context(), userID, &req)
 		if err != nil {
 			if strings.Contains(err.Error(), "not found") {
 				writeJSONError(w, "User not found", http.StatusNotFound)
@@ -334,6 +406,8 @@ func (s *Server) RegisterAuthRoutes(router *mux.Router) {
 	authRouter.HandleFunc("/register", s.handleRegister()).Methods("POST")
 	authRouter.HandleFunc("/login", s.handleLogin()).Methods("POST")
 	authRouter.HandleFunc("/refresh", s.handleRefreshToken()).Methods("POST")
+	authRouter.HandleFunc("/forgot-password", s.handleForgotPassword()).Methods("POST")
+	authRouter.HandleFunc("/reset-password", s.handleResetPassword()).Methods("POST")
 
 	// Protected auth routes
 	jwtManager := s.GetJWTManager()
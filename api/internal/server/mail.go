@@ -0,0 +1,48 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"log"
+	"time"
+
+	"news-portal-web/api/internal/mailer"
+)
+
+// ========================================
+// EMAIL HELPERS
+// ========================================
+
+// sendMail sends a message in the background so the response time does not
+// depend on the mail server and does not reveal whether an email was sent
+func (s *Server) sendMail(msg mailer.Message) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		if err := s.mailer.Send(ctx, msg); err != nil {
+			log.Printf("⚠️  Failed to send mail to %s: %v", msg.To, err)
+		}
+	}()
+}
+
+// newOneTimeToken returns a random token for links sent by email together
+// with its hash. Only the hash is stored, so a leaked database cannot be
+// used to take over accounts.
+func newOneTimeToken() (token, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, hashToken(token), nil
+}
+
+// hashToken returns the stored form of a one-time token
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
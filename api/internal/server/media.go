@@ -0,0 +1,399 @@
+package server
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"news-portal-web/api/internal/auth"
+	"news-portal-web/api/internal/database"
+
+	"github.com/gorilla/mux"
+)
+
+// ========================================
+// MEDIA LIBRARY
+// ========================================
+
+// maxSignedURLSeconds caps the lifetime of signed media URLs (24 hours)
+const maxSignedURLSeconds = 86400
+
+// UpdateMediaRequest - Request body untuk mengubah metadata media
+type UpdateMediaRequest struct {
+	AltText *string `json:"alt_text"`
+	Caption *string `json:"caption"`
+}
+
+// AttachMediaRequest - Request body untuk memasang media ke artikel
+type AttachMediaRequest struct {
+	MediaID int `json:"media_id"`
+}
+
+// handleListMedia - GET /api/v1/editor/media?search=&tipe_media=&artikel_id=&user_id=
+func (s *Server) handleListMedia() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		page := parsePagination(r, defaultPageLimit)
+		query := r.URL.Query()
+
+		filter := database.MediaFilter{
+			Search:    strings.TrimSpace(query.Get("search")),
+			TipeMedia: query.Get("tipe_media"),
+			Limit:     page.Limit,
+			Offset:    page.Offset,
+		}
+		if id, err := strconv.Atoi(query.Get("artikel_id")); err == nil {
+			filter.ArtikelID = id
+		}
+		if id, err := strconv.Atoi(query.Get("user_id")); err == nil {
+			filter.UserID = id
+		}
+
+		media, err := database.ListMedia(r.Context(), s.GetDB(), filter)
+		if err != nil {
+			writeJSONError(w, "Error fetching media", http.StatusInternalServerError)
+			return
+		}
+
+		total, err := database.CountMedia(r.Context(), s.GetDB(), filter)
+		if err != nil {
+			writeJSONError(w, "Error counting media", http.StatusInternalServerError)
+			return
+		}
+
+		writePaginated(w, media, total, page, "")
+	}
+}
+
+// handleGetMedia - GET /api/v1/editor/media/{id}
+func (s *Server) handleGetMedia() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			writeJSONError(w, "Invalid media ID", http.StatusBadRequest)
+			return
+		}
+
+		media, err := database.GetMediaByID(r.Context(), s.GetDB(), id)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				writeJSONError(w, "Media not found", http.StatusNotFound)
+				return
+			}
+			writeJSONError(w, "Error fetching media", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(media)
+	}
+}
+
+// handleMediaSignedURL - GET /api/v1/editor/media/{id}/signed-url?expires=900
+// URL sementara untuk objek yang tidak dapat diakses publik
+func (s *Server) handleMediaSignedURL() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			writeJSONError(w, "Invalid media ID", http.StatusBadRequest)
+			return
+		}
+
+		expiry := 15 * time.Minute
+		if v := r.URL.Query().Get("expires"); v != "" {
+			seconds, err := strconv.Atoi(v)
+			if err != nil || seconds <= 0 || seconds > maxSignedURLSeconds {
+				writeJSONError(w, "expires harus antara 1 dan 86400 detik", http.StatusBadRequest)
+				return
+			}
+			expiry = time.Duration(seconds) * time.Second
+		}
+
+		media, err := database.GetMediaByID(r.Context(), s.GetDB(), id)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				writeJSONError(w, "Media not found", http.StatusNotFound)
+				return
+			}
+			writeJSONError(w, "Error fetching media", http.StatusInternalServerError)
+			return
+		}
+
+		key := media.StorageKey
+		if key == "" {
+			// Uploads from before the media library only have a URL
+			var ok bool
+			if key, ok = s.storage.KeyFromURL(media.URL); !ok {
+				writeJSONError(w, "Media tidak tersimpan di storage", http.StatusNotFound)
+				return
+			}
+		}
+
+		url, err := s.storage.SignedURL(r.Context(), key, expiry)
+		if err != nil {
+			writeJSONError(w, "Error signing URL", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"url":        url,
+			"expires_at": time.Now().Add(expiry).UTC(),
+		})
+	}
+}
+
+// handleUpdateMedia - PUT /api/v1/editor/media/{id}
+// Mengubah alt text dan caption. Butuh media.edit.any, atau media.edit.own
+// untuk media milik sendiri
+func (s *Server) handleUpdateMedia() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			writeJSONError(w, "Invalid media ID", http.StatusBadRequest)
+			return
+		}
+
+		var req UpdateMediaRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		existing, err := database.GetMediaByID(r.Context(), s.GetDB(), id)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				writeJSONError(w, "Media not found", http.StatusNotFound)
+				return
+			}
+			writeJSONError(w, "Error fetching media", http.StatusInternalServerError)
+			return
+		}
+
+		if !s.canOnOwned(r.Context(), existing.UserID, auth.PermMediaEditOwn, auth.PermMediaEditAny) {
+			writeJSONError(w, "Anda hanya dapat mengubah media milik sendiri", http.StatusForbidden)
+			return
+		}
+
+		// Field yang tidak dikirim dibiarkan apa adanya
+		altText, caption := existing.AltText, existing.Caption
+		if req.AltText != nil {
+			altText = optionalString(*req.AltText)
+		}
+		if req.Caption != nil {
+			caption = optionalString(*req.Caption)
+		}
+
+		media, err := database.UpdateMediaMetadata(r.Context(), s.GetDB(), id, altText, caption)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				writeJSONError(w, "Media not found", http.StatusNotFound)
+				return
+			}
+			writeJSONError(w, "Error updating media", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(media)
+	}
+}
+
+// handleDeleteMedia - DELETE /api/v1/editor/media/{id}
+// Butuh media.delete.any, atau media.delete.own untuk media milik sendiri
+func (s *Server) handleDeleteMedia() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			writeJSONError(w, "Invalid media ID", http.StatusBadRequest)
+			return
+		}
+
+		existing, err := database.GetMediaByID(r.Context(), s.GetDB(), id)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				writeJSONError(w, "Media not found", http.StatusNotFound)
+				return
+			}
+			writeJSONError(w, "Error fetching media", http.StatusInternalServerError)
+			return
+		}
+
+		if !s.canOnOwned(r.Context(), existing.UserID, auth.PermMediaDeleteOwn, auth.PermMediaDeleteAny) {
+			writeJSONError(w, "Anda hanya dapat menghapus media milik sendiri", http.StatusForbidden)
+			return
+		}
+
+		media, err := database.DeleteMedia(r.Context(), s.GetDB(), id)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				writeJSONError(w, "Media not found", http.StatusNotFound)
+				return
+			}
+			writeJSONError(w, "Error deleting media", http.StatusInternalServerError)
+			return
+		}
+
+		// The record is gone; leftover files are only logged
+		keys := media.Variants.Keys()
+		if media.StorageKey != "" {
+			keys = append(keys, media.StorageKey)
+		}
+		for _, key := range keys {
+			if err := s.storage.Delete(r.Context(), key); err != nil {
+				log.Printf("⚠️  Failed to remove media file %s: %v", key, err)
+			}
+		}
+
+		writeJSONSuccess(w, "Media berhasil dihapus", nil, http.StatusOK)
+	}
+}
+
+// handleListArticleMedia - GET /api/v1/editor/articles/{id}/media
+func (s *Server) handleListArticleMedia() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		articleID, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			writeJSONError(w, "Invalid article ID", http.StatusBadRequest)
+			return
+		}
+
+		page := parsePagination(r, defaultPageLimit)
+		filter := database.MediaFilter{ArtikelID: articleID, Limit: page.Limit, Offset: page.Offset}
+
+		media, err := database.ListMedia(r.Context(), s.GetDB(), filter)
+		if err != nil {
+			writeJSONError(w, "Error fetching media", http.StatusInternalServerError)
+			return
+		}
+
+		total, err := database.CountMedia(r.Context(), s.GetDB(), filter)
+		if err != nil {
+			writeJSONError(w, "Error counting media", http.StatusInternalServerError)
+			return
+		}
+
+		writePaginated(w, media, total, page, "")
+	}
+}
+
+// canEditArticleMedia checks that the article exists and that the user may
+// edit it, since attaching or detaching media changes the article. It writes
+// the error response when not.
+func (s *Server) canEditArticleMedia(w http.ResponseWriter, r *http.Request, articleID int) bool {
+	article, err := s.articles.GetByID(r.Context(), articleID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			writeJSONError(w, "Article not found", http.StatusNotFound)
+			return false
+		}
+		writeJSONError(w, "Error fetching article", http.StatusInternalServerError)
+		return false
+	}
+
+	if !s.canOnOwned(r.Context(), &article.UserID, auth.PermArticleEditOwn, auth.PermArticleEditAny) {
+		writeJSONError(w, "Anda tidak memiliki izin untuk mengubah artikel ini", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// handleAttachMedia - POST /api/v1/editor/articles/{id}/media
+func (s *Server) handleAttachMedia() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		articleID, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			writeJSONError(w, "Invalid article ID", http.StatusBadRequest)
+			return
+		}
+
+		var req AttachMediaRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.MediaID <= 0 {
+			writeJSONError(w, "media_id harus diisi", http.StatusBadRequest)
+			return
+		}
+
+		if !s.canEditArticleMedia(w, r, articleID) {
+			return
+		}
+
+		media, err := database.AttachMedia(r.Context(), s.GetDB(), req.MediaID, &articleID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				writeJSONError(w, "Media not found", http.StatusNotFound)
+				return
+			}
+			writeJSONError(w, "Error attaching media", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(media)
+	}
+}
+
+// handleDetachMedia - DELETE /api/v1/editor/articles/{id}/media/{mediaId}
+// Melepas media dari artikel; file tetap ada di media library
+func (s *Server) handleDetachMedia() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		articleID, err := strconv.Atoi(vars["id"])
+		if err != nil {
+			writeJSONError(w, "Invalid article ID", http.StatusBadRequest)
+			return
+		}
+		mediaID, err := strconv.Atoi(vars["mediaId"])
+		if err != nil {
+			writeJSONError(w, "Invalid media ID", http.StatusBadRequest)
+			return
+		}
+
+		if !s.canEditArticleMedia(w, r, articleID) {
+			return
+		}
+
+		existing, err := database.GetMediaByID(r.Context(), s.GetDB(), mediaID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				writeJSONError(w, "Media not found", http.StatusNotFound)
+				return
+			}
+			writeJSONError(w, "Error fetching media", http.StatusInternalServerError)
+			return
+		}
+		if existing.ArtikelID == nil || *existing.ArtikelID != articleID {
+			writeJSONError(w, "Media tidak terpasang pada artikel ini", http.StatusNotFound)
+			return
+		}
+
+		media, err := database.AttachMedia(r.Context(), s.GetDB(), mediaID, nil)
+		if err != nil {
+			writeJSONError(w, "Error detaching media", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(media)
+	}
+}
+
+// ========================================
+// ROUTE REGISTRATION
+// ========================================
+
+// RegisterEditorMediaRoutes registers media library routes
+func (s *Server) RegisterEditorMediaRoutes(r *mux.Router) {
+	r.HandleFunc("/media", s.handleListMedia()).Methods("GET")
+	r.HandleFunc("/media", s.handleUpload()).Methods("POST")
+	r.HandleFunc("/media/{id:[0-9]+}", s.handleGetMedia()).Methods("GET")
+	r.HandleFunc("/media/{id:[0-9]+}/signed-url", s.handleMediaSignedURL()).Methods("GET")
+	r.HandleFunc("/media/{id:[0-9]+}", s.handleUpdateMedia()).Methods("PUT")
+	r.HandleFunc("/media/{id:[0-9]+}", s.handleDeleteMedia()).Methods("DELETE")
+
+	r.HandleFunc("/articles/{id:[0-9]+}/media", s.handleListArticleMedia()).Methods("GET")
+	r.HandleFunc("/articles/{id:[0-9]+}/media", s.handleAttachMedia()).Methods("POST")
+	r.HandleFunc("/articles/{id:[0-9]+}/media/{mediaId:[0-9]+}", s.handleDetachMedia()).Methods("DELETE")
+}
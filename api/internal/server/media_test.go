@@ -0,0 +1,244 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io/fs"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"news-portal-web/api/internal/database"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+var mediaColumns = []string{
+	"media_id", "url", "tipe_media", "artikel_id", "user_id", "username",
+	"nama_file", "storage_key", "mime_type", "ukuran", "lebar", "tinggi",
+	"alt_text", "caption", "variants", "created_at", "updated_at",
+}
+
+// mediaRows returns one media row uploaded by userID
+func mediaRows(id, userID int, altText any) *sqlmock.Rows {
+	now := time.Now()
+	return sqlmock.NewRows(mediaColumns).AddRow(
+		id, fmt.Sprintf("/uploads/foto-%d.jpg", id), "image", nil, userID, "penulis",
+		fmt.Sprintf("foto-%d.jpg", id), fmt.Sprintf("foto-%d.jpg", id), "image/jpeg", 2048, 800, 600,
+		altText, nil, []byte(`{}`), now, now,
+	)
+}
+
+// expectGetMedia expects GetMediaByID to load the media item
+func (ts *testServer) expectGetMedia(id, userID int, altText any) {
+	ts.mock.ExpectQuery(`FROM media m\s+LEFT JOIN users u ON m.user_id = u.user_id\s+WHERE m.media_id = \$1`).
+		WithArgs(id).
+		WillReturnRows(mediaRows(id, userID, altText))
+}
+
+func TestListMedia(t *testing.T) {
+	ts := newTestServer(t)
+	_, token := ts.createUser("penulis", "editor", true)
+
+	ts.mock.ExpectQuery(`FROM media m.*m.nama_file ILIKE \$1.*m.tipe_media = \$2.*LIMIT \$3`).
+		WithArgs("%banjir%", "image", 20).
+		WillReturnRows(mediaRows(4, 1, "Banjir"))
+	ts.mock.ExpectQuery(`SELECT COUNT\(\*\) FROM media m WHERE`).
+		WithArgs("%banjir%", "image").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	var media []database.Media
+	if total := ts.listPage("/editor/media?search=banjir&tipe_media=image", token, &media); total != 1 || len(media) != 1 {
+		t.Fatalf("media: total %d, got %d", total, len(media))
+	}
+	if media[0].MediaID != 4 {
+		t.Errorf("got media %d, want 4", media[0].MediaID)
+	}
+}
+
+func TestUpdateMedia(t *testing.T) {
+	ts := newTestServer(t)
+	editor, token := ts.createUser("penulis", "editor", true)
+
+	ts.expectGetMedia(4, editor.UserID, nil)
+	ts.mock.ExpectBegin()
+	ts.expectSnapshot("media")
+	ts.mock.ExpectExec(`UPDATE media SET alt_text = \$1, caption = \$2 WHERE media_id = \$3`).
+		WithArgs("Banjir di Kemang", nil, 4).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	ts.expectSnapshot("media")
+	ts.expectAudit(editor.UserID, "media.update", "media", "4")
+	ts.mock.ExpectCommit()
+	ts.expectGetMedia(4, editor.UserID, "Banjir di Kemang")
+
+	var media database.Media
+	body := map[string]string{"alt_text": "Banjir di Kemang"}
+	ts.expectStatus(http.MethodPut, "/editor/media/4", token, body, &media, http.StatusOK)
+	if media.AltText == nil || *media.AltText != "Banjir di Kemang" {
+		t.Errorf("alt_text = %v", media.AltText)
+	}
+}
+
+func TestMediaOwnership(t *testing.T) {
+	ts := newTestServer(t)
+	_, token := ts.createUser("penulis", "editor", true)
+
+	// Editors only change their own uploads
+	ts.expectGetMedia(4, 99, nil)
+	ts.expectStatus(http.MethodPut, "/editor/media/4", token, map[string]string{"caption": "x"}, nil, http.StatusForbidden)
+
+	ts.expectGetMedia(4, 99, nil)
+	ts.expectStatus(http.MethodDelete, "/editor/media/4", token, nil, nil, http.StatusForbidden)
+
+	ts.mock.ExpectQuery("FROM media m").WithArgs(5).WillReturnRows(sqlmock.NewRows(mediaColumns))
+	ts.expectStatus(http.MethodGet, "/editor/media/5", token, nil, nil, http.StatusNotFound)
+}
+
+func TestAttachMedia(t *testing.T) {
+	ts := newTestServer(t)
+	editor, token := ts.createUser("penulis", "editor", true)
+	other, _ := ts.createUser("penulis2", "editor", true)
+
+	own := ts.createArticle(editor.UserID, database.ArticleInput{Judul: "Milik Sendiri"})
+	foreign := ts.createArticle(other.UserID, database.ArticleInput{Judul: "Milik Orang Lain"})
+
+	ts.mock.ExpectBegin()
+	ts.expectSnapshot("media")
+	ts.mock.ExpectExec(`UPDATE media SET artikel_id = \$1 WHERE media_id = \$2`).
+		WithArgs(own.ArtikelID, 4).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	ts.expectSnapshot("media")
+	ts.expectAudit(editor.UserID, "media.attach", "media", "4")
+	ts.mock.ExpectCommit()
+	ts.expectGetMedia(4, editor.UserID, nil)
+
+	path := fmt.Sprintf("/editor/articles/%d/media", own.ArtikelID)
+	ts.expectStatus(http.MethodPost, path, token, AttachMediaRequest{MediaID: 4}, nil, http.StatusOK)
+	ts.expectStatus(http.MethodPost, path, token, AttachMediaRequest{}, nil, http.StatusBadRequest)
+
+	path = fmt.Sprintf("/editor/articles/%d/media", foreign.ArtikelID)
+	ts.expectStatus(http.MethodPost, path, token, AttachMediaRequest{MediaID: 4}, nil, http.StatusForbidden)
+}
+
+// upload posts a generated PNG to the media library, attached to artikelID
+// unless it is empty
+func (ts *testServer) upload(token, artikelID string) (int, map[string]any) {
+	ts.t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, 40, 30))
+	var raw bytes.Buffer
+	if err := png.Encode(&raw, img); err != nil {
+		ts.t.Fatal(err)
+	}
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", "foto.png")
+	if err != nil {
+		ts.t.Fatal(err)
+	}
+	part.Write(raw.Bytes())
+	form.WriteField("alt_text", "Foto banjir")
+	if artikelID != "" {
+		form.WriteField("artikel_id", artikelID)
+	}
+	form.Close()
+
+	req, err := http.NewRequest(http.MethodPost, ts.url+"/editor/media", &body)
+	if err != nil {
+		ts.t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		ts.t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var out map[string]any
+	json.NewDecoder(resp.Body).Decode(&out)
+	return resp.StatusCode, out
+}
+
+// storedFiles counts the files in the upload directory
+func (ts *testServer) storedFiles() int {
+	ts.t.Helper()
+
+	n := 0
+	err := filepath.WalkDir(ts.dir, func(_ string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			n++
+		}
+		return err
+	})
+	if err != nil {
+		ts.t.Fatal(err)
+	}
+	return n
+}
+
+func TestUploadMedia(t *testing.T) {
+	ts := newTestServer(t)
+	editor, token := ts.createUser("penulis", "editor", true)
+	_, userToken := ts.createUser("pembaca", "user", true)
+
+	ts.mock.ExpectQuery("INSERT INTO media").
+		WithArgs(sqlmock.AnyArg(), "image", nil, editor.UserID, "foto.png", sqlmock.AnyArg(), "image/png",
+			sqlmock.AnyArg(), 40, 30, "Foto banjir", nil, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"media_id"}).AddRow(4))
+	ts.expectGetMedia(4, editor.UserID, "Foto banjir")
+
+	status, resp := ts.upload(token, "")
+	if status != http.StatusCreated {
+		t.Fatalf("upload: status %d, %v", status, resp)
+	}
+	if path, _ := resp["path"].(string); !strings.HasPrefix(path, "/uploads/articles/") {
+		t.Errorf("path = %q", path)
+	}
+	uploaded := ts.storedFiles()
+	if uploaded == 0 {
+		t.Fatal("upload stored no files")
+	}
+
+	// Files are removed again when the media row cannot be saved
+	ts.mock.ExpectQuery("INSERT INTO media").WillReturnError(errors.New("connection reset"))
+	if status, _ := ts.upload(token, ""); status != http.StatusInternalServerError {
+		t.Fatalf("failed insert: status %d, want 500", status)
+	}
+	if n := ts.storedFiles(); n != uploaded {
+		t.Errorf("%d files stored after a failed upload, want %d", n, uploaded)
+	}
+
+	if status, _ := ts.upload(userToken, ""); status != http.StatusForbidden {
+		t.Errorf("upload by user: status %d, want 403", status)
+	}
+}
+
+func TestUploadToArticle(t *testing.T) {
+	ts := newTestServer(t)
+	_, token := ts.createUser("penulis", "editor", true)
+	other, _ := ts.createUser("penulis2", "editor", true)
+
+	foreign := ts.createArticle(other.UserID, database.ArticleInput{Judul: "Milik Orang Lain"})
+
+	// Neither upload reaches storage or the database
+	if status, _ := ts.upload(token, strconv.Itoa(foreign.ArtikelID)); status != http.StatusForbidden {
+		t.Errorf("upload to someone else's article: status %d, want 403", status)
+	}
+	if status, _ := ts.upload(token, "9999"); status != http.StatusNotFound {
+		t.Errorf("upload to missing article: status %d, want 404", status)
+	}
+	if n := ts.storedFiles(); n != 0 {
+		t.Errorf("%d files stored for rejected uploads", n)
+	}
+}
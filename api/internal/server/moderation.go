@@ -0,0 +1,155 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"news-portal-web/api/internal/database"
+	"news-portal-web/api/internal/moderation"
+
+	"github.com/gorilla/mux"
+)
+
+// ========================================
+// COMMENT MODERATION PIPELINE
+// ========================================
+
+// moderateComment gathers the banned word list, the author's history and
+// duplicate counts, then lets the moderation package decide the status.
+// userID is nil for anonymous comments. excludeID is the comment being
+// edited, or 0 for a new comment.
+func (s *Server) moderateComment(ctx context.Context, konten string, userID *int, artikelID, excludeID int) (moderation.Decision, error) {
+	cfg := s.moderation
+
+	banned, err := s.comments.BannedWords(ctx)
+	if err != nil {
+		return moderation.Decision{}, err
+	}
+	words := make([]moderation.BannedWord, len(banned))
+	for i, w := range banned {
+		words[i] = moderation.BannedWord{Word: w.Kata, Severity: w.Tingkat}
+	}
+
+	sig := moderation.Signals{Content: konten, Authenticated: userID != nil}
+	if userID != nil {
+		sig.ApprovedCount, sig.RejectedCount, err = s.comments.AuthorHistory(ctx, *userID)
+		if err != nil {
+			return moderation.Decision{}, err
+		}
+	}
+
+	sig.DuplicatesByAuthor, sig.DuplicatesOnArticle, err = s.comments.CountDuplicates(ctx,
+		konten, userID, artikelID, excludeID, time.Now().Add(-cfg.DuplicateWindow))
+	if err != nil {
+		return moderation.Decision{}, err
+	}
+
+	return moderation.Evaluate(cfg, words, sig), nil
+}
+
+// ========================================
+// BANNED WORD HANDLERS (ADMIN)
+// ========================================
+
+// BannedWordRequest - Request body untuk menambah kata terlarang
+type BannedWordRequest struct {
+	Kata    string `json:"kata"`
+	Tingkat string `json:"tingkat"` // reject atau review (default review)
+}
+
+// handleListBannedWords - GET /api/v1/admin/banned-words
+func (s *Server) handleListBannedWords() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		words, err := database.ListBannedWords(r.Context(), s.GetDB())
+		if err != nil {
+			writeJSONError(w, "Error fetching banned words", http.StatusInternalServerError)
+			return
+		}
+
+		page := parsePagination(r, maxPageLimit)
+		writePaginated(w, paginateSlice(words, page), len(words), page, "")
+	}
+}
+
+// handleCreateBannedWord - POST /api/v1/admin/banned-words
+func (s *Server) handleCreateBannedWord() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := getUserIDFromContext(r.Context())
+		if !ok {
+			writeJSONError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		var req BannedWordRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		req.Kata = strings.TrimSpace(req.Kata)
+		if req.Kata == "" || len(req.Kata) > 100 {
+			writeJSONError(w, "Kata harus diisi (maks 100 karakter)", http.StatusBadRequest)
+			return
+		}
+		if req.Tingkat == "" {
+			req.Tingkat = moderation.SeverityReview
+		}
+		if req.Tingkat != moderation.SeverityReject && req.Tingkat != moderation.SeverityReview {
+			writeJSONError(w, "Tingkat harus 'reject' atau 'review'", http.StatusBadRequest)
+			return
+		}
+
+		word, err := database.CreateBannedWord(r.Context(), s.GetDB(), req.Kata, req.Tingkat, userID)
+		if err != nil {
+			if errors.Is(err, database.ErrBannedWordExists) {
+				writeJSONError(w, "Kata sudah ada di daftar", http.StatusConflict)
+				return
+			}
+			writeJSONError(w, "Error creating banned word", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(word)
+	}
+}
+
+// handleDeleteBannedWord - DELETE /api/v1/admin/banned-words/{id}
+func (s *Server) handleDeleteBannedWord() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			writeJSONError(w, "Invalid word ID", http.StatusBadRequest)
+			return
+		}
+
+		if err := database.DeleteBannedWord(r.Context(), s.GetDB(), id); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				writeJSONError(w, "Kata tidak ditemukan", http.StatusNotFound)
+				return
+			}
+			writeJSONError(w, "Error deleting banned word", http.StatusInternalServerError)
+			return
+		}
+
+		writeJSONSuccess(w, "Kata berhasil dihapus", nil, http.StatusOK)
+	}
+}
+
+// ========================================
+// ROUTE REGISTRATION
+// ========================================
+
+// RegisterAdminModerationRoutes registers banned word management routes
+func (s *Server) RegisterAdminModerationRoutes(r *mux.Router) {
+	r.HandleFunc("/banned-words", s.handleListBannedWords()).Methods("GET")
+	r.HandleFunc("/banned-words", s.handleCreateBannedWord()).Methods("POST")
+	r.HandleFunc("/banned-words/{id:[0-9]+}", s.handleDeleteBannedWord()).Methods("DELETE")
+}
@@ -0,0 +1,55 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"news-portal-web/api/internal/database"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+)
+
+func TestBannedWords(t *testing.T) {
+	ts := newTestServer(t)
+	admin, token := ts.createUser("admin", "admin", true)
+
+	ts.mock.ExpectQuery(`FROM banned_words\s+ORDER BY LOWER\(kata\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"word_id", "kata", "tingkat", "created_by", "created_at"}).
+			AddRow(1, "judi", "reject", admin.UserID, time.Now()))
+
+	var words []database.BannedWord
+	if total := ts.listPage("/admin/banned-words", token, &words); total != 1 || len(words) != 1 {
+		t.Fatalf("banned words: total %d, got %d", total, len(words))
+	}
+
+	ts.expectStatus(http.MethodPost, "/admin/banned-words", token, BannedWordRequest{Kata: " "}, nil, http.StatusBadRequest)
+	ts.expectStatus(http.MethodPost, "/admin/banned-words", token, BannedWordRequest{Kata: "slot", Tingkat: "hapus"}, nil, http.StatusBadRequest)
+
+	// Words are stored in lower case, tingkat defaults to review
+	ts.mock.ExpectBegin()
+	ts.mock.ExpectQuery("INSERT INTO banned_words").
+		WithArgs("slot gacor", "review", admin.UserID).
+		WillReturnRows(sqlmock.NewRows([]string{"word_id", "created_by", "created_at"}).AddRow(2, admin.UserID, time.Now()))
+	ts.expectSnapshot("banned_words")
+	ts.expectAudit(admin.UserID, "banned_word.create", "banned_word", "2")
+	ts.mock.ExpectCommit()
+
+	var word database.BannedWord
+	ts.expectStatus(http.MethodPost, "/admin/banned-words", token, BannedWordRequest{Kata: "Slot Gacor"}, &word, http.StatusCreated)
+	if word.WordID != 2 || word.Kata != "slot gacor" {
+		t.Errorf("created word = %+v", word)
+	}
+
+	ts.mock.ExpectBegin()
+	ts.mock.ExpectQuery("INSERT INTO banned_words").WillReturnError(&pq.Error{Code: "23505"})
+	ts.mock.ExpectRollback()
+	ts.expectStatus(http.MethodPost, "/admin/banned-words", token, BannedWordRequest{Kata: "judi"}, nil, http.StatusConflict)
+
+	ts.mock.ExpectBegin()
+	ts.mock.ExpectQuery("FROM banned_words t").WithArgs(9, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"snapshot"}))
+	ts.mock.ExpectRollback()
+	ts.expectStatus(http.MethodDelete, "/admin/banned-words/9", token, nil, nil, http.StatusNotFound)
+}
@@ -0,0 +1,144 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"news-portal-web/api/internal/database"
+	"news-portal-web/api/internal/mailer"
+)
+
+// ========================================
+// PASSWORD RESET HANDLERS
+// ========================================
+
+// ForgotPasswordRequest - Request body untuk minta link reset password
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+// ResetPasswordRequest - Request body untuk set password baru
+type ResetPasswordRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
+// handleForgotPassword - POST /api/v1/auth/forgot-password
+func (s *Server) handleForgotPassword() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req ForgotPasswordRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		req.Email = strings.TrimSpace(req.Email)
+		if !isValidEmail(req.Email) {
+			writeJSONError(w, "Format email tidak valid", http.StatusBadRequest)
+			return
+		}
+
+		// Same answer whether or not the email is registered, so the endpoint
+		// cannot be used to find accounts
+		const message = "Jika email terdaftar, link reset password telah dikirim"
+
+		user, err := s.users.GetByEmail(r.Context(), req.Email)
+		if err != nil {
+			writeJSONSuccess(w, message, nil, http.StatusOK)
+			return
+		}
+
+		token, hash, err := newOneTimeToken()
+		if err != nil {
+			writeJSONError(w, "Gagal membuat token reset", http.StatusInternalServerError)
+			return
+		}
+
+		expiresAt := time.Now().Add(passwordResetTTL())
+		if err := database.CreatePasswordResetToken(r.Context(), s.GetDB(), user.UserID, hash, expiresAt, s.clientIP(r)); err != nil {
+			log.Printf("⚠️  Failed to create password reset token: %v", err)
+			writeJSONError(w, "Gagal membuat token reset", http.StatusInternalServerError)
+			return
+		}
+
+		s.sendMail(mailer.Message{
+			To:      user.Email,
+			Subject: "Reset password " + siteName(),
+			Body: fmt.Sprintf(`Halo %s,
+
+Kami menerima permintaan untuk mereset password akun Anda. Buka link berikut untuk membuat password baru:
+
+%s
+
+Link ini berlaku selama %s dan hanya dapat digunakan sekali. Jika Anda tidak meminta reset password, abaikan email ini.
+
+%s`, user.Username, passwordResetURL(token), formatTTL(passwordResetTTL()), siteName()),
+		})
+
+		writeJSONSuccess(w, message, nil, http.StatusOK)
+	}
+}
+
+// handleResetPassword - POST /api/v1/auth/reset-password
+func (s *Server) handleResetPassword() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req ResetPasswordRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		if req.Token == "" || req.NewPassword == "" {
+			writeJSONError(w, "Token dan password baru harus diisi", http.StatusBadRequest)
+			return
+		}
+
+		if len(req.NewPassword) < 8 {
+			writeJSONError(w, "Password baru minimal 8 karakter", http.StatusBadRequest)
+			return
+		}
+
+		userID, err := database.ResetPasswordWithToken(r.Context(), s.GetDB(), hashToken(req.Token), req.NewPassword)
+		if err != nil {
+			if errors.Is(err, database.ErrResetTokenInvalid) {
+				writeJSONError(w, "Link reset password tidak valid atau sudah kedaluwarsa", http.StatusBadRequest)
+				return
+			}
+			writeJSONError(w, "Gagal mereset password", http.StatusInternalServerError)
+			return
+		}
+
+		// Log out every device that may still use the old password
+		if err := s.GetJWTManager().RevokeAllUserTokens(r.Context(), userID); err != nil {
+			log.Printf("⚠️  Failed to revoke sessions of user %d: %v", userID, err)
+			writeJSONError(w, "Password diperbarui, tetapi gagal mengakhiri sesi lain", http.StatusInternalServerError)
+			return
+		}
+
+		// Proving access to the email also lifts a login lockout
+		if err := database.ResetLoginFailures(r.Context(), s.GetDB(), userID); err != nil {
+			log.Printf("⚠️  Failed to reset login failures of user %d: %v", userID, err)
+		}
+
+		writeJSONSuccess(w, "Password berhasil direset, silakan login kembali", nil, http.StatusOK)
+	}
+}
+
+// passwordResetURL returns the frontend page where a reset token is used
+func passwordResetURL(token string) string {
+	return siteURL() + "/reset-password?token=" + url.QueryEscape(token)
+}
+
+// formatTTL renders a token lifetime for emails ("60 menit", "24 jam")
+func formatTTL(d time.Duration) string {
+	if d < 2*time.Hour {
+		return fmt.Sprintf("%d menit", int(d.Minutes()))
+	}
+	return fmt.Sprintf("%d jam", int(d.Hours()))
+}
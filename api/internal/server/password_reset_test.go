@@ -0,0 +1,54 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestForgotPassword(t *testing.T) {
+	ts := newTestServer(t)
+	user, _ := ts.createUser("pembaca", "user", true)
+
+	// Unknown emails get the same answer without a token being created
+	ts.expectStatus(http.MethodPost, "/auth/forgot-password", "", ForgotPasswordRequest{Email: "tidak-ada@example.com"}, nil, http.StatusOK)
+	ts.expectStatus(http.MethodPost, "/auth/forgot-password", "", ForgotPasswordRequest{Email: "bukan-email"}, nil, http.StatusBadRequest)
+
+	ts.mock.ExpectBegin()
+	ts.mock.ExpectExec(`DELETE FROM password_reset_tokens WHERE user_id = \$1 AND used_at IS NULL`).
+		WithArgs(user.UserID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	ts.mock.ExpectExec("INSERT INTO password_reset_tokens").
+		WithArgs(user.UserID, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	ts.mock.ExpectCommit()
+	ts.expectStatus(http.MethodPost, "/auth/forgot-password", "", ForgotPasswordRequest{Email: user.Email}, nil, http.StatusOK)
+}
+
+func TestResetPassword(t *testing.T) {
+	ts := newTestServer(t)
+	user, _ := ts.createUser("pembaca", "user", true)
+
+	ts.expectStatus(http.MethodPost, "/auth/reset-password", "", ResetPasswordRequest{Token: "abc", NewPassword: "pendek"}, nil, http.StatusBadRequest)
+
+	ts.mock.ExpectBegin()
+	ts.mock.ExpectQuery(`UPDATE password_reset_tokens\s+SET used_at = NOW\(\)`).WithArgs(hashToken("bekas")).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}))
+	ts.mock.ExpectRollback()
+	req := ResetPasswordRequest{Token: "bekas", NewPassword: "passwordbaru"}
+	ts.expectStatus(http.MethodPost, "/auth/reset-password", "", req, nil, http.StatusBadRequest)
+
+	// A successful reset also lifts a login lockout
+	ts.mock.ExpectBegin()
+	ts.mock.ExpectQuery(`UPDATE password_reset_tokens\s+SET used_at = NOW\(\)`).WithArgs(hashToken("baru")).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(user.UserID))
+	ts.mock.ExpectExec(`UPDATE users SET password = \$1 WHERE user_id = \$2`).
+		WithArgs(sqlmock.AnyArg(), user.UserID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	ts.mock.ExpectCommit()
+	ts.mock.ExpectExec(`UPDATE users\s+SET locked_until = NULL`).WithArgs(user.UserID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	req = ResetPasswordRequest{Token: "baru", NewPassword: "passwordbaru"}
+	ts.expectStatus(http.MethodPost, "/auth/reset-password", "", req, nil, http.StatusOK)
+}
@@ -0,0 +1,249 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"news-portal-web/api/internal/auth"
+	"news-portal-web/api/internal/database"
+
+	"github.com/gorilla/mux"
+)
+
+// ========================================
+// PERMISSION CHECKS
+// ========================================
+
+// requirePermission returns a middleware that only lets roles granting perm
+// through. Must run after the auth middleware.
+func (s *Server) requirePermission(perm string) mux.MiddlewareFunc {
+	return auth.RequirePermission(s.permissions, perm)
+}
+
+// can reports whether the logged-in user's role grants perm. A failed
+// lookup denies.
+func (s *Server) can(ctx context.Context, perm string) bool {
+	role, ok := GetUserRoleFromContext(ctx)
+	if !ok {
+		return false
+	}
+	allowed, err := s.permissions.HasPermission(ctx, role, perm)
+	if err != nil {
+		log.Printf("⚠️  Permission check failed: %v", err)
+		return false
+	}
+	return allowed
+}
+
+// canOnOwned reports whether the logged-in user may act on a resource owned
+// by ownerID: anyPerm covers every resource, ownPerm only their own
+func (s *Server) canOnOwned(ctx context.Context, ownerID *int, ownPerm, anyPerm string) bool {
+	if s.can(ctx, anyPerm) {
+		return true
+	}
+	userID, ok := getUserIDFromContext(ctx)
+	return ok && ownerID != nil && *ownerID == userID && s.can(ctx, ownPerm)
+}
+
+// roleNameRegex - huruf kecil, angka, '_' dan '-', diawali huruf
+var roleNameRegex = regexp.MustCompile(`^[a-z][a-z0-9_-]{1,19}$`)
+
+// checkRole validates that role is a defined role, writing the error
+// response when it is not
+func (s *Server) checkRole(w http.ResponseWriter, r *http.Request, role string) bool {
+	exists, err := s.permissions.RoleExists(r.Context(), role)
+	if err != nil {
+		writeJSONError(w, "Error checking role", http.StatusInternalServerError)
+		return false
+	}
+	if !exists {
+		writeJSONError(w, "Role '"+role+"' tidak dikenal", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// ========================================
+// ROLE MANAGEMENT HANDLERS (ADMIN)
+// ========================================
+
+// RoleRequest - Request body untuk membuat/mengubah role
+type RoleRequest struct {
+	Name        string   `json:"name"` // hanya saat membuat
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// handleListPermissions - GET /api/v1/admin/permissions
+func (s *Server) handleListPermissions() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		perms, err := database.ListPermissions(r.Context(), s.GetDB())
+		if err != nil {
+			writeJSONError(w, "Error fetching permissions", http.StatusInternalServerError)
+			return
+		}
+
+		page := parsePagination(r, maxPageLimit)
+		writePaginated(w, paginateSlice(perms, page), len(perms), page, "")
+	}
+}
+
+// handleListRoles - GET /api/v1/admin/roles
+func (s *Server) handleListRoles() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		roles, err := database.ListRoles(r.Context(), s.GetDB())
+		if err != nil {
+			writeJSONError(w, "Error fetching roles", http.StatusInternalServerError)
+			return
+		}
+
+		page := parsePagination(r, maxPageLimit)
+		writePaginated(w, paginateSlice(roles, page), len(roles), page, "")
+	}
+}
+
+// handleGetRole - GET /api/v1/admin/roles/{name}
+func (s *Server) handleGetRole() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		role, err := database.GetRole(r.Context(), s.GetDB(), mux.Vars(r)["name"])
+		if err != nil {
+			if err == sql.ErrNoRows {
+				writeJSONError(w, "Role tidak ditemukan", http.StatusNotFound)
+				return
+			}
+			writeJSONError(w, "Error fetching role", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(role)
+	}
+}
+
+// handleCreateRole - POST /api/v1/admin/roles
+func (s *Server) handleCreateRole() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req RoleRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		req.Name = strings.TrimSpace(req.Name)
+		if !roleNameRegex.MatchString(req.Name) {
+			writeJSONError(w, "Nama role harus 2-20 karakter: huruf kecil, angka, '_' atau '-', diawali huruf", http.StatusBadRequest)
+			return
+		}
+
+		role, err := database.CreateRole(r.Context(), s.GetDB(), req.Name, strings.TrimSpace(req.Description), req.Permissions)
+		if err != nil {
+			s.writeRoleError(w, err)
+			return
+		}
+		s.permissions.Invalidate()
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(role)
+	}
+}
+
+// handleUpdateRole - PUT /api/v1/admin/roles/{name}
+// Mengganti deskripsi dan seluruh daftar izin role
+func (s *Server) handleUpdateRole() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req RoleRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		role, err := database.UpdateRole(r.Context(), s.GetDB(), mux.Vars(r)["name"],
+			strings.TrimSpace(req.Description), req.Permissions)
+		if err != nil {
+			s.writeRoleError(w, err)
+			return
+		}
+		s.permissions.Invalidate()
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(role)
+	}
+}
+
+// handleDeleteRole - DELETE /api/v1/admin/roles/{name}
+func (s *Server) handleDeleteRole() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := database.DeleteRole(r.Context(), s.GetDB(), mux.Vars(r)["name"]); err != nil {
+			s.writeRoleError(w, err)
+			return
+		}
+		s.permissions.Invalidate()
+
+		writeJSONSuccess(w, "Role berhasil dihapus", nil, http.StatusOK)
+	}
+}
+
+// writeRoleError maps role management errors to responses
+func (s *Server) writeRoleError(w http.ResponseWriter, err error) {
+	switch {
+	case err == sql.ErrNoRows:
+		writeJSONError(w, "Role tidak ditemukan", http.StatusNotFound)
+	case errors.Is(err, database.ErrRoleExists):
+		writeJSONError(w, "Role sudah ada", http.StatusConflict)
+	case errors.Is(err, database.ErrRoleInUse):
+		writeJSONError(w, "Role masih dipakai user, pindahkan user ke role lain terlebih dahulu", http.StatusConflict)
+	case errors.Is(err, database.ErrRoleProtected):
+		writeJSONError(w, "Role bawaan tidak dapat dihapus dan izin role admin tidak dapat diubah", http.StatusForbidden)
+	case errors.Is(err, database.ErrUnknownPermission):
+		writeJSONError(w, "Izin tidak dikenal, lihat GET /admin/permissions", http.StatusBadRequest)
+	default:
+		writeJSONError(w, "Error saving role", http.StatusInternalServerError)
+	}
+}
+
+// ========================================
+// CURRENT USER PERMISSIONS
+// ========================================
+
+// handleGetCurrentUserPermissions - GET /api/v1/users/me/permissions
+// Dipakai frontend untuk menampilkan menu sesuai hak akses
+func (s *Server) handleGetCurrentUserPermissions() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		role, _ := GetUserRoleFromContext(r.Context())
+
+		perms, err := s.permissions.Permissions(r.Context(), role)
+		if err != nil {
+			writeJSONError(w, "Error fetching permissions", http.StatusInternalServerError)
+			return
+		}
+		sort.Strings(perms)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"role":        role,
+			"permissions": perms,
+		})
+	}
+}
+
+// ========================================
+// ROUTE REGISTRATION
+// ========================================
+
+// RegisterAdminRoleRoutes registers role and permission management routes
+func (s *Server) RegisterAdminRoleRoutes(r *mux.Router) {
+	r.HandleFunc("/permissions", s.handleListPermissions()).Methods("GET")
+	r.HandleFunc("/roles", s.handleListRoles()).Methods("GET")
+	r.HandleFunc("/roles", s.handleCreateRole()).Methods("POST")
+	r.HandleFunc("/roles/{name}", s.handleGetRole()).Methods("GET")
+	r.HandleFunc("/roles/{name}", s.handleUpdateRole()).Methods("PUT")
+	r.HandleFunc("/roles/{name}", s.handleDeleteRole()).Methods("DELETE")
+}
@@ -0,0 +1,177 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"news-portal-web/api/internal/ratelimit"
+
+	"github.com/gorilla/mux"
+)
+
+// ========================================
+// RATE LIMIT CONFIGURATION
+// ========================================
+
+// rateLimitConfig holds the policy of every route group
+type rateLimitConfig struct {
+	API          ratelimit.Policy // seluruh /api/v1, per IP
+	Auth         ratelimit.Policy // login, register, reset password, per IP
+	Comments     ratelimit.Policy // kirim komentar, per user atau IP
+	Verification ratelimit.Policy // kirim ulang email verifikasi, per user
+
+	// TrustProxy takes the client IP from X-Forwarded-For, set it only
+	// when the API runs behind a reverse proxy that overwrites the header
+	TrustProxy bool
+}
+
+// rateLimitConfigFromEnv reads RATE_LIMIT_API (default 300/m:100),
+// RATE_LIMIT_AUTH (default 10/m), RATE_LIMIT_COMMENTS (default 5/m) and
+// RATE_LIMIT_VERIFICATION (default 3/h), each "N/unit[:burst]" or "off",
+// and RATE_LIMIT_TRUST_PROXY
+func rateLimitConfigFromEnv() rateLimitConfig {
+	policy := func(name, env, fallback string) ratelimit.Policy {
+		p, err := ratelimit.ParsePolicy(name, getEnv(env, fallback))
+		if err != nil {
+			log.Printf("⚠️  %v, using %s", err, fallback)
+			p, _ = ratelimit.ParsePolicy(name, fallback)
+		}
+		return p
+	}
+
+	return rateLimitConfig{
+		API:          policy("api", "RATE_LIMIT_API", "300/m:100"),
+		Auth:         policy("auth", "RATE_LIMIT_AUTH", "10/m"),
+		Comments:     policy("comments", "RATE_LIMIT_COMMENTS", "5/m"),
+		Verification: policy("verification", "RATE_LIMIT_VERIFICATION", "3/h"),
+		TrustProxy:   os.Getenv("RATE_LIMIT_TRUST_PROXY") == "true",
+	}
+}
+
+// newRateLimitStore picks the bucket store from RATE_LIMIT_STORE: "memory"
+// (default) or "postgres" to share limits between replicas
+func newRateLimitStore(db *sql.DB) ratelimit.Store {
+	if os.Getenv("RATE_LIMIT_STORE") == "postgres" {
+		return ratelimit.NewPostgresStore(db)
+	}
+	return ratelimit.NewMemoryStore()
+}
+
+// ========================================
+// RATE LIMIT MIDDLEWARE
+// ========================================
+
+// rateLimitKey identifies who a request is counted against
+type rateLimitKey func(r *http.Request) string
+
+// rateLimit returns a middleware taking one token per request from the
+// bucket of the policy and key. Every response carries the RateLimit-*
+// headers; requests over the limit get 429 with Retry-After.
+func (s *Server) rateLimit(policy ratelimit.Policy, key rateLimitKey) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		if !policy.Enabled() {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			res, err := s.limiter.Take(r.Context(), policy.Name+":"+key(r), policy)
+			if err != nil {
+				// Fail open: an unavailable store must not take the API down
+				log.Printf("⚠️  Rate limit check failed: %v", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h := w.Header()
+			h.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+			h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			h.Set("RateLimit-Reset", ceilSeconds(res.ResetAfter))
+			h.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", policy.Limit, int(policy.Window.Seconds())))
+
+			if !res.Allowed {
+				h.Set("Retry-After", ceilSeconds(res.RetryAfter))
+				writeJSONError(w, "Terlalu banyak permintaan, coba lagi nanti", http.StatusTooManyRequests)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// byIP counts requests per client IP
+func (s *Server) byIP(r *http.Request) string {
+	return "ip:" + s.clientIP(r)
+}
+
+// byUser counts requests per logged-in user, falling back to the client IP
+// for anonymous requests. Must run after the auth middleware.
+func (s *Server) byUser(r *http.Request) string {
+	if userID, ok := getUserIDFromContext(r.Context()); ok {
+		return "user:" + strconv.Itoa(userID)
+	}
+	return s.byIP(r)
+}
+
+// clientIP returns the IP of the client. Behind a trusted proxy this is the
+// last X-Forwarded-For entry, the one appended by the proxy itself.
+func (s *Server) clientIP(r *http.Request) string {
+	if s.rateLimits.TrustProxy {
+		if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+			parts := strings.Split(fwd, ",")
+			if ip := strings.TrimSpace(parts[len(parts)-1]); ip != "" {
+				return ip
+			}
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// ceilSeconds formats a duration as whole seconds, rounded up
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
+
+// runRateLimitCleanup drops idle buckets at the given interval until ctx is
+// cancelled. A bucket idle for longer than its refill time is full, so
+// dropping it changes nothing.
+func (s *Server) runRateLimitCleanup(ctx context.Context, interval time.Duration) {
+	idle := time.Hour
+	for _, p := range []ratelimit.Policy{s.rateLimits.API, s.rateLimits.Auth, s.rateLimits.Comments, s.rateLimits.Verification} {
+		if !p.Enabled() {
+			continue
+		}
+		if refill := time.Duration(p.Capacity() / p.Rate() * float64(time.Second)); refill > idle {
+			idle = refill
+		}
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := s.limiter.Cleanup(ctx, idle); err != nil {
+			log.Printf("⚠️  Rate limit cleanup failed: %v", err)
+		}
+	}
+}
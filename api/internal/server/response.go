@@ -3,6 +3,7 @@ package server
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
 )
 
 type ErrorResponse struct {
@@ -16,6 +17,71 @@ type SuccessResponse struct {
 	Data    interface{} `json:"data,omitempty"`
 }
 
+// PaginatedResponse is the envelope returned by every list endpoint.
+// NextCursor is only set by endpoints that support keyset pagination.
+type PaginatedResponse struct {
+	Data       interface{} `json:"data"`
+	Total      int         `json:"total"`
+	Limit      int         `json:"limit"`
+	Offset     int         `json:"offset"`
+	NextCursor *string     `json:"next_cursor"`
+}
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// pagination holds the limit/offset requested by the client
+type pagination struct {
+	Limit  int
+	Offset int
+}
+
+// parsePagination reads ?limit= and ?offset=, falling back to defaultLimit
+// and capping the limit at maxPageLimit
+func parsePagination(r *http.Request, defaultLimit int) pagination {
+	page := pagination{Limit: defaultLimit}
+
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 {
+		page.Limit = l
+	}
+	if page.Limit > maxPageLimit {
+		page.Limit = maxPageLimit
+	}
+
+	if o, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil && o > 0 {
+		page.Offset = o
+	}
+
+	return page
+}
+
+// paginateSlice returns the requested page of an already loaded list
+func paginateSlice[T any](items []T, page pagination) []T {
+	if page.Offset >= len(items) {
+		return []T{}
+	}
+	end := min(page.Offset+page.Limit, len(items))
+	return items[page.Offset:end]
+}
+
+func writePaginated(w http.ResponseWriter, data interface{}, total int, page pagination, nextCursor string) {
+	w.Header().Set("Content-Type", "application/json")
+
+	resp := PaginatedResponse{
+		Data:   data,
+		Total:  total,
+		Limit:  page.Limit,
+		Offset: page.Offset,
+	}
+	if nextCursor != "" {
+		resp.NextCursor = &nextCursor
+	}
+
+	json.NewEncoder(w).Encode(resp)
+}
+
 func writeJSONError(w http.ResponseWriter, message string, statusCode int) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
@@ -53,6 +119,10 @@ func getErrorMessage(statusCode int) string {
 		return "Not Found"
 	case http.StatusConflict:
 		return "Conflict"
+	case http.StatusLocked:
+		return "Locked"
+	case http.StatusTooManyRequests:
+		return "Too Many Requests"
 	case http.StatusInternalServerError:
 		return "Internal Server Error"
 	default:
@@ -72,6 +142,10 @@ func getErrorCode(statusCode int) string {
 		return "NOT_FOUND"
 	case http.StatusConflict:
 		return "CONFLICT"
+	case http.StatusLocked:
+		return "ACCOUNT_LOCKED"
+	case http.StatusTooManyRequests:
+		return "RATE_LIMITED"
 	case http.StatusInternalServerError:
 		return "INTERNAL_ERROR"
 	default:
@@ -0,0 +1,461 @@
+package server
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"news-portal-web/api/internal/auth"
+	"news-portal-web/api/internal/database"
+
+	"github.com/gorilla/mux"
+)
+
+// ========================================
+// ARTICLE REVISIONS
+// ========================================
+//
+// Every update saves the previous content to article_revisions. Revisions
+// are numbered per article; "current" refers to the live article.
+
+// RevisionDiff - Response body untuk perbandingan dua revisi
+type RevisionDiff struct {
+	ArtikelID int         `json:"artikel_id"`
+	From      string      `json:"from"`
+	To        string      `json:"to"`
+	Changes   []FieldDiff `json:"changes"`
+}
+
+// FieldDiff describes how one field changed between two revisions. Text
+// fields carry a line diff, category and tag IDs carry added/removed sets.
+type FieldDiff struct {
+	Field   string     `json:"field"`
+	Lines   []DiffLine `json:"lines,omitempty"`
+	Added   []int      `json:"added,omitempty"`
+	Removed []int      `json:"removed,omitempty"`
+}
+
+// DiffLine is one line of a line diff; Op is "equal", "insert" or "delete"
+type DiffLine struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// articleSnapshot is the comparable content of a revision or the live article
+type articleSnapshot struct {
+	Judul       string
+	Slug        string
+	Konten      string
+	Excerpt     string
+	GambarUtama string
+	Penulis     string
+	KategoriIDs []int
+	TagIDs      []int
+}
+
+// handleListArticleRevisions - GET /api/v1/editor/articles/{id}/revisions
+func (s *Server) handleListArticleRevisions() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		articleID, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			writeJSONError(w, "Invalid article ID", http.StatusBadRequest)
+			return
+		}
+
+		page := parsePagination(r, defaultPageLimit)
+
+		revisions, err := database.ListArticleRevisions(r.Context(), s.GetDB(), articleID, page.Limit, page.Offset)
+		if err != nil {
+			writeJSONError(w, "Error fetching revisions", http.StatusInternalServerError)
+			return
+		}
+
+		total, err := database.CountArticleRevisions(r.Context(), s.GetDB(), articleID)
+		if err != nil {
+			writeJSONError(w, "Error counting revisions", http.StatusInternalServerError)
+			return
+		}
+
+		writePaginated(w, revisions, total, page, "")
+	}
+}
+
+// handleGetArticleRevision - GET /api/v1/editor/articles/{id}/revisions/{rev}
+func (s *Server) handleGetArticleRevision() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		articleID, err := strconv.Atoi(vars["id"])
+		if err != nil {
+			writeJSONError(w, "Invalid article ID", http.StatusBadRequest)
+			return
+		}
+		nomor, err := strconv.Atoi(vars["rev"])
+		if err != nil {
+			writeJSONError(w, "Invalid revision number", http.StatusBadRequest)
+			return
+		}
+
+		revision, err := database.GetArticleRevision(r.Context(), s.GetDB(), articleID, nomor)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				writeJSONError(w, "Revision not found", http.StatusNotFound)
+				return
+			}
+			writeJSONError(w, "Error fetching revision", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(revision)
+	}
+}
+
+// handleDiffArticleRevisions - GET /api/v1/editor/articles/{id}/revisions/diff?from=1&to=current
+// from dan to berupa nomor revisi atau "current"; to default ke "current"
+func (s *Server) handleDiffArticleRevisions() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		articleID, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			writeJSONError(w, "Invalid article ID", http.StatusBadRequest)
+			return
+		}
+
+		from := r.URL.Query().Get("from")
+		to := r.URL.Query().Get("to")
+		if from == "" {
+			writeJSONError(w, "Parameter 'from' harus diisi", http.StatusBadRequest)
+			return
+		}
+		if to == "" {
+			to = "current"
+		}
+
+		fromSnap, status, msg := s.loadArticleSnapshot(r, articleID, from)
+		if status != 0 {
+			writeJSONError(w, msg, status)
+			return
+		}
+		toSnap, status, msg := s.loadArticleSnapshot(r, articleID, to)
+		if status != 0 {
+			writeJSONError(w, msg, status)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(RevisionDiff{
+			ArtikelID: articleID,
+			From:      from,
+			To:        to,
+			Changes:   diffArticleSnapshots(fromSnap, toSnap),
+		})
+	}
+}
+
+// handleRestoreArticleRevision - POST /api/v1/editor/articles/{id}/revisions/{rev}/restore
+// Mengembalikan konten artikel ke revisi tertentu. Status dan tanggal publikasi
+// tidak berubah, dan konten yang sedang aktif disimpan sebagai revisi baru.
+func (s *Server) handleRestoreArticleRevision() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		articleID, err := strconv.Atoi(vars["id"])
+		if err != nil {
+			writeJSONError(w, "Invalid article ID", http.StatusBadRequest)
+			return
+		}
+		nomor, err := strconv.Atoi(vars["rev"])
+		if err != nil {
+			writeJSONError(w, "Invalid revision number", http.StatusBadRequest)
+			return
+		}
+
+		userID, ok := getUserIDFromContext(r.Context())
+		if !ok {
+			writeJSONError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		existing, err := s.articles.GetByID(r.Context(), articleID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				writeJSONError(w, "Article not found", http.StatusNotFound)
+				return
+			}
+			writeJSONError(w, "Error fetching article", http.StatusInternalServerError)
+			return
+		}
+
+		if !s.canOnOwned(r.Context(), &existing.UserID, auth.PermArticleEditOwn, auth.PermArticleEditAny) {
+			writeJSONError(w, "Anda tidak memiliki izin untuk mengubah artikel ini", http.StatusForbidden)
+			return
+		}
+
+		revision, err := database.GetArticleRevision(r.Context(), s.GetDB(), articleID, nomor)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				writeJSONError(w, "Revision not found", http.StatusNotFound)
+				return
+			}
+			writeJSONError(w, "Error fetching revision", http.StatusInternalServerError)
+			return
+		}
+
+		input := database.ArticleInput{
+			Judul:       revision.Judul,
+			Slug:        derefString(revision.Slug),
+			Konten:      revision.Konten,
+			Excerpt:     derefString(revision.Excerpt),
+			GambarUtama: derefString(revision.GambarUtama),
+			Penulis:     derefString(revision.Penulis),
+			Status:      existing.Status,
+			KategoriIDs: revision.KategoriIDs,
+			TagIDs:      revision.TagIDs,
+		}
+
+		article, err := s.articles.Update(r.Context(), articleID, input, userID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				writeJSONError(w, "Article not found", http.StatusNotFound)
+				return
+			}
+			log.Printf("⚠️  Failed to restore revision %d of article %d: %v", nomor, articleID, err)
+			writeJSONError(w, "Error restoring revision", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(article)
+	}
+}
+
+// loadArticleSnapshot resolves a revision number or "current" to a snapshot.
+// On failure it returns the HTTP status and message to report.
+func (s *Server) loadArticleSnapshot(r *http.Request, articleID int, ref string) (articleSnapshot, int, string) {
+	if ref == "current" {
+		article, err := s.articles.GetByID(r.Context(), articleID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return articleSnapshot{}, http.StatusNotFound, "Article not found"
+			}
+			return articleSnapshot{}, http.StatusInternalServerError, "Error fetching article"
+		}
+		return snapshotFromArticle(article), 0, ""
+	}
+
+	nomor, err := strconv.Atoi(ref)
+	if err != nil {
+		return articleSnapshot{}, http.StatusBadRequest, "Nomor revisi tidak valid: " + ref
+	}
+
+	revision, err := database.GetArticleRevision(r.Context(), s.GetDB(), articleID, nomor)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return articleSnapshot{}, http.StatusNotFound, "Revision " + ref + " not found"
+		}
+		return articleSnapshot{}, http.StatusInternalServerError, "Error fetching revision"
+	}
+	return snapshotFromRevision(revision), 0, ""
+}
+
+func snapshotFromArticle(a *database.Article) articleSnapshot {
+	snap := articleSnapshot{
+		Judul:       a.Judul,
+		Slug:        a.Slug,
+		Konten:      a.Konten,
+		Excerpt:     derefString(a.Excerpt),
+		GambarUtama: derefString(a.GambarUtama),
+		Penulis:     derefString(a.Penulis),
+	}
+	for _, k := range a.Kategori {
+		snap.KategoriIDs = append(snap.KategoriIDs, k.KategoriID)
+	}
+	for _, t := range a.Tags {
+		snap.TagIDs = append(snap.TagIDs, t.TagID)
+	}
+	return snap
+}
+
+func snapshotFromRevision(rev *database.ArticleRevision) articleSnapshot {
+	return articleSnapshot{
+		Judul:       rev.Judul,
+		Slug:        derefString(rev.Slug),
+		Konten:      rev.Konten,
+		Excerpt:     derefString(rev.Excerpt),
+		GambarUtama: derefString(rev.GambarUtama),
+		Penulis:     derefString(rev.Penulis),
+		KategoriIDs: rev.KategoriIDs,
+		TagIDs:      rev.TagIDs,
+	}
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// ========================================
+// DIFF HELPERS
+// ========================================
+
+// diffArticleSnapshots returns only the fields that differ
+func diffArticleSnapshots(from, to articleSnapshot) []FieldDiff {
+	changes := []FieldDiff{}
+
+	textFields := []struct {
+		name     string
+		from, to string
+	}{
+		{"judul", from.Judul, to.Judul},
+		{"slug", from.Slug, to.Slug},
+		{"konten", from.Konten, to.Konten},
+		{"excerpt", from.Excerpt, to.Excerpt},
+		{"gambar_utama", from.GambarUtama, to.GambarUtama},
+		{"penulis", from.Penulis, to.Penulis},
+	}
+	for _, f := range textFields {
+		if f.from != f.to {
+			changes = append(changes, FieldDiff{Field: f.name, Lines: diffLines(f.from, f.to)})
+		}
+	}
+
+	if added, removed := diffIDs(from.KategoriIDs, to.KategoriIDs); len(added) > 0 || len(removed) > 0 {
+		changes = append(changes, FieldDiff{Field: "kategori_ids", Added: added, Removed: removed})
+	}
+	if added, removed := diffIDs(from.TagIDs, to.TagIDs); len(added) > 0 || len(removed) > 0 {
+		changes = append(changes, FieldDiff{Field: "tag_ids", Added: added, Removed: removed})
+	}
+
+	return changes
+}
+
+// maxDiffCells bounds the LCS table diffLines builds (about 32MB of ints).
+// Larger changes are reported as a replacement of the whole field.
+const maxDiffCells = 4_000_000
+
+// diffLines computes a line diff using the longest common subsequence.
+// Lines shared at the start and end are matched first so that small edits to
+// long articles stay under maxDiffCells.
+func diffLines(from, to string) []DiffLine {
+	a := splitLines(from)
+	b := splitLines(to)
+
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var lines []DiffLine
+	for _, line := range a[:prefix] {
+		lines = append(lines, DiffLine{Op: "equal", Text: line})
+	}
+	lines = append(lines, diffLinesLCS(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		lines = append(lines, DiffLine{Op: "equal", Text: line})
+	}
+
+	return lines
+}
+
+func diffLinesLCS(a, b []string) []DiffLine {
+	var lines []DiffLine
+
+	if (len(a)+1)*(len(b)+1) > maxDiffCells {
+		for _, line := range a {
+			lines = append(lines, DiffLine{Op: "delete", Text: line})
+		}
+		for _, line := range b {
+			lines = append(lines, DiffLine{Op: "insert", Text: line})
+		}
+		return lines
+	}
+
+	// lcs[i][j] = length of the LCS of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, DiffLine{Op: "equal", Text: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, DiffLine{Op: "delete", Text: a[i]})
+			i++
+		default:
+			lines = append(lines, DiffLine{Op: "insert", Text: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		lines = append(lines, DiffLine{Op: "delete", Text: a[i]})
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, DiffLine{Op: "insert", Text: b[j]})
+	}
+
+	return lines
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+}
+
+// diffIDs returns the IDs present only in to (added) and only in from (removed)
+func diffIDs(from, to []int) (added, removed []int) {
+	inFrom := make(map[int]bool, len(from))
+	for _, id := range from {
+		inFrom[id] = true
+	}
+	inTo := make(map[int]bool, len(to))
+	for _, id := range to {
+		inTo[id] = true
+		if !inFrom[id] {
+			added = append(added, id)
+		}
+	}
+	for _, id := range from {
+		if !inTo[id] {
+			removed = append(removed, id)
+		}
+	}
+	sort.Ints(added)
+	sort.Ints(removed)
+	return added, removed
+}
+
+// ========================================
+// ROUTE REGISTRATION
+// ========================================
+
+// RegisterEditorRevisionRoutes registers article revision routes
+func (s *Server) RegisterEditorRevisionRoutes(r *mux.Router) {
+	r.HandleFunc("/articles/{id:[0-9]+}/revisions", s.handleListArticleRevisions()).Methods("GET")
+	r.HandleFunc("/articles/{id:[0-9]+}/revisions/diff", s.handleDiffArticleRevisions()).Methods("GET")
+	r.HandleFunc("/articles/{id:[0-9]+}/revisions/{rev:[0-9]+}", s.handleGetArticleRevision()).Methods("GET")
+	r.HandleFunc("/articles/{id:[0-9]+}/revisions/{rev:[0-9]+}/restore", s.handleRestoreArticleRevision()).Methods("POST")
+}
@@ -0,0 +1,130 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"news-portal-web/api/internal/database"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+var revisionColumns = []string{
+	"revisi_id", "artikel_id", "nomor_revisi", "judul", "slug", "konten",
+	"excerpt", "gambar_utama", "penulis", "kategori_ids", "tag_ids",
+	"user_id", "username", "created_at",
+}
+
+// expectGetRevision expects GetArticleRevision to load revision nomor with
+// the given title and content
+func (ts *testServer) expectGetRevision(articleID, nomor int, judul, konten string) {
+	ts.mock.ExpectQuery(`FROM article_revisions r.*WHERE r.artikel_id = \$1 AND r.nomor_revisi = \$2`).
+		WithArgs(articleID, nomor).
+		WillReturnRows(sqlmock.NewRows(revisionColumns).AddRow(
+			nomor, articleID, nomor, judul, nil, konten, nil, nil, nil,
+			[]byte("{}"), []byte("{}"), 1, "penulis", time.Now()))
+}
+
+func TestListArticleRevisions(t *testing.T) {
+	ts := newTestServer(t)
+	_, token := ts.createUser("penulis", "editor", true)
+
+	// Paged in SQL, without the content of each revision
+	ts.mock.ExpectQuery(`'' AS konten.*FROM article_revisions r.*WHERE r.artikel_id = \$1\s+ORDER BY r.nomor_revisi DESC\s+LIMIT NULLIF\(\$2, 0\) OFFSET \$3`).
+		WithArgs(7, 2, 1).
+		WillReturnRows(sqlmock.NewRows(revisionColumns).
+			AddRow(2, 7, 2, "Judul Kedua", nil, "", nil, nil, nil, []byte("{1}"), []byte("{}"), 1, "penulis", time.Now()).
+			AddRow(1, 7, 1, "Judul Pertama", nil, "", nil, nil, nil, []byte("{}"), []byte("{3,4}"), 1, "penulis", time.Now()))
+	ts.mock.ExpectQuery(`SELECT COUNT\(\*\) FROM article_revisions WHERE artikel_id = \$1`).
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+	var revisions []database.ArticleRevision
+	if total := ts.listPage("/editor/articles/7/revisions?limit=2&offset=1", token, &revisions); total != 3 || len(revisions) != 2 {
+		t.Fatalf("revisions: total %d, got %d", total, len(revisions))
+	}
+	if got := revisions[1].TagIDs; len(got) != 2 || got[0] != 3 || got[1] != 4 {
+		t.Errorf("tag_ids of revision 1 = %v", got)
+	}
+
+	ts.mock.ExpectQuery("FROM article_revisions r").WithArgs(7, 9).
+		WillReturnRows(sqlmock.NewRows(revisionColumns))
+	ts.expectStatus(http.MethodGet, "/editor/articles/7/revisions/9", token, nil, nil, http.StatusNotFound)
+}
+
+func TestDiffArticleRevisions(t *testing.T) {
+	ts := newTestServer(t)
+	editor, token := ts.createUser("penulis", "editor", true)
+	article := ts.createArticle(editor.UserID, database.ArticleInput{Judul: "Judul Baru", Konten: "Isi"})
+
+	ts.expectGetRevision(article.ArtikelID, 1, "Judul Lama", "Isi")
+
+	var diff RevisionDiff
+	path := fmt.Sprintf("/editor/articles/%d/revisions/diff?from=1", article.ArtikelID)
+	ts.expectStatus(http.MethodGet, path, token, nil, &diff, http.StatusOK)
+	if diff.To != "current" {
+		t.Errorf("to = %q, want current", diff.To)
+	}
+	if len(diff.Changes) == 0 || diff.Changes[0].Field != "judul" {
+		t.Fatalf("changes = %+v, want a judul change first", diff.Changes)
+	}
+
+	path = fmt.Sprintf("/editor/articles/%d/revisions/diff", article.ArtikelID)
+	ts.expectStatus(http.MethodGet, path, token, nil, nil, http.StatusBadRequest)
+}
+
+func TestRestoreArticleRevision(t *testing.T) {
+	ts := newTestServer(t)
+	editor, token := ts.createUser("penulis", "editor", true)
+	_, otherToken := ts.createUser("penulis2", "editor", true)
+	article := ts.createArticle(editor.UserID, database.ArticleInput{Judul: "Judul Baru", Konten: "Isi baru"})
+
+	path := fmt.Sprintf("/editor/articles/%d/revisions/1/restore", article.ArtikelID)
+
+	// Other editors are refused before the revision is loaded
+	ts.expectStatus(http.MethodPost, path, otherToken, nil, nil, http.StatusForbidden)
+
+	ts.expectGetRevision(article.ArtikelID, 1, "Judul Lama", "Isi lama")
+
+	var restored database.Article
+	ts.expectStatus(http.MethodPost, path, token, nil, &restored, http.StatusOK)
+	if restored.Judul != "Judul Lama" || restored.Konten != "Isi lama" {
+		t.Errorf("restored article = %q / %q", restored.Judul, restored.Konten)
+	}
+	if restored.Status != article.Status {
+		t.Errorf("restore changed status from %q to %q", article.Status, restored.Status)
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	got := diffLines("a\nb\nc\nd", "a\nx\nc\nd\ne")
+	want := []DiffLine{
+		{Op: "equal", Text: "a"},
+		{Op: "delete", Text: "b"},
+		{Op: "insert", Text: "x"},
+		{Op: "equal", Text: "c"},
+		{Op: "equal", Text: "d"},
+		{Op: "insert", Text: "e"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("diffLines = %+v, want %+v", got, want)
+	}
+
+	// Bodies too large for the LCS table are replaced as a whole, apart from
+	// the lines they share at the start and end
+	from := make([]string, 3000)
+	to := make([]string, 3000)
+	for i := range from {
+		from[i] = fmt.Sprintf("lama %d", i)
+		to[i] = fmt.Sprintf("baru %d", i)
+	}
+	from[0], to[0] = "judul", "judul"
+	got = diffLines(strings.Join(from, "\n"), strings.Join(to, "\n"))
+	if len(got) != 1+2*2999 || got[0].Op != "equal" || got[1].Op != "delete" || got[len(got)-1].Op != "insert" {
+		t.Fatalf("large diff has %d lines, starting %+v", len(got), got[:2])
+	}
+}
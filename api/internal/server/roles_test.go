@@ -0,0 +1,98 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"news-portal-web/api/internal/database"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+)
+
+var roleColumns = []string{"name", "description", "is_system", "created_at", "updated_at", "permissions", "user_count"}
+
+// roleRows returns one role row
+func roleRows(name string, system bool, perms string) *sqlmock.Rows {
+	now := time.Now()
+	return sqlmock.NewRows(roleColumns).AddRow(name, "Role "+name, system, now, now, []byte(perms), 0)
+}
+
+func TestListRoles(t *testing.T) {
+	ts := newTestServer(t)
+	_, token := ts.createUser("admin", "admin", true)
+
+	ts.mock.ExpectQuery(`FROM roles r\s+ORDER BY r.is_system DESC, r.name`).
+		WillReturnRows(sqlmock.NewRows(roleColumns).
+			AddRow("admin", "Administrator", true, time.Now(), time.Now(), []byte("{role.manage,user.manage}"), 1).
+			AddRow("kontributor", "Kontributor", false, time.Now(), time.Now(), []byte("{}"), 0))
+
+	var roles []database.Role
+	if total := ts.listPage("/admin/roles", token, &roles); total != 2 || len(roles) != 2 {
+		t.Fatalf("roles: total %d, got %d", total, len(roles))
+	}
+	if len(roles[0].Permissions) != 2 {
+		t.Errorf("admin permissions = %v", roles[0].Permissions)
+	}
+
+	ts.mock.ExpectQuery(`FROM roles r\s+WHERE r.name = \$1`).WithArgs("tamu").
+		WillReturnRows(sqlmock.NewRows(roleColumns))
+	ts.expectStatus(http.MethodGet, "/admin/roles/tamu", token, nil, nil, http.StatusNotFound)
+}
+
+func TestCreateRole(t *testing.T) {
+	ts := newTestServer(t)
+	admin, token := ts.createUser("admin", "admin", true)
+
+	ts.expectStatus(http.MethodPost, "/admin/roles", token, RoleRequest{Name: "Bukan Nama"}, nil, http.StatusBadRequest)
+
+	ts.mock.ExpectBegin()
+	ts.mock.ExpectExec(`INSERT INTO roles \(name, description\)`).
+		WithArgs("kontributor", "Penulis lepas").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	ts.mock.ExpectExec("INSERT INTO role_permissions").
+		WithArgs("kontributor", pq.Array([]string{"article.create"})).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	ts.mock.ExpectQuery(`FROM roles r\s+WHERE r.name = \$1`).WithArgs("kontributor").
+		WillReturnRows(roleRows("kontributor", false, "{article.create}"))
+	ts.expectAudit(admin.UserID, "role.create", "role", "kontributor")
+	ts.mock.ExpectCommit()
+
+	var role database.Role
+	req := RoleRequest{Name: "kontributor", Description: " Penulis lepas ", Permissions: []string{"article.create"}}
+	ts.expectStatus(http.MethodPost, "/admin/roles", token, req, &role, http.StatusCreated)
+	if role.Name != "kontributor" || len(role.Permissions) != 1 {
+		t.Errorf("created role = %+v", role)
+	}
+
+	// Unknown permissions violate the foreign key on role_permissions
+	ts.mock.ExpectBegin()
+	ts.mock.ExpectExec("INSERT INTO roles").WillReturnResult(sqlmock.NewResult(0, 1))
+	ts.mock.ExpectExec("INSERT INTO role_permissions").WillReturnError(&pq.Error{Code: "23503"})
+	ts.mock.ExpectRollback()
+	req = RoleRequest{Name: "tamu", Permissions: []string{"semua.boleh"}}
+	ts.expectStatus(http.MethodPost, "/admin/roles", token, req, nil, http.StatusBadRequest)
+}
+
+func TestProtectedRoles(t *testing.T) {
+	ts := newTestServer(t)
+	_, token := ts.createUser("admin", "admin", true)
+
+	// The admin role is refused before any query
+	ts.expectStatus(http.MethodPut, "/admin/roles/admin", token, RoleRequest{}, nil, http.StatusForbidden)
+
+	ts.mock.ExpectBegin()
+	ts.mock.ExpectQuery(`FROM roles r\s+WHERE r.name = \$1`).WithArgs("editor").
+		WillReturnRows(roleRows("editor", true, "{editor.access}"))
+	ts.mock.ExpectRollback()
+	ts.expectStatus(http.MethodDelete, "/admin/roles/editor", token, nil, nil, http.StatusForbidden)
+
+	ts.mock.ExpectBegin()
+	ts.mock.ExpectQuery(`FROM roles r\s+WHERE r.name = \$1`).WithArgs("kontributor").
+		WillReturnRows(roleRows("kontributor", false, "{}"))
+	ts.mock.ExpectExec(`DELETE FROM roles WHERE name = \$1`).WithArgs("kontributor").
+		WillReturnError(&pq.Error{Code: "23503"})
+	ts.mock.ExpectRollback()
+	ts.expectStatus(http.MethodDelete, "/admin/roles/kontributor", token, nil, nil, http.StatusConflict)
+}
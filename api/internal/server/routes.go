@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"news-portal-web/api/internal/auth"
+	"news-portal-web/api/internal/storage"
 
 	"github.com/gorilla/mux"
 )
@@ -12,10 +13,17 @@ import (
 func (s *Server) SetupRoutes() *mux.Router {
 	r := mux.NewRouter()
 
+	// Batas waktu per request, query yang masih berjalan dibatalkan
+	r.Use(s.requestDeadline(requestTimeout()))
+
 	// ========================================
 	// API v1 ROUTER
 	// ========================================
 	api := r.PathPrefix("/api/v1").Subrouter()
+	api.Use(s.requestID)
+	api.Use(s.rateLimit(s.rateLimits.API, s.byIP))
+	// Perubahan tanpa login (registrasi, reset password) tetap dicatat dengan IP
+	api.Use(s.auditActor)
 
 	// ========================================
 	// BASIC ROUTES (tanpa prefix)
@@ -25,6 +33,12 @@ func (s *Server) SetupRoutes() *mux.Router {
 	r.HandleFunc("/ping", s.handlePing()).Methods("GET")
 	r.HandleFunc("/db-test", s.handleDBTest()).Methods("GET")
 
+	// RSS & Atom feeds (situs, per kategori, per tag)
+	s.RegisterFeedRoutes(r)
+
+	// Sitemap index, chunk sitemap & Google News sitemap
+	s.RegisterSitemapRoutes(r)
+
 	// ========================================
 	// PUBLIC ROUTES (no auth required)
 	// ========================================
@@ -33,6 +47,9 @@ func (s *Server) SetupRoutes() *mux.Router {
 	// Articles - public endpoints
 	s.RegisterPublicArticleRoutes(public)
 
+	// Full-text search
+	s.RegisterPublicSearchRoutes(public)
+
 	// Categories - public endpoints
 	s.RegisterPublicCategoryRoutes(public)
 
@@ -42,20 +59,33 @@ func (s *Server) SetupRoutes() *mux.Router {
 	// Comments - public endpoints (get & create)
 	s.RegisterPublicCommentRoutes(public)
 
-	// Auth routes (login, register, refresh)
-	public.HandleFunc("/auth/login", s.handleLogin()).Methods("POST")
-	public.HandleFunc("/auth/register", s.handleRegister()).Methods("POST")
-	public.HandleFunc("/auth/refresh", s.handleRefreshToken()).Methods("POST")
+	// Auth routes (login, register, refresh, reset password, verifikasi email,
+	// undangan) - dibatasi per IP
+	authRoutes := api.NewRoute().Subrouter()
+	authRoutes.Use(s.rateLimit(s.rateLimits.Auth, s.byIP))
+	authRoutes.HandleFunc("/auth/login", s.handleLogin()).Methods("POST")
+	authRoutes.HandleFunc("/auth/register", s.handleRegister()).Methods("POST")
+	authRoutes.HandleFunc("/auth/refresh", s.handleRefreshToken()).Methods("POST")
+	authRoutes.HandleFunc("/auth/forgot-password", s.handleForgotPassword()).Methods("POST")
+	authRoutes.HandleFunc("/auth/reset-password", s.handleResetPassword()).Methods("POST")
+	authRoutes.HandleFunc("/auth/verify-email", s.handleVerifyEmail()).Methods("POST")
+	authRoutes.HandleFunc("/auth/invitation", s.handleGetInvitation()).Methods("GET")
+	authRoutes.HandleFunc("/auth/accept-invite", s.handleAcceptInvitation()).Methods("POST")
 
 	// ========================================
 	// AUTHENTICATED USER ROUTES
 	// ========================================
 	authenticated := api.NewRoute().Subrouter()
 	authenticated.Use(auth.AuthMiddleware(s.GetJWTManager()))
+	authenticated.Use(s.auditActor)
 
 	// Auth - logout (requires token)
 	authenticated.HandleFunc("/auth/logout", s.handleLogout()).Methods("POST")
 
+	// Kirim ulang email verifikasi (dibatasi per user)
+	authenticated.Handle("/auth/resend-verification",
+		s.rateLimit(s.rateLimits.Verification, s.byUser)(s.handleResendVerification())).Methods("POST")
+
 	// User profile routes
 	s.RegisterUserRoutes(authenticated)
 
@@ -63,50 +93,86 @@ func (s *Server) SetupRoutes() *mux.Router {
 	s.RegisterUserCommentRoutes(authenticated)
 
 	// ========================================
-	// EDITOR ROUTES (editor + admin only)
+	// EDITOR ROUTES (role dengan izin editor.access)
 	// ========================================
+	// Izin per aksi (membuat, mengubah, menerbitkan, ...) dicek di handler
 	editor := api.PathPrefix("/editor").Subrouter()
 	editor.Use(auth.AuthMiddleware(s.GetJWTManager()))
-	editor.Use(auth.EditorOrAdmin)
+	editor.Use(s.auditActor)
+	editor.Use(s.requirePermission(auth.PermEditorAccess))
+	editor.Use(s.requireVerifiedEmail)
 
 	// Editor article management
 	s.RegisterEditorArticleRoutes(editor)
 
+	// Editorial workflow (submit, approve, reject, publish, archive)
+	s.RegisterEditorWorkflowRoutes(editor)
+
+	// Article revision history (list, diff, restore)
+	s.RegisterEditorRevisionRoutes(editor)
+
 	// Upload file (untuk gambar artikel)
 	editor.HandleFunc("/upload", s.handleUpload()).Methods("POST")
 
+	// Media library (list, search, metadata, attach ke artikel)
+	s.RegisterEditorMediaRoutes(editor)
+
 	// ========================================
-	// ADMIN ROUTES (admin only)
+	// ADMIN ROUTES (per kelompok, sesuai izin)
 	// ========================================
 	admin := api.PathPrefix("/admin").Subrouter()
 	admin.Use(auth.AuthMiddleware(s.GetJWTManager()))
-	admin.Use(auth.AdminOnly)
+	admin.Use(s.auditActor)
+
+	adminGroup := func(perm string) *mux.Router {
+		group := admin.NewRoute().Subrouter()
+		group.Use(s.requirePermission(perm))
+		return group
+	}
 
-	// User management
-	s.RegisterAdminUserRoutes(admin)
+	// User management & undangan user baru lewat email
+	users := adminGroup(auth.PermUserManage)
+	s.RegisterAdminUserRoutes(users)
+	s.RegisterAdminInvitationRoutes(users)
+
+	// Role & izin
+	s.RegisterAdminRoleRoutes(adminGroup(auth.PermRoleManage))
 
 	// Category management
-	s.RegisterAdminCategoryRoutes(admin)
+	s.RegisterAdminCategoryRoutes(adminGroup(auth.PermCategoryManage))
 
 	// Tag management
-	s.RegisterAdminTagRoutes(admin)
+	s.RegisterAdminTagRoutes(adminGroup(auth.PermTagManage))
+
+	// Comment moderation & kata terlarang untuk moderasi otomatis
+	moderators := adminGroup(auth.PermCommentModerate)
+	s.RegisterAdminCommentRoutes(moderators)
+	s.RegisterAdminModerationRoutes(moderators)
+
+	// Audit log (lihat & ekspor CSV)
+	s.RegisterAdminAuditRoutes(adminGroup(auth.PermAuditView))
 
-	// Comment moderation
-	s.RegisterAdminCommentRoutes(admin)
+	// Tempat sampah (pulihkan / hapus permanen)
+	s.RegisterAdminTrashRoutes(adminGroup(auth.PermTrashManage))
 
 	// ...existing code...
-    // Comments - POST komentar harus login jika token disertakan (optional auth)
+    // Comments - POST komentar harus login jika token disertakan (optional auth),
+    // user yang login harus sudah verifikasi email
     authComment := api.NewRoute().Subrouter()
     authComment.Use(auth.OptionalAuthMiddleware(s.GetJWTManager()))
+    authComment.Use(s.requireVerifiedEmail)
+    authComment.Use(s.rateLimit(s.rateLimits.Comments, s.byUser))
     authComment.HandleFunc("/articles/{id:[0-9]+}/comments", s.handleCreateComment()).Methods("POST")
 // ...existing code...
 
 	// ========================================
 	// STATIC FILES
 	// ========================================
-	r.PathPrefix("/uploads/").Handler(
-		http.StripPrefix("/uploads/",
-			http.FileServer(http.Dir("./uploads"))))
+	// Only the local backend serves files itself; S3 URLs point at the bucket
+	if local, ok := s.storage.(*storage.Local); ok {
+		r.PathPrefix(local.URLPrefix()).Handler(
+			http.StripPrefix(local.URLPrefix(), local))
+	}
 
 	return r
 }
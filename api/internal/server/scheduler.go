@@ -0,0 +1,71 @@
+package server
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"news-portal-web/api/internal/database"
+)
+
+// publishBatchSize limits how many articles one scheduler tick publishes
+const publishBatchSize = 100
+
+// runPublishScheduler promotes due scheduled articles to published at the
+// given interval until ctx is cancelled. Safe to run on every instance:
+// PublishDueArticles claims rows with FOR UPDATE SKIP LOCKED.
+func (s *Server) runPublishScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.publishDueArticles(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// publishDueArticles publishes due articles in batches until none are left
+func (s *Server) publishDueArticles(ctx context.Context) {
+	for {
+		ids, err := database.PublishDueArticles(ctx, s.GetDB(), publishBatchSize)
+		if err != nil {
+			log.Printf("⚠️  Publish scheduler failed: %v", err)
+			return
+		}
+
+		if len(ids) > 0 {
+			log.Printf("🗞️  Published %d scheduled article(s): %v", len(ids), ids)
+		}
+
+		if len(ids) < publishBatchSize {
+			return
+		}
+	}
+}
+
+// runTrashPurge permanently deletes items that have been in the trash longer
+// than retention, at the given interval until ctx is cancelled
+func (s *Server) runTrashPurge(ctx context.Context, interval, retention time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := database.PurgeExpiredTrash(ctx, s.GetDB(), time.Now().Add(-retention))
+		if err != nil {
+			log.Printf("⚠️  Trash purge failed: %v", err)
+		} else if n > 0 {
+			log.Printf("🗑️  Purged %d item(s) from the trash", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+
+	"news-portal-web/api/internal/database"
+
+	"github.com/gorilla/mux"
+)
+
+// handleSearchArticles - GET /api/v1/search?q=...&limit=20&offset=0
+// Pencarian full-text artikel published, diurutkan berdasarkan relevansi
+func (s *Server) handleSearchArticles() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := strings.TrimSpace(r.URL.Query().Get("q"))
+		if q == "" {
+			writeJSONError(w, "Parameter 'q' harus diisi", http.StatusBadRequest)
+			return
+		}
+
+		page := parsePagination(r, defaultPageLimit)
+
+		results, err := database.SearchArticles(r.Context(), s.GetDB(), q, page.Limit, page.Offset)
+		if err != nil {
+			writeJSONError(w, "Error searching articles", http.StatusInternalServerError)
+			return
+		}
+
+		total, err := database.CountSearchResults(r.Context(), s.GetDB(), q)
+		if err != nil {
+			writeJSONError(w, "Error counting search results", http.StatusInternalServerError)
+			return
+		}
+
+		writePaginated(w, results, total, page, "")
+	}
+}
+
+// RegisterPublicSearchRoutes registers the public search route
+func (s *Server) RegisterPublicSearchRoutes(r *mux.Router) {
+	r.HandleFunc("/search", s.handleSearchArticles()).Methods("GET")
+}
@@ -0,0 +1,39 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+
+	"news-portal-web/api/internal/database"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestSearchArticles(t *testing.T) {
+	ts := newTestServer(t)
+
+	columns := append(append([]string{}, articleColumns...), "rank", "judul_highlight", "snippet")
+	row := append(articleRow(7, "Banjir di Jakarta", 1), 0.5, "<mark>Banjir</mark> di Jakarta", "Hujan deras...")
+	ts.mock.ExpectQuery(`FROM articles a, websearch_to_tsquery`).
+		WithArgs("banjir jakarta", 1, 0).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(row...))
+	ts.expectArticleRelations()
+	ts.mock.ExpectQuery(`SELECT COUNT\(\*\)\s+FROM articles a`).
+		WithArgs("banjir jakarta").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+	var results []database.SearchResult
+	if total := ts.listPage("/search?q=+banjir+jakarta+&limit=1", "", &results); total != 3 || len(results) != 1 {
+		t.Fatalf("search: total %d, got %d results", total, len(results))
+	}
+	if results[0].JudulHighlight != "<mark>Banjir</mark> di Jakarta" {
+		t.Errorf("judul_highlight = %q", results[0].JudulHighlight)
+	}
+}
+
+func TestSearchRequiresQuery(t *testing.T) {
+	ts := newTestServer(t)
+
+	ts.expectStatus(http.MethodGet, "/search", "", nil, nil, http.StatusBadRequest)
+	ts.expectStatus(http.MethodGet, "/search?q=++", "", nil, nil, http.StatusBadRequest)
+}
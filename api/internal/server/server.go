@@ -1,12 +1,19 @@
 package server
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"log"
 	"net/http"
+	"time"
 
 	"news-portal-web/api/internal/auth"
+	"news-portal-web/api/internal/database"
+	"news-portal-web/api/internal/mailer"
+	"news-portal-web/api/internal/moderation"
+	"news-portal-web/api/internal/ratelimit"
+	"news-portal-web/api/internal/storage"
 
 	"github.com/gorilla/mux"
 	"github.com/rs/cors"
@@ -14,24 +21,54 @@ import (
 
 // Server holds dependencies for HTTP handlers
 type Server struct {
-	db         *sql.DB
-	jwtManager *auth.JWTManager
+	db          *database.DB
+	articles    database.ArticleRepository
+	comments    database.CommentRepository
+	users       database.UserRepository
+	taxonomy    database.TaxonomyRepository
+	jwtManager  *auth.JWTManager
+	permissions *auth.PermissionCache
+	sitemaps    *sitemapCache
+	storage     storage.Storage
+	mailer      mailer.Mailer
+	moderation  moderation.Config
+	limiter     ratelimit.Store
+	rateLimits  rateLimitConfig
+	lockout     database.LockoutPolicy
 }
 
 // NewServer creates a new server instance
 // Ganti fungsi NewServer menjadi:
-func NewServer(db *sql.DB, secretKey string) *Server {
-	jwtManager := auth.NewJWTManager(secretKey)
+func NewServer(db *database.DB, repos database.Repositories, secretKey string, store storage.Storage, mail mailer.Mailer) *Server {
+	// Revoked and refresh tokens live in Postgres so they survive restarts
+	jwtManager := auth.NewJWTManagerWithStore(secretKey, auth.NewPostgresRevocationStore(db.DB))
+
+	// Role permissions come from the role_permissions table
+	permissions := auth.NewPermissionCache(func(ctx context.Context) (map[string][]string, error) {
+		return database.LoadRolePermissions(ctx, db.DB)
+	}, permissionCacheTTL())
 
 	return &Server{
-		db:         db,
-		jwtManager: jwtManager,
+		db:          db,
+		articles:    repos.Articles,
+		comments:    repos.Comments,
+		users:       repos.Users,
+		taxonomy:    repos.Taxonomy,
+		jwtManager:  jwtManager,
+		permissions: permissions,
+		sitemaps:    newSitemapCache(),
+		storage:     store,
+		mailer:      mail,
+		moderation:  moderation.ConfigFromEnv(),
+		limiter:     newRateLimitStore(db.DB),
+		rateLimits:  rateLimitConfigFromEnv(),
+		lockout:     lockoutPolicyFromEnv(),
 	}
 }
 
 // GetDB returns the database connection
 func (s *Server) GetDB() *sql.DB {
-	return s.db
+	return s.db.DB
 }
 
 // GetJWTManager returns the JWT manager
@@ -40,8 +77,9 @@ func (s *Server) GetJWTManager() *auth.JWTManager {
 }
 
 // Start starts the server with CORS enabled
-// Ganti fungsi Start menjadi:
-func (s *Server) Start(addr string) error {
+// Start serves on addr until ctx is cancelled, then stops the background
+// jobs and shuts the HTTP server down gracefully
+func (s *Server) Start(ctx context.Context, addr string) error {
 	router := s.SetupRoutes()
 
 	// Debug: log semua routes yang terdaftar
@@ -64,8 +102,65 @@ func (s *Server) Start(addr string) error {
 
 	handler := c.Handler(router)
 
+	// Periodically purge expired entries from the token revocation store
+	go s.runTokenCleanup(ctx, time.Hour)
+
+	// Drop rate limit buckets that are no longer in use
+	go s.runRateLimitCleanup(ctx, 10*time.Minute)
+
+	// Publish scheduled articles when their tanggal_publikasi arrives
+	go s.runPublishScheduler(ctx, time.Minute)
+
+	// Permanently delete trash older than the retention period
+	if retention := trashRetention(); retention > 0 {
+		go s.runTrashPurge(ctx, time.Hour, retention)
+	}
+
+	httpServer := &http.Server{Addr: addr, Handler: handler}
+	shutdownErr := make(chan error, 1)
+	go func() {
+		<-ctx.Done()
+		log.Printf("🛑 Shutting down server...")
+
+		// Requests in flight get a grace period to finish
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		shutdownErr <- httpServer.Shutdown(shutdownCtx)
+	}()
+
 	log.Printf("🚀 Server listening on %s", addr)
-	return http.ListenAndServe(addr, handler)
+	if err := httpServer.ListenAndServe(); err != http.ErrServerClosed {
+		return err
+	}
+	return <-shutdownErr
+}
+
+// shutdownTimeout bounds how long Start waits for requests in flight
+const shutdownTimeout = 15 * time.Second
+
+// runTokenCleanup removes expired revoked/refresh tokens and one-time email
+// tokens at the given interval until ctx is cancelled
+func (s *Server) runTokenCleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := s.jwtManager.CleanupRevokedTokens(ctx); err != nil {
+			log.Printf("⚠️  Token cleanup failed: %v", err)
+		}
+		if err := database.DeleteExpiredPasswordResetTokens(ctx, s.GetDB()); err != nil {
+			log.Printf("⚠️  Password reset token cleanup failed: %v", err)
+		}
+		if err := database.DeleteExpiredEmailVerificationTokens(ctx, s.GetDB()); err != nil {
+			log.Printf("⚠️  Email verification token cleanup failed: %v", err)
+		}
+	}
 }
 
 // ========================================
@@ -77,7 +172,7 @@ func (s *Server) handleHealth() http.HandlerFunc {
 		w.Header().Set("Content-Type", "application/json")
 
 		// Check database connection
-		if err := s.db.Ping(); err != nil {
+		if err := s.db.PingContext(r.Context()); err != nil {
 			w.WriteHeader(http.StatusServiceUnavailable)
 			json.NewEncoder(w).Encode(map[string]string{
 				"status":   "unhealthy",
@@ -124,7 +219,7 @@ func (s *Server) handleDBTest() http.HandlerFunc {
 
 		// Test simple query
 		var result int
-		err := s.db.QueryRow("SELECT 1").Scan(&result)
+		err := s.db.QueryRowContext(r.Context(), "SELECT 1").Scan(&result)
 		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
 			json.NewEncoder(w).Encode(map[string]string{
@@ -0,0 +1,360 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"news-portal-web/api/internal/auth"
+	"news-portal-web/api/internal/database"
+	"news-portal-web/api/internal/database/memdb"
+	"news-portal-web/api/internal/mailer"
+	"news-portal-web/api/internal/moderation"
+	"news-portal-web/api/internal/ratelimit"
+	"news-portal-web/api/internal/storage"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// ========================================
+// TEST SERVER
+// ========================================
+
+// testPassword is the password of every seeded account
+const testPassword = "rahasia123"
+
+// testRolePermissions mirrors the roles seeded by the permission migrations
+var testRolePermissions = map[string][]string{
+	"admin": {
+		auth.PermEditorAccess, auth.PermArticleCreate, auth.PermArticleEditOwn, auth.PermArticleEditAny,
+		auth.PermArticleDeleteOwn, auth.PermArticleDeleteAny, auth.PermArticleSubmit, auth.PermArticleReview,
+		auth.PermArticlePublish, auth.PermMediaUpload, auth.PermMediaEditOwn, auth.PermMediaEditAny,
+		auth.PermMediaDeleteOwn, auth.PermMediaDeleteAny,
+		auth.PermCommentModerate, auth.PermCategoryManage, auth.PermTagManage, auth.PermUserManage,
+		auth.PermRoleManage, auth.PermAuditView, auth.PermTrashManage,
+	},
+	"editor": {
+		auth.PermEditorAccess, auth.PermArticleCreate, auth.PermArticleEditOwn, auth.PermArticleDeleteOwn,
+		auth.PermArticleSubmit, auth.PermMediaUpload, auth.PermMediaEditOwn, auth.PermMediaDeleteOwn,
+	},
+	"reviewer": {
+		auth.PermEditorAccess, auth.PermArticleCreate, auth.PermArticleEditAny, auth.PermArticleDeleteAny,
+		auth.PermArticleReview, auth.PermArticlePublish, auth.PermMediaUpload, auth.PermMediaEditAny,
+		auth.PermMediaDeleteAny,
+	},
+	"user": {},
+}
+
+// testServer runs the router against the in-memory repositories and a
+// local storage directory. Routes that still query Postgres directly
+// (media, uploads, audit, roles, invitations, trash, workflow, revisions,
+// search, sitemaps, banned words, email tokens, login history) run against
+// sqlmock: tests list the queries they expect on mock, and any other query
+// fails the request.
+type testServer struct {
+	t     *testing.T
+	repos database.Repositories
+	mock  sqlmock.Sqlmock
+	jwt   *auth.JWTManager
+	dir   string // uploads end up here
+	root  string // feeds and sitemaps live outside /api/v1
+	url   string
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+		conn.Close()
+	})
+
+	dir := t.TempDir()
+	store, err := storage.NewLocal(dir, "/uploads", []byte("test-signing-key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repos := memdb.NewRepositories()
+	jwtManager := auth.NewJWTManager("test-secret")
+	s := &Server{
+		db:         &database.DB{DB: conn},
+		articles:   repos.Articles,
+		comments:   repos.Comments,
+		users:      repos.Users,
+		taxonomy:   repos.Taxonomy,
+		jwtManager: jwtManager,
+		permissions: auth.NewPermissionCache(func(ctx context.Context) (map[string][]string, error) {
+			return testRolePermissions, nil
+		}, time.Hour),
+		sitemaps: newSitemapCache(),
+		storage:  store,
+		mailer:   mailer.NewLog("test@localhost"),
+		moderation: moderation.Config{
+			MaxLinks:        2,
+			DuplicateWindow: 24 * time.Hour,
+			TrustedApproved: 3,
+			AnonymousStatus: moderation.StatusPending,
+		},
+		limiter: ratelimit.NewMemoryStore(),
+	}
+
+	srv := httptest.NewServer(s.SetupRoutes())
+	t.Cleanup(srv.Close)
+
+	return &testServer{t: t, repos: repos, mock: mock, jwt: jwtManager, dir: dir, root: srv.URL, url: srv.URL + "/api/v1"}
+}
+
+// createUser seeds an account and returns it with an access token
+func (ts *testServer) createUser(username, role string, verified bool) (*database.User, string) {
+	ts.t.Helper()
+	ctx := context.Background()
+
+	user, err := ts.repos.Users.Create(ctx, &database.UserRequest{
+		Username: username,
+		Email:    username + "@example.com",
+		Password: testPassword,
+		Role:     role,
+	})
+	if err != nil {
+		ts.t.Fatal(err)
+	}
+	if verified {
+		if err := ts.repos.Users.MarkEmailVerified(ctx, user.UserID); err != nil {
+			ts.t.Fatal(err)
+		}
+	}
+
+	tokens, err := ts.jwt.GenerateTokenPair(ctx, user.UserID, user.Username, user.Email, user.Role)
+	if err != nil {
+		ts.t.Fatal(err)
+	}
+	return user, tokens.AccessToken
+}
+
+// createArticle seeds an article owned by userID
+func (ts *testServer) createArticle(userID int, input database.ArticleInput) *database.Article {
+	ts.t.Helper()
+	if input.Konten == "" {
+		input.Konten = "Isi artikel " + input.Judul
+	}
+	article, err := ts.repos.Articles.Create(context.Background(), input, userID)
+	if err != nil {
+		ts.t.Fatal(err)
+	}
+	return article
+}
+
+// request sends a JSON request, decodes the response into out when it is
+// not nil and returns the status code
+func (ts *testServer) request(method, path, token string, body, out any) int {
+	ts.t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			ts.t.Fatal(err)
+		}
+		reader = bytes.NewReader(raw)
+	}
+
+	req, err := http.NewRequest(method, ts.url+path, reader)
+	if err != nil {
+		ts.t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		ts.t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			ts.t.Fatalf("%s %s: decode response: %v", method, path, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+// expectStatus fails the test when the request does not answer with want
+func (ts *testServer) expectStatus(method, path, token string, body, out any, want int) {
+	ts.t.Helper()
+	if got := ts.request(method, path, token, body, out); got != want {
+		ts.t.Fatalf("%s %s: status %d, want %d", method, path, got, want)
+	}
+}
+
+// page is the pagination envelope with the data left raw
+type page struct {
+	Data  json.RawMessage `json:"data"`
+	Total int             `json:"total"`
+}
+
+// listPage requests a paginated list and decodes its items into out
+func (ts *testServer) listPage(path, token string, out any) int {
+	ts.t.Helper()
+
+	var p page
+	ts.expectStatus(http.MethodGet, path, token, nil, &p, http.StatusOK)
+	if out != nil {
+		if err := json.Unmarshal(p.Data, out); err != nil {
+			ts.t.Fatalf("GET %s: decode data: %v", path, err)
+		}
+	}
+	return p.Total
+}
+
+// get fetches a page outside /api/v1 and returns its status and body
+func (ts *testServer) get(path string) (int, string) {
+	ts.t.Helper()
+
+	resp, err := http.Get(ts.root + path)
+	if err != nil {
+		ts.t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		ts.t.Fatal(err)
+	}
+	return resp.StatusCode, string(body)
+}
+
+// ========================================
+// SQLMOCK HELPERS
+// ========================================
+
+// articleColumns are the article columns the database package scans
+var articleColumns = []string{
+	"artikel_id", "judul", "slug", "konten", "excerpt", "gambar_utama",
+	"penulis", "status", "user_id", "tanggal_publikasi", "tanggal_dibuat", "tanggal_diperbarui",
+}
+
+// articleRow returns the articleColumns values of a published article
+func articleRow(id int, judul string, userID int) []driver.Value {
+	now := time.Now()
+	slug := strings.ToLower(strings.ReplaceAll(judul, " ", "-"))
+	return []driver.Value{id, judul, slug, "Isi " + judul, nil, nil, nil, "published", userID, now, now, now}
+}
+
+// expectArticleRelations expects the category and tag lookups that follow
+// an article query, answering with no rows
+func (ts *testServer) expectArticleRelations() {
+	ts.mock.ExpectQuery(`FROM categories c\s+JOIN artikel_kategori`).
+		WillReturnRows(sqlmock.NewRows([]string{"artikel_id", "kategori_id", "nama_kategori", "deskripsi", "created_at"}))
+	ts.mock.ExpectQuery(`FROM tags t\s+JOIN artikel_tag`).
+		WillReturnRows(sqlmock.NewRows([]string{"artikel_id", "tag_id", "nama_tag", "created_at"}))
+}
+
+// expectGetArticle expects GetArticleByID to load the article
+func (ts *testServer) expectGetArticle(id int, judul string, userID int) {
+	ts.mock.ExpectQuery(`FROM articles\s+WHERE artikel_id = \$1`).WithArgs(id).
+		WillReturnRows(sqlmock.NewRows(articleColumns).AddRow(articleRow(id, judul, userID)...))
+	ts.expectArticleRelations()
+}
+
+// expectAudit expects an audit entry for action on the target, written by
+// the given actor
+func (ts *testServer) expectAudit(actorID int, action, targetType, targetID string) {
+	ts.mock.ExpectExec("INSERT INTO audit_log").
+		WithArgs(actorID, sqlmock.AnyArg(), action, targetType, targetID,
+			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+// expectSnapshot expects an audit snapshot of one row of table
+func (ts *testServer) expectSnapshot(table string) {
+	ts.mock.ExpectQuery(`SELECT to_jsonb\(t\) - \$2::text\[\] FROM ` + table + ` t`).
+		WillReturnRows(sqlmock.NewRows([]string{"snapshot"}).AddRow([]byte(`{}`)))
+}
+
+// ========================================
+// AUTHENTICATION & PERMISSIONS
+// ========================================
+
+func TestProtectedRoutesRequireToken(t *testing.T) {
+	ts := newTestServer(t)
+
+	routes := []struct{ method, path string }{
+		{http.MethodGet, "/users/me"},
+		{http.MethodGet, "/users/me/comments"},
+		{http.MethodPost, "/editor/articles"},
+		{http.MethodGet, "/editor/articles"},
+		{http.MethodGet, "/editor/media"},
+		{http.MethodGet, "/editor/articles/1/revisions"},
+		{http.MethodPost, "/editor/articles/1/submit"},
+		{http.MethodGet, "/admin/users"},
+		{http.MethodPost, "/admin/categories"},
+		{http.MethodGet, "/admin/comments"},
+		{http.MethodGet, "/admin/audit-log"},
+		{http.MethodGet, "/admin/roles"},
+		{http.MethodGet, "/admin/invitations"},
+		{http.MethodGet, "/admin/trash"},
+		{http.MethodGet, "/admin/banned-words"},
+	}
+	for _, rt := range routes {
+		ts.expectStatus(rt.method, rt.path, "", nil, nil, http.StatusUnauthorized)
+		ts.expectStatus(rt.method, rt.path, "not-a-token", nil, nil, http.StatusUnauthorized)
+	}
+}
+
+func TestRoutesRequirePermission(t *testing.T) {
+	ts := newTestServer(t)
+	_, userToken := ts.createUser("pembaca", "user", true)
+	_, editorToken := ts.createUser("penulis", "editor", true)
+
+	tests := []struct {
+		name, method, path, token string
+	}{
+		{"user opens editor", http.MethodPost, "/editor/articles", userToken},
+		{"user lists drafts", http.MethodGet, "/editor/articles", userToken},
+		{"user opens media", http.MethodGet, "/editor/media", userToken},
+		{"user reads revisions", http.MethodGet, "/editor/articles/1/revisions", userToken},
+		{"user manages users", http.MethodGet, "/admin/users", userToken},
+		{"user moderates", http.MethodGet, "/admin/comments", userToken},
+		{"editor manages users", http.MethodGet, "/admin/users", editorToken},
+		{"editor manages categories", http.MethodPost, "/admin/categories", editorToken},
+		{"editor manages tags", http.MethodPost, "/admin/tags", editorToken},
+		{"editor approves", http.MethodPost, "/editor/articles/1/approve", editorToken},
+		{"editor schedules", http.MethodPost, "/editor/articles/1/schedule", editorToken},
+		{"editor reads audit log", http.MethodGet, "/admin/audit-log", editorToken},
+		{"editor exports audit log", http.MethodGet, "/admin/audit-log/export", editorToken},
+		{"editor manages roles", http.MethodGet, "/admin/roles", editorToken},
+		{"editor invites", http.MethodPost, "/admin/invitations", editorToken},
+		{"editor empties trash", http.MethodDelete, "/admin/trash/article/1", editorToken},
+		{"editor manages banned words", http.MethodGet, "/admin/banned-words", editorToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts.expectStatus(tt.method, tt.path, tt.token, nil, nil, http.StatusForbidden)
+		})
+	}
+}
+
+func TestEditorRoutesRequireVerifiedEmail(t *testing.T) {
+	ts := newTestServer(t)
+	_, token := ts.createUser("penulis", "editor", false)
+
+	body := database.ArticleInput{Judul: "Berita", Konten: "Isi"}
+	ts.expectStatus(http.MethodPost, "/editor/articles", token, body, nil, http.StatusForbidden)
+}
@@ -0,0 +1,347 @@
+package server
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/xml"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"news-portal-web/api/internal/database"
+
+	"github.com/gorilla/mux"
+)
+
+// ========================================
+// SITEMAPS
+// ========================================
+//
+// /sitemap.xml is an index pointing to /sitemaps/pages.xml (home, category
+// and tag pages) and /sitemaps/articles-N.xml (published articles, 50k per
+// chunk). /news-sitemap.xml follows the Google News format. Documents are
+// cached until GetSitemapVersion reports a publish event.
+
+const (
+	sitemapChunkSize = 50000
+	newsSitemapAge   = 48 * time.Hour
+)
+
+type sitemapIndex struct {
+	XMLName  xml.Name     `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 sitemapindex"`
+	Sitemaps []sitemapRef `xml:"sitemap"`
+}
+
+type sitemapRef struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	NewsNS  string       `xml:"xmlns:news,attr,omitempty"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc     string     `xml:"loc"`
+	LastMod string     `xml:"lastmod,omitempty"`
+	News    *newsEntry `xml:"news:news"`
+}
+
+type newsEntry struct {
+	Publication     newsPublication `xml:"news:publication"`
+	PublicationDate string          `xml:"news:publication_date"`
+	Title           string          `xml:"news:title"`
+	Keywords        string          `xml:"news:keywords,omitempty"`
+}
+
+type newsPublication struct {
+	Name     string `xml:"news:name"`
+	Language string `xml:"news:language"`
+}
+
+// sitemapCache keeps generated sitemap documents per path until the
+// sitemap version changes
+type sitemapCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedDocument
+}
+
+type cachedDocument struct {
+	version      string
+	body         []byte
+	etag         string
+	lastModified time.Time
+}
+
+func newSitemapCache() *sitemapCache {
+	return &sitemapCache{entries: make(map[string]cachedDocument)}
+}
+
+func (c *sitemapCache) get(key, version string) (cachedDocument, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	doc, ok := c.entries[key]
+	if !ok || doc.version != version {
+		return cachedDocument{}, false
+	}
+	return doc, true
+}
+
+func (c *sitemapCache) put(key string, doc cachedDocument) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[key] = doc
+}
+
+// sitemapBuilder renders one sitemap document and returns its last modification
+type sitemapBuilder func(ctx context.Context) (interface{}, time.Time, error)
+
+// serveSitemap answers from cache when the sitemap version is unchanged and
+// rebuilds the document otherwise
+func (s *Server) serveSitemap(w http.ResponseWriter, r *http.Request, key, version string, build sitemapBuilder) {
+	doc, ok := s.sitemaps.get(key, version)
+	if !ok {
+		v, lastModified, err := build(r.Context())
+		if err != nil {
+			http.Error(w, "Error generating sitemap", http.StatusInternalServerError)
+			return
+		}
+		if v == nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		out, err := xml.MarshalIndent(v, "", "  ")
+		if err != nil {
+			http.Error(w, "Error generating sitemap", http.StatusInternalServerError)
+			return
+		}
+
+		body := append([]byte(xml.Header), out...)
+		sum := sha1.Sum(body)
+		doc = cachedDocument{
+			version:      version,
+			body:         body,
+			etag:         `"` + hex.EncodeToString(sum[:])[:20] + `"`,
+			lastModified: lastModified.Truncate(time.Second),
+		}
+		s.sitemaps.put(key, doc)
+	}
+
+	if checkNotModified(w, r, doc.etag, doc.lastModified) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.Write(doc.body)
+}
+
+func (s *Server) sitemapVersion(w http.ResponseWriter, r *http.Request) (string, bool) {
+	version, err := database.GetSitemapVersion(r.Context(), s.GetDB())
+	if err != nil {
+		http.Error(w, "Error generating sitemap", http.StatusInternalServerError)
+		return "", false
+	}
+	return version, true
+}
+
+// handleSitemapIndex - GET /sitemap.xml
+func (s *Server) handleSitemapIndex() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		version, ok := s.sitemapVersion(w, r)
+		if !ok {
+			return
+		}
+
+		s.serveSitemap(w, r, "index", version, func(ctx context.Context) (interface{}, time.Time, error) {
+			total, err := database.CountSitemapArticles(ctx, s.GetDB())
+			if err != nil {
+				return nil, time.Time{}, err
+			}
+
+			index := sitemapIndex{
+				Sitemaps: []sitemapRef{{Loc: apiURL() + "/sitemaps/pages.xml"}},
+			}
+			chunks := (total + sitemapChunkSize - 1) / sitemapChunkSize
+			for i := 1; i <= chunks; i++ {
+				index.Sitemaps = append(index.Sitemaps, sitemapRef{
+					Loc: apiURL() + "/sitemaps/articles-" + strconv.Itoa(i) + ".xml",
+				})
+			}
+
+			return index, time.Now(), nil
+		})
+	}
+}
+
+// handleSitemapPages - GET /sitemaps/pages.xml
+// Halaman utama serta halaman kategori dan tag
+func (s *Server) handleSitemapPages() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		version, ok := s.sitemapVersion(w, r)
+		if !ok {
+			return
+		}
+
+		s.serveSitemap(w, r, "pages", version, func(ctx context.Context) (interface{}, time.Time, error) {
+			pages, err := database.ListSitemapLandingPages(ctx, s.GetDB())
+			if err != nil {
+				return nil, time.Time{}, err
+			}
+
+			var latest time.Time
+			urls := []sitemapURL{}
+			for _, p := range pages {
+				urls = append(urls, landingPageURL(p))
+				if p.LastMod != nil && p.LastMod.After(latest) {
+					latest = *p.LastMod
+				}
+			}
+
+			home := sitemapURL{Loc: siteURL() + "/"}
+			if !latest.IsZero() {
+				home.LastMod = latest.UTC().Format(time.RFC3339)
+			}
+			urls = append([]sitemapURL{home}, urls...)
+
+			return newURLSet(urls, false), latest, nil
+		})
+	}
+}
+
+// handleSitemapArticles - GET /sitemaps/articles-{n}.xml
+func (s *Server) handleSitemapArticles() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		chunk, err := strconv.Atoi(mux.Vars(r)["n"])
+		if err != nil || chunk < 1 {
+			http.NotFound(w, r)
+			return
+		}
+
+		version, ok := s.sitemapVersion(w, r)
+		if !ok {
+			return
+		}
+
+		key := "articles-" + strconv.Itoa(chunk)
+		s.serveSitemap(w, r, key, version, func(ctx context.Context) (interface{}, time.Time, error) {
+			articles, err := database.ListSitemapArticles(ctx, s.GetDB(), sitemapChunkSize, (chunk-1)*sitemapChunkSize)
+			if err != nil {
+				return nil, time.Time{}, err
+			}
+			if len(articles) == 0 {
+				return nil, time.Time{}, nil
+			}
+
+			var latest time.Time
+			urls := make([]sitemapURL, 0, len(articles))
+			for _, a := range articles {
+				urls = append(urls, sitemapURL{
+					Loc:     articleURL(a.Slug),
+					LastMod: a.TanggalDiperbarui.UTC().Format(time.RFC3339),
+				})
+				if a.TanggalDiperbarui.After(latest) {
+					latest = a.TanggalDiperbarui
+				}
+			}
+
+			return newURLSet(urls, false), latest, nil
+		})
+	}
+}
+
+// handleNewsSitemap - GET /news-sitemap.xml
+// Artikel yang terbit dalam 48 jam terakhir (format Google News), ditambah
+// halaman kategori dan tag yang memuat artikel tersebut
+func (s *Server) handleNewsSitemap() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		version, ok := s.sitemapVersion(w, r)
+		if !ok {
+			return
+		}
+
+		// Articles also age out of the 48 hour window without a publish
+		// event, so the cached copy is at most an hour old
+		version += "-" + strconv.FormatInt(time.Now().Truncate(time.Hour).Unix(), 10)
+
+		s.serveSitemap(w, r, "news", version, func(ctx context.Context) (interface{}, time.Time, error) {
+			since := time.Now().Add(-newsSitemapAge)
+
+			articles, err := database.ListRecentlyPublishedArticles(ctx, s.GetDB(), since)
+			if err != nil {
+				return nil, time.Time{}, err
+			}
+			pages, err := database.ListSitemapLandingPages(ctx, s.GetDB())
+			if err != nil {
+				return nil, time.Time{}, err
+			}
+
+			var latest time.Time
+			urls := []sitemapURL{}
+			for _, a := range articles {
+				urls = append(urls, sitemapURL{
+					Loc:     articleURL(a.Slug),
+					LastMod: a.TanggalDiperbarui.UTC().Format(time.RFC3339),
+					News: &newsEntry{
+						Publication:     newsPublication{Name: siteName(), Language: "id"},
+						PublicationDate: a.TanggalPublikasi.UTC().Format(time.RFC3339),
+						Title:           a.Judul,
+						Keywords:        strings.Join(a.Tags, ", "),
+					},
+				})
+				if a.TanggalDiperbarui.After(latest) {
+					latest = a.TanggalDiperbarui
+				}
+			}
+			for _, p := range pages {
+				if p.LastMod != nil && p.LastMod.After(since) {
+					urls = append(urls, landingPageURL(p))
+				}
+			}
+
+			return newURLSet(urls, true), latest, nil
+		})
+	}
+}
+
+func newURLSet(urls []sitemapURL, news bool) sitemapURLSet {
+	set := sitemapURLSet{
+		XMLNS: "http://www.sitemaps.org/schemas/sitemap/0.9",
+		URLs:  urls,
+	}
+	if news {
+		set.NewsNS = "http://www.google.com/schemas/sitemap-news/0.9"
+	}
+	return set
+}
+
+func landingPageURL(p database.SitemapLandingPage) sitemapURL {
+	u := sitemapURL{Loc: categoryURL(p.Name)}
+	if p.Type == "tag" {
+		u.Loc = tagURL(p.Name)
+	}
+	if p.LastMod != nil {
+		u.LastMod = p.LastMod.UTC().Format(time.RFC3339)
+	}
+	return u
+}
+
+// ========================================
+// ROUTE REGISTRATION
+// ========================================
+
+// RegisterSitemapRoutes registers the sitemap routes on the root router
+func (s *Server) RegisterSitemapRoutes(r *mux.Router) {
+	r.HandleFunc("/sitemap.xml", s.handleSitemapIndex()).Methods("GET", "HEAD")
+	r.HandleFunc("/sitemaps/pages.xml", s.handleSitemapPages()).Methods("GET", "HEAD")
+	r.HandleFunc("/sitemaps/articles-{n:[0-9]+}.xml", s.handleSitemapArticles()).Methods("GET", "HEAD")
+	r.HandleFunc("/news-sitemap.xml", s.handleNewsSitemap()).Methods("GET", "HEAD")
+}
@@ -0,0 +1,89 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// expectSitemapVersion expects GetSitemapVersion; categories stands for the
+// md5 of the category names
+func (ts *testServer) expectSitemapVersion(categories string) {
+	ts.mock.ExpectQuery(`SELECT\s+\(SELECT COUNT\(\*\) FROM articles`).
+		WillReturnRows(sqlmock.NewRows([]string{"published", "last_update", "categories", "tags"}).
+			AddRow(1, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), categories, "tags"))
+}
+
+// expectLandingPages expects ListSitemapLandingPages to return one category
+func (ts *testServer) expectLandingPages(category string) {
+	ts.mock.ExpectQuery(`SELECT 'kategori', c.nama_kategori`).
+		WillReturnRows(sqlmock.NewRows([]string{"type", "name", "lastmod"}).
+			AddRow("kategori", category, time.Now()))
+}
+
+func TestSitemapPagesCache(t *testing.T) {
+	ts := newTestServer(t)
+
+	ts.expectSitemapVersion("v1")
+	ts.expectLandingPages("Olahraga")
+	status, body := ts.get("/sitemaps/pages.xml")
+	if status != http.StatusOK || !strings.Contains(body, "/olahraga</loc>") {
+		t.Fatalf("pages.xml: status %d, body %s", status, body)
+	}
+
+	// Same version: served from cache without listing the pages again
+	ts.expectSitemapVersion("v1")
+	if status, cached := ts.get("/sitemaps/pages.xml"); status != http.StatusOK || cached != body {
+		t.Fatalf("cached pages.xml: status %d, body changed", status)
+	}
+
+	// A renamed category changes the version and rebuilds the sitemap
+	ts.expectSitemapVersion("v2")
+	ts.expectLandingPages("Sepak Bola")
+	status, body = ts.get("/sitemaps/pages.xml")
+	if status != http.StatusOK || !strings.Contains(body, "/sepak-bola</loc>") {
+		t.Fatalf("rebuilt pages.xml: status %d, body %s", status, body)
+	}
+}
+
+func TestSitemapIndex(t *testing.T) {
+	ts := newTestServer(t)
+
+	// Chunks are counted with the same predicate the chunks are listed with
+	ts.expectSitemapVersion("v1")
+	ts.mock.ExpectQuery(`SELECT COUNT\(\*\) FROM articles WHERE status = 'published' AND slug IS NOT NULL AND deleted_at IS NULL`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(sitemapChunkSize + 1))
+	status, body := ts.get("/sitemap.xml")
+	if status != http.StatusOK {
+		t.Fatalf("sitemap.xml: status %d", status)
+	}
+	if !strings.Contains(body, "/sitemaps/articles-2.xml</loc>") || strings.Contains(body, "/sitemaps/articles-3.xml") {
+		t.Errorf("sitemap.xml lists the wrong chunks: %s", body)
+	}
+}
+
+func TestSitemapArticles(t *testing.T) {
+	ts := newTestServer(t)
+
+	ts.expectSitemapVersion("v1")
+	ts.mock.ExpectQuery(`SELECT artikel_id, judul, slug`).
+		WithArgs(sitemapChunkSize, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"artikel_id", "judul", "slug", "tanggal_publikasi", "tanggal_diperbarui"}).
+			AddRow(7, "Banjir di Jakarta", "banjir-di-jakarta", time.Now(), time.Now()))
+	status, body := ts.get("/sitemaps/articles-1.xml")
+	if status != http.StatusOK || !strings.Contains(body, "/article/banjir-di-jakarta</loc>") {
+		t.Fatalf("articles-1.xml: status %d, body %s", status, body)
+	}
+
+	// Chunks past the last article do not exist
+	ts.expectSitemapVersion("v1")
+	ts.mock.ExpectQuery(`SELECT artikel_id, judul, slug`).
+		WithArgs(sitemapChunkSize, sitemapChunkSize).
+		WillReturnRows(sqlmock.NewRows([]string{"artikel_id", "judul", "slug", "tanggal_publikasi", "tanggal_diperbarui"}))
+	if status, _ := ts.get("/sitemaps/articles-2.xml"); status != http.StatusNotFound {
+		t.Fatalf("articles-2.xml: status %d, want 404", status)
+	}
+}
@@ -27,7 +27,7 @@ func (s *Server) handleCreateTag() http.HandlerFunc {
 		}
 
 		// Check if tag already exists
-		exists, err := database.IsTagExists(r.Context(), s.GetDB(), req.NamaTag)
+		exists, err := s.taxonomy.TagExists(r.Context(), req.NamaTag)
 		if err != nil {
 			writeJSONError(w, "Failed to check tag existence: "+err.Error(), http.StatusInternalServerError)
 			return
@@ -37,7 +37,7 @@ func (s *Server) handleCreateTag() http.HandlerFunc {
 			return
 		}
 
-		tag, err := database.CreateTag(r.Context(), s.GetDB(), &req)
+		tag, err := s.taxonomy.CreateTag(r.Context(), &req)
 		if err != nil {
 			if strings.Contains(err.Error(), "duplicate") {
 				writeJSONError(w, "Tag already exists", http.StatusConflict)
@@ -65,7 +65,7 @@ func (s *Server) handleGetTag() http.HandlerFunc {
 				return
 			}
 
-			tag, err := database.GetTagByID(r.Context(), s.GetDB(), tagID)
+			tag, err := s.taxonomy.GetTagByID(r.Context(), tagID)
 			if err != nil {
 				if errors.Is(err, sql.ErrNoRows) || strings.Contains(err.Error(), "not found") {
 					writeJSONError(w, "Tag not found", http.StatusNotFound)
@@ -90,71 +90,61 @@ func (s *Server) handleListTags() http.HandlerFunc {
 		withCount := r.URL.Query().Get("with_count")
 		popular := r.URL.Query().Get("popular")
 		search := r.URL.Query().Get("search")
-		limitStr := r.URL.Query().Get("limit")
 
 		// Search tags
 		if search != "" {
-			tags, err := database.SearchTags(r.Context(), s.GetDB(), search)
+			page := parsePagination(r, defaultPageLimit)
+
+			tags, err := s.taxonomy.SearchTags(r.Context(), search)
 			if err != nil {
 				writeJSONError(w, "Failed to search tags: "+err.Error(), http.StatusInternalServerError)
 				return
 			}
 
-			w.Header().Set("Content-Type", "application/json")
-			json.NewEncoder(w).Encode(map[string]interface{}{
-				"tags": tags,
-			})
+			writePaginated(w, paginateSlice(tags, page), len(tags), page, "")
 			return
 		}
 
-		// Popular tags
+		// Popular tags: top-N list, limit is capped at 50 and offset is not used
 		if popular == "true" {
-			limit := 10
-			if limitStr != "" {
-				if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 50 {
-					limit = l
-				}
-			}
+			page := parsePagination(r, 10)
+			page.Limit = min(page.Limit, 50)
+			page.Offset = 0
 
-			tags, err := database.ListPopularTags(r.Context(), s.GetDB(), limit)
+			tags, err := s.taxonomy.ListPopularTags(r.Context(), page.Limit)
 			if err != nil {
 				writeJSONError(w, "Failed to fetch popular tags: "+err.Error(), http.StatusInternalServerError)
 				return
 			}
 
-			w.Header().Set("Content-Type", "application/json")
-			json.NewEncoder(w).Encode(map[string]interface{}{
-				"tags": tags,
-			})
+			writePaginated(w, paginateSlice(tags, page), len(tags), page, "")
 			return
 		}
 
 		// Tags with article count
 		if withCount == "true" {
-			tags, err := database.ListTagsWithArticleCount(r.Context(), s.GetDB())
+			page := parsePagination(r, maxPageLimit)
+
+			tags, err := s.taxonomy.ListTagsWithArticleCount(r.Context())
 			if err != nil {
 				writeJSONError(w, "Failed to fetch tags: "+err.Error(), http.StatusInternalServerError)
 				return
 			}
 
-			w.Header().Set("Content-Type", "application/json")
-			json.NewEncoder(w).Encode(map[string]interface{}{
-				"tags": tags,
-			})
+			writePaginated(w, paginateSlice(tags, page), len(tags), page, "")
 			return
 		}
 
 		// Default: list all tags
-		tags, err := database.ListTags(r.Context(), s.GetDB())
+		page := parsePagination(r, maxPageLimit)
+
+		tags, err := s.taxonomy.ListTags(r.Context())
 		if err != nil {
 			writeJSONError(w, "Failed to fetch tags: "+err.Error(), http.StatusInternalServerError)
 			return
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"tags": tags,
-		})
+		writePaginated(w, paginateSlice(tags, page), len(tags), page, "")
 	}
 }
 
@@ -179,13 +169,13 @@ func (s *Server) handleUpdateTag() http.HandlerFunc {
 		}
 
 		// Check if new name already exists (excluding current tag)
-		existing, err := database.GetTagByName(r.Context(), s.GetDB(), req.NamaTag)
+		existing, err := s.taxonomy.GetTagByName(r.Context(), req.NamaTag)
 		if err == nil && existing.TagID != tagID {
 			writeJSONError(w, "Tag name already exists", http.StatusConflict)
 			return
 		}
 
-		tag, err := database.UpdateTag(r.Context(), s.GetDB(), tagID, &req)
+		tag, err := s.taxonomy.UpdateTag(r.Context(), tagID, &req)
 		if err != nil {
 			if strings.Contains(err.Error(), "not found") {
 				writeJSONError(w, "Tag not found", http.StatusNotFound)
@@ -218,11 +208,11 @@ func (s *Server) handleDeleteTag() http.HandlerFunc {
 		force := r.URL.Query().Get("force")
 
 		if force == "true" {
-			// Force delete - removes tag and all its relations
-			err = database.ForceDeleteTag(r.Context(), s.GetDB(), tagID)
+			// Force delete - moves the tag to the trash even if articles use it
+			err = s.taxonomy.ForceDeleteTag(r.Context(), tagID)
 		} else {
 			// Safe delete - only delete if no articles are using this tag
-			err = database.DeleteTag(r.Context(), s.GetDB(), tagID)
+			err = s.taxonomy.DeleteTag(r.Context(), tagID)
 		}
 
 		if err != nil {
@@ -266,7 +256,7 @@ func (s *Server) handleCreateMultipleTags() http.HandlerFunc {
 			}
 		}
 
-		tagIDs, err := database.GetOrCreateTags(r.Context(), s.GetDB(), req.TagNames)
+		tagIDs, err := s.taxonomy.GetOrCreateTags(r.Context(), req.TagNames)
 		if err != nil {
 			writeJSONError(w, "Failed to create tags: "+err.Error(), http.StatusInternalServerError)
 			return
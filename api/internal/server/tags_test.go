@@ -0,0 +1,73 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"news-portal-web/api/internal/database"
+)
+
+func TestTagCRUD(t *testing.T) {
+	ts := newTestServer(t)
+	admin, token := ts.createUser("admin", "admin", true)
+
+	ts.expectStatus(http.MethodPost, "/admin/tags", token, database.TagRequest{NamaTag: "pemilu#2024"}, nil, http.StatusBadRequest)
+
+	var tag database.Tag
+	ts.expectStatus(http.MethodPost, "/admin/tags", token, database.TagRequest{NamaTag: "pemilu"}, &tag, http.StatusCreated)
+	ts.expectStatus(http.MethodPost, "/admin/tags", token, database.TagRequest{NamaTag: "pemilu"}, nil, http.StatusConflict)
+	ts.expectStatus(http.MethodPost, "/admin/tags", token, database.TagRequest{NamaTag: "banjir"}, nil, http.StatusCreated)
+
+	var tags []database.Tag
+	if total := ts.listPage("/tags", "", &tags); total != 2 {
+		t.Fatalf("listed %d tags, want 2", total)
+	}
+
+	tags = nil
+	ts.listPage("/tags?search=pem", "", &tags)
+	if len(tags) != 1 || tags[0].TagID != tag.TagID {
+		t.Fatalf("search returned %+v", tags)
+	}
+
+	path := fmt.Sprintf("/tags/%d", tag.TagID)
+	ts.expectStatus(http.MethodGet, path, "", nil, &tag, http.StatusOK)
+
+	ts.expectStatus(http.MethodPut, "/admin"+path, token, database.TagRequest{NamaTag: "banjir"}, nil, http.StatusConflict)
+	ts.expectStatus(http.MethodPut, "/admin"+path, token, database.TagRequest{NamaTag: "pilkada"}, &tag, http.StatusOK)
+	if tag.NamaTag != "pilkada" {
+		t.Fatalf("updated tag %q", tag.NamaTag)
+	}
+
+	// Tags in use are only removed with force=true
+	ts.createArticle(admin.UserID, database.ArticleInput{Judul: "Kampanye", TagIDs: []int{tag.TagID}})
+	ts.expectStatus(http.MethodDelete, "/admin"+path, token, nil, nil, http.StatusConflict)
+	ts.expectStatus(http.MethodDelete, "/admin"+path+"?force=true", token, nil, nil, http.StatusNoContent)
+	ts.expectStatus(http.MethodGet, path, "", nil, nil, http.StatusNotFound)
+}
+
+func TestTagArticleCounts(t *testing.T) {
+	ts := newTestServer(t)
+	admin, token := ts.createUser("admin", "admin", true)
+
+	var live, drafted database.Tag
+	ts.expectStatus(http.MethodPost, "/admin/tags", token, database.TagRequest{NamaTag: "pemilu"}, &live, http.StatusCreated)
+	ts.expectStatus(http.MethodPost, "/admin/tags", token, database.TagRequest{NamaTag: "banjir"}, &drafted, http.StatusCreated)
+
+	ts.createArticle(admin.UserID, database.ArticleInput{Judul: "Kampanye", Status: "published", TagIDs: []int{live.TagID}})
+	ts.createArticle(admin.UserID, database.ArticleInput{Judul: "Draf", TagIDs: []int{live.TagID, drafted.TagID}})
+
+	// Only published articles are counted, so a tag used by drafts alone is not popular
+	var popular []map[string]any
+	if total := ts.listPage("/tags?popular=true", "", &popular); total != 1 || popular[0]["article_count"] != float64(1) {
+		t.Fatalf("popular tags: total %d, got %v", total, popular)
+	}
+
+	var counted []map[string]any
+	ts.listPage("/tags?with_count=true", "", &counted)
+	for _, tag := range counted {
+		if want := map[string]float64{"pemilu": 1, "banjir": 0}[tag["nama_tag"].(string)]; tag["article_count"] != want {
+			t.Fatalf("tag %v counted %v articles, want %v", tag["nama_tag"], tag["article_count"], want)
+		}
+	}
+}
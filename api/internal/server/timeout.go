@@ -0,0 +1,44 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// routeAuditLogExport names the CSV export route so it can skip the request
+// deadline: it streams as long as there are rows and stops on its own when
+// the client disconnects
+const routeAuditLogExport = "audit-log-export"
+
+// untimedRoutes are route names exempt from the request deadline
+var untimedRoutes = map[string]bool{
+	routeAuditLogExport: true,
+}
+
+// requestDeadline cancels the request context after timeout so queries of a
+// slow or abandoned request do not keep running. Handlers must pass
+// r.Context() to the database for it to take effect.
+func (s *Server) requestDeadline(timeout time.Duration) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if route := mux.CurrentRoute(r); route != nil && untimedRoutes[route.GetName()] {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				log.Printf("⏱️  %s %s exceeded the %s request timeout", r.Method, r.URL.Path, timeout)
+			}
+		})
+	}
+}
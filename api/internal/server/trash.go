@@ -0,0 +1,107 @@
+package server
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"news-portal-web/api/internal/database"
+
+	"github.com/gorilla/mux"
+)
+
+// ========================================
+// TRASH HANDLERS (ADMIN)
+// ========================================
+
+// handleListTrash - GET /api/v1/admin/trash
+// Query params: type (comment, article, category, tag, user), limit, offset
+func (s *Server) handleListTrash() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		items, err := database.ListTrash(r.Context(), s.GetDB(), r.URL.Query().Get("type"))
+		if err != nil {
+			if errors.Is(err, database.ErrUnknownTrashType) {
+				writeJSONError(w, "type harus salah satu dari: "+strings.Join(database.TrashTypes(), ", "), http.StatusBadRequest)
+				return
+			}
+			writeJSONError(w, "Error fetching trash", http.StatusInternalServerError)
+			return
+		}
+
+		page := parsePagination(r, defaultPageLimit)
+		writePaginated(w, paginateSlice(items, page), len(items), page, "")
+	}
+}
+
+// handleRestoreTrashItem - POST /api/v1/admin/trash/{type}/{id}/restore
+func (s *Server) handleRestoreTrashItem() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		itemType, id, ok := trashItemParams(w, r)
+		if !ok {
+			return
+		}
+
+		if err := database.RestoreTrashItem(r.Context(), s.GetDB(), itemType, id); err != nil {
+			writeTrashItemError(w, err, "Failed to restore item")
+			return
+		}
+
+		writeJSONSuccess(w, "Item berhasil dipulihkan", map[string]interface{}{"type": itemType, "id": id}, http.StatusOK)
+	}
+}
+
+// handlePurgeTrashItem - DELETE /api/v1/admin/trash/{type}/{id}
+// Menghapus permanen item beserta data yang ikut terhapus (cascade)
+func (s *Server) handlePurgeTrashItem() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		itemType, id, ok := trashItemParams(w, r)
+		if !ok {
+			return
+		}
+
+		if err := database.PurgeTrashItem(r.Context(), s.GetDB(), itemType, id); err != nil {
+			writeTrashItemError(w, err, "Failed to purge item")
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// trashItemParams reads {type} and {id} from the path, writing a 400 when
+// they are invalid
+func trashItemParams(w http.ResponseWriter, r *http.Request) (string, int, bool) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeJSONError(w, "Invalid item ID", http.StatusBadRequest)
+		return "", 0, false
+	}
+	return vars["type"], id, true
+}
+
+func writeTrashItemError(w http.ResponseWriter, err error, msg string) {
+	switch {
+	case errors.Is(err, database.ErrUnknownTrashType):
+		writeJSONError(w, "type harus salah satu dari: "+strings.Join(database.TrashTypes(), ", "), http.StatusBadRequest)
+	case errors.Is(err, sql.ErrNoRows):
+		writeJSONError(w, "Item not found in trash", http.StatusNotFound)
+	case errors.Is(err, database.ErrTrashItemConflict):
+		writeJSONError(w, "Sudah ada item aktif dengan nama yang sama", http.StatusConflict)
+	default:
+		writeJSONError(w, msg, http.StatusInternalServerError)
+	}
+}
+
+// ========================================
+// ROUTE REGISTRATION
+// ========================================
+
+// RegisterAdminTrashRoutes registers trash routes
+func (s *Server) RegisterAdminTrashRoutes(r *mux.Router) {
+	r.HandleFunc("/trash", s.handleListTrash()).Methods("GET")
+	r.HandleFunc("/trash/{type}/{id:[0-9]+}/restore", s.handleRestoreTrashItem()).Methods("POST")
+	r.HandleFunc("/trash/{type}/{id:[0-9]+}", s.handlePurgeTrashItem()).Methods("DELETE")
+}
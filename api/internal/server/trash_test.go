@@ -0,0 +1,104 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"news-portal-web/api/internal/database"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestListTrash(t *testing.T) {
+	ts := newTestServer(t)
+	_, token := ts.createUser("admin", "admin", true)
+
+	ts.mock.ExpectQuery(`SELECT 'tag', tag_id, nama_tag, deleted_at FROM tags WHERE deleted_at IS NOT NULL\s+ORDER BY 4 DESC`).
+		WillReturnRows(sqlmock.NewRows([]string{"type", "id", "title", "deleted_at"}).
+			AddRow("tag", 5, "Pemilu", time.Now()))
+
+	var items []database.TrashItem
+	if total := ts.listPage("/admin/trash?type=tag", token, &items); total != 1 || len(items) != 1 {
+		t.Fatalf("trash: total %d, got %d", total, len(items))
+	}
+	if items[0].Type != "tag" || items[0].Title != "Pemilu" {
+		t.Errorf("trash item = %+v", items[0])
+	}
+
+	ts.expectStatus(http.MethodGet, "/admin/trash?type=media", token, nil, nil, http.StatusBadRequest)
+}
+
+func TestRestoreTrashItem(t *testing.T) {
+	ts := newTestServer(t)
+	admin, token := ts.createUser("admin", "admin", true)
+
+	ts.mock.ExpectBegin()
+	ts.expectSnapshot("articles")
+	ts.mock.ExpectExec(`UPDATE articles SET deleted_at = NULL WHERE artikel_id = \$1 AND deleted_at IS NOT NULL`).
+		WithArgs(7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	ts.expectSnapshot("articles")
+	ts.expectAudit(admin.UserID, "article.restore", "article", "7")
+	ts.mock.ExpectCommit()
+	ts.expectStatus(http.MethodPost, "/admin/trash/article/7/restore", token, nil, nil, http.StatusOK)
+
+	// Rows that are not in the trash are not restored
+	ts.mock.ExpectBegin()
+	ts.expectSnapshot("articles")
+	ts.mock.ExpectExec("UPDATE articles SET deleted_at = NULL").WithArgs(8).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	ts.mock.ExpectRollback()
+	ts.expectStatus(http.MethodPost, "/admin/trash/article/8/restore", token, nil, nil, http.StatusNotFound)
+
+	ts.expectStatus(http.MethodPost, "/admin/trash/media/7/restore", token, nil, nil, http.StatusBadRequest)
+}
+
+func TestRestoreTrashItemNameTaken(t *testing.T) {
+	ts := newTestServer(t)
+	admin, token := ts.createUser("admin", "admin", true)
+
+	ts.mock.ExpectBegin()
+	ts.expectSnapshot("categories")
+	ts.mock.ExpectQuery(`FROM categories old JOIN categories live ON live.nama_kategori = old.nama_kategori\s+WHERE old.kategori_id = \$1`).
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	ts.mock.ExpectExec(`UPDATE categories SET deleted_at = NULL`).WithArgs(3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	ts.expectSnapshot("categories")
+	ts.expectAudit(admin.UserID, "category.restore", "category", "3")
+	ts.mock.ExpectCommit()
+	ts.expectStatus(http.MethodPost, "/admin/trash/category/3/restore", token, nil, nil, http.StatusOK)
+
+	// Deleting a tag and creating it again leaves the old one in the trash
+	ts.mock.ExpectBegin()
+	ts.expectSnapshot("tags")
+	ts.mock.ExpectQuery(`FROM tags old JOIN tags live ON live.nama_tag = old.nama_tag`).
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	ts.mock.ExpectRollback()
+	ts.expectStatus(http.MethodPost, "/admin/trash/tag/5/restore", token, nil, nil, http.StatusConflict)
+
+	ts.mock.ExpectBegin()
+	ts.expectSnapshot("users")
+	ts.mock.ExpectQuery(`FROM users old JOIN users live ON live.username = old.username OR live.email = old.email`).
+		WithArgs(9).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	ts.mock.ExpectRollback()
+	ts.expectStatus(http.MethodPost, "/admin/trash/user/9/restore", token, nil, nil, http.StatusConflict)
+}
+
+func TestPurgeTrashItem(t *testing.T) {
+	ts := newTestServer(t)
+	admin, token := ts.createUser("admin", "admin", true)
+
+	ts.mock.ExpectBegin()
+	ts.expectSnapshot("comments")
+	ts.mock.ExpectExec(`DELETE FROM comments WHERE komentar_id = \$1 AND deleted_at IS NOT NULL`).
+		WithArgs(12).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	ts.mock.ExpectQuery("FROM comments t").WillReturnRows(sqlmock.NewRows([]string{"snapshot"}))
+	ts.expectAudit(admin.UserID, "comment.purge", "comment", "12")
+	ts.mock.ExpectCommit()
+	ts.expectStatus(http.MethodDelete, "/admin/trash/comment/12", token, nil, nil, http.StatusNoContent)
+}
@@ -1,21 +1,50 @@
 package server
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
-	"os"
 	"path/filepath"
-	// "strings"
+	"strconv"
+	"strings"
 	"time"
+
+	"news-portal-web/api/internal/auth"
+	"news-portal-web/api/internal/database"
+	"news-portal-web/api/internal/imaging"
 )
 
-// handleUpload - POST /api/v1/editor/upload
+// maxUploadSize is the largest accepted upload (10MB)
+const maxUploadSize = 10 << 20
+
+// handleUpload - POST /api/v1/editor/upload (juga POST /api/v1/editor/media)
+// Menyimpan file gambar dan mencatatnya di media library. Field form opsional:
+// alt_text, caption, artikel_id.
+//
+// Jenis file ditentukan dari isinya, bukan dari Content-Type yang dikirim
+// client. Metadata EXIF/GPS dibuang dan varian thumbnail, medium dan large
+// (beserta versi WebP) ikut disimpan.
 func (s *Server) handleUpload() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		// Max 10MB
-		r.ParseMultipartForm(10 << 20)
+		userID, ok := getUserIDFromContext(r.Context())
+		if !ok {
+			writeJSONError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if !s.can(r.Context(), auth.PermMediaUpload) {
+			writeJSONError(w, "Anda tidak memiliki izin untuk mengunggah media", http.StatusForbidden)
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize+1<<20)
+		if err := r.ParseMultipartForm(maxUploadSize); err != nil {
+			writeJSONError(w, "File terlalu besar (maks 10MB)", http.StatusRequestEntityTooLarge)
+			return
+		}
 
 		file, header, err := r.FormFile("file")
 		if err != nil {
@@ -24,51 +53,121 @@ func (s *Server) handleUpload() http.HandlerFunc {
 		}
 		defer file.Close()
 
-		// Validate file type
-		contentType := header.Header.Get("Content-Type")
-		allowedTypes := map[string]string{
-			"image/jpeg": ".jpg",
-			"image/png":  ".png",
-			"image/gif":  ".gif",
-			"image/webp": ".webp",
+		var artikelID *int
+		if v := r.FormValue("artikel_id"); v != "" {
+			id, err := strconv.Atoi(v)
+			if err != nil {
+				writeJSONError(w, "Invalid artikel_id", http.StatusBadRequest)
+				return
+			}
+			if !s.canEditArticleMedia(w, r, id) {
+				return
+			}
+			artikelID = &id
 		}
 
-		ext, ok := allowedTypes[contentType]
-		if !ok {
-			writeJSONError(w, "Format file tidak didukung", http.StatusBadRequest)
+		data, err := io.ReadAll(io.LimitReader(file, maxUploadSize+1))
+		if err != nil {
+			writeJSONError(w, "Gagal membaca file", http.StatusBadRequest)
+			return
+		}
+		if len(data) > maxUploadSize {
+			writeJSONError(w, "File terlalu besar (maks 10MB)", http.StatusRequestEntityTooLarge)
 			return
 		}
 
-		// Create uploads directory
-		uploadDir := "./uploads/articles"
-		if err := os.MkdirAll(uploadDir, os.ModePerm); err != nil {
-			writeJSONError(w, "Gagal membuat direktori", http.StatusInternalServerError)
+		img, err := imaging.Process(data)
+		if err != nil {
+			switch {
+			case errors.Is(err, imaging.ErrUnsupportedFormat):
+				writeJSONError(w, "Format file tidak didukung", http.StatusBadRequest)
+			case errors.Is(err, imaging.ErrImageTooLarge):
+				writeJSONError(w, "Dimensi gambar terlalu besar", http.StatusBadRequest)
+			default:
+				writeJSONError(w, "File bukan gambar yang valid", http.StatusBadRequest)
+			}
 			return
 		}
 
-		// Generate filename
-		filename := fmt.Sprintf("%d%s", time.Now().UnixNano(), ext)
-		filePath := filepath.Join(uploadDir, filename)
+		// Generate key and save file with its variants
+		base := fmt.Sprintf("articles/%d", time.Now().UnixNano())
+		key := base + img.Ext
+		stored := []string{}
+		cleanup := func() {
+			for _, k := range stored {
+				s.storage.Delete(r.Context(), k)
+			}
+		}
 
-		// Save file
-		dst, err := os.Create(filePath)
-		if err != nil {
+		if err := s.storage.Put(r.Context(), key, bytes.NewReader(img.Original), int64(len(img.Original)), img.ContentType); err != nil {
+			log.Printf("⚠️  Upload failed: %v", err)
 			writeJSONError(w, "Gagal menyimpan file", http.StatusInternalServerError)
 			return
 		}
-		defer dst.Close()
+		stored = append(stored, key)
 
-		if _, err := io.Copy(dst, file); err != nil {
-			writeJSONError(w, "Gagal menyimpan file", http.StatusInternalServerError)
+		variants := database.ImageVariants{}
+		for _, v := range img.Variants {
+			variantKey := base + "-" + v.Name + v.Ext
+			if err := s.storage.Put(r.Context(), variantKey, bytes.NewReader(v.Data), int64(len(v.Data)), v.ContentType); err != nil {
+				log.Printf("⚠️  Upload of variant %s failed: %v", variantKey, err)
+				cleanup()
+				writeJSONError(w, "Gagal menyimpan file", http.StatusInternalServerError)
+				return
+			}
+			stored = append(stored, variantKey)
+
+			entry := variants[v.Name]
+			entry.Width, entry.Height = v.Width, v.Height
+			if v.ContentType == "image/webp" {
+				entry.WebPURL, entry.WebPKey = s.storage.URL(variantKey), variantKey
+			} else {
+				entry.URL, entry.Key = s.storage.URL(variantKey), variantKey
+			}
+			variants[v.Name] = entry
+		}
+
+		fileURL := s.storage.URL(key)
+		media := &database.Media{
+			URL:        fileURL,
+			TipeMedia:  "image",
+			ArtikelID:  artikelID,
+			UserID:     &userID,
+			NamaFile:   filepath.Base(header.Filename),
+			StorageKey: key,
+			MimeType:   img.ContentType,
+			Ukuran:     int64(len(img.Original)),
+			Lebar:      &img.Width,
+			Tinggi:     &img.Height,
+			AltText:    optionalString(r.FormValue("alt_text")),
+			Caption:    optionalString(r.FormValue("caption")),
+			Variants:   variants,
+		}
+
+		media, err = database.CreateMedia(r.Context(), s.GetDB(), media)
+		if err != nil {
+			cleanup()
+			writeJSONError(w, "Gagal menyimpan data media", http.StatusInternalServerError)
 			return
 		}
 
 		// Return path
-		relativePath := "/uploads/articles/" + filename
 		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
 		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": true,
-			"path":    relativePath,
+			"success":  true,
+			"path":     fileURL,
+			"variants": variants,
+			"media":    media,
 		})
 	}
 }
+
+// optionalString returns nil for blank strings
+func optionalString(s string) *string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	return &s
+}
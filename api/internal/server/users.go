@@ -2,9 +2,13 @@ package server
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"log"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"news-portal-web/api/internal/auth"
 	"news-portal-web/api/internal/database"
@@ -41,7 +45,7 @@ func (s *Server) handleGetCurrentUser() http.HandlerFunc {
 			return
 		}
 
-		user, err := database.GetUserByID(r.Context(), s.GetDB(), userID)
+		user, err := s.users.GetByID(r.Context(), userID)
 		if err != nil {
 			writeJSONError(w, "User tidak ditemukan", http.StatusNotFound)
 			return
@@ -90,7 +94,7 @@ func (s *Server) handleUpdateCurrentUser() http.HandlerFunc {
 		}
 
 		// Check username uniqueness
-		exists, err := database.CheckUsernameExists(s.GetDB(), req.Username, userID)
+		exists, err := s.users.UsernameTaken(r.Context(), req.Username, userID)
 		if err != nil {
 			writeJSONError(w, "Error checking username", http.StatusInternalServerError)
 			return
@@ -101,7 +105,7 @@ func (s *Server) handleUpdateCurrentUser() http.HandlerFunc {
 		}
 
 		// Check email uniqueness
-		exists, err = database.CheckEmailExists(s.GetDB(), req.Email, userID)
+		exists, err = s.users.EmailTaken(r.Context(), req.Email, userID)
 		if err != nil {
 			writeJSONError(w, "Error checking email", http.StatusInternalServerError)
 			return
@@ -111,13 +115,26 @@ func (s *Server) handleUpdateCurrentUser() http.HandlerFunc {
 			return
 		}
 
+		current, err := s.users.GetByID(r.Context(), userID)
+		if err != nil {
+			writeJSONError(w, "User tidak ditemukan", http.StatusNotFound)
+			return
+		}
+
 		// Update user
-		user, err := database.UpdateUserBasic(s.GetDB(), userID, req.Username, req.Email)
+		user, err := s.users.UpdateBasic(r.Context(), userID, req.Username, req.Email)
 		if err != nil {
 			writeJSONError(w, "Error updating user", http.StatusInternalServerError)
 			return
 		}
 
+		// Email baru harus diverifikasi ulang
+		if user.Email != current.Email {
+			if err := s.sendVerificationEmail(r.Context(), user); err != nil {
+				log.Printf("⚠️  Failed to send verification email to user %d: %v", user.UserID, err)
+			}
+		}
+
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(user.ToPublic())
 	}
@@ -155,7 +172,7 @@ func (s *Server) handleChangePassword() http.HandlerFunc {
 		}
 
 		// Get current user
-		user, err := database.GetUserByID(r.Context(), s.GetDB(), userID)
+		user, err := s.users.GetByID(r.Context(), userID)
 		if err != nil {
 			writeJSONError(w, "User tidak ditemukan", http.StatusNotFound)
 			return
@@ -168,7 +185,7 @@ func (s *Server) handleChangePassword() http.HandlerFunc {
 		}
 
 		// Update password
-		err = database.UpdateUserPassword(s.GetDB(), userID, req.NewPassword)
+		err = s.users.UpdatePassword(r.Context(), userID, req.NewPassword)
 		if err != nil {
 			writeJSONError(w, "Error updating password", http.StatusInternalServerError)
 			return
@@ -188,14 +205,86 @@ func (s *Server) handleChangePassword() http.HandlerFunc {
 // handleGetAllUsers - GET /api/v1/admin/users
 func (s *Server) handleGetAllUsers() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		users, err := database.GetAllUsers(s.GetDB())
+		page := parsePagination(r, defaultPageLimit)
+
+		users, err := s.users.List(r.Context(), page.Limit, page.Offset)
 		if err != nil {
 			writeJSONError(w, "Error fetching users", http.StatusInternalServerError)
 			return
 		}
 
+		total, err := s.users.Count(r.Context())
+		if err != nil {
+			writeJSONError(w, "Error counting users", http.StatusInternalServerError)
+			return
+		}
+
+		writePaginated(w, users, total, page, "")
+	}
+}
+
+// handleCreateUser - POST /api/v1/admin/users
+// Admin membuat akun dengan role apa pun; email dianggap terverifikasi
+func (s *Server) handleCreateUser() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req database.UserRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		if req.Role == "" {
+			req.Role = "user"
+		}
+
+		if err := validateUserRequest(&req); err != nil {
+			writeJSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if !s.checkRole(w, r, req.Role) {
+			return
+		}
+
+		emailExists, err := s.users.EmailExists(r.Context(), req.Email)
+		if err != nil {
+			writeJSONError(w, "Error checking email", http.StatusInternalServerError)
+			return
+		}
+		if emailExists {
+			writeJSONError(w, "Email sudah terdaftar", http.StatusConflict)
+			return
+		}
+
+		usernameExists, err := s.users.UsernameExists(r.Context(), req.Username)
+		if err != nil {
+			writeJSONError(w, "Error checking username", http.StatusInternalServerError)
+			return
+		}
+		if usernameExists {
+			writeJSONError(w, "Username sudah digunakan", http.StatusConflict)
+			return
+		}
+
+		user, err := s.users.Create(r.Context(), &req)
+		if err != nil {
+			if strings.Contains(err.Error(), "duplicate") {
+				writeJSONError(w, "Email atau username sudah terdaftar", http.StatusConflict)
+				return
+			}
+			writeJSONError(w, "Gagal membuat user", http.StatusInternalServerError)
+			return
+		}
+
+		if err := s.users.MarkEmailVerified(r.Context(), user.UserID); err != nil {
+			writeJSONError(w, "Gagal memverifikasi email user", http.StatusInternalServerError)
+			return
+		}
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(users)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(user.ToPublic())
 	}
 }
 
@@ -209,7 +298,7 @@ func (s *Server) handleGetUserByID() http.HandlerFunc {
 			return
 		}
 
-		user, err := database.GetUserByID(r.Context(), s.GetDB(), userID)
+		user, err := s.users.GetByID(r.Context(), userID)
 		if err != nil {
 			writeJSONError(w, "User tidak ditemukan", http.StatusNotFound)
 			return
@@ -236,7 +325,7 @@ func (s *Server) handleUpdateUserRole() http.HandlerFunc {
 		}
 
 		// Check if user exists
-		_, err = database.GetUserByID(r.Context(), s.GetDB(), userID)
+		_, err = s.users.GetByID(r.Context(), userID)
 		if err != nil {
 			writeJSONError(w, "User tidak ditemukan", http.StatusNotFound)
 			return
@@ -249,12 +338,11 @@ func (s *Server) handleUpdateUserRole() http.HandlerFunc {
 		}
 
 		// Validate role
-		if !isValidRole(req.Role) {
-			writeJSONError(w, "Role tidak valid (admin, editor, user)", http.StatusBadRequest)
+		if !s.checkRole(w, r, req.Role) {
 			return
 		}
 
-		err = database.UpdateUserRole(s.GetDB(), userID, req.Role)
+		err = s.users.UpdateRole(r.Context(), userID, req.Role)
 		if err != nil {
 			writeJSONError(w, "Error updating role", http.StatusInternalServerError)
 			return
@@ -291,18 +379,23 @@ func (s *Server) handleDeleteUser() http.HandlerFunc {
 		}
 
 		// Check if user exists
-		_, err = database.GetUserByID(r.Context(), s.GetDB(), userID)
+		_, err = s.users.GetByID(r.Context(), userID)
 		if err != nil {
 			writeJSONError(w, "User tidak ditemukan", http.StatusNotFound)
 			return
 		}
 
-		err = database.DeleteUser(s.GetDB(), userID)
+		err = s.users.Delete(r.Context(), userID)
 		if err != nil {
 			writeJSONError(w, "Error deleting user", http.StatusInternalServerError)
 			return
 		}
 
+		// User di tempat sampah tidak boleh tetap login
+		if err := s.GetJWTManager().RevokeAllUserTokens(r.Context(), userID); err != nil {
+			log.Printf("⚠️  Failed to revoke sessions of user %d: %v", userID, err)
+		}
+
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]string{
 			"message": "User berhasil dihapus",
@@ -310,6 +403,92 @@ func (s *Server) handleDeleteUser() http.HandlerFunc {
 	}
 }
 
+// handleGetUserLoginHistory - GET /api/v1/admin/users/{id}/login-history
+func (s *Server) handleGetUserLoginHistory() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			writeJSONError(w, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+
+		if _, err := s.users.GetByID(r.Context(), userID); err != nil {
+			writeJSONError(w, "User tidak ditemukan", http.StatusNotFound)
+			return
+		}
+
+		page := parsePagination(r, defaultPageLimit)
+
+		attempts, err := database.ListLoginAttempts(r.Context(), s.GetDB(), userID, page.Limit, page.Offset)
+		if err != nil {
+			writeJSONError(w, "Error fetching login history", http.StatusInternalServerError)
+			return
+		}
+
+		total, err := database.CountLoginAttempts(r.Context(), s.GetDB(), userID)
+		if err != nil {
+			writeJSONError(w, "Error counting login history", http.StatusInternalServerError)
+			return
+		}
+
+		writePaginated(w, attempts, total, page, "")
+	}
+}
+
+// handleGetUserLockStatus - GET /api/v1/admin/users/{id}/lock
+func (s *Server) handleGetUserLockStatus() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			writeJSONError(w, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+
+		if _, err := s.users.GetByID(r.Context(), userID); err != nil {
+			writeJSONError(w, "User tidak ditemukan", http.StatusNotFound)
+			return
+		}
+
+		lockedUntil, err := database.GetAccountLock(r.Context(), s.GetDB(), userID)
+		if err != nil {
+			writeJSONError(w, "Error fetching lock status", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"user_id":      userID,
+			"locked":       lockedUntil != nil,
+			"locked_until": lockedUntil,
+		})
+	}
+}
+
+// handleUnlockUser - POST /api/v1/admin/users/{id}/unlock
+func (s *Server) handleUnlockUser() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			writeJSONError(w, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+
+		if err := database.UnlockUser(r.Context(), s.GetDB(), userID); err != nil {
+			if err == sql.ErrNoRows {
+				writeJSONError(w, "User tidak ditemukan", http.StatusNotFound)
+				return
+			}
+			writeJSONError(w, "Error unlocking user", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"message": "Akun berhasil dibuka",
+		})
+	}
+}
+
 // ========================================
 // ROUTE REGISTRATION
 // ========================================
@@ -319,12 +498,18 @@ func (s *Server) RegisterUserRoutes(r *mux.Router) {
 	r.HandleFunc("/users/me", s.handleGetCurrentUser()).Methods("GET")
 	r.HandleFunc("/users/me", s.handleUpdateCurrentUser()).Methods("PUT")
 	r.HandleFunc("/users/me/password", s.handleChangePassword()).Methods("PUT")
+	r.HandleFunc("/users/me/permissions", s.handleGetCurrentUserPermissions()).Methods("GET")
 }
 
 // RegisterAdminUserRoutes registers admin user management routes
 func (s *Server) RegisterAdminUserRoutes(r *mux.Router) {
 	r.HandleFunc("/users", s.handleGetAllUsers()).Methods("GET")
+	r.HandleFunc("/users", s.handleCreateUser()).Methods("POST")
 	r.HandleFunc("/users/{id:[0-9]+}", s.handleGetUserByID()).Methods("GET")
 	r.HandleFunc("/users/{id:[0-9]+}/role", s.handleUpdateUserRole()).Methods("PUT")
 	r.HandleFunc("/users/{id:[0-9]+}", s.handleDeleteUser()).Methods("DELETE")
+	r.HandleFunc("/users/{id:[0-9]+}/login-history", s.handleGetUserLoginHistory()).Methods("GET")
+	r.HandleFunc("/users/{id:[0-9]+}/lock", s.handleGetUserLockStatus()).Methods("GET")
+	r.HandleFunc("/users/{id:[0-9]+}/unlock", s.handleUnlockUser()).Methods("POST")
+	r.HandleFunc("/users/{id:[0-9]+}/verify-email", s.handleAdminVerifyEmail()).Methods("POST")
 }
@@ -0,0 +1,178 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"news-portal-web/api/internal/auth"
+	"news-portal-web/api/internal/database"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestLogin(t *testing.T) {
+	ts := newTestServer(t)
+	ts.createUser("pembaca", "user", true)
+
+	var resp struct {
+		User   database.UserResponse `json:"user"`
+		Tokens auth.TokenPair        `json:"tokens"`
+	}
+	login := database.LoginRequest{Email: "pembaca@example.com", Password: testPassword}
+	ts.expectStatus(http.MethodPost, "/auth/login", "", login, &resp, http.StatusOK)
+	if resp.User.Username != "pembaca" || resp.Tokens.AccessToken == "" || resp.Tokens.RefreshToken == "" {
+		t.Fatalf("login response: %+v", resp)
+	}
+	ts.expectStatus(http.MethodGet, "/users/me", resp.Tokens.AccessToken, nil, nil, http.StatusOK)
+
+	tests := []struct {
+		name string
+		req  database.LoginRequest
+		want int
+	}{
+		{"wrong password", database.LoginRequest{Email: "pembaca@example.com", Password: "salah123"}, http.StatusUnauthorized},
+		{"unknown email", database.LoginRequest{Email: "siapa@example.com", Password: testPassword}, http.StatusUnauthorized},
+		{"invalid email", database.LoginRequest{Email: "pembaca", Password: testPassword}, http.StatusBadRequest},
+		{"missing password", database.LoginRequest{Email: "pembaca@example.com"}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts.expectStatus(http.MethodPost, "/auth/login", "", tt.req, nil, tt.want)
+		})
+	}
+}
+
+func TestCurrentUserProfile(t *testing.T) {
+	ts := newTestServer(t)
+	user, token := ts.createUser("pembaca", "user", true)
+	ts.createUser("pembaca2", "user", true)
+
+	var profile database.UserResponse
+	ts.expectStatus(http.MethodGet, "/users/me", token, nil, &profile, http.StatusOK)
+	if profile.UserID != user.UserID || !profile.EmailVerified {
+		t.Fatalf("profile: %+v", profile)
+	}
+
+	taken := UpdateUserProfileRequest{Username: "pembaca2", Email: user.Email}
+	ts.expectStatus(http.MethodPut, "/users/me", token, taken, nil, http.StatusConflict)
+
+	taken = UpdateUserProfileRequest{Username: user.Username, Email: "pembaca2@example.com"}
+	ts.expectStatus(http.MethodPut, "/users/me", token, taken, nil, http.StatusConflict)
+
+	// Keep the email so no verification mail has to be sent
+	update := UpdateUserProfileRequest{Username: "pembaca_setia", Email: user.Email}
+	ts.expectStatus(http.MethodPut, "/users/me", token, update, &profile, http.StatusOK)
+	if profile.Username != "pembaca_setia" || profile.Email != user.Email {
+		t.Fatalf("updated profile: %+v", profile)
+	}
+}
+
+func TestChangePassword(t *testing.T) {
+	ts := newTestServer(t)
+	_, token := ts.createUser("pembaca", "user", true)
+
+	ts.expectStatus(http.MethodPut, "/users/me/password", token,
+		ChangePasswordRequest{CurrentPassword: "salah123", NewPassword: "rahasiabaru"}, nil, http.StatusBadRequest)
+	ts.expectStatus(http.MethodPut, "/users/me/password", token,
+		ChangePasswordRequest{CurrentPassword: testPassword, NewPassword: "pendek"}, nil, http.StatusBadRequest)
+	ts.expectStatus(http.MethodPut, "/users/me/password", token,
+		ChangePasswordRequest{CurrentPassword: testPassword, NewPassword: "rahasiabaru"}, nil, http.StatusOK)
+
+	ts.expectStatus(http.MethodPost, "/auth/login", "",
+		database.LoginRequest{Email: "pembaca@example.com", Password: testPassword}, nil, http.StatusUnauthorized)
+	ts.expectStatus(http.MethodPost, "/auth/login", "",
+		database.LoginRequest{Email: "pembaca@example.com", Password: "rahasiabaru"}, nil, http.StatusOK)
+}
+
+func TestAdminUserManagement(t *testing.T) {
+	ts := newTestServer(t)
+	_, token := ts.createUser("admin", "admin", true)
+
+	var created database.UserResponse
+	req := database.UserRequest{Username: "redaktur", Email: "redaktur@example.com", Password: testPassword, Role: "editor"}
+	ts.expectStatus(http.MethodPost, "/admin/users", token, req, &created, http.StatusCreated)
+	if created.Role != "editor" || !created.EmailVerified {
+		t.Fatalf("created user: %+v", created)
+	}
+	ts.expectStatus(http.MethodPost, "/admin/users", token, req, nil, http.StatusConflict)
+
+	req = database.UserRequest{Username: "tamu", Email: "tamu@example.com", Password: testPassword, Role: "superadmin"}
+	ts.expectStatus(http.MethodPost, "/admin/users", token, req, nil, http.StatusBadRequest)
+
+	var users []database.UserResponse
+	if total := ts.listPage("/admin/users", token, &users); total != 2 {
+		t.Fatalf("listed %d users, want 2", total)
+	}
+
+	path := fmt.Sprintf("/admin/users/%d", created.UserID)
+	ts.expectStatus(http.MethodGet, path, token, nil, &created, http.StatusOK)
+	ts.expectStatus(http.MethodGet, "/admin/users/9999", token, nil, nil, http.StatusNotFound)
+
+	ts.expectStatus(http.MethodPut, path+"/role", token, UpdateUserRoleRequest{Role: "superadmin"}, nil, http.StatusBadRequest)
+	ts.expectStatus(http.MethodPut, path+"/role", token, UpdateUserRoleRequest{Role: "user"}, nil, http.StatusOK)
+	ts.expectStatus(http.MethodGet, path, token, nil, &created, http.StatusOK)
+	if created.Role != "user" {
+		t.Fatalf("role after update: %q", created.Role)
+	}
+}
+
+func TestAdminDeleteUser(t *testing.T) {
+	ts := newTestServer(t)
+	admin, adminToken := ts.createUser("admin", "admin", true)
+	user, userToken := ts.createUser("pembaca", "user", true)
+
+	ts.expectStatus(http.MethodDelete, fmt.Sprintf("/admin/users/%d", admin.UserID), adminToken, nil, nil, http.StatusBadRequest)
+
+	path := fmt.Sprintf("/admin/users/%d", user.UserID)
+	ts.expectStatus(http.MethodDelete, path, adminToken, nil, nil, http.StatusOK)
+	ts.expectStatus(http.MethodGet, path, adminToken, nil, nil, http.StatusNotFound)
+
+	// Sessions of a deleted user end immediately
+	ts.expectStatus(http.MethodGet, "/users/me", userToken, nil, nil, http.StatusUnauthorized)
+	ts.expectStatus(http.MethodPost, "/auth/login", "",
+		database.LoginRequest{Email: user.Email, Password: testPassword}, nil, http.StatusUnauthorized)
+}
+
+func TestAdminLoginHistoryAndLock(t *testing.T) {
+	ts := newTestServer(t)
+	admin, token := ts.createUser("admin", "admin", true)
+	user, _ := ts.createUser("pembaca", "user", true)
+
+	ts.mock.ExpectQuery(`FROM login_attempts\s+WHERE user_id = \$1`).
+		WithArgs(user.UserID, 20, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"attempt_id", "user_id", "email", "ip_address", "user_agent", "success", "failure_reason", "created_at"}).
+			AddRow(2, user.UserID, user.Email, "10.0.0.1", "curl", false, "wrong_password", time.Now()))
+	ts.mock.ExpectQuery(`SELECT COUNT\(\*\) FROM login_attempts WHERE user_id = \$1`).WithArgs(user.UserID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(5))
+
+	var attempts []database.LoginAttempt
+	path := fmt.Sprintf("/admin/users/%d/login-history", user.UserID)
+	if total := ts.listPage(path, token, &attempts); total != 5 || len(attempts) != 1 {
+		t.Fatalf("login history: total %d, got %d", total, len(attempts))
+	}
+	ts.expectStatus(http.MethodGet, "/admin/users/9999/login-history", token, nil, nil, http.StatusNotFound)
+
+	until := time.Now().Add(time.Hour)
+	ts.mock.ExpectQuery(`SELECT locked_until FROM users WHERE user_id = \$1 AND locked_until > NOW\(\)`).
+		WithArgs(user.UserID).
+		WillReturnRows(sqlmock.NewRows([]string{"locked_until"}).AddRow(until))
+
+	var lock struct {
+		Locked bool `json:"locked"`
+	}
+	ts.expectStatus(http.MethodGet, fmt.Sprintf("/admin/users/%d/lock", user.UserID), token, nil, &lock, http.StatusOK)
+	if !lock.Locked {
+		t.Error("lock status: locked = false")
+	}
+
+	ts.mock.ExpectBegin()
+	ts.expectSnapshot("users")
+	ts.mock.ExpectExec(`UPDATE users\s+SET locked_until = NULL`).WithArgs(user.UserID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	ts.expectSnapshot("users")
+	ts.expectAudit(admin.UserID, "user.unlock", "user", fmt.Sprint(user.UserID))
+	ts.mock.ExpectCommit()
+	ts.expectStatus(http.MethodPost, fmt.Sprintf("/admin/users/%d/unlock", user.UserID), token, nil, nil, http.StatusOK)
+}
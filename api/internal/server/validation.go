@@ -27,9 +27,10 @@ func isValidEmail(email string) bool {
 // ROLE VALIDATION
 // ========================================
 
-// isValidRole validates user role
-func isValidRole(role string) bool {
-	return role == "admin" || role == "editor" || role == "user"
+// isValidRoleName validates the format of a role name. Whether the role
+// exists is checked against the roles table (see Server.checkRole).
+func isValidRoleName(role string) bool {
+	return roleNameRegex.MatchString(role)
 }
 
 // ========================================
@@ -38,7 +39,11 @@ func isValidRole(role string) bool {
 
 // isValidArticleStatus validates article status
 func isValidArticleStatus(status string) bool {
-	return status == "draft" || status == "published" || status == "archived"
+	switch status {
+	case "draft", "in_review", "approved", "scheduled", "published", "archived":
+		return true
+	}
+	return false
 }
 
 // isValidCommentStatus validates comment status
@@ -97,8 +102,8 @@ func validateUserRequest(req *database.UserRequest) error {
 		return errors.New("password must be at least 8 characters")
 	}
 
-	if req.Role != "" && !isValidRole(req.Role) {
-		return errors.New("invalid role (must be admin, editor, or user)")
+	if req.Role != "" && !isValidRoleName(req.Role) {
+		return errors.New("invalid role name")
 	}
 
 	return nil
@@ -122,8 +127,8 @@ func validateUserUpdateRequest(req *database.UserUpdateRequest) error {
 		return errors.New("password must be at least 8 characters")
 	}
 
-	if req.Role != "" && !isValidRole(req.Role) {
-		return errors.New("invalid role (must be admin, editor, or user)")
+	if req.Role != "" && !isValidRoleName(req.Role) {
+		return errors.New("invalid role name")
 	}
 
 	return nil
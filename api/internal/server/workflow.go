@@ -0,0 +1,312 @@
+package server
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"news-portal-web/api/internal/auth"
+	"news-portal-web/api/internal/database"
+
+	"github.com/gorilla/mux"
+)
+
+// ========================================
+// EDITORIAL WORKFLOW
+// ========================================
+//
+// draft -> in_review -> approved -> (scheduled ->) published -> archived
+//
+// Roles with article.submit send drafts to review, article.review approves
+// or rejects (back to draft with a note) and article.publish schedules,
+// publishes and archives.
+
+// articleTransition describes one workflow step
+type articleTransition struct {
+	From        []string
+	To          string
+	Permission  string
+	OwnerOnly   bool // without article.edit.any only the author's own articles
+	RequireNote bool
+}
+
+var articleTransitions = map[string]articleTransition{
+	"submit": {
+		From:       []string{"draft"},
+		To:         "in_review",
+		Permission: auth.PermArticleSubmit,
+		OwnerOnly:  true,
+	},
+	"approve": {
+		From:       []string{"in_review"},
+		To:         "approved",
+		Permission: auth.PermArticleReview,
+	},
+	"reject": {
+		From:        []string{"in_review", "approved"},
+		To:          "draft",
+		Permission:  auth.PermArticleReview,
+		RequireNote: true,
+	},
+	"publish": {
+		From:       []string{"approved"},
+		To:         "published",
+		Permission: auth.PermArticlePublish,
+	},
+	"archive": {
+		From:       []string{"published"},
+		To:         "archived",
+		Permission: auth.PermArticlePublish,
+	},
+}
+
+// ArticleTransitionRequest - Request body untuk transisi status artikel
+type ArticleTransitionRequest struct {
+	Catatan string `json:"catatan,omitempty"`
+}
+
+// handleArticleTransition - POST /api/v1/editor/articles/{id}/{action}
+func (s *Server) handleArticleTransition(action string) http.HandlerFunc {
+	transition := articleTransitions[action]
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		articleID, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			writeJSONError(w, "Invalid article ID", http.StatusBadRequest)
+			return
+		}
+
+		userID, ok := getUserIDFromContext(r.Context())
+		if !ok {
+			writeJSONError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		if !s.can(r.Context(), transition.Permission) {
+			writeJSONError(w, "Role Anda tidak diizinkan untuk aksi '"+action+"'", http.StatusForbidden)
+			return
+		}
+
+		var req ArticleTransitionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			writeJSONError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		req.Catatan = strings.TrimSpace(req.Catatan)
+
+		if transition.RequireNote && req.Catatan == "" {
+			writeJSONError(w, "Catatan reviewer harus diisi", http.StatusBadRequest)
+			return
+		}
+
+		if transition.OwnerOnly && !s.can(r.Context(), auth.PermArticleEditAny) {
+			article, err := s.articles.GetByID(r.Context(), articleID)
+			if err != nil {
+				if err == sql.ErrNoRows {
+					writeJSONError(w, "Article not found", http.StatusNotFound)
+					return
+				}
+				writeJSONError(w, "Error fetching article", http.StatusInternalServerError)
+				return
+			}
+			if article.UserID != userID {
+				writeJSONError(w, "Anda hanya dapat mengajukan artikel milik sendiri", http.StatusForbidden)
+				return
+			}
+		}
+
+		article, err := database.TransitionArticleStatus(r.Context(), s.GetDB(), articleID,
+			transition.From, transition.To, userID, req.Catatan)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				writeJSONError(w, "Article not found", http.StatusNotFound)
+				return
+			}
+			if errors.Is(err, database.ErrInvalidStatusTransition) {
+				writeJSONError(w, "Status artikel tidak dapat diubah: "+err.Error(), http.StatusConflict)
+				return
+			}
+			writeJSONError(w, "Error updating article status", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(article)
+	}
+}
+
+// handleGetArticleStatusHistory - GET /api/v1/editor/articles/{id}/history
+func (s *Server) handleGetArticleStatusHistory() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		articleID, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			writeJSONError(w, "Invalid article ID", http.StatusBadRequest)
+			return
+		}
+
+		page := parsePagination(r, defaultPageLimit)
+
+		history, err := database.GetArticleStatusHistory(r.Context(), s.GetDB(), articleID)
+		if err != nil {
+			writeJSONError(w, "Error fetching status history", http.StatusInternalServerError)
+			return
+		}
+
+		writePaginated(w, paginateSlice(history, page), len(history), page, "")
+	}
+}
+
+// ========================================
+// SCHEDULED PUBLISHING
+// ========================================
+
+// ScheduleArticleRequest - Request body untuk menjadwalkan publikasi
+type ScheduleArticleRequest struct {
+	TanggalPublikasi string `json:"tanggal_publikasi"`
+	Catatan          string `json:"catatan,omitempty"`
+}
+
+// handleScheduleArticle - POST /api/v1/editor/articles/{id}/schedule
+// Menjadwalkan artikel approved (atau menjadwal ulang) untuk terbit otomatis
+func (s *Server) handleScheduleArticle() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		articleID, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			writeJSONError(w, "Invalid article ID", http.StatusBadRequest)
+			return
+		}
+
+		userID, ok := getUserIDFromContext(r.Context())
+		if !ok {
+			writeJSONError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if !s.can(r.Context(), auth.PermArticlePublish) {
+			writeJSONError(w, "Role Anda tidak diizinkan untuk menjadwalkan artikel", http.StatusForbidden)
+			return
+		}
+
+		var req ScheduleArticleRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		publishAt, err := time.Parse(time.RFC3339, req.TanggalPublikasi)
+		if err != nil {
+			writeJSONError(w, "tanggal_publikasi harus berformat RFC3339", http.StatusBadRequest)
+			return
+		}
+		if !publishAt.After(time.Now()) {
+			writeJSONError(w, "tanggal_publikasi harus di masa depan", http.StatusBadRequest)
+			return
+		}
+
+		article, err := database.ScheduleArticle(r.Context(), s.GetDB(), articleID,
+			[]string{"approved", "scheduled"}, publishAt, userID, strings.TrimSpace(req.Catatan))
+		if err != nil {
+			if err == sql.ErrNoRows {
+				writeJSONError(w, "Article not found", http.StatusNotFound)
+				return
+			}
+			if errors.Is(err, database.ErrInvalidStatusTransition) {
+				writeJSONError(w, "Hanya artikel approved yang dapat dijadwalkan", http.StatusConflict)
+				return
+			}
+			writeJSONError(w, "Error scheduling article", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(article)
+	}
+}
+
+// handleCancelScheduledArticle - DELETE /api/v1/editor/articles/{id}/schedule
+// Membatalkan jadwal publikasi, artikel kembali ke status approved
+func (s *Server) handleCancelScheduledArticle() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		articleID, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			writeJSONError(w, "Invalid article ID", http.StatusBadRequest)
+			return
+		}
+
+		userID, ok := getUserIDFromContext(r.Context())
+		if !ok {
+			writeJSONError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if !s.can(r.Context(), auth.PermArticlePublish) {
+			writeJSONError(w, "Role Anda tidak diizinkan untuk membatalkan jadwal", http.StatusForbidden)
+			return
+		}
+
+		article, err := database.TransitionArticleStatus(r.Context(), s.GetDB(), articleID,
+			[]string{"scheduled"}, "approved", userID, "Jadwal publikasi dibatalkan")
+		if err != nil {
+			if err == sql.ErrNoRows {
+				writeJSONError(w, "Article not found", http.StatusNotFound)
+				return
+			}
+			if errors.Is(err, database.ErrInvalidStatusTransition) {
+				writeJSONError(w, "Artikel tidak sedang dijadwalkan", http.StatusConflict)
+				return
+			}
+			writeJSONError(w, "Error cancelling schedule", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(article)
+	}
+}
+
+// handleGetScheduledArticles - GET /api/v1/editor/articles/scheduled
+// Daftar artikel terjadwal, yang paling dekat waktu terbitnya lebih dulu
+func (s *Server) handleGetScheduledArticles() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		page := parsePagination(r, defaultPageLimit)
+		filter := database.ArticleFilter{Status: "scheduled", Limit: page.Limit, Offset: page.Offset}
+
+		articles, err := s.articles.List(r.Context(), filter)
+		if err != nil {
+			writeJSONError(w, "Error fetching scheduled articles", http.StatusInternalServerError)
+			return
+		}
+
+		total, err := s.articles.Count(r.Context(), filter)
+		if err != nil {
+			writeJSONError(w, "Error counting scheduled articles", http.StatusInternalServerError)
+			return
+		}
+
+		if articles == nil {
+			articles = []database.Article{}
+		}
+
+		writePaginated(w, articles, total, page, "")
+	}
+}
+
+// ========================================
+// ROUTE REGISTRATION
+// ========================================
+
+// RegisterEditorWorkflowRoutes registers article workflow transition routes
+func (s *Server) RegisterEditorWorkflowRoutes(r *mux.Router) {
+	for action := range articleTransitions {
+		r.HandleFunc("/articles/{id:[0-9]+}/"+action, s.handleArticleTransition(action)).Methods("POST")
+	}
+	r.HandleFunc("/articles/{id:[0-9]+}/history", s.handleGetArticleStatusHistory()).Methods("GET")
+
+	// Scheduled publishing
+	r.HandleFunc("/articles/scheduled", s.handleGetScheduledArticles()).Methods("GET")
+	r.HandleFunc("/articles/{id:[0-9]+}/schedule", s.handleScheduleArticle()).Methods("POST")
+	r.HandleFunc("/articles/{id:[0-9]+}/schedule", s.handleCancelScheduledArticle()).Methods("DELETE")
+}
@@ -0,0 +1,155 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"news-portal-web/api/internal/database"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// expectTransition expects TransitionArticleStatus to move the article from
+// one status to another on behalf of userID
+func (ts *testServer) expectTransition(articleID int, from, to string, userID int) {
+	ts.mock.ExpectBegin()
+	ts.mock.ExpectQuery(`SELECT status FROM articles WHERE artikel_id = \$1 AND deleted_at IS NULL FOR UPDATE`).
+		WithArgs(articleID).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(from))
+	ts.mock.ExpectExec("UPDATE articles").
+		WithArgs(to, articleID, nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	ts.mock.ExpectExec("INSERT INTO article_status_history").
+		WithArgs(articleID, from, to, userID, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	ts.expectAudit(userID, "article.status_change", "article", strconv.Itoa(articleID))
+	ts.mock.ExpectCommit()
+}
+
+func TestArticleTransition(t *testing.T) {
+	ts := newTestServer(t)
+	reviewer, token := ts.createUser("redaktur", "reviewer", true)
+
+	ts.expectTransition(7, "in_review", "approved", reviewer.UserID)
+	ts.expectGetArticle(7, "Banjir di Jakarta", 1)
+
+	var article database.Article
+	ts.expectStatus(http.MethodPost, "/editor/articles/7/approve", token, nil, &article, http.StatusOK)
+	if article.ArtikelID != 7 {
+		t.Errorf("approve returned article %d, want 7", article.ArtikelID)
+	}
+}
+
+func TestArticleTransitionFromWrongStatus(t *testing.T) {
+	ts := newTestServer(t)
+	_, token := ts.createUser("redaktur", "reviewer", true)
+
+	ts.mock.ExpectBegin()
+	ts.mock.ExpectQuery("SELECT status FROM articles").WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("draft"))
+	ts.mock.ExpectRollback()
+	ts.expectStatus(http.MethodPost, "/editor/articles/7/approve", token, nil, nil, http.StatusConflict)
+
+	ts.mock.ExpectBegin()
+	ts.mock.ExpectQuery("SELECT status FROM articles").WithArgs(8).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}))
+	ts.mock.ExpectRollback()
+	ts.expectStatus(http.MethodPost, "/editor/articles/8/approve", token, nil, nil, http.StatusNotFound)
+}
+
+func TestArticleTransitionChecks(t *testing.T) {
+	ts := newTestServer(t)
+	author, _ := ts.createUser("penulis", "editor", true)
+	_, otherToken := ts.createUser("penulis2", "editor", true)
+	_, reviewerToken := ts.createUser("redaktur", "reviewer", true)
+
+	article := ts.createArticle(author.UserID, database.ArticleInput{Judul: "Milik Orang Lain"})
+
+	// None of these reach the database
+	tests := []struct {
+		name, path, token string
+		body              any
+		want              int
+	}{
+		{"editor approves", "/editor/articles/7/approve", otherToken, nil, http.StatusForbidden},
+		{"editor publishes", "/editor/articles/7/publish", otherToken, nil, http.StatusForbidden},
+		{"reject without note", "/editor/articles/7/reject", reviewerToken, ArticleTransitionRequest{Catatan: "  "}, http.StatusBadRequest},
+		{"submit someone else's article", fmt.Sprintf("/editor/articles/%d/submit", article.ArtikelID), otherToken, nil, http.StatusForbidden},
+		{"submit missing article", "/editor/articles/9999/submit", otherToken, nil, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts.expectStatus(http.MethodPost, tt.path, tt.token, tt.body, nil, tt.want)
+		})
+	}
+}
+
+func TestArticleStatusHistory(t *testing.T) {
+	ts := newTestServer(t)
+	_, token := ts.createUser("penulis", "editor", true)
+
+	columns := []string{"riwayat_id", "artikel_id", "status_awal", "status_baru", "user_id", "username", "catatan", "created_at"}
+	ts.mock.ExpectQuery("FROM article_status_history h").WithArgs(7).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(2, 7, "in_review", "draft", 3, "redaktur", "Perbaiki judul", time.Now()).
+			AddRow(1, 7, "draft", "in_review", 1, "penulis", nil, time.Now()))
+
+	var history []database.ArticleStatusChange
+	if total := ts.listPage("/editor/articles/7/history?limit=1", token, &history); total != 2 || len(history) != 1 {
+		t.Fatalf("history: total %d, got %d", total, len(history))
+	}
+	if history[0].StatusBaru != "draft" {
+		t.Errorf("newest change moved to %q, want draft", history[0].StatusBaru)
+	}
+}
+
+func TestPublishAfterCancelledSchedule(t *testing.T) {
+	ts := newTestServer(t)
+	reviewer, token := ts.createUser("redaktur", "reviewer", true)
+
+	ts.expectTransition(7, "scheduled", "approved", reviewer.UserID)
+	ts.expectGetArticle(7, "Banjir di Jakarta", 1)
+	ts.expectStatus(http.MethodDelete, "/editor/articles/7/schedule", token, nil, nil, http.StatusOK)
+
+	// The cancelled schedule left a future tanggal_publikasi behind; publishing
+	// must replace it so the article does not go live dated in the future
+	ts.mock.ExpectBegin()
+	ts.mock.ExpectQuery("SELECT status FROM articles").WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("approved"))
+	ts.mock.ExpectExec(`WHEN \$1 = 'published' AND \(tanggal_publikasi IS NULL OR tanggal_publikasi > NOW\(\)\) THEN NOW\(\)`).
+		WithArgs("published", 7, nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	ts.mock.ExpectExec("INSERT INTO article_status_history").
+		WithArgs(7, "approved", "published", reviewer.UserID, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	ts.expectAudit(reviewer.UserID, "article.status_change", "article", "7")
+	ts.mock.ExpectCommit()
+	ts.expectGetArticle(7, "Banjir di Jakarta", 1)
+	ts.expectStatus(http.MethodPost, "/editor/articles/7/publish", token, nil, nil, http.StatusOK)
+}
+
+func TestScheduledArticles(t *testing.T) {
+	ts := newTestServer(t)
+	editor, token := ts.createUser("penulis", "editor", true)
+
+	soon := time.Now().Add(time.Hour)
+	for i, judul := range []string{"Lusa", "Besok", "Nanti Sore"} {
+		ts.createArticle(editor.UserID, database.ArticleInput{
+			Judul: judul, Status: "scheduled",
+			TanggalPublikasi: soon.Add(time.Duration(2-i) * time.Hour).Format(time.RFC3339),
+		})
+	}
+	ts.createArticle(editor.UserID, database.ArticleInput{Judul: "Rancangan"})
+
+	// Soonest first, paged by the repository
+	var articles []database.Article
+	if total := ts.listPage("/editor/articles/scheduled?limit=2&offset=1", token, &articles); total != 3 || len(articles) != 2 {
+		t.Fatalf("scheduled: total %d, got %d", total, len(articles))
+	}
+	if articles[0].Judul != "Besok" || articles[1].Judul != "Lusa" {
+		t.Errorf("scheduled order = %q, %q", articles[0].Judul, articles[1].Judul)
+	}
+}
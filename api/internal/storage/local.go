@@ -0,0 +1,236 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PrivatePrefix marks keys that the local backend only serves through a
+// signed URL
+const PrivatePrefix = "private/"
+
+// Local stores files in a directory and serves them itself. Only suitable
+// for single-instance deployments.
+type Local struct {
+	dir        string
+	urlPrefix  string
+	signingKey []byte
+}
+
+// NewLocal creates a local backend rooted at dir whose objects are served
+// under urlPrefix. Without a signing key a random one is generated, so
+// signed URLs stop working after a restart.
+func NewLocal(dir, urlPrefix string, signingKey []byte) (*Local, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	if len(signingKey) == 0 {
+		signingKey = make([]byte, 32)
+		if _, err := rand.Read(signingKey); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Local{
+		dir:        dir,
+		urlPrefix:  strings.TrimRight(urlPrefix, "/") + "/",
+		signingKey: signingKey,
+	}, nil
+}
+
+// URLPrefix is the path prefix under which ServeHTTP must be mounted
+func (l *Local) URLPrefix() string {
+	return l.urlPrefix
+}
+
+func (l *Local) path(key string) (string, string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", "", err
+	}
+	return key, filepath.Join(l.dir, filepath.FromSlash(key)), nil
+}
+
+func (l *Local) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	_, p, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	// Write to a temporary file first so readers never see partial content
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), p)
+}
+
+func (l *Local) Get(ctx context.Context, key string) (io.ReadCloser, *Object, error) {
+	key, p, err := l.path(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	f, err := os.Open(p)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil, ErrNotFound
+		}
+		return nil, nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		f.Close()
+		return nil, nil, ErrNotFound
+	}
+
+	return f, localObject(key, info), nil
+}
+
+func (l *Local) Stat(ctx context.Context, key string) (*Object, error) {
+	key, p, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(p)
+	if err != nil || info.IsDir() {
+		if err == nil || errors.Is(err, fs.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return localObject(key, info), nil
+}
+
+func (l *Local) Delete(ctx context.Context, key string) error {
+	_, p, err := l.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (l *Local) URL(key string) string {
+	key, _ = cleanKey(key)
+	return l.urlPrefix + (&url.URL{Path: key}).EscapedPath()
+}
+
+func (l *Local) SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	expires := strconv.FormatInt(time.Now().Add(expiry).Unix(), 10)
+	query := url.Values{}
+	query.Set("expires", expires)
+	query.Set("signature", l.sign(key, expires))
+
+	return l.URL(key) + "?" + query.Encode(), nil
+}
+
+func (l *Local) KeyFromURL(rawURL string) (string, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil || !strings.HasPrefix(u.Path, l.urlPrefix) {
+		return "", false
+	}
+	key, err := cleanKey(strings.TrimPrefix(u.Path, l.urlPrefix))
+	if err != nil {
+		return "", false
+	}
+	return key, true
+}
+
+// ServeHTTP serves objects by key. Mount it with http.StripPrefix(URLPrefix()).
+// Objects under PrivatePrefix require a valid signature.
+func (l *Local) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key, p, err := l.path(r.URL.Path)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if strings.HasPrefix(key, PrivatePrefix) && !l.validSignature(key, r.URL.Query()) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	f, err := os.Open(p)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+
+	http.ServeContent(w, r, info.Name(), info.ModTime(), f)
+}
+
+func (l *Local) sign(key, expires string) string {
+	mac := hmac.New(sha256.New, l.signingKey)
+	mac.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (l *Local) validSignature(key string, query url.Values) bool {
+	expires := query.Get("expires")
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > unix {
+		return false
+	}
+	return hmac.Equal([]byte(query.Get("signature")), []byte(l.sign(key, expires)))
+}
+
+func localObject(key string, info fs.FileInfo) *Object {
+	return &Object{
+		Key:          key,
+		Size:         info.Size(),
+		ContentType:  mime.TypeByExtension(strings.ToLower(path.Ext(key))),
+		LastModified: info.ModTime(),
+	}
+}
@@ -0,0 +1,343 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// S3Config configures an S3-compatible backend (AWS S3, MinIO, R2, ...)
+type S3Config struct {
+	Endpoint       string // e.g. https://s3.ap-southeast-1.amazonaws.com or http://minio:9000
+	Region         string
+	Bucket         string
+	AccessKeyID    string
+	SecretKey      string
+	PublicURL      string // base URL for public links; defaults to the bucket URL
+	ForcePathStyle bool   // address the bucket as endpoint/bucket instead of bucket.endpoint
+	HTTPClient     *http.Client
+}
+
+// S3 stores objects in an S3-compatible bucket. Requests are signed with
+// AWS Signature Version 4. Whether objects are publicly readable is decided
+// by the bucket policy; SignedURL works either way.
+type S3 struct {
+	cfg      S3Config
+	endpoint *url.URL
+	client   *http.Client
+}
+
+const (
+	s3Algorithm     = "AWS4-HMAC-SHA256"
+	s3Service       = "s3"
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+	maxPresignAge   = 7 * 24 * time.Hour
+)
+
+// NewS3 creates an S3 backend
+func NewS3(cfg S3Config) (*S3, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" || cfg.AccessKeyID == "" || cfg.SecretKey == "" {
+		return nil, errors.New("s3 storage requires endpoint, bucket, access key and secret key")
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+
+	endpoint, err := url.Parse(strings.TrimRight(cfg.Endpoint, "/"))
+	if err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint %q", cfg.Endpoint)
+	}
+
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 60 * time.Second}
+	}
+
+	return &S3{cfg: cfg, endpoint: endpoint, client: client}, nil
+}
+
+// objectURL returns the unsigned URL of an object
+func (s *S3) objectURL(key string) *url.URL {
+	u := *s.endpoint
+	escaped := uriEncode(key, false)
+	if s.cfg.ForcePathStyle {
+		u.Path = "/" + s.cfg.Bucket + "/" + key
+		u.RawPath = "/" + s.cfg.Bucket + "/" + escaped
+	} else {
+		u.Host = s.cfg.Bucket + "." + u.Host
+		u.Path = "/" + key
+		u.RawPath = "/" + escaped
+	}
+	return &u
+}
+
+func (s *S3) do(ctx context.Context, method, key string, body io.Reader, size int64, header http.Header) (*http.Response, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, s.objectURL(key).String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	s.signRequest(req, time.Now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("s3 %s %s: %w", method, key, err)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("s3 %s %s: %s: %s", method, key, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return resp, nil
+}
+
+func (s *S3) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+
+	resp, err := s.do(ctx, http.MethodPut, key, r, size, header)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (s *S3) Get(ctx context.Context, key string) (io.ReadCloser, *Object, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, nil, 0, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp.Body, s3Object(key, resp), nil
+}
+
+func (s *S3) Stat(ctx context.Context, key string) (*Object, error) {
+	resp, err := s.do(ctx, http.MethodHead, key, nil, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return s3Object(key, resp), nil
+}
+
+func (s *S3) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, 0, nil)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (s *S3) URL(key string) string {
+	key, _ = cleanKey(key)
+	if s.cfg.PublicURL != "" {
+		return strings.TrimRight(s.cfg.PublicURL, "/") + "/" + uriEncode(key, false)
+	}
+	return s.objectURL(key).String()
+}
+
+// SignedURL returns a presigned GET URL. S3 caps the expiry at seven days.
+func (s *S3) SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	if expiry <= 0 || expiry > maxPresignAge {
+		return "", fmt.Errorf("presigned URL expiry must be between 1s and %s", maxPresignAge)
+	}
+
+	now := time.Now().UTC()
+	u := s.objectURL(key)
+
+	query := url.Values{}
+	query.Set("X-Amz-Algorithm", s3Algorithm)
+	query.Set("X-Amz-Credential", s.cfg.AccessKeyID+"/"+s.scope(now))
+	query.Set("X-Amz-Date", now.Format("20060102T150405Z"))
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expiry.Seconds())))
+	query.Set("X-Amz-SignedHeaders", "host")
+
+	header := http.Header{}
+	header.Set("Host", u.Host)
+	canonical := canonicalRequest(http.MethodGet, u.EscapedPath(), query, header, []string{"host"}, unsignedPayload)
+	query.Set("X-Amz-Signature", s.signature(now, canonical))
+
+	u.RawQuery = canonicalQuery(query)
+	return u.String(), nil
+}
+
+func (s *S3) KeyFromURL(rawURL string) (string, bool) {
+	bases := []string{s.URL("")}
+	if s.cfg.PublicURL != "" {
+		bases = append(bases, s.objectURL("").String())
+	}
+
+	rawURL, _, _ = strings.Cut(rawURL, "?")
+	for _, base := range bases {
+		base = strings.TrimRight(base, "/") + "/"
+		if rest, ok := strings.CutPrefix(rawURL, base); ok {
+			key, err := url.PathUnescape(rest)
+			if err != nil {
+				return "", false
+			}
+			if key, err = cleanKey(key); err == nil {
+				return key, true
+			}
+		}
+	}
+	return "", false
+}
+
+// ========================================
+// SIGNATURE VERSION 4
+// ========================================
+
+func (s *S3) scope(t time.Time) string {
+	return t.Format("20060102") + "/" + s.cfg.Region + "/" + s3Service + "/aws4_request"
+}
+
+// signRequest adds the x-amz-date, x-amz-content-sha256 and Authorization
+// headers. The payload is not hashed so bodies can be streamed.
+func (s *S3) signRequest(req *http.Request, now time.Time) {
+	now = now.UTC()
+	req.Header.Set("X-Amz-Date", now.Format("20060102T150405Z"))
+	req.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+
+	header := req.Header.Clone()
+	header.Set("Host", req.URL.Host)
+
+	signed := []string{"host", "x-amz-content-sha256", "x-amz-date"}
+	if req.Header.Get("Content-Type") != "" {
+		signed = append(signed, "content-type")
+		sort.Strings(signed)
+	}
+
+	canonical := canonicalRequest(req.Method, req.URL.EscapedPath(), req.URL.Query(), header, signed, unsignedPayload)
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, s.cfg.AccessKeyID, s.scope(now), strings.Join(signed, ";"), s.signature(now, canonical)))
+}
+
+func (s *S3) signature(t time.Time, canonical string) string {
+	hash := sha256.Sum256([]byte(canonical))
+	stringToSign := strings.Join([]string{
+		s3Algorithm,
+		t.Format("20060102T150405Z"),
+		s.scope(t),
+		hex.EncodeToString(hash[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.cfg.SecretKey), t.Format("20060102"))
+	key = hmacSHA256(key, s.cfg.Region)
+	key = hmacSHA256(key, s3Service)
+	key = hmacSHA256(key, "aws4_request")
+
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+// canonicalRequest builds the SigV4 canonical request. signedHeaders must be
+// lower case and sorted.
+func canonicalRequest(method, escapedPath string, query url.Values, header http.Header, signedHeaders []string, payloadHash string) string {
+	if escapedPath == "" {
+		escapedPath = "/"
+	}
+
+	var headers strings.Builder
+	for _, name := range signedHeaders {
+		headers.WriteString(name + ":" + strings.TrimSpace(header.Get(name)) + "\n")
+	}
+
+	return strings.Join([]string{
+		method,
+		escapedPath,
+		canonicalQuery(query),
+		headers.String(),
+		strings.Join(signedHeaders, ";"),
+		payloadHash,
+	}, "\n")
+}
+
+// canonicalQuery encodes the query sorted by key with SigV4 escaping
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, k := range keys {
+		values := append([]string(nil), query[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			parts = append(parts, uriEncode(k, true)+"="+uriEncode(v, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// uriEncode escapes everything except unreserved characters, as SigV4
+// requires. Slashes are kept when encodeSlash is false.
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func s3Object(key string, resp *http.Response) *Object {
+	obj := &Object{
+		Key:         key,
+		Size:        resp.ContentLength,
+		ContentType: resp.Header.Get("Content-Type"),
+	}
+	if t, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		obj.LastModified = t
+	}
+	return obj
+}
@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNotFound   = errors.New("object not found")
+	ErrInvalidKey = errors.New("invalid object key")
+)
+
+// Object describes a stored file
+type Object struct {
+	Key          string
+	Size         int64
+	ContentType  string
+	LastModified time.Time
+}
+
+// Storage stores uploaded files. Keys are slash separated paths relative to
+// the storage root, e.g. "articles/1700000000.jpg".
+type Storage interface {
+	// Put stores the content under key, replacing any existing object
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+
+	// Get opens the object for reading. The caller must close the reader.
+	Get(ctx context.Context, key string) (io.ReadCloser, *Object, error)
+
+	// Stat returns object metadata or ErrNotFound
+	Stat(ctx context.Context, key string) (*Object, error)
+
+	// Delete removes the object. Deleting a missing object is not an error.
+	Delete(ctx context.Context, key string) error
+
+	// URL returns the public URL of the object
+	URL(key string) string
+
+	// SignedURL returns a URL that grants read access to the object until
+	// the expiry passes, also for objects that are not publicly readable
+	SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error)
+
+	// KeyFromURL reverses URL. It reports false for URLs that do not point
+	// into this storage.
+	KeyFromURL(url string) (string, bool)
+}
+
+// NewFromEnv creates the backend selected by STORAGE_DRIVER ("local" or "s3").
+//
+// local: STORAGE_LOCAL_DIR (default ./uploads), STORAGE_LOCAL_URL (default
+// /uploads/) and STORAGE_SIGNING_KEY for signed URLs.
+//
+// s3: S3_ENDPOINT, S3_REGION (default us-east-1), S3_BUCKET,
+// S3_ACCESS_KEY_ID, S3_SECRET_ACCESS_KEY, S3_PUBLIC_URL (optional, e.g. a
+// CDN) and S3_FORCE_PATH_STYLE (default true, required for MinIO).
+func NewFromEnv() (Storage, error) {
+	switch driver := getEnv("STORAGE_DRIVER", "local"); driver {
+	case "local":
+		return NewLocal(
+			getEnv("STORAGE_LOCAL_DIR", "./uploads"),
+			getEnv("STORAGE_LOCAL_URL", "/uploads/"),
+			[]byte(os.Getenv("STORAGE_SIGNING_KEY")),
+		)
+	case "s3":
+		return NewS3(S3Config{
+			Endpoint:       os.Getenv("S3_ENDPOINT"),
+			Region:         getEnv("S3_REGION", "us-east-1"),
+			Bucket:         os.Getenv("S3_BUCKET"),
+			AccessKeyID:    os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretKey:      os.Getenv("S3_SECRET_ACCESS_KEY"),
+			PublicURL:      os.Getenv("S3_PUBLIC_URL"),
+			ForcePathStyle: getEnv("S3_FORCE_PATH_STYLE", "true") == "true",
+		})
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_DRIVER %q", driver)
+	}
+}
+
+// cleanKey normalises a key and rejects keys that would escape the storage
+// root
+func cleanKey(key string) (string, error) {
+	key = strings.TrimLeft(key, "/")
+	if key == "" || strings.Contains(key, "\\") {
+		return "", ErrInvalidKey
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == ".." {
+			return "", ErrInvalidKey
+		}
+	}
+	return path.Clean(key), nil
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,35 @@
+-- +goose Up
+
+-- ========================================
+-- ARTICLES - Status workflow redaksi
+-- ========================================
+ALTER TABLE articles DROP CONSTRAINT IF EXISTS articles_status_check;
+ALTER TABLE articles
+  ADD CONSTRAINT articles_status_check
+  CHECK (status IN ('draft', 'in_review', 'approved', 'published', 'archived'));
+
+-- ========================================
+-- ARTICLE STATUS HISTORY - Siapa memindahkan artikel, kapan, dan catatan reviewer
+-- ========================================
+CREATE TABLE IF NOT EXISTS article_status_history (
+  riwayat_id SERIAL PRIMARY KEY,
+  artikel_id INTEGER NOT NULL REFERENCES articles(artikel_id) ON DELETE CASCADE,
+  status_awal VARCHAR(20) NOT NULL,
+  status_baru VARCHAR(20) NOT NULL,
+  user_id INTEGER REFERENCES users(user_id) ON DELETE SET NULL,
+  catatan TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_article_status_history_artikel_id ON article_status_history(artikel_id);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_article_status_history_artikel_id;
+DROP TABLE IF EXISTS article_status_history;
+
+UPDATE articles SET status = 'draft' WHERE status IN ('in_review', 'approved');
+ALTER TABLE articles DROP CONSTRAINT IF EXISTS articles_status_check;
+ALTER TABLE articles
+  ADD CONSTRAINT articles_status_check
+  CHECK (status IN ('draft', 'published', 'archived'));
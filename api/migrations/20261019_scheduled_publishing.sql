@@ -0,0 +1,24 @@
+-- +goose Up
+
+-- ========================================
+-- ARTICLES - Status 'scheduled' untuk publikasi terjadwal
+-- ========================================
+ALTER TABLE articles DROP CONSTRAINT IF EXISTS articles_status_check;
+ALTER TABLE articles
+  ADD CONSTRAINT articles_status_check
+  CHECK (status IN ('draft', 'in_review', 'approved', 'scheduled', 'published', 'archived'));
+
+-- Index untuk scheduler: hanya artikel terjadwal
+CREATE INDEX IF NOT EXISTS idx_articles_scheduled
+  ON articles(tanggal_publikasi)
+  WHERE status = 'scheduled';
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_articles_scheduled;
+
+UPDATE articles SET status = 'approved' WHERE status = 'scheduled';
+ALTER TABLE articles DROP CONSTRAINT IF EXISTS articles_status_check;
+ALTER TABLE articles
+  ADD CONSTRAINT articles_status_check
+  CHECK (status IN ('draft', 'in_review', 'approved', 'published', 'archived'));
@@ -0,0 +1,25 @@
+-- +goose Up
+
+-- ========================================
+-- ARTICLE REVISIONS - Snapshot konten sebelum setiap update
+-- ========================================
+CREATE TABLE IF NOT EXISTS article_revisions (
+  revisi_id SERIAL PRIMARY KEY,
+  artikel_id INTEGER NOT NULL REFERENCES articles(artikel_id) ON DELETE CASCADE,
+  nomor_revisi INTEGER NOT NULL,
+  judul VARCHAR(200) NOT NULL,
+  slug VARCHAR(255),
+  konten TEXT NOT NULL,
+  excerpt TEXT,
+  gambar_utama VARCHAR(255),
+  penulis VARCHAR(100),
+  kategori_ids INTEGER[] NOT NULL DEFAULT '{}',
+  tag_ids INTEGER[] NOT NULL DEFAULT '{}',
+  user_id INTEGER REFERENCES users(user_id) ON DELETE SET NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (artikel_id, nomor_revisi)
+);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS article_revisions;
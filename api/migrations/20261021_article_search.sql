@@ -0,0 +1,59 @@
+-- +goose Up
+
+-- ========================================
+-- TEXT SEARCH CONFIG - Stemming Bahasa Indonesia jika tersedia, fallback ke simple
+-- ========================================
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_ts_config WHERE cfgname = 'news_search') THEN
+    IF EXISTS (SELECT 1 FROM pg_ts_config WHERE cfgname = 'indonesian') THEN
+      CREATE TEXT SEARCH CONFIGURATION news_search (COPY = pg_catalog.indonesian);
+    ELSE
+      CREATE TEXT SEARCH CONFIGURATION news_search (COPY = pg_catalog.simple);
+    END IF;
+  END IF;
+END $$;
+
+-- ========================================
+-- ARTICLES - Kolom search_vector (judul > excerpt > konten)
+-- ========================================
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS search_vector TSVECTOR;
+
+-- Function untuk menyusun tsvector artikel, tag HTML di konten dibuang
+CREATE OR REPLACE FUNCTION articles_search_vector(p_judul TEXT, p_excerpt TEXT, p_konten TEXT)
+RETURNS TSVECTOR AS $$
+BEGIN
+  RETURN setweight(to_tsvector('news_search', COALESCE(p_judul, '')), 'A')
+      || setweight(to_tsvector('news_search', COALESCE(p_excerpt, '')), 'B')
+      || setweight(to_tsvector('news_search', regexp_replace(COALESCE(p_konten, ''), '<[^>]*>', ' ', 'g')), 'C');
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+-- Function trigger untuk update search_vector
+CREATE OR REPLACE FUNCTION update_articles_search_vector()
+RETURNS TRIGGER AS $$
+BEGIN
+  NEW.search_vector = articles_search_vector(NEW.judul, NEW.excerpt, NEW.konten);
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_articles_search_vector ON articles;
+CREATE TRIGGER trg_articles_search_vector
+  BEFORE INSERT OR UPDATE OF judul, excerpt, konten ON articles
+  FOR EACH ROW
+  EXECUTE FUNCTION update_articles_search_vector();
+
+-- Isi search_vector untuk artikel yang sudah ada
+UPDATE articles SET search_vector = articles_search_vector(judul, excerpt, konten);
+
+CREATE INDEX IF NOT EXISTS idx_articles_search_vector ON articles USING GIN(search_vector);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_articles_search_vector;
+DROP TRIGGER IF EXISTS trg_articles_search_vector ON articles;
+DROP FUNCTION IF EXISTS update_articles_search_vector();
+DROP FUNCTION IF EXISTS articles_search_vector(TEXT, TEXT, TEXT);
+ALTER TABLE articles DROP COLUMN IF EXISTS search_vector;
+DROP TEXT SEARCH CONFIGURATION IF EXISTS news_search;
@@ -0,0 +1,18 @@
+-- +goose Up
+
+-- ========================================
+-- ARTICLES - Keyset pagination untuk feed publik
+-- ========================================
+
+-- Artikel published lama tanpa tanggal_publikasi memakai tanggal_dibuat
+UPDATE articles
+SET tanggal_publikasi = tanggal_dibuat
+WHERE status = 'published' AND tanggal_publikasi IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_articles_published_feed
+  ON articles(tanggal_publikasi DESC, artikel_id DESC)
+  WHERE status = 'published';
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_articles_published_feed;
@@ -0,0 +1,58 @@
+-- +goose Up
+
+-- ========================================
+-- MEDIA - Media library (uploader, ukuran, dimensi, alt text, caption)
+-- ========================================
+
+-- Media bisa ada di library tanpa terpasang ke artikel
+ALTER TABLE media ALTER COLUMN artikel_id DROP NOT NULL;
+ALTER TABLE media DROP CONSTRAINT IF EXISTS media_artikel_id_fkey;
+ALTER TABLE media
+  ADD CONSTRAINT media_artikel_id_fkey
+  FOREIGN KEY (artikel_id) REFERENCES articles(artikel_id) ON DELETE SET NULL;
+
+ALTER TABLE media
+  ADD COLUMN IF NOT EXISTS user_id INTEGER REFERENCES users(user_id) ON DELETE SET NULL,
+  ADD COLUMN IF NOT EXISTS nama_file VARCHAR(255),
+  ADD COLUMN IF NOT EXISTS storage_key TEXT,
+  ADD COLUMN IF NOT EXISTS mime_type VARCHAR(100),
+  ADD COLUMN IF NOT EXISTS ukuran BIGINT,
+  ADD COLUMN IF NOT EXISTS lebar INTEGER,
+  ADD COLUMN IF NOT EXISTS tinggi INTEGER,
+  ADD COLUMN IF NOT EXISTS alt_text TEXT,
+  ADD COLUMN IF NOT EXISTS caption TEXT,
+  ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_media_user_id ON media(user_id);
+CREATE INDEX IF NOT EXISTS idx_media_created_at ON media(created_at DESC);
+
+DROP TRIGGER IF EXISTS trg_media_update ON media;
+CREATE TRIGGER trg_media_update
+  BEFORE UPDATE ON media
+  FOR EACH ROW
+  EXECUTE FUNCTION update_updated_at();
+
+-- +goose Down
+
+DROP TRIGGER IF EXISTS trg_media_update ON media;
+DROP INDEX IF EXISTS idx_media_created_at;
+DROP INDEX IF EXISTS idx_media_user_id;
+
+ALTER TABLE media
+  DROP COLUMN IF EXISTS updated_at,
+  DROP COLUMN IF EXISTS caption,
+  DROP COLUMN IF EXISTS alt_text,
+  DROP COLUMN IF EXISTS tinggi,
+  DROP COLUMN IF EXISTS lebar,
+  DROP COLUMN IF EXISTS ukuran,
+  DROP COLUMN IF EXISTS mime_type,
+  DROP COLUMN IF EXISTS storage_key,
+  DROP COLUMN IF EXISTS nama_file,
+  DROP COLUMN IF EXISTS user_id;
+
+DELETE FROM media WHERE artikel_id IS NULL;
+ALTER TABLE media DROP CONSTRAINT IF EXISTS media_artikel_id_fkey;
+ALTER TABLE media
+  ADD CONSTRAINT media_artikel_id_fkey
+  FOREIGN KEY (artikel_id) REFERENCES articles(artikel_id) ON DELETE CASCADE;
+ALTER TABLE media ALTER COLUMN artikel_id SET NOT NULL;
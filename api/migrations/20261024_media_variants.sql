@@ -0,0 +1,17 @@
+-- +goose Up
+
+-- ========================================
+-- MEDIA - Varian gambar responsif (thumbnail, medium, large + WebP)
+-- ========================================
+
+ALTER TABLE media
+  ADD COLUMN IF NOT EXISTS variants JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+-- gambar_utama artikel dicocokkan ke media lewat URL
+CREATE INDEX IF NOT EXISTS idx_media_url ON media(url);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_media_url;
+
+ALTER TABLE media DROP COLUMN IF EXISTS variants;
@@ -0,0 +1,31 @@
+-- +goose Up
+
+-- ========================================
+-- COMMENTS - Balasan bertingkat (threaded replies)
+-- ========================================
+
+-- depth 0 = komentar utama; balasan ikut terhapus bersama induknya
+ALTER TABLE comments
+  ADD COLUMN IF NOT EXISTS parent_id INTEGER REFERENCES comments(komentar_id) ON DELETE CASCADE,
+  ADD COLUMN IF NOT EXISTS depth SMALLINT NOT NULL DEFAULT 0;
+
+ALTER TABLE comments DROP CONSTRAINT IF EXISTS chk_comments_depth;
+ALTER TABLE comments
+  ADD CONSTRAINT chk_comments_depth
+  CHECK ((parent_id IS NULL AND depth = 0) OR (parent_id IS NOT NULL AND depth > 0));
+
+-- Daftar thread per artikel dan balasan per komentar
+CREATE INDEX IF NOT EXISTS idx_comments_threads
+  ON comments(artikel_id, tanggal_dibuat DESC) WHERE parent_id IS NULL;
+CREATE INDEX IF NOT EXISTS idx_comments_parent_id
+  ON comments(parent_id, tanggal_dibuat) WHERE parent_id IS NOT NULL;
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_comments_parent_id;
+DROP INDEX IF EXISTS idx_comments_threads;
+
+ALTER TABLE comments DROP CONSTRAINT IF EXISTS chk_comments_depth;
+ALTER TABLE comments
+  DROP COLUMN IF EXISTS depth,
+  DROP COLUMN IF EXISTS parent_id;
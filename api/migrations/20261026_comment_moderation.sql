@@ -0,0 +1,49 @@
+-- +goose Up
+
+-- ========================================
+-- BANNED WORDS - Daftar kata terlarang yang dikelola admin
+-- ========================================
+CREATE TABLE IF NOT EXISTS banned_words (
+  word_id SERIAL PRIMARY KEY,
+  kata VARCHAR(100) NOT NULL,
+  tingkat VARCHAR(20) NOT NULL DEFAULT 'review'
+    CHECK (tingkat IN ('reject', 'review')),
+  created_by INTEGER REFERENCES users(user_id) ON DELETE SET NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_banned_words_kata ON banned_words(LOWER(kata));
+
+-- Daftar awal, termasuk variasi slang yang umum
+INSERT INTO banned_words (kata, tingkat) VALUES
+  ('anjing', 'reject'), ('anjg', 'reject'), ('anjir', 'review'), ('njing', 'reject'),
+  ('bangsat', 'reject'), ('bgst', 'reject'),
+  ('bajingan', 'reject'), ('keparat', 'reject'),
+  ('kontol', 'reject'), ('kntl', 'reject'), ('memek', 'reject'), ('mmk', 'reject'),
+  ('ngentot', 'reject'), ('ngentd', 'reject'), ('ngewe', 'reject'),
+  ('jancok', 'reject'), ('jancuk', 'reject'), ('jnck', 'reject'), ('cok', 'review'),
+  ('asu', 'review'), ('babi', 'review'),
+  ('goblok', 'review'), ('gblk', 'review'), ('tolol', 'review'), ('tll', 'review'),
+  ('bego', 'review'), ('idiot', 'review'), ('brengsek', 'review'), ('kampret', 'review'),
+  ('judi online', 'reject'), ('slot gacor', 'reject'), ('situs gacor', 'reject')
+ON CONFLICT DO NOTHING;
+
+-- ========================================
+-- COMMENTS - Hasil moderasi otomatis
+-- ========================================
+ALTER TABLE comments
+  ADD COLUMN IF NOT EXISTS moderation_score INTEGER,
+  ADD COLUMN IF NOT EXISTS moderation_reasons TEXT[] NOT NULL DEFAULT '{}';
+
+-- Riwayat komentar per user (skor kepercayaan)
+CREATE INDEX IF NOT EXISTS idx_comments_user_status ON comments(user_id, status);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_comments_user_status;
+
+ALTER TABLE comments
+  DROP COLUMN IF EXISTS moderation_reasons,
+  DROP COLUMN IF EXISTS moderation_score;
+
+DROP TABLE IF EXISTS banned_words;
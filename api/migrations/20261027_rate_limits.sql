@@ -0,0 +1,18 @@
+-- +goose Up
+
+-- ========================================
+-- RATE LIMIT BUCKETS - Token bucket bersama untuk semua instance API
+-- ========================================
+CREATE UNLOGGED TABLE IF NOT EXISTS rate_limit_buckets (
+  bucket_key TEXT PRIMARY KEY,
+  tokens DOUBLE PRECISION NOT NULL,
+  allowed BOOLEAN NOT NULL DEFAULT TRUE,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Untuk pembersihan bucket yang sudah lama tidak dipakai
+CREATE INDEX IF NOT EXISTS idx_rate_limit_buckets_updated ON rate_limit_buckets(updated_at);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS rate_limit_buckets;
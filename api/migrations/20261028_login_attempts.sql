@@ -0,0 +1,35 @@
+-- +goose Up
+
+-- ========================================
+-- LOGIN ATTEMPTS - Riwayat login (berhasil & gagal) per akun
+-- ========================================
+CREATE TABLE IF NOT EXISTS login_attempts (
+  attempt_id BIGSERIAL PRIMARY KEY,
+  user_id INTEGER REFERENCES users(user_id) ON DELETE CASCADE,
+  email VARCHAR(255) NOT NULL,
+  ip_address VARCHAR(45),
+  user_agent TEXT,
+  success BOOLEAN NOT NULL,
+  failure_reason VARCHAR(30),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_attempts_user_created ON login_attempts(user_id, created_at DESC);
+
+-- ========================================
+-- ACCOUNT LOCKOUT - Kunci sementara dengan backoff eksponensial
+-- ========================================
+ALTER TABLE users
+  ADD COLUMN IF NOT EXISTS locked_until TIMESTAMPTZ,
+  ADD COLUMN IF NOT EXISTS lockout_count INTEGER NOT NULL DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS failures_reset_at TIMESTAMPTZ;
+
+-- +goose Down
+
+ALTER TABLE users
+  DROP COLUMN IF EXISTS failures_reset_at,
+  DROP COLUMN IF EXISTS lockout_count,
+  DROP COLUMN IF EXISTS locked_until;
+
+DROP INDEX IF EXISTS idx_login_attempts_user_created;
+DROP TABLE IF EXISTS login_attempts;
@@ -0,0 +1,34 @@
+-- +goose Up
+
+-- ========================================
+-- PASSWORD RESET TOKENS - Hanya hash yang disimpan, sekali pakai
+-- ========================================
+CREATE TABLE IF NOT EXISTS password_reset_tokens (
+  token_id SERIAL PRIMARY KEY,
+  user_id INTEGER NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+  token_hash CHAR(64) NOT NULL UNIQUE,
+  ip_address VARCHAR(45),
+  expires_at TIMESTAMPTZ NOT NULL,
+  used_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_user_id ON password_reset_tokens(user_id);
+CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_expires_at ON password_reset_tokens(expires_at);
+
+-- ========================================
+-- USER TOKEN REVOCATIONS - Semua sesi user yang dibuat sebelum waktu tertentu
+-- ========================================
+CREATE TABLE IF NOT EXISTS user_token_revocations (
+  user_id INTEGER PRIMARY KEY REFERENCES users(user_id) ON DELETE CASCADE,
+  revoked_before TIMESTAMPTZ NOT NULL,
+  expires_at TIMESTAMPTZ NOT NULL
+);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS user_token_revocations;
+
+DROP INDEX IF EXISTS idx_password_reset_tokens_expires_at;
+DROP INDEX IF EXISTS idx_password_reset_tokens_user_id;
+DROP TABLE IF EXISTS password_reset_tokens;
@@ -0,0 +1,26 @@
+-- +goose Up
+
+-- ========================================
+-- USER INVITATIONS - Admin mengundang editor/reviewer lewat email
+-- ========================================
+CREATE TABLE IF NOT EXISTS user_invitations (
+  invitation_id SERIAL PRIMARY KEY,
+  email VARCHAR(255) NOT NULL,
+  role VARCHAR(20) NOT NULL
+    CHECK (role IN ('admin', 'editor', 'reviewer', 'user')),
+  token_hash CHAR(64) NOT NULL UNIQUE,
+  invited_by INTEGER REFERENCES users(user_id) ON DELETE SET NULL,
+  expires_at TIMESTAMPTZ NOT NULL,
+  accepted_at TIMESTAMPTZ,
+  accepted_user_id INTEGER REFERENCES users(user_id) ON DELETE SET NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Hanya satu undangan aktif per email
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_invitations_pending_email
+  ON user_invitations(LOWER(email)) WHERE accepted_at IS NULL;
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_user_invitations_pending_email;
+DROP TABLE IF EXISTS user_invitations;
@@ -0,0 +1,102 @@
+-- +goose Up
+
+-- ========================================
+-- ROLES & PERMISSIONS - Hak akses per role disimpan di database
+-- ========================================
+CREATE TABLE IF NOT EXISTS roles (
+  name VARCHAR(20) PRIMARY KEY,
+  description TEXT NOT NULL DEFAULT '',
+  is_system BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS permissions (
+  name VARCHAR(50) PRIMARY KEY,
+  description TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+  role VARCHAR(20) NOT NULL REFERENCES roles(name) ON UPDATE CASCADE ON DELETE CASCADE,
+  permission VARCHAR(50) NOT NULL REFERENCES permissions(name) ON DELETE CASCADE,
+  PRIMARY KEY (role, permission)
+);
+
+-- Role bawaan; tidak dapat dihapus
+INSERT INTO roles (name, description, is_system) VALUES
+  ('admin', 'Akses penuh, selalu memiliki semua izin', TRUE),
+  ('editor', 'Menulis dan mengelola artikel sendiri', TRUE),
+  ('reviewer', 'Meninjau, menjadwalkan dan menerbitkan artikel', TRUE),
+  ('user', 'Pembaca terdaftar', TRUE)
+ON CONFLICT (name) DO NOTHING;
+
+-- Role lain yang sudah dipakai user tetap dipertahankan (tanpa izin)
+INSERT INTO roles (name)
+SELECT DISTINCT role FROM users
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO permissions (name, description) VALUES
+  ('editor.access', 'Membuka area editor (artikel, revisi, media)'),
+  ('article.create', 'Membuat artikel baru'),
+  ('article.edit.own', 'Mengubah artikel milik sendiri'),
+  ('article.edit.any', 'Mengubah artikel siapa pun'),
+  ('article.delete.own', 'Menghapus artikel milik sendiri'),
+  ('article.delete.any', 'Menghapus artikel siapa pun'),
+  ('article.submit', 'Mengajukan artikel untuk ditinjau'),
+  ('article.review', 'Menyetujui atau menolak artikel'),
+  ('article.publish', 'Menerbitkan, menjadwalkan dan mengarsipkan artikel'),
+  ('media.upload', 'Mengunggah media'),
+  ('media.edit.own', 'Mengubah metadata media milik sendiri'),
+  ('media.edit.any', 'Mengubah metadata media siapa pun'),
+  ('media.delete.own', 'Menghapus media milik sendiri'),
+  ('media.delete.any', 'Menghapus media siapa pun'),
+  ('comment.moderate', 'Memoderasi komentar dan kata terlarang'),
+  ('category.manage', 'Mengelola kategori'),
+  ('tag.manage', 'Mengelola tag'),
+  ('user.manage', 'Mengelola user dan undangan'),
+  ('role.manage', 'Mengelola role dan izinnya')
+ON CONFLICT (name) DO NOTHING;
+
+-- Pemetaan awal mengikuti aturan role yang sebelumnya ada di kode
+INSERT INTO role_permissions (role, permission)
+SELECT 'admin', name FROM permissions
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES
+  ('editor', 'editor.access'),
+  ('editor', 'article.create'),
+  ('editor', 'article.edit.own'),
+  ('editor', 'article.delete.own'),
+  ('editor', 'article.submit'),
+  ('editor', 'media.upload'),
+  ('editor', 'media.edit.own'),
+  ('editor', 'media.delete.own'),
+  ('reviewer', 'editor.access'),
+  ('reviewer', 'article.create'),
+  ('reviewer', 'article.edit.any'),
+  ('reviewer', 'article.delete.any'),
+  ('reviewer', 'article.review'),
+  ('reviewer', 'article.publish'),
+  ('reviewer', 'media.upload'),
+  ('reviewer', 'media.edit.any'),
+  ('reviewer', 'media.delete.any')
+ON CONFLICT DO NOTHING;
+
+-- Role user dan undangan harus terdaftar di tabel roles
+ALTER TABLE users
+  ADD CONSTRAINT users_role_fkey FOREIGN KEY (role) REFERENCES roles(name) ON UPDATE CASCADE;
+
+ALTER TABLE user_invitations DROP CONSTRAINT IF EXISTS user_invitations_role_check;
+ALTER TABLE user_invitations
+  ADD CONSTRAINT user_invitations_role_fkey FOREIGN KEY (role) REFERENCES roles(name) ON UPDATE CASCADE ON DELETE CASCADE;
+
+-- +goose Down
+
+ALTER TABLE user_invitations DROP CONSTRAINT IF EXISTS user_invitations_role_fkey;
+ALTER TABLE user_invitations
+  ADD CONSTRAINT user_invitations_role_check CHECK (role IN ('admin', 'editor', 'reviewer', 'user')) NOT VALID;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_fkey;
+
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS permissions;
+DROP TABLE IF EXISTS roles;
@@ -0,0 +1,63 @@
+-- +goose Up
+
+-- ========================================
+-- AUDIT LOG - Jejak semua aksi administratif dan editorial (append-only)
+-- ========================================
+-- actor_id sengaja tanpa foreign key: entri harus tetap utuh setelah user dihapus
+CREATE TABLE IF NOT EXISTS audit_log (
+  audit_id BIGSERIAL PRIMARY KEY,
+  actor_id INTEGER,
+  actor_role VARCHAR(20),
+  action VARCHAR(50) NOT NULL,
+  target_type VARCHAR(30) NOT NULL,
+  target_id VARCHAR(100) NOT NULL,
+  before JSONB,
+  after JSONB,
+  ip_address VARCHAR(45),
+  request_id VARCHAR(64),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_created ON audit_log(created_at DESC, audit_id DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log(actor_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_target ON audit_log(target_type, target_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_action ON audit_log(action, created_at DESC);
+
+-- Entri tidak dapat diubah atau dihapus
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION audit_log_append_only()
+RETURNS TRIGGER AS $$
+BEGIN
+  RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+DROP TRIGGER IF EXISTS trg_audit_log_append_only ON audit_log;
+CREATE TRIGGER trg_audit_log_append_only
+  BEFORE UPDATE OR DELETE ON audit_log
+  FOR EACH ROW
+  EXECUTE FUNCTION audit_log_append_only();
+
+DROP TRIGGER IF EXISTS trg_audit_log_no_truncate ON audit_log;
+CREATE TRIGGER trg_audit_log_no_truncate
+  BEFORE TRUNCATE ON audit_log
+  FOR EACH STATEMENT
+  EXECUTE FUNCTION audit_log_append_only();
+
+-- Izin melihat audit log, default hanya admin
+INSERT INTO permissions (name, description) VALUES
+  ('audit.view', 'Melihat dan mengekspor audit log')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES ('admin', 'audit.view')
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+
+DELETE FROM permissions WHERE name = 'audit.view';
+
+DROP TRIGGER IF EXISTS trg_audit_log_no_truncate ON audit_log;
+DROP TRIGGER IF EXISTS trg_audit_log_append_only ON audit_log;
+DROP TABLE IF EXISTS audit_log;
+DROP FUNCTION IF EXISTS audit_log_append_only();
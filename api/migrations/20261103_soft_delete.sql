@@ -0,0 +1,50 @@
+-- +goose Up
+
+-- ========================================
+-- SOFT DELETE - Data yang dihapus masuk tempat sampah dulu
+-- ========================================
+-- Baris dengan deleted_at terisi disembunyikan dari semua query biasa dan
+-- dihapus permanen oleh admin atau job retensi setelah N hari
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE tags ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+-- Index untuk daftar tempat sampah & job retensi (hanya baris yang terhapus)
+CREATE INDEX IF NOT EXISTS idx_articles_deleted_at ON articles(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_comments_deleted_at ON comments(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_categories_deleted_at ON categories(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_tags_deleted_at ON tags(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users(deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- Izin mengelola tempat sampah, default hanya admin
+INSERT INTO permissions (name, description) VALUES
+  ('trash.manage', 'Melihat, memulihkan dan menghapus permanen isi tempat sampah')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES ('admin', 'trash.manage')
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+
+DELETE FROM permissions WHERE name = 'trash.manage';
+
+DROP INDEX IF EXISTS idx_users_deleted_at;
+DROP INDEX IF EXISTS idx_tags_deleted_at;
+DROP INDEX IF EXISTS idx_categories_deleted_at;
+DROP INDEX IF EXISTS idx_comments_deleted_at;
+DROP INDEX IF EXISTS idx_articles_deleted_at;
+
+-- Baris di tempat sampah ikut terhapus permanen saat rollback
+DELETE FROM comments WHERE deleted_at IS NOT NULL;
+DELETE FROM articles WHERE deleted_at IS NOT NULL;
+DELETE FROM categories WHERE deleted_at IS NOT NULL;
+DELETE FROM tags WHERE deleted_at IS NOT NULL;
+DELETE FROM users WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE users DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE tags DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE categories DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE comments DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE articles DROP COLUMN IF EXISTS deleted_at;
@@ -58,7 +58,8 @@ async function getComments(articleId) {
     });
     
     if (!res.ok) return [];
-    return res.json();
+    const { data } = await res.json();
+    return Array.isArray(data) ? data : [];
   } catch (error) {
     console.error('Error fetching comments:', error);
     return [];
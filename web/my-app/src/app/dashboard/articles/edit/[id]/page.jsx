@@ -36,7 +36,10 @@ export default function EditArticlePage({ params }) {
 
   const fetchArticle = async () => {
     try {
-      const res = await fetch(`${API_URL}/articles/${id}`);
+      const token = localStorage.getItem("access_token");
+      const res = await fetch(`${API_URL}/editor/articles/${id}`, {
+        headers: { Authorization: `Bearer ${token}` },
+      });
       if (!res.ok) throw new Error("Artikel tidak ditemukan");
       
       const article = await res.json();
@@ -57,7 +60,7 @@ export default function EditArticlePage({ params }) {
   const fetchCategories = async () => {
     try {
       const res = await fetch(`${API_URL}/categories`);
-      const data = await res.json();
+      const { data } = await res.json();
       setCategories(Array.isArray(data) ? data : []);
     } catch (err) {
       console.error("Failed to fetch categories:", err);
@@ -24,8 +24,11 @@ export default function ArticlesListPage() {
 
   const fetchArticles = async () => {
     try {
-      const res = await fetch(`${API_URL}/articles`);
-      const data = await res.json();
+      const token = localStorage.getItem("access_token");
+      const res = await fetch(`${API_URL}/editor/articles`, {
+        headers: { Authorization: `Bearer ${token}` },
+      });
+      const { data } = await res.json();
       setArticles(Array.isArray(data) ? data : []);
     } catch (err) {
       setError("Gagal memuat artikel");
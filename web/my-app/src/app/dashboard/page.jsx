@@ -37,12 +37,15 @@ export default function DashboardPage() {
   const fetchStats = async () => {
     try {
       const API_URL = process.env.NEXT_PUBLIC_API_URL || "http://localhost:8080/api/v1";
-      const res = await fetch(`${API_URL}/articles`);
-      const articles = await res.json();
+      const token = localStorage.getItem("access_token");
+      const res = await fetch(`${API_URL}/editor/articles`, {
+        headers: { Authorization: `Bearer ${token}` },
+      });
+      const { data: articles, total } = await res.json();
       
       if (Array.isArray(articles)) {
         setStats({
-          totalArticles: articles.length,
+          totalArticles: total,
           publishedArticles: articles.filter(a => a.status === "published").length,
           draftArticles: articles.filter(a => a.status === "draft").length,
         });
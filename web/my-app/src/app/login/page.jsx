@@ -122,6 +122,12 @@ export default function LoginPage() {
           </button>
         </form>
 
+        <div className="mt-4 text-right">
+          <Link href="/forgot-password" className="text-sm text-blue-600 hover:underline">
+            Lupa password?
+          </Link>
+        </div>
+
         <div className="mt-6 text-center">
           <p className="text-sm text-gray-600">
             Belum punya akun?{" "}
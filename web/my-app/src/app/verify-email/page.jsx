@@ -0,0 +1,85 @@
+"use client";
+
+import { Suspense, useEffect, useState } from "react";
+import { useSearchParams } from "next/navigation";
+import Link from "next/link";
+
+const API_URL = process.env.NEXT_PUBLIC_API_URL || "http://localhost:8080/api/v1";
+
+function VerifyEmailStatus() {
+  const searchParams = useSearchParams();
+  const token = searchParams.get("token") || "";
+
+  const [status, setStatus] = useState(token ? "loading" : "error");
+  const [message, setMessage] = useState(token ? "" : "Link verifikasi tidak valid.");
+
+  useEffect(() => {
+    if (!token) return;
+
+    const verify = async () => {
+      try {
+        const response = await fetch(`${API_URL}/auth/verify-email`, {
+          method: "POST",
+          headers: { "Content-Type": "application/json" },
+          body: JSON.stringify({ token }),
+        });
+
+        const data = await response.json();
+
+        if (!response.ok) {
+          throw new Error(data.error || data.message || "Verifikasi gagal");
+        }
+
+        // Perbarui data user yang tersimpan jika sedang login
+        const stored = localStorage.getItem("user");
+        if (stored) {
+          localStorage.setItem("user", JSON.stringify({ ...JSON.parse(stored), email_verified: true }));
+        }
+
+        setStatus("success");
+        setMessage(data.message);
+      } catch (err) {
+        setStatus("error");
+        setMessage(err.message);
+      }
+    };
+
+    verify();
+  }, [token]);
+
+  if (status === "loading") {
+    return <p className="text-center text-gray-600 text-sm">Memverifikasi email...</p>;
+  }
+
+  return (
+    <div
+      className={`p-3 rounded text-sm ${
+        status === "success" ? "bg-green-100 text-green-700" : "bg-red-100 text-red-700"
+      }`}
+    >
+      {message}
+    </div>
+  );
+}
+
+export default function VerifyEmailPage() {
+  return (
+    <div className="min-h-screen flex items-center justify-center bg-gray-100">
+      <div className="max-w-md w-full bg-white rounded-lg shadow-lg p-8">
+        <div className="text-center mb-8">
+          <h1 className="text-2xl font-bold text-gray-900">Verifikasi Email</h1>
+        </div>
+
+        <Suspense fallback={null}>
+          <VerifyEmailStatus />
+        </Suspense>
+
+        <div className="mt-6 text-center">
+          <Link href="/" className="text-sm text-blue-600 hover:underline">
+            ← Kembali ke Beranda
+          </Link>
+        </div>
+      </div>
+    </div>
+  );
+}
@@ -51,8 +51,8 @@ export default function CommentSection({ articleId, initialComments = [] }) {
     try {
       const response = await fetch(`${API_URL}/articles/${articleId}/comments`);
       if (!response.ok) throw new Error('Failed to load comments');
-      const data = await response.json();
-      setComments(data);
+      const { data } = await response.json();
+      setComments(Array.isArray(data) ? data : []);
     } catch (error) {
       console.error('Error loading comments:', error);
     }
@@ -101,6 +101,12 @@ export default function CommentSection({ articleId, initialComments = [] }) {
         status: data.status ?? 'pending',
       };
 
+      if (normalized.status === 'rejected') {
+        // komentar ditolak moderasi otomatis, jangan ditampilkan
+        alert('Komentar tidak dapat ditampilkan karena melanggar aturan komentar.');
+        return;
+      }
+
       setComments((prev) => [normalized, ...prev]);
       setNewComment('');
 
@@ -24,7 +24,7 @@ export async function getArticlesByCategory(categoryName) {
       return [];
     }
 
-    const data = await res.json();
+    const { data } = await res.json();
     return Array.isArray(data) ? data : [];
   } catch (error) {
     console.error("Error fetching articles:", error);
@@ -43,7 +43,7 @@ export async function getAllArticles() {
       return [];
     }
 
-    const data = await res.json();
+    const { data } = await res.json();
     return Array.isArray(data) ? data : [];
   } catch (error) {
     console.error("Error fetching articles:", error);
@@ -51,16 +51,36 @@ export async function getAllArticles() {
   }
 }
 
-export function getImageUrl(article) {
+function toAbsolute(path) {
+  return path.startsWith("http") ? path : `${API_BASE}${path}`;
+}
+
+// List pages use the resized variant (thumbnail, medium, large) when the
+// API provides one and fall back to the original upload
+export function getImageUrl(article, size = "medium") {
+  const variant = article?.gambar_utama_variants?.[size];
+  if (variant?.url) {
+    return toAbsolute(variant.url);
+  }
   if (article?.gambar_utama) {
-    if (article.gambar_utama.startsWith("http")) {
-      return article.gambar_utama;
-    }
-    return `${API_BASE}${article.gambar_utama}`;
+    return toAbsolute(article.gambar_utama);
   }
   return "/test.png";
 }
 
+// srcset string for gambar_utama, e.g. "…-thumbnail.jpg 320w, …-medium.jpg 768w"
+export function getImageSrcSet(article, format = "url") {
+  const variants = article?.gambar_utama_variants;
+  if (!variants) return undefined;
+
+  const entries = Object.values(variants)
+    .filter((v) => v[format])
+    .sort((a, b) => a.width - b.width)
+    .map((v) => `${toAbsolute(v[format])} ${v.width}w`);
+
+  return entries.length ? entries.join(", ") : undefined;
+}
+
 export function formatDate(dateString) {
   if (!dateString) return "";
   const date = new Date(dateString);